@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -11,82 +14,200 @@ import (
 	"github.com/siddhantgupta/forgetai-backend/internal/services"
 )
 
-// ClerkAuth handles JWT verification with Clerk
+// minForcedRefreshInterval limits how often an unknown key ID can force a JWKS fetch, so
+// tokens with bogus key IDs can't hammer Clerk
+const minForcedRefreshInterval = 30 * time.Second
+
+// ClerkAuth handles JWT verification with one or more Clerk instances
 type ClerkAuth struct {
-	JWKSet     jwk.Set
-	IssuerURL  string
-	LastUpdate time.Time
-	Redis      *services.RedisService
+	Issuers map[string]*ClerkIssuer // trusted issuers keyed by issuer URL
+	Redis   *services.RedisService
+	Options VerifyOptions
 }
 
-// NewClerkAuth creates a new Clerk authenticator
-func NewClerkAuth(redisService *services.RedisService, issuerURL string) (*ClerkAuth, error) {
-	if issuerURL == "" {
-		return nil, fmt.Errorf("clerk issuer URL is not set")
-	}
+// VerifyOptions hardens token verification beyond issuer and expiry
+type VerifyOptions struct {
+	// AuthorizedParties are the frontend origins tokens may be issued to, checked against
+	// the azp claim, or the aud claim when there is no azp. Empty allows any.
+	AuthorizedParties []string
+	// ClockSkew is the leeway allowed on exp and nbf for clocks out of sync with Clerk's
+	ClockSkew time.Duration
+}
 
-	auth := &ClerkAuth{
-		IssuerURL: issuerURL,
-		Redis:     redisService,
+// ClerkIssuer is a trusted Clerk instance and its signing keys
+type ClerkIssuer struct {
+	URL string
+
+	mu     sync.RWMutex
+	jwkSet jwk.Set
+
+	refreshMu  sync.Mutex // serializes forced refreshes
+	lastForced time.Time
+}
+
+// NewClerkAuth creates a new Clerk authenticator that accepts tokens from any of the
+// given issuers, e.g. separate dev and prod Clerk instances
+func NewClerkAuth(redisService *services.RedisService, issuerURLs []string, opts VerifyOptions) (*ClerkAuth, error) {
+	auth, err := NewLazyClerkAuth(redisService, issuerURLs, opts)
+	if err != nil {
+		return nil, err
 	}
 
 	// Fetch JWKs on initialization
-	if err := auth.RefreshJWKs(); err != nil {
+	if err := auth.RefreshAllJWKs(); err != nil {
 		return nil, err
 	}
 
 	return auth, nil
 }
 
-// RefreshJWKs fetches the latest JWKs from Clerk
-func (c *ClerkAuth) RefreshJWKs() error {
+// NewLazyClerkAuth creates a Clerk authenticator without loading the issuers' signing keys.
+// They're loaded by RefreshAllJWKs, or fetched when a token from the issuer is first verified.
+func NewLazyClerkAuth(redisService *services.RedisService, issuerURLs []string, opts VerifyOptions) (*ClerkAuth, error) {
+	if len(issuerURLs) == 0 {
+		return nil, fmt.Errorf("clerk issuer URL is not set")
+	}
+
+	auth := &ClerkAuth{
+		Issuers: make(map[string]*ClerkIssuer, len(issuerURLs)),
+		Redis:   redisService,
+		Options: opts,
+	}
+	for _, issuerURL := range issuerURLs {
+		auth.Issuers[issuerURL] = &ClerkIssuer{URL: issuerURL}
+	}
+
+	return auth, nil
+}
+
+// RefreshAllJWKs loads the JWKs of every issuer with RefreshJWKs
+func (c *ClerkAuth) RefreshAllJWKs() error {
+	for _, issuer := range c.Issuers {
+		if err := c.RefreshJWKs(issuer); err != nil {
+			return fmt.Errorf("%s: %v", issuer.URL, err)
+		}
+	}
+	return nil
+}
+
+// RefreshJWKs loads the JWKs for an issuer from the Redis cache, or from Clerk if they
+// aren't cached or Redis is unavailable
+func (c *ClerkAuth) RefreshJWKs(issuer *ClerkIssuer) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	// Try to get JWKs from Redis first
 	if c.Redis != nil {
-		jwksData, err := c.Redis.GetJWKs(ctx)
+		jwksData, err := c.Redis.GetJWKs(ctx, issuer.URL)
 		if err == nil && len(jwksData) > 0 {
 			set, err := jwk.Parse(jwksData)
 			if err == nil {
-				c.JWKSet = set
-				c.LastUpdate = time.Now()
+				issuer.setKeys(set)
 				return nil
 			}
 		}
 	}
 
-	// Fetch from Clerk if not in Redis
-	jwksURL := fmt.Sprintf("%s/.well-known/jwks.json", c.IssuerURL)
+	return c.fetchJWKs(ctx, issuer)
+}
+
+// RunJWKSRefresh refreshes every issuer's JWKs from Clerk until ctx is cancelled. Each
+// wait is jittered so instances started together don't refresh in lockstep.
+func (c *ClerkAuth) RunJWKSRefresh(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	for {
+		jitter := time.Duration(rand.Int63n(int64(interval)/5+1)) - interval/10
+		timer := time.NewTimer(interval + jitter)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		for _, issuer := range c.Issuers {
+			fetchCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			if err := c.fetchJWKs(fetchCtx, issuer); err != nil {
+				// Continue with existing keys if refresh fails
+				fmt.Printf("Warning: Failed to refresh JWKs for %s: %v\n", issuer.URL, err)
+			}
+			cancel()
+		}
+	}
+}
+
+// lookupKey finds a signing key by ID. Clerk rotates keys by publishing a new one, so an
+// unknown ID forces one fetch from Clerk before the token is rejected.
+func (c *ClerkAuth) lookupKey(issuer *ClerkIssuer, kid string) (jwk.Key, bool) {
+	if key, found := issuer.keys().LookupKeyID(kid); found {
+		return key, true
+	}
+
+	issuer.refreshMu.Lock()
+	defer issuer.refreshMu.Unlock()
+
+	// Another request may have fetched the new keys while this one waited
+	if key, found := issuer.keys().LookupKeyID(kid); found {
+		return key, true
+	}
+	if time.Since(issuer.lastForced) < minForcedRefreshInterval {
+		return nil, false
+	}
+	issuer.lastForced = time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.fetchJWKs(ctx, issuer); err != nil {
+		fmt.Printf("Warning: Failed to refresh JWKs for %s: %v\n", issuer.URL, err)
+		return nil, false
+	}
+	return issuer.keys().LookupKeyID(kid)
+}
+
+// fetchJWKs fetches the latest JWKs for an issuer from Clerk and caches them in Redis
+func (c *ClerkAuth) fetchJWKs(ctx context.Context, issuer *ClerkIssuer) error {
+	jwksURL := fmt.Sprintf("%s/.well-known/jwks.json", issuer.URL)
 	set, err := jwk.Fetch(ctx, jwksURL)
 	if err != nil {
 		return fmt.Errorf("failed to fetch JWKs: %v", err)
 	}
 
-	c.JWKSet = set
-	c.LastUpdate = time.Now()
+	issuer.setKeys(set)
 
 	// Store in Redis for future use
 	if c.Redis != nil {
 		jwksJSON, err := json.Marshal(set)
 		if err == nil {
-			c.Redis.StoreJWKs(ctx, jwksJSON)
+			c.Redis.StoreJWKs(ctx, issuer.URL, jwksJSON)
 		}
 	}
 
 	return nil
 }
 
-// VerifyToken verifies a JWT token from Clerk
-func (c *ClerkAuth) VerifyToken(tokenString string) (jwt.MapClaims, error) {
-	// Check if JWKs need refreshing (every 30 minutes instead of 24 hours)
-	if time.Since(c.LastUpdate) > 30*time.Minute {
-		if err := c.RefreshJWKs(); err != nil {
-			// Continue with existing keys if refresh fails
-			fmt.Printf("Warning: Failed to refresh JWKs: %v\n", err)
-		}
+// setKeys replaces the issuer's signing keys
+func (i *ClerkIssuer) setKeys(set jwk.Set) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.jwkSet = set
+}
+
+// keys returns the issuer's signing keys, an empty set until they're loaded
+func (i *ClerkIssuer) keys() jwk.Set {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	if i.jwkSet == nil {
+		return jwk.NewSet()
 	}
+	return i.jwkSet
+}
 
+// VerifyToken verifies a JWT token from one of the trusted Clerk issuers, using the
+// signing keys of the issuer named in the token
+func (c *ClerkAuth) VerifyToken(tokenString string) (jwt.MapClaims, error) {
 	// Parse the token
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		// Validate the algorithm
@@ -100,8 +221,16 @@ func (c *ClerkAuth) VerifyToken(tokenString string) (jwt.MapClaims, error) {
 			return nil, fmt.Errorf("kid header not found in token")
 		}
 
+		// Select the issuer's keys; the signature check below proves the issuer claim
+		claims, _ := token.Claims.(jwt.MapClaims)
+		issuerURL, _ := claims["iss"].(string)
+		issuer, ok := c.Issuers[issuerURL]
+		if !ok {
+			return nil, fmt.Errorf("untrusted issuer %q", issuerURL)
+		}
+
 		// Find the key with matching kid
-		if key, found := c.JWKSet.LookupKeyID(kid); found {
+		if key, found := c.lookupKey(issuer, kid); found {
 			var rawKey interface{}
 			if err := key.Raw(&rawKey); err != nil {
 				return nil, fmt.Errorf("failed to get raw key: %v", err)
@@ -110,7 +239,7 @@ func (c *ClerkAuth) VerifyToken(tokenString string) (jwt.MapClaims, error) {
 		}
 
 		return nil, fmt.Errorf("key with ID %s not found", kid)
-	})
+	}, jwt.WithLeeway(c.Options.ClockSkew), jwt.WithExpirationRequired())
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token: %v", err)
@@ -126,14 +255,65 @@ func (c *ClerkAuth) VerifyToken(tokenString string) (jwt.MapClaims, error) {
 	}
 
 	issuer, ok := claims["iss"].(string)
-	if !ok || issuer != c.IssuerURL {
+	if _, trusted := c.Issuers[issuer]; !ok || !trusted {
 		return nil, fmt.Errorf("invalid issuer")
 	}
 
-	exp, ok := claims["exp"].(float64) // JWT expiry is usually a float64 timestamp
-	if !ok || time.Now().Unix() > int64(exp) {
-		return nil, fmt.Errorf("token expired")
+	// exp and nbf are checked by the parser, within the configured clock skew
+	if err := c.checkAuthorizedParty(claims); err != nil {
+		return nil, err
 	}
 
 	return claims, nil
 }
+
+// checkAuthorizedParty checks the token was issued to one of the configured frontend
+// origins: the azp claim if present, otherwise any of the aud values
+func (c *ClerkAuth) checkAuthorizedParty(claims jwt.MapClaims) error {
+	if len(c.Options.AuthorizedParties) == 0 {
+		return nil
+	}
+
+	allowed := func(party string) bool {
+		party = strings.TrimRight(party, "/")
+		for _, authorized := range c.Options.AuthorizedParties {
+			if party == authorized {
+				return true
+			}
+		}
+		return false
+	}
+
+	if azp, ok := claims["azp"].(string); ok {
+		if !allowed(azp) {
+			return fmt.Errorf("unauthorized party %q", azp)
+		}
+		return nil
+	}
+
+	audience, err := claims.GetAudience()
+	if err != nil {
+		return fmt.Errorf("invalid audience: %v", err)
+	}
+	for _, aud := range audience {
+		if allowed(aud) {
+			return nil
+		}
+	}
+	return fmt.Errorf("token has no authorized party")
+}
+
+// safeClaims are the claims that may be shown back to the token's owner. Anything else,
+// such as custom session claims, is left out.
+var safeClaims = []string{"iss", "sub", "azp", "sid", "plan", "iat", "nbf", "exp"}
+
+// SafeClaims returns the subset of verified claims that is safe to return in responses
+func SafeClaims(claims jwt.MapClaims) map[string]interface{} {
+	safe := make(map[string]interface{}, len(safeClaims))
+	for _, name := range safeClaims {
+		if value, ok := claims[name]; ok {
+			safe[name] = value
+		}
+	}
+	return safe
+}
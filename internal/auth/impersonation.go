@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// ImpersonationHeader carries an impersonation token in place of a Clerk token
+	ImpersonationHeader = "X-Impersonation-Token"
+	// impersonationTokenAudience keeps impersonation tokens from being accepted anywhere
+	// else tokens are signed with the same secret
+	impersonationTokenAudience = "forgetai-impersonation"
+)
+
+// impersonationReadOnlyRoutes are the non-GET routes allowed while impersonating because
+// they only read the user's data
+var impersonationReadOnlyRoutes = map[string]bool{
+	"POST /api/query":         true,
+	"POST /api/query/explain": true,
+}
+
+// Impersonation describes an admin acting on behalf of a user in read-only mode
+type Impersonation struct {
+	TokenID   string    `json:"token_id"`
+	UserID    string    `json:"user_id"`
+	Admin     string    `json:"admin"`
+	Reason    string    `json:"reason"`
+	Plan      string    `json:"plan,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// impersonationClaims are the claims of a signed impersonation token
+type impersonationClaims struct {
+	Admin  string `json:"admin"`
+	Reason string `json:"reason"`
+	Plan   string `json:"plan,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Impersonator signs and verifies support tokens that let an admin issue read-only
+// requests as a user
+type Impersonator struct {
+	secret []byte
+}
+
+// NewImpersonator creates an impersonation token signer, or returns nil when no secret
+// is configured
+func NewImpersonator(secret string) *Impersonator {
+	if secret == "" {
+		return nil
+	}
+	return &Impersonator{secret: []byte(secret)}
+}
+
+// SignToken creates an HS256 token for the admin to act as the user until ttl passes
+func (i *Impersonator) SignToken(userID, admin, reason, plan string, ttl time.Duration) (string, *Impersonation, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", nil, fmt.Errorf("failed to generate token ID: %v", err)
+	}
+
+	now := time.Now()
+	impersonation := &Impersonation{
+		TokenID:   hex.EncodeToString(id),
+		UserID:    userID,
+		Admin:     admin,
+		Reason:    reason,
+		Plan:      plan,
+		ExpiresAt: now.Add(ttl).Truncate(time.Second),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, impersonationClaims{
+		Admin:  admin,
+		Reason: reason,
+		Plan:   plan,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        impersonation.TokenID,
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings{impersonationTokenAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(impersonation.ExpiresAt),
+		},
+	})
+	signed, err := token.SignedString(i.secret)
+	if err != nil {
+		return "", nil, err
+	}
+	return signed, impersonation, nil
+}
+
+// VerifyToken verifies an impersonation token
+func (i *Impersonator) VerifyToken(tokenString string) (*Impersonation, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &impersonationClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return i.secret, nil
+	},
+		jwt.WithAudience(impersonationTokenAudience),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(internalClockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse token: %v", err)
+	}
+
+	claims, ok := token.Claims.(*impersonationClaims)
+	if !ok || claims.Subject == "" || claims.Admin == "" {
+		return nil, fmt.Errorf("user or admin not found in token")
+	}
+	return &Impersonation{
+		TokenID:   claims.ID,
+		UserID:    claims.Subject,
+		Admin:     claims.Admin,
+		Reason:    claims.Reason,
+		Plan:      claims.Plan,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}, nil
+}
+
+// allowedWhileImpersonating reports whether a request only reads the user's data
+func allowedWhileImpersonating(c *gin.Context) bool {
+	method := c.Request.Method
+	if method == http.MethodGet || method == http.MethodHead {
+		return true
+	}
+	return impersonationReadOnlyRoutes[method+" "+c.FullPath()]
+}
+
+// GetImpersonation returns the impersonation a request is made under, or nil for
+// requests made by the user themselves
+func GetImpersonation(c *gin.Context) *Impersonation {
+	value, exists := c.Get("impersonation")
+	if !exists {
+		return nil
+	}
+	impersonation, _ := value.(*Impersonation)
+	return impersonation
+}
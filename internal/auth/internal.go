@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+)
+
+const (
+	// internalTokenAudience is the audience of internal tokens, so tokens signed with the
+	// same secret for another purpose are not accepted
+	internalTokenAudience = "forgetai-internal"
+	// internalClockSkew is the leeway allowed on internal token expiry
+	internalClockSkew = 30 * time.Second
+)
+
+// InternalAuth signs and verifies tokens that let background infrastructure, such as
+// task queues and schedulers, call worker endpoints without a Clerk user token
+type InternalAuth struct {
+	secret []byte
+}
+
+// NewInternalAuth creates an internal token authenticator, or returns nil when no
+// secret is configured
+func NewInternalAuth(secret string) *InternalAuth {
+	if secret == "" {
+		return nil
+	}
+	return &InternalAuth{secret: []byte(secret)}
+}
+
+// SignToken creates an HS256 token identifying the calling service that expires after ttl
+func (a *InternalAuth) SignToken(service string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   service,
+		Audience:  jwt.ClaimStrings{internalTokenAudience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+	})
+	return token.SignedString(a.secret)
+}
+
+// VerifyToken verifies an internal token and returns the calling service's name
+func (a *InternalAuth) VerifyToken(tokenString string) (string, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return a.secret, nil
+	},
+		jwt.WithAudience(internalTokenAudience),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(internalClockSkew),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse token: %v", err)
+	}
+
+	claims, ok := token.Claims.(*jwt.RegisteredClaims)
+	if !ok || claims.Subject == "" {
+		return "", fmt.Errorf("service not found in token")
+	}
+	return claims.Subject, nil
+}
+
+// InternalAuthMiddleware creates a middleware that only admits requests with a valid
+// internal token, setting the calling service's name in the context
+func InternalAuthMiddleware(internalAuth *InternalAuth) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if internalAuth == nil {
+			i18n.RespondError(c, http.StatusServiceUnavailable, nil, "Internal authentication is not configured")
+			c.Abort()
+			return
+		}
+
+		authHeader := c.GetHeader("Authorization")
+		if !strings.HasPrefix(authHeader, "Bearer ") {
+			i18n.RespondError(c, http.StatusUnauthorized, nil, "Authorization header must be Bearer token")
+			c.Abort()
+			return
+		}
+
+		service, err := internalAuth.VerifyToken(strings.TrimPrefix(authHeader, "Bearer "))
+		if err != nil {
+			i18n.RespondError(c, http.StatusUnauthorized, err, "Invalid token")
+			c.Abort()
+			return
+		}
+
+		c.Set("service", service)
+		c.Next()
+	}
+}
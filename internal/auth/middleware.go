@@ -1,27 +1,37 @@
 package auth
 
 import (
+	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
 	"github.com/siddhantgupta/forgetai-backend/internal/services"
+	"github.com/siddhantgupta/forgetai-backend/internal/utils"
 )
 
-// AuthMiddleware creates a middleware for Clerk authentication
-func AuthMiddleware(clerkAuth *ClerkAuth) gin.HandlerFunc {
+// AuthMiddleware creates a middleware for Clerk authentication. Requests carrying an
+// impersonation token instead are made as the impersonated user and limited to reads.
+func AuthMiddleware(clerkAuth *ClerkAuth, impersonator *Impersonator) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if token := c.GetHeader(ImpersonationHeader); token != "" {
+			impersonate(c, impersonator, token)
+			return
+		}
+
 		// Get the Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+			i18n.RespondError(c, http.StatusUnauthorized, nil, "Authorization header is required")
 			c.Abort()
 			return
 		}
 
 		// Check for Bearer token format
 		if !strings.HasPrefix(authHeader, "Bearer ") {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header must be Bearer token"})
+			i18n.RespondError(c, http.StatusUnauthorized, nil, "Authorization header must be Bearer token")
 			c.Abort()
 			return
 		}
@@ -32,7 +42,7 @@ func AuthMiddleware(clerkAuth *ClerkAuth) gin.HandlerFunc {
 		// Verify the token
 		claims, err := clerkAuth.VerifyToken(token)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token: " + err.Error()})
+			i18n.RespondError(c, http.StatusUnauthorized, err, "Invalid token")
 			c.Abort()
 			return
 		}
@@ -40,17 +50,80 @@ func AuthMiddleware(clerkAuth *ClerkAuth) gin.HandlerFunc {
 		// Get user ID from claims
 		userId, ok := claims["sub"].(string)
 		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+			i18n.RespondError(c, http.StatusUnauthorized, nil, "User ID not found in token")
 			c.Abort()
 			return
 		}
 
-		// Set user ID in context for downstream handlers
+		// Set user ID and verified claims in context for downstream handlers
 		c.Set("userId", userId)
+		c.Set("claims", claims)
+		c.Request = c.Request.WithContext(services.WithBillingUser(c.Request.Context(), userId))
+
+		// The plan claim selects storage quotas; users without one get the default plan
+		if plan, ok := claims["plan"].(string); ok {
+			c.Set("plan", plan)
+		}
 		c.Next()
 	}
 }
 
+// PublicRateLimitMiddleware limits unauthenticated requests by client address
+func PublicRateLimitMiddleware(redisService *services.RedisService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_, exceeded, err := redisService.CheckPublicRateLimit(c.Request.Context(), "client:"+c.ClientIP(), services.PublicClientHourlyLimit, time.Hour)
+		if err != nil {
+			// Let the request through if there's an issue with rate limiting
+			c.Next()
+			return
+		}
+
+		if exceeded {
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error":       i18n.T(c, "Rate limit exceeded. Maximum %d requests per hour.", services.PublicClientHourlyLimit),
+				"code":        i18n.ErrorCode(http.StatusTooManyRequests),
+				"limit":       services.PublicClientHourlyLimit,
+				"retry_after": i18n.T(c, "Try again later"),
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// impersonate authenticates a request with an impersonation token
+func impersonate(c *gin.Context, impersonator *Impersonator, token string) {
+	if impersonator == nil {
+		i18n.RespondError(c, http.StatusServiceUnavailable, nil, "Impersonation is not configured")
+		c.Abort()
+		return
+	}
+
+	impersonation, err := impersonator.VerifyToken(token)
+	if err != nil {
+		i18n.RespondError(c, http.StatusUnauthorized, err, "Invalid token")
+		c.Abort()
+		return
+	}
+
+	if !allowedWhileImpersonating(c) {
+		i18n.RespondError(c, http.StatusForbidden, nil, "Impersonated requests are read-only")
+		c.Abort()
+		return
+	}
+
+	fmt.Printf("Impersonation: %s %s as user %s by %s (token %s)\n",
+		c.Request.Method, c.Request.URL.Path, impersonation.UserID, impersonation.Admin, impersonation.TokenID)
+
+	c.Set("userId", impersonation.UserID)
+	c.Set("impersonation", impersonation)
+	if impersonation.Plan != "" {
+		c.Set("plan", impersonation.Plan)
+	}
+	c.Next()
+}
+
 // RateLimitMiddleware creates a middleware for rate limiting
 func RateLimitMiddleware(redisService *services.RedisService) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -61,6 +134,12 @@ func RateLimitMiddleware(redisService *services.RedisService) gin.HandlerFunc {
 			return
 		}
 
+		// Support requests don't use up the user's daily allowance
+		if GetImpersonation(c) != nil {
+			c.Next()
+			return
+		}
+
 		// Extract endpoint from request path
 		path := c.Request.URL.Path
 		endpoint := strings.TrimPrefix(path, "/api/")
@@ -68,8 +147,12 @@ func RateLimitMiddleware(redisService *services.RedisService) gin.HandlerFunc {
 			endpoint = endpoint[:idx] // Only use the first part of the path
 		}
 
+		// Collect warnings raised while handling the request, e.g. storage quotas nearly full
+		ctx := services.WithWarnings(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+
 		// Check rate limit
-		exceeded, err := redisService.CheckRateLimit(c.Request.Context(), userId.(string), endpoint)
+		count, exceeded, err := redisService.CheckRateLimit(ctx, userId.(string), endpoint)
 		if err != nil {
 			// Log error but let request through if there's an issue with rate limiting
 			c.Next()
@@ -77,17 +160,30 @@ func RateLimitMiddleware(redisService *services.RedisService) gin.HandlerFunc {
 		}
 
 		if exceeded {
-			count, _ := redisService.GetRateLimitCount(c.Request.Context(), userId.(string), endpoint)
 			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error":       "Rate limit exceeded. Maximum 10 requests per API endpoint per day.",
-				"limit":       10,
+				"error":       i18n.T(c, "Rate limit exceeded. Maximum %d requests per API endpoint per day.", services.DailyRateLimit),
+				"code":        i18n.ErrorCode(http.StatusTooManyRequests),
+				"limit":       services.DailyRateLimit,
 				"count":       count,
-				"retry_after": "Try again tomorrow",
+				"retry_after": i18n.T(c, "Try again tomorrow"),
 			})
 			c.Abort()
 			return
 		}
 
+		// Counted calls are recorded in the user's usage history by the metrics middleware
+		c.Set("rateLimitEndpoint", endpoint)
+
+		if float64(count) >= services.WarningThreshold*services.DailyRateLimit {
+			services.AddWarning(ctx, "%d %s request(s) left today", services.DailyRateLimit-count, endpoint)
+		}
+
+		buffer := utils.NewResponseBuffer(c.Writer)
+		c.Writer = buffer
+		defer func() {
+			c.Writer = buffer.ResponseWriter
+			flushWithWarnings(c, buffer, services.Warnings(ctx))
+		}()
 		c.Next()
 	}
 }
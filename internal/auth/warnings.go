@@ -0,0 +1,22 @@
+package auth
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+	"github.com/siddhantgupta/forgetai-backend/internal/utils"
+)
+
+// flushWithWarnings writes a held response, adding a translated "warnings" array to
+// successful JSON object bodies
+func flushWithWarnings(c *gin.Context, buffer *utils.ResponseBuffer, warnings []services.Warning) {
+	body := buffer.Body()
+	if len(warnings) > 0 && buffer.Status() >= 200 && buffer.Status() < 300 {
+		messages := make([]string, 0, len(warnings))
+		for _, warning := range warnings {
+			messages = append(messages, i18n.T(c, warning.Message, warning.Args...))
+		}
+		body = utils.AddJSONField(body, "warnings", messages)
+	}
+	buffer.Commit(body)
+}
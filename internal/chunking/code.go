@@ -0,0 +1,221 @@
+package chunking
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/siddhantgupta/forgetai-backend/internal/textnorm"
+)
+
+// DefaultCodeChunkSize is the target maximum size of a code chunk in characters
+const DefaultCodeChunkSize = 1500
+
+// blockStarts matches lines that begin a top-level definition, per language
+var blockStarts = map[string]*regexp.Regexp{
+	"go":         regexp.MustCompile(`^(func|type|var|const)\b`),
+	"python":     regexp.MustCompile(`^(def|class|async def)\b`),
+	"javascript": regexp.MustCompile(`^(export\s+)?(default\s+)?(async\s+)?(function|class|const|let|var)\b`),
+	"typescript": regexp.MustCompile(`^(export\s+)?(default\s+)?(async\s+)?(function|class|const|let|var|interface|type|enum)\b`),
+	"java":       regexp.MustCompile(`^\s{0,4}(public|private|protected|static|final|abstract|class|interface|enum)\b`),
+	"rust":       regexp.MustCompile(`^(pub(\([^)]*\))?\s+)?(fn|struct|enum|impl|trait|mod|type|const|static)\b`),
+	"ruby":       regexp.MustCompile(`^\s{0,2}(def|class|module)\b`),
+	"c":          regexp.MustCompile(`^[A-Za-z_][\w\s\*]*\([^;]*\)\s*\{?\s*$|^(struct|typedef|#define)\b`),
+	"cpp":        regexp.MustCompile(`^[A-Za-z_][\w\s\*:<>&]*\([^;]*\)\s*(const)?\s*\{?\s*$|^(class|struct|namespace|template|typedef|#define)\b`),
+	"sql":        regexp.MustCompile(`(?i)^(select|insert|update|delete|create|alter|drop|with)\b`),
+	"shell":      regexp.MustCompile(`^([A-Za-z_][\w-]*\s*\(\)\s*\{|function\s+)`),
+}
+
+// languageAliases maps common names and file extensions to canonical languages
+var languageAliases = map[string]string{
+	"golang": "go",
+	"py":     "python",
+	"js":     "javascript",
+	"jsx":    "javascript",
+	"node":   "javascript",
+	"ts":     "typescript",
+	"tsx":    "typescript",
+	"rs":     "rust",
+	"rb":     "ruby",
+	"h":      "c",
+	"c++":    "cpp",
+	"cc":     "cpp",
+	"hpp":    "cpp",
+	"sh":     "shell",
+	"bash":   "shell",
+	"zsh":    "shell",
+	"kotlin": "java",
+	"kt":     "java",
+	"scala":  "java",
+	"csharp": "java",
+	"cs":     "java",
+}
+
+// NormalizeLanguage returns the canonical lowercase name for a language or extension
+func NormalizeLanguage(language string) string {
+	language = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(language, ".")))
+	if canonical, ok := languageAliases[language]; ok {
+		return canonical
+	}
+	return language
+}
+
+// ChunkCode splits source code into chunks on function/block boundaries. Consecutive
+// small blocks are merged up to maxChars, and oversized blocks are split on line breaks.
+func ChunkCode(code, language string, maxChars int) []string {
+	if maxChars <= 0 {
+		maxChars = DefaultCodeChunkSize
+	}
+	code = textnorm.NormalizeCode(code)
+
+	var chunks []string
+	var current strings.Builder
+	flush := func() {
+		if text := strings.Trim(current.String(), "\n"); strings.TrimSpace(text) != "" {
+			chunks = append(chunks, text)
+		}
+		current.Reset()
+	}
+
+	for _, block := range splitBlocks(code, NormalizeLanguage(language)) {
+		if len(block) > maxChars {
+			flush()
+			chunks = append(chunks, splitLines(block, maxChars)...)
+			continue
+		}
+		if current.Len() > 0 && current.Len()+len(block) > maxChars {
+			flush()
+		}
+		current.WriteString(block)
+	}
+	flush()
+
+	return chunks
+}
+
+// splitBlocks splits code into top-level blocks. A block starts at a line matching the
+// language's definition pattern (or, for unknown languages, after a blank line) while
+// outside any brackets. Leading comments stay attached to the definition they precede.
+func splitBlocks(code, language string) []string {
+	starts := blockStarts[language]
+	lines := strings.SplitAfter(code, "\n")
+
+	var blocks []string
+	var current strings.Builder
+	var pending strings.Builder // comments and blank lines awaiting the next block
+	depth := 0
+	prevBlank := false
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		isComment := isCommentLine(trimmed, language)
+
+		boundary := false
+		if depth <= 0 && trimmed != "" && !isComment {
+			if starts != nil {
+				boundary = starts.MatchString(line)
+			} else {
+				boundary = prevBlank
+			}
+		}
+
+		if boundary && current.Len() > 0 {
+			blocks = append(blocks, current.String())
+			current.Reset()
+		}
+
+		if depth <= 0 && (trimmed == "" || isComment) && current.Len() > 0 && starts != nil {
+			// Hold comments until we know whether they introduce a new block
+			pending.WriteString(line)
+		} else {
+			// Pending comments belong to the new block on a boundary, otherwise to the current one
+			current.WriteString(pending.String())
+			pending.Reset()
+			current.WriteString(line)
+		}
+
+		depth += bracketDelta(line)
+		prevBlank = trimmed == ""
+	}
+
+	current.WriteString(pending.String())
+	if current.Len() > 0 {
+		blocks = append(blocks, current.String())
+	}
+
+	return blocks
+}
+
+// bracketDelta returns the net change in bracket depth for a line, ignoring string contents
+func bracketDelta(line string) int {
+	delta := 0
+	var quote rune
+	escaped := false
+	for _, r := range line {
+		if quote != 0 {
+			switch {
+			case escaped:
+				escaped = false
+			case r == '\\':
+				escaped = true
+			case r == quote:
+				quote = 0
+			}
+			continue
+		}
+		switch r {
+		case '"', '\'', '`':
+			quote = r
+		case '{', '(', '[':
+			delta++
+		case '}', ')', ']':
+			delta--
+		}
+	}
+	return delta
+}
+
+// splitLines splits text into pieces of at most maxChars, breaking only on newlines
+// unless a single line is longer than maxChars
+func splitLines(text string, maxChars int) []string {
+	var pieces []string
+	var current strings.Builder
+	for _, line := range strings.SplitAfter(text, "\n") {
+		for len(line) > maxChars {
+			if current.Len() > 0 {
+				pieces = append(pieces, strings.TrimRight(current.String(), "\n"))
+				current.Reset()
+			}
+			var piece string
+			piece, line = cutAt(line, maxChars)
+			pieces = append(pieces, piece)
+		}
+		if current.Len() > 0 && current.Len()+len(line) > maxChars {
+			pieces = append(pieces, strings.TrimRight(current.String(), "\n"))
+			current.Reset()
+		}
+		current.WriteString(line)
+	}
+	if strings.TrimSpace(current.String()) != "" {
+		pieces = append(pieces, strings.TrimRight(current.String(), "\n"))
+	}
+	return pieces
+}
+
+// isCommentLine reports whether a trimmed line is a comment, or an annotation that
+// like a comment belongs to the definition after it. In C-like languages '#' starts a
+// directive rather than a comment.
+func isCommentLine(trimmed, language string) bool {
+	switch {
+	case strings.HasPrefix(trimmed, "@"):
+		return language == "python" || language == "java"
+	case strings.HasPrefix(trimmed, "#["):
+		return language == "rust"
+	case strings.HasPrefix(trimmed, "//"), strings.HasPrefix(trimmed, "/*"), strings.HasPrefix(trimmed, "*"):
+		return true
+	case strings.HasPrefix(trimmed, "--"):
+		return language == "sql"
+	case strings.HasPrefix(trimmed, "#"):
+		return language != "c" && language != "cpp" && language != "rust"
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package chunking
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/siddhantgupta/forgetai-backend/internal/textnorm"
+)
+
+// atxHeading matches a markdown heading line such as "## Decisions" or "# Title #"
+var atxHeading = regexp.MustCompile(`^ {0,3}(#{1,6})[ \t]+(.*?)(?:[ \t]+#+)?[ \t]*$`)
+
+// MarkdownChunk is a chunk of a markdown document and the headings it falls under
+type MarkdownChunk struct {
+	Text     string
+	Headings []string // enclosing headings from the top level down, e.g. ["Project", "Decisions"]
+}
+
+// ChunkMarkdown splits a markdown document into chunks that don't cross headings. Each
+// section, a heading and the text up to the next heading, is chunked like prose with
+// ChunkText, and its chunks carry the path of headings they fall under. Headings with no
+// text of their own before the next heading start the next section's first chunk, so
+// joining the chunks restores the document. Lines in fenced code blocks are never headings.
+func ChunkMarkdown(text string, maxChars int) []MarkdownChunk {
+	text = textnorm.Normalize(text)
+
+	type heading struct {
+		level int
+		title string
+	}
+	var (
+		chunks  []MarkdownChunk
+		open    []heading // enclosing headings of the current line
+		lines   []string  // lines of the current section
+		path    []string  // heading path of the current section
+		hasText bool      // the current section has text besides headings
+		fence   string    // marker of the fenced code block the current line is in
+	)
+	flush := func() {
+		for _, chunk := range ChunkText(strings.Join(lines, "\n"), maxChars) {
+			chunks = append(chunks, MarkdownChunk{Text: chunk, Headings: path})
+		}
+		lines = nil
+		hasText = false
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fence == "" && (strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")) {
+			fence = trimmed[:3]
+		} else if fence != "" && strings.HasPrefix(trimmed, fence) {
+			fence = ""
+		} else if match := atxHeading.FindStringSubmatch(line); fence == "" && match != nil && match[2] != "" {
+			if hasText {
+				flush()
+			}
+			level := len(match[1])
+			for len(open) > 0 && open[len(open)-1].level >= level {
+				open = open[:len(open)-1]
+			}
+			open = append(open, heading{level: level, title: match[2]})
+
+			path = make([]string, len(open))
+			for i, h := range open {
+				path[i] = h.title
+			}
+			lines = append(lines, line)
+			continue
+		}
+
+		if trimmed != "" {
+			hasText = true
+		}
+		lines = append(lines, line)
+	}
+	flush()
+
+	return chunks
+}
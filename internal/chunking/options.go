@@ -0,0 +1,83 @@
+package chunking
+
+import "fmt"
+
+// Chunking strategies for prose documents
+const (
+	// StrategyCharacters merges paragraphs into chunks of up to Size characters
+	StrategyCharacters = "characters"
+	// StrategyTokens merges paragraphs into chunks of up to Size estimated tokens
+	StrategyTokens = "tokens"
+	// StrategyHeadings splits markdown at its headings first, then sections by characters
+	StrategyHeadings = "headings"
+)
+
+// CharsPerToken is the average characters per embedding token of English text, used to
+// size chunks of the tokens strategy and estimate embedding costs
+const CharsPerToken = 4
+
+// Bounds on chunk sizes, in characters
+const (
+	MinChunkSize = 100
+	MaxChunkSize = 8000
+)
+
+// Options are the parameters prose documents are chunked with
+type Options struct {
+	Strategy string // "" for the document type's default: headings for markdown, characters otherwise
+	Size     int    // maximum chunk size, in tokens for StrategyTokens and characters otherwise
+	Overlap  int    // maximum context repeated from the previous chunk when embedding, in the same unit
+}
+
+// DefaultOptions are the chunking parameters used when none are configured
+var DefaultOptions = Options{Size: DefaultTextChunkSize, Overlap: DefaultTextChunkOverlap}
+
+// WithStrategy returns the options with another strategy, converting the sizes to its unit
+func (o Options) WithStrategy(strategy string) Options {
+	chars, overlapChars := o.MaxChars(), o.OverlapChars()
+	o.Strategy = strategy
+	o.Size, o.Overlap = chars, overlapChars
+	if strategy == StrategyTokens {
+		o.Size, o.Overlap = chars/CharsPerToken, overlapChars/CharsPerToken
+	}
+	return o
+}
+
+// Validate checks that the strategy is known and the sizes are within bounds
+func (o Options) Validate() error {
+	switch o.Strategy {
+	case "", StrategyCharacters, StrategyTokens, StrategyHeadings:
+	default:
+		return fmt.Errorf("unknown chunking strategy %q", o.Strategy)
+	}
+	if size := o.MaxChars(); size < MinChunkSize || size > MaxChunkSize {
+		return fmt.Errorf("chunk size must be between %d and %d characters (%d and %d tokens)",
+			MinChunkSize, MaxChunkSize, MinChunkSize/CharsPerToken, MaxChunkSize/CharsPerToken)
+	}
+	if o.Overlap < 0 || o.Overlap >= o.Size {
+		return fmt.Errorf("chunk overlap must be at least 0 and less than the chunk size")
+	}
+	return nil
+}
+
+// MaxChars returns the maximum chunk size in characters
+func (o Options) MaxChars() int {
+	if o.Strategy == StrategyTokens {
+		return o.Size * CharsPerToken
+	}
+	return o.Size
+}
+
+// OverlapChars returns the maximum overlap in characters
+func (o Options) OverlapChars() int {
+	if o.Strategy == StrategyTokens {
+		return o.Overlap * CharsPerToken
+	}
+	return o.Overlap
+}
+
+// SplitsHeadings reports whether a document of the given type is split at its markdown
+// headings
+func (o Options) SplitsHeadings(dataType string) bool {
+	return o.Strategy == StrategyHeadings || (o.Strategy == "" && dataType == "markdown")
+}
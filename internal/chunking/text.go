@@ -0,0 +1,149 @@
+package chunking
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/siddhantgupta/forgetai-backend/internal/textnorm"
+)
+
+// DefaultTextChunkSize is the target maximum size of a prose chunk in characters
+const DefaultTextChunkSize = 1000
+
+// DefaultTextChunkOverlap is the maximum size in characters of the context repeated from
+// the end of one prose chunk when embedding the next
+const DefaultTextChunkOverlap = 200
+
+// ChunkText splits prose or markdown into chunks on paragraph boundaries. Consecutive
+// paragraphs are merged up to maxChars, and oversized paragraphs are split between
+// sentences, or on line breaks when a single sentence is too long. The text is
+// normalized first, so differently formatted copies of a text chunk identically.
+func ChunkText(text string, maxChars int) []string {
+	if maxChars <= 0 {
+		maxChars = DefaultTextChunkSize
+	}
+	text = textnorm.Normalize(text)
+
+	var chunks []string
+	var current strings.Builder
+	flush := func() {
+		if chunk := strings.TrimSpace(current.String()); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		current.Reset()
+	}
+
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		paragraph = strings.Trim(paragraph, "\n")
+		if strings.TrimSpace(paragraph) == "" {
+			continue
+		}
+		if len(paragraph) > maxChars {
+			flush()
+			chunks = append(chunks, splitSentences(paragraph, maxChars)...)
+			continue
+		}
+		if current.Len() > 0 && current.Len()+len(paragraph)+2 > maxChars {
+			flush()
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n\n")
+		}
+		current.WriteString(paragraph)
+	}
+	flush()
+
+	return chunks
+}
+
+// Overlap returns the trailing whole sentences of a chunk that fit in maxChars, to be
+// repeated as context before the next chunk. It returns "" if even the last sentence is
+// longer than maxChars.
+func Overlap(chunk string, maxChars int) string {
+	if maxChars <= 0 {
+		return ""
+	}
+	sentences := sentences(chunk)
+	start := len(sentences)
+	size := 0
+	for start > 0 && size+len(sentences[start-1]) <= maxChars {
+		start--
+		size += len(sentences[start])
+	}
+	return strings.TrimSpace(strings.Join(sentences[start:], ""))
+}
+
+// splitSentences splits text into pieces of at most maxChars, breaking between sentences
+// unless a single sentence is longer than maxChars
+func splitSentences(text string, maxChars int) []string {
+	var pieces []string
+	var current strings.Builder
+	flush := func() {
+		if piece := strings.TrimSpace(current.String()); piece != "" {
+			pieces = append(pieces, piece)
+		}
+		current.Reset()
+	}
+
+	for _, sentence := range sentences(text) {
+		if len(sentence) > maxChars {
+			flush()
+			pieces = append(pieces, splitLines(strings.TrimSpace(sentence), maxChars)...)
+			continue
+		}
+		if current.Len() > 0 && current.Len()+len(strings.TrimRightFunc(sentence, unicode.IsSpace)) > maxChars {
+			flush()
+		}
+		current.WriteString(sentence)
+	}
+	flush()
+
+	return pieces
+}
+
+// sentences splits text after each sentence-ending '.', '!' or '?' that is followed by
+// whitespace. Each sentence keeps its trailing whitespace, so joining them restores the text.
+func sentences(text string) []string {
+	var result []string
+	start := 0
+	for i := 0; i < len(text)-1; i++ {
+		switch text[i] {
+		case '.', '!', '?':
+		default:
+			continue
+		}
+		if next := text[i+1]; next != ' ' && next != '\n' && next != '\t' {
+			continue
+		}
+		end := i + 1
+		for end < len(text) && (text[end] == ' ' || text[end] == '\n' || text[end] == '\t') {
+			end++
+		}
+		result = append(result, text[start:end])
+		start = end
+		i = end - 1
+	}
+	if start < len(text) {
+		result = append(result, text[start:])
+	}
+	return result
+}
+
+// cutAt returns the longest prefix of s that is at most maxBytes long and does not end
+// inside a UTF-8 sequence, and the remainder
+func cutAt(s string, maxBytes int) (string, string) {
+	if len(s) <= maxBytes {
+		return s, ""
+	}
+	end := maxBytes
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+	if end == 0 {
+		// A single rune longer than maxBytes; keep it whole
+		_, size := utf8.DecodeRuneInString(s)
+		end = size
+	}
+	return s[:end], s[end:]
+}
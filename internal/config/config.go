@@ -3,8 +3,11 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/siddhantgupta/forgetai-backend/internal/chunking"
 )
 
 // Config holds all configuration for the application
@@ -12,12 +15,235 @@ type Config struct {
 	Port              string
 	OpenAIAPIKey      string
 	PineconeAPIKey    string
-	PineconeIndexHost string
-	ClerkIssuerURL    string
-	RedisURL          string
+	PineconeIndexHost string   // optional when PineconeIndexName is set
+	ClerkIssuerURLs   []string // comma-separated in CLERK_ISSUER_URL, e.g. dev and prod instances
+	RedisURL          string   // Redis protocol URL; not needed when RedisRESTURL is set
 	XAPIBearerToken   string
+	GitHubToken       string // optional, raises GitHub API rate limits
 	AdminAPIKey       string
 	MongoDBURI        string
+
+	// Upstash REST API endpoint and token, used instead of RedisURL when set, so Redis is
+	// reached over HTTPS rather than a TCP connection
+	RedisRESTURL   string
+	RedisRESTToken string
+
+	// ClerkSecretKey enables Clerk user lookups for admin endpoints (optional)
+	ClerkSecretKey string
+
+	// JWT hardening: frontend origins tokens may be issued to (empty allows any) and the
+	// clock skew tolerated on exp and nbf
+	ClerkAuthorizedParties []string
+	JWTClockSkew           time.Duration
+
+	// InternalAuthSecret signs tokens for worker endpoints called by background
+	// infrastructure (optional; worker endpoints are disabled without it)
+	InternalAuthSecret string
+
+	// ImpersonationSecret signs read-only support tokens issued by admins
+	// (optional; impersonation is disabled without it)
+	ImpersonationSecret string
+
+	// JWKSRefreshInterval is how often Clerk signing keys are refreshed in the background
+	JWKSRefreshInterval time.Duration
+
+	// KeepaliveInterval is how often MongoDB and Redis are pinged to keep pooled
+	// connections alive through idle periods (0 disables)
+	KeepaliveInterval time.Duration
+
+	// LazyStartup starts listening right away and connects to MongoDB, Pinecone, Redis and
+	// Clerk in the background, retrying until each is reachable. On by default in Cloud Run,
+	// where the network may not be up yet when the server starts.
+	LazyStartup bool
+
+	// RedisMaxLatency is the Redis round trip above which the startup preflight warns
+	RedisMaxLatency time.Duration
+
+	// Pinecone index provisioned by name at startup (optional, replaces PineconeIndexHost)
+	PineconeIndexName string
+	PineconeCloud     string
+	PineconeRegion    string
+
+	// Embedding model and optional shortened dimensions; must match the Pinecone index
+	EmbeddingModel      string
+	EmbeddingDimensions int
+	// OpenAI-compatible embeddings API serving models other than OpenAI's, e.g. a
+	// self-hosted e5 or nomic-embed-text server, and its API key (optional)
+	EmbeddingBaseURL string
+	EmbeddingAPIKey  string
+	// Prefixes embedded before search queries and saved content, e.g. "query: " and
+	// "passage: " for e5 models; default to the model family's recommended prefixes
+	EmbeddingQueryPrefix    string
+	EmbeddingDocumentPrefix string
+
+	// MongoDB client tuning. Sizes and durations of 0, an empty read preference and nil
+	// retry writes leave the connection string's settings, or the driver's defaults, in place.
+	MongoMaxPoolSize            uint64
+	MongoMinPoolSize            uint64
+	MongoMaxConnIdleTime        time.Duration
+	MongoConnectTimeout         time.Duration
+	MongoServerSelectionTimeout time.Duration
+	MongoReadPreference         string
+	MongoRetryWrites            *bool
+
+	// SMTP settings for email notifications (optional)
+	SMTPHost              string
+	SMTPPort              string
+	SMTPUsername          string
+	SMTPPassword          string
+	NotificationFromEmail string
+
+	// AssemblyAIAPIKey enables meeting transcription with speaker diarization (optional)
+	AssemblyAIAPIKey string
+
+	// ZoteroSyncInterval is how often connected Zotero libraries are synced (0 disables)
+	ZoteroSyncInterval time.Duration
+	// NotionSyncInterval is how often connected Notion workspaces are synced (0 disables)
+	NotionSyncInterval time.Duration
+	// GitHubSyncInterval is how often starred repositories of connected GitHub accounts are synced (0 disables)
+	GitHubSyncInterval time.Duration
+
+	// GoogleAccessToken authorizes Google Cloud requests (backups, Pub/Sub) when running
+	// outside Google Cloud; otherwise the instance's service account is used
+	GoogleAccessToken string
+
+	// BackupBucket is the GCS bucket user data backups are written to (optional; backups
+	// are disabled without it)
+	BackupBucket string
+	// BackupInterval is how often scheduled full backups run (0 disables)
+	BackupInterval time.Duration
+
+	// Original uploaded files (documents, images, recordings) are kept for download in
+	// FileBucket when FileStorage is "gcs" or "s3", and discarded after their text is
+	// extracted when it is ""
+	FileStorage string
+	FileBucket  string
+	// S3 credentials and region when FileStorage is "s3"; S3Endpoint is set for
+	// S3-compatible stores and defaults to Amazon S3 in S3Region
+	S3Region          string
+	S3Endpoint        string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+
+	// Usage-based billing: where metering events are sent ("" disables, "webhook",
+	// "pubsub" or "stripe") and the settings of each sink
+	BillingSink            string
+	BillingWebhookURL      string
+	BillingWebhookSecret   string
+	BillingPubSubTopic     string // projects/<project>/topics/<topic>
+	StripeSecretKey        string
+	StripeMeterEventPrefix string
+	BillingFlushInterval   time.Duration
+	BillingStorageInterval time.Duration // how often storage snapshots are emitted (0 disables)
+
+	// ContradictionScanInterval is how often newly saved items are checked for
+	// contradictions with the rest of the user's data (0 disables)
+	ContradictionScanInterval time.Duration
+
+	// DigestInterval is how often digests, such as the knowledge-gap report, are sent (0 disables)
+	DigestInterval time.Duration
+
+	// SavesDigestCheckInterval is how often scheduled digests of recent saves are checked
+	// for being due (0 disables)
+	SavesDigestCheckInterval time.Duration
+
+	// AbuseDetectionInterval is how often usage is checked for abuse, such as scripted
+	// query storms, which is queued for admin review and throttled (0 disables)
+	AbuseDetectionInterval time.Duration
+
+	// RetrievalMinScore is the similarity score below which retrieval matches are discarded
+	// instead of used as context; queries may override it
+	RetrievalMinScore float64
+
+	// Chunking is how uploaded documents are chunked unless a request says otherwise: the
+	// strategy ("characters", "tokens" or "headings"; "" for each type's default) in
+	// CHUNK_STRATEGY, and the chunk size and overlap in CHUNK_SIZE and CHUNK_OVERLAP
+	Chunking chunking.Options
+
+	// DailySpendCapUSD is the estimated OpenAI spend per user per UTC day beyond which their
+	// saves and queries are rejected until the next day (0 disables the cap)
+	DailySpendCapUSD float64
+
+	// TranslateForRetrieval embeds non-English content and queries together with an English
+	// translation, so items match queries written in other languages
+	TranslateForRetrieval bool
+
+	// GenerationTimeout is how long answer generation may take before a query returns its
+	// retrieved context with a short answer instead (0 disables)
+	GenerationTimeout time.Duration
+
+	// VAPID keys for Web Push notifications (optional)
+	VAPIDPublicKey  string
+	VAPIDPrivateKey string
+	VAPIDSubject    string
+
+	// Telegram bot that saves messages sent to it (optional). The webhook secret is the
+	// secret_token given to setWebhook; the username is used for links to the bot.
+	TelegramBotToken      string
+	TelegramWebhookSecret string
+	TelegramBotUsername   string
+
+	// Slack app whose message action and save reaction store Slack messages (optional).
+	// Users install it through OAuth, returning to the redirect URL.
+	SlackClientID      string
+	SlackClientSecret  string
+	SlackSigningSecret string
+	SlackRedirectURL   string // this server's /slack/oauth/callback URL, as registered with the app
+	SlackInstalledURL  string // where the browser is sent after installing; "" to respond with JSON
+	SlackSaveReaction  string // emoji name, without colons, that saves the message it's added to
+
+	// Storage quotas by plan name; users without a known plan get QuotaDefaultPlan
+	QuotaPlans       map[string]PlanQuota
+	QuotaDefaultPlan string
+}
+
+// PlanQuota holds one plan's storage limits and single-document size limits (0 means unlimited)
+type PlanQuota struct {
+	MaxItems          int64
+	MaxCharacters     int64
+	MaxVectors        int64
+	MaxDocumentPages  int64
+	MaxDocumentChunks int64
+}
+
+// defaultPlanQuotas are the built-in limits for plans that aren't configured
+var defaultPlanQuotas = map[string]PlanQuota{
+	"free": {MaxItems: 1000, MaxCharacters: 5000000, MaxVectors: 20000, MaxDocumentPages: 200, MaxDocumentChunks: 1000},
+	"pro":  {MaxDocumentPages: 1500, MaxDocumentChunks: 8000},
+}
+
+// splitList splits a comma-separated list, dropping empty entries and trailing slashes
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimRight(strings.TrimSpace(item), "/"); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// loadPlanQuotas reads QUOTA_<PLAN>_MAX_ITEMS, QUOTA_<PLAN>_MAX_CHARACTERS,
+// QUOTA_<PLAN>_MAX_VECTORS, QUOTA_<PLAN>_MAX_DOCUMENT_PAGES and
+// QUOTA_<PLAN>_MAX_DOCUMENT_CHUNKS for each plan listed in QUOTA_PLANS
+func loadPlanQuotas(env *envReader) map[string]PlanQuota {
+	plans := make(map[string]PlanQuota)
+	for _, plan := range strings.Split(env.String("QUOTA_PLANS", "free,pro"), ",") {
+		plan = strings.ToLower(strings.TrimSpace(plan))
+		if plan == "" {
+			continue
+		}
+		def := defaultPlanQuotas[plan]
+		prefix := "QUOTA_" + strings.ToUpper(plan) + "_"
+		plans[plan] = PlanQuota{
+			MaxItems:          env.Int64(prefix+"MAX_ITEMS", def.MaxItems),
+			MaxCharacters:     env.Int64(prefix+"MAX_CHARACTERS", def.MaxCharacters),
+			MaxVectors:        env.Int64(prefix+"MAX_VECTORS", def.MaxVectors),
+			MaxDocumentPages:  env.Int64(prefix+"MAX_DOCUMENT_PAGES", def.MaxDocumentPages),
+			MaxDocumentChunks: env.Int64(prefix+"MAX_DOCUMENT_CHUNKS", def.MaxDocumentChunks),
+		}
+	}
+	return plans
 }
 
 // LoadConfig loads configuration from environment variables
@@ -29,9 +255,7 @@ func LoadConfig() (*Config, error) {
 	requiredEnvVars := []string{
 		"OPENAI_API_KEY",
 		"PINECONE_API_KEY",
-		"PINECONE_INDEX_HOST",
 		"CLERK_ISSUER_URL",
-		"UPSTASH_REDIS_URL",
 	}
 
 	for _, envVar := range requiredEnvVars {
@@ -40,26 +264,205 @@ func LoadConfig() (*Config, error) {
 		}
 	}
 
+	if os.Getenv("UPSTASH_REDIS_URL") == "" && os.Getenv("UPSTASH_REDIS_REST_URL") == "" {
+		return nil, fmt.Errorf("UPSTASH_REDIS_URL or UPSTASH_REDIS_REST_URL environment variable must be set")
+	}
+
 	// Get port from environment or use default
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
 
+	if os.Getenv("PINECONE_INDEX_HOST") == "" && os.Getenv("PINECONE_INDEX_NAME") == "" {
+		return nil, fmt.Errorf("PINECONE_INDEX_HOST or PINECONE_INDEX_NAME environment variable must be set")
+	}
+
 	mongoDBURI := os.Getenv("MONGODB_URI")
 	if mongoDBURI == "" {
 		return nil, fmt.Errorf("MONGODB_URI environment variable is required")
 	}
 
-	return &Config{
+	env := &envReader{}
+	cfg := &Config{
 		Port:              port,
 		OpenAIAPIKey:      os.Getenv("OPENAI_API_KEY"),
 		PineconeAPIKey:    os.Getenv("PINECONE_API_KEY"),
 		PineconeIndexHost: os.Getenv("PINECONE_INDEX_HOST"),
-		ClerkIssuerURL:    os.Getenv("CLERK_ISSUER_URL"),
+		ClerkIssuerURLs:   splitList(os.Getenv("CLERK_ISSUER_URL")),
 		RedisURL:          os.Getenv("UPSTASH_REDIS_URL"),
 		XAPIBearerToken:   os.Getenv("X_API_BEARER_TOKEN"),
+		GitHubToken:       os.Getenv("GITHUB_TOKEN"),
 		AdminAPIKey:       os.Getenv("ADMIN_API_KEY"),
 		MongoDBURI:        mongoDBURI,
-	}, nil
+
+		RedisRESTURL:   os.Getenv("UPSTASH_REDIS_REST_URL"),
+		RedisRESTToken: os.Getenv("UPSTASH_REDIS_REST_TOKEN"),
+
+		ClerkSecretKey:         os.Getenv("CLERK_SECRET_KEY"),
+		ClerkAuthorizedParties: splitList(os.Getenv("CLERK_AUTHORIZED_PARTIES")),
+		JWTClockSkew:           env.Duration("JWT_CLOCK_SKEW", 5*time.Second),
+		JWKSRefreshInterval:    env.Duration("JWKS_REFRESH_INTERVAL", 30*time.Minute),
+		KeepaliveInterval:      env.Duration("KEEPALIVE_INTERVAL", time.Minute),
+		LazyStartup:            env.Bool("LAZY_STARTUP", os.Getenv("CLOUD_RUN") == "true"),
+		RedisMaxLatency:        env.Duration("PREFLIGHT_REDIS_MAX_LATENCY", 250*time.Millisecond),
+		InternalAuthSecret:     os.Getenv("INTERNAL_AUTH_SECRET"),
+		ImpersonationSecret:    os.Getenv("IMPERSONATION_SECRET"),
+
+		PineconeIndexName: os.Getenv("PINECONE_INDEX_NAME"),
+		PineconeCloud:     env.String("PINECONE_CLOUD", "aws"),
+		PineconeRegion:    env.String("PINECONE_REGION", "us-east-1"),
+
+		EmbeddingModel:      env.String("OPENAI_EMBEDDING_MODEL", "text-embedding-3-small"),
+		EmbeddingDimensions: env.Int("OPENAI_EMBEDDING_DIMENSIONS", 0),
+
+		EmbeddingBaseURL:        os.Getenv("EMBEDDING_BASE_URL"),
+		EmbeddingAPIKey:         os.Getenv("EMBEDDING_API_KEY"),
+		EmbeddingQueryPrefix:    os.Getenv("EMBEDDING_QUERY_PREFIX"),
+		EmbeddingDocumentPrefix: os.Getenv("EMBEDDING_DOCUMENT_PREFIX"),
+
+		MongoMaxPoolSize:            env.Uint64("MONGODB_MAX_POOL_SIZE", 0),
+		MongoMinPoolSize:            env.Uint64("MONGODB_MIN_POOL_SIZE", 0),
+		MongoMaxConnIdleTime:        env.Duration("MONGODB_MAX_CONN_IDLE_TIME", 0),
+		MongoConnectTimeout:         env.Duration("MONGODB_CONNECT_TIMEOUT", 0),
+		MongoServerSelectionTimeout: env.Duration("MONGODB_SERVER_SELECTION_TIMEOUT", 0),
+		MongoReadPreference:         env.String("MONGODB_READ_PREFERENCE", ""),
+		MongoRetryWrites:            env.OptionalBool("MONGODB_RETRY_WRITES"),
+
+		SMTPHost:              os.Getenv("SMTP_HOST"),
+		SMTPPort:              env.String("SMTP_PORT", "587"),
+		SMTPUsername:          os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:          os.Getenv("SMTP_PASSWORD"),
+		NotificationFromEmail: os.Getenv("NOTIFICATION_FROM_EMAIL"),
+
+		AssemblyAIAPIKey: os.Getenv("ASSEMBLYAI_API_KEY"),
+
+		ZoteroSyncInterval: env.Duration("ZOTERO_SYNC_INTERVAL", 6*time.Hour),
+		NotionSyncInterval: env.Duration("NOTION_SYNC_INTERVAL", 6*time.Hour),
+		GitHubSyncInterval: env.Duration("GITHUB_SYNC_INTERVAL", 6*time.Hour),
+
+		GoogleAccessToken: os.Getenv("GOOGLE_ACCESS_TOKEN"),
+
+		BackupBucket:   os.Getenv("BACKUP_BUCKET"),
+		BackupInterval: env.Duration("BACKUP_INTERVAL", 24*time.Hour),
+
+		FileStorage:       os.Getenv("FILE_STORAGE"),
+		FileBucket:        os.Getenv("FILE_BUCKET"),
+		S3Region:          env.String("AWS_REGION", "us-east-1"),
+		S3Endpoint:        os.Getenv("S3_ENDPOINT"),
+		S3AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		S3SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+
+		BillingSink:            os.Getenv("BILLING_SINK"),
+		BillingWebhookURL:      os.Getenv("BILLING_WEBHOOK_URL"),
+		BillingWebhookSecret:   os.Getenv("BILLING_WEBHOOK_SECRET"),
+		BillingPubSubTopic:     os.Getenv("BILLING_PUBSUB_TOPIC"),
+		StripeSecretKey:        os.Getenv("STRIPE_SECRET_KEY"),
+		StripeMeterEventPrefix: env.String("STRIPE_METER_EVENT_PREFIX", "forgetai_"),
+		BillingFlushInterval:   env.Duration("BILLING_FLUSH_INTERVAL", time.Minute),
+		BillingStorageInterval: env.Duration("BILLING_STORAGE_INTERVAL", 24*time.Hour),
+
+		ContradictionScanInterval: env.Duration("CONTRADICTION_SCAN_INTERVAL", 24*time.Hour),
+
+		DigestInterval: env.Duration("DIGEST_INTERVAL", 7*24*time.Hour),
+
+		SavesDigestCheckInterval: env.Duration("SAVES_DIGEST_CHECK_INTERVAL", 15*time.Minute),
+
+		AbuseDetectionInterval: env.Duration("ABUSE_DETECTION_INTERVAL", 5*time.Minute),
+
+		RetrievalMinScore: env.Float64("RETRIEVAL_MIN_SCORE", 0),
+
+		Chunking: chunking.Options{
+			Strategy: strings.ToLower(os.Getenv("CHUNK_STRATEGY")),
+			Size:     env.Int("CHUNK_SIZE", chunking.DefaultTextChunkSize),
+			Overlap:  env.Int("CHUNK_OVERLAP", chunking.DefaultTextChunkOverlap),
+		},
+
+		DailySpendCapUSD: env.Float64("DAILY_SPEND_CAP_USD", 0),
+
+		TranslateForRetrieval: env.Bool("TRANSLATE_FOR_RETRIEVAL", false),
+
+		GenerationTimeout: env.Duration("GENERATION_TIMEOUT", 0),
+
+		VAPIDPublicKey:  os.Getenv("VAPID_PUBLIC_KEY"),
+		VAPIDPrivateKey: os.Getenv("VAPID_PRIVATE_KEY"),
+		VAPIDSubject:    os.Getenv("VAPID_SUBJECT"),
+
+		TelegramBotToken:      os.Getenv("TELEGRAM_BOT_TOKEN"),
+		TelegramWebhookSecret: os.Getenv("TELEGRAM_WEBHOOK_SECRET"),
+		TelegramBotUsername:   strings.TrimPrefix(os.Getenv("TELEGRAM_BOT_USERNAME"), "@"),
+
+		SlackClientID:      os.Getenv("SLACK_CLIENT_ID"),
+		SlackClientSecret:  os.Getenv("SLACK_CLIENT_SECRET"),
+		SlackSigningSecret: os.Getenv("SLACK_SIGNING_SECRET"),
+		SlackRedirectURL:   os.Getenv("SLACK_REDIRECT_URL"),
+		SlackInstalledURL:  os.Getenv("SLACK_INSTALLED_URL"),
+		SlackSaveReaction:  strings.Trim(env.String("SLACK_SAVE_REACTION", "brain"), ":"),
+
+		QuotaPlans:       loadPlanQuotas(env),
+		QuotaDefaultPlan: env.String("QUOTA_DEFAULT_PLAN", "free"),
+	}
+	if env.err != nil {
+		return nil, env.err
+	}
+
+	if cfg.RetrievalMinScore < 0 || cfg.RetrievalMinScore > 1 {
+		return nil, fmt.Errorf("RETRIEVAL_MIN_SCORE (%g) must be between 0 and 1", cfg.RetrievalMinScore)
+	}
+	if err := cfg.Chunking.Validate(); err != nil {
+		return nil, fmt.Errorf("CHUNK_STRATEGY, CHUNK_SIZE and CHUNK_OVERLAP: %v", err)
+	}
+	if cfg.DailySpendCapUSD < 0 {
+		return nil, fmt.Errorf("DAILY_SPEND_CAP_USD (%g) must not be negative", cfg.DailySpendCapUSD)
+	}
+
+	if cfg.TelegramBotToken != "" && cfg.TelegramWebhookSecret == "" {
+		return nil, fmt.Errorf("TELEGRAM_WEBHOOK_SECRET is required when TELEGRAM_BOT_TOKEN is set")
+	}
+
+	if cfg.SlackClientID != "" && (cfg.SlackClientSecret == "" || cfg.SlackSigningSecret == "" || cfg.SlackRedirectURL == "") {
+		return nil, fmt.Errorf("SLACK_CLIENT_SECRET, SLACK_SIGNING_SECRET and SLACK_REDIRECT_URL are required when SLACK_CLIENT_ID is set")
+	}
+
+	if cfg.MongoMinPoolSize > cfg.MongoMaxPoolSize && cfg.MongoMaxPoolSize != 0 {
+		return nil, fmt.Errorf("MONGODB_MIN_POOL_SIZE (%d) cannot exceed MONGODB_MAX_POOL_SIZE (%d)", cfg.MongoMinPoolSize, cfg.MongoMaxPoolSize)
+	}
+
+	switch cfg.FileStorage {
+	case "":
+	case "gcs":
+		if cfg.FileBucket == "" {
+			return nil, fmt.Errorf("FILE_BUCKET is required when FILE_STORAGE is gcs")
+		}
+	case "s3":
+		if cfg.FileBucket == "" || cfg.S3AccessKeyID == "" || cfg.S3SecretAccessKey == "" {
+			return nil, fmt.Errorf("FILE_BUCKET, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required when FILE_STORAGE is s3")
+		}
+	default:
+		return nil, fmt.Errorf("FILE_STORAGE must be gcs or s3, got %q", cfg.FileStorage)
+	}
+
+	switch cfg.BillingSink {
+	case "":
+	case "webhook":
+		if cfg.BillingWebhookURL == "" {
+			return nil, fmt.Errorf("BILLING_WEBHOOK_URL is required when BILLING_SINK is webhook")
+		}
+	case "pubsub":
+		if cfg.BillingPubSubTopic == "" {
+			return nil, fmt.Errorf("BILLING_PUBSUB_TOPIC is required when BILLING_SINK is pubsub")
+		}
+	case "stripe":
+		if cfg.StripeSecretKey == "" || cfg.ClerkSecretKey == "" {
+			return nil, fmt.Errorf("STRIPE_SECRET_KEY and CLERK_SECRET_KEY are required when BILLING_SINK is stripe")
+		}
+	default:
+		return nil, fmt.Errorf("BILLING_SINK must be webhook, pubsub or stripe, got %q", cfg.BillingSink)
+	}
+
+	if _, ok := cfg.QuotaPlans[cfg.QuotaDefaultPlan]; !ok {
+		return nil, fmt.Errorf("QUOTA_DEFAULT_PLAN %q is not listed in QUOTA_PLANS", cfg.QuotaDefaultPlan)
+	}
+
+	return cfg, nil
 }
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// envReader reads typed values from environment variables, remembering the
+// first parse error so LoadConfig can report it once
+type envReader struct {
+	err error
+}
+
+// String returns the variable's value or def when it is unset
+func (r *envReader) String(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// Int returns the variable parsed as an int or def when it is unset
+func (r *envReader) Int(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		r.fail(key, v, err)
+		return def
+	}
+	return n
+}
+
+// Int64 returns the variable parsed as an int64 or def when it is unset
+func (r *envReader) Int64(key string, def int64) int64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		r.fail(key, v, err)
+		return def
+	}
+	return n
+}
+
+// Uint64 returns the variable parsed as a uint64 or def when it is unset
+func (r *envReader) Uint64(key string, def uint64) uint64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		r.fail(key, v, err)
+		return def
+	}
+	return n
+}
+
+// Float64 returns the variable parsed as a float64 or def when it is unset
+func (r *envReader) Float64(key string, def float64) float64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		r.fail(key, v, err)
+		return def
+	}
+	return f
+}
+
+// Bool returns the variable parsed as a bool or def when it is unset
+func (r *envReader) Bool(key string, def bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		r.fail(key, v, err)
+		return def
+	}
+	return b
+}
+
+// OptionalBool returns the variable parsed as a bool, or nil when it is unset
+func (r *envReader) OptionalBool(key string) *bool {
+	if os.Getenv(key) == "" {
+		return nil
+	}
+	b := r.Bool(key, false)
+	return &b
+}
+
+// Duration returns the variable parsed as a time.Duration (e.g. "30s") or def when it is unset
+func (r *envReader) Duration(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		r.fail(key, v, err)
+		return def
+	}
+	return d
+}
+
+func (r *envReader) fail(key, value string, err error) {
+	if r.err == nil {
+		r.err = fmt.Errorf("invalid value %q for %s: %v", value, key, err)
+	}
+}
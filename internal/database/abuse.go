@@ -0,0 +1,241 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Abuse flag kinds, one per usage pattern the abuse analyzer looks for
+const (
+	AbuseRepeatedSaves = "repeated_saves" // the same text saved many times
+	AbuseQueryStorm    = "query_storm"    // more queries in a short window than a person makes
+	AbuseSharedAddress = "shared_address" // many accounts used from one client address
+)
+
+// Abuse flag statuses
+const (
+	AbuseFlagOpen      = "open"
+	AbuseFlagConfirmed = "confirmed"
+	AbuseFlagDismissed = "dismissed"
+)
+
+// AbuseFlag is a user's anomalous usage queued for admin review
+type AbuseFlag struct {
+	ID             primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	UserID         string                 `bson:"user_id" json:"user_id"`
+	Kind           string                 `bson:"kind" json:"kind"`
+	Details        map[string]interface{} `bson:"details,omitempty" json:"details,omitempty"`
+	Status         string                 `bson:"status" json:"status"`
+	ThrottledUntil time.Time              `bson:"throttled_until" json:"throttled_until"`
+	DetectedAt     time.Time              `bson:"detected_at" json:"detected_at"`
+	ReviewedAt     *time.Time             `bson:"reviewed_at,omitempty" json:"reviewed_at,omitempty"`
+	UpdatedAt      time.Time              `bson:"updated_at" json:"updated_at"`
+}
+
+// FlagAbuse queues anomalous usage seen since the given time for review, or updates the
+// details and throttling of the user's open flag of the same kind. Usage in a window that
+// a flag of the kind was already reviewed in isn't flagged again, as the analyzer's
+// windows overlap. It reports whether the usage was flagged.
+func (m *MongoDB) FlagAbuse(ctx context.Context, flag *AbuseFlag, since time.Time) (bool, error) {
+	collection := m.database.Collection("abuse_flags")
+	reviewed, err := collection.CountDocuments(ctx, bson.M{
+		"user_id":     flag.UserID,
+		"kind":        flag.Kind,
+		"reviewed_at": bson.M{"$gte": since},
+	})
+	if err != nil {
+		return false, err
+	}
+	if reviewed > 0 {
+		return false, nil
+	}
+
+	now := time.Now()
+	_, err = collection.UpdateOne(
+		ctx,
+		bson.M{"user_id": flag.UserID, "kind": flag.Kind, "status": AbuseFlagOpen},
+		bson.M{
+			"$set": bson.M{
+				"details":         flag.Details,
+				"throttled_until": flag.ThrottledUntil,
+				"updated_at":      now,
+			},
+			"$setOnInsert": bson.M{"detected_at": now},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ListAbuseFlags gets abuse flags with the given status ("" for any), newest first
+func (m *MongoDB) ListAbuseFlags(ctx context.Context, status string, limit int64) ([]*AbuseFlag, error) {
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+
+	cursor, err := m.database.Collection("abuse_flags").Find(
+		ctx,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "detected_at", Value: -1}}).SetLimit(limit),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	flags := []*AbuseFlag{}
+	if err := cursor.All(ctx, &flags); err != nil {
+		return nil, err
+	}
+	return flags, nil
+}
+
+// ReviewAbuseFlag sets the status of an abuse flag, returning the reviewed flag. It
+// returns mongo.ErrNoDocuments if there is no such flag.
+func (m *MongoDB) ReviewAbuseFlag(ctx context.Context, id, status string) (*AbuseFlag, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid object ID: %w", err)
+	}
+
+	now := time.Now()
+	var flag AbuseFlag
+	err = m.database.Collection("abuse_flags").FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": objID},
+		bson.M{"$set": bson.M{"status": status, "reviewed_at": now, "updated_at": now}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&flag)
+	if err != nil {
+		return nil, err
+	}
+	return &flag, nil
+}
+
+// RepeatedSave is text a user saved many times within a window. Saves are compared by
+// their audit summary, the start of the saved text.
+type RepeatedSave struct {
+	UserID string `bson:"user_id"`
+	Text   string `bson:"text"`
+	Count  int    `bson:"count"`
+}
+
+// GetRepeatedSaves finds texts saved at least minCount times by one user since the given time
+func (m *MongoDB) GetRepeatedSaves(ctx context.Context, since time.Time, minCount int) ([]*RepeatedSave, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"action":     AuditActionSave,
+			"created_at": bson.M{"$gte": since},
+			"summary":    bson.M{"$gt": ""},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"user_id": "$user_id", "text": "$summary"},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$match", Value: bson.M{"count": bson.M{"$gte": minCount}}}},
+		{{Key: "$project", Value: bson.M{"_id": 0, "user_id": "$_id.user_id", "text": "$_id.text", "count": 1}}},
+	}
+
+	cursor, err := m.database.Collection("audit_log").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var saves []*RepeatedSave
+	if err := cursor.All(ctx, &saves); err != nil {
+		return nil, err
+	}
+	return saves, nil
+}
+
+// QueryBurst is how many queries a user made within a window
+type QueryBurst struct {
+	UserID string `bson:"_id"`
+	Count  int    `bson:"count"`
+}
+
+// GetQueryBursts finds users who made at least minCount queries since the given time
+func (m *MongoDB) GetQueryBursts(ctx context.Context, since time.Time, minCount int) ([]*QueryBurst, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"action":     AuditActionQuery,
+			"created_at": bson.M{"$gte": since},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$user_id",
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$match", Value: bson.M{"count": bson.M{"$gte": minCount}}}},
+	}
+
+	cursor, err := m.database.Collection("audit_log").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var bursts []*QueryBurst
+	if err := cursor.All(ctx, &bursts); err != nil {
+		return nil, err
+	}
+	return bursts, nil
+}
+
+// RecordClientAddress notes that a user made a request from a client address
+func (m *MongoDB) RecordClientAddress(ctx context.Context, userID, address string) error {
+	now := time.Now()
+	_, err := m.database.Collection("client_addresses").UpdateOne(
+		ctx,
+		bson.M{"address": address, "user_id": userID},
+		bson.M{
+			"$set":         bson.M{"last_seen_at": now},
+			"$setOnInsert": bson.M{"first_seen_at": now},
+		},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// SharedAddress is a client address several accounts made requests from
+type SharedAddress struct {
+	Address string   `bson:"_id"`
+	UserIDs []string `bson:"user_ids"`
+}
+
+// GetSharedAddresses finds client addresses at least minUsers accounts made requests
+// from since the given time
+func (m *MongoDB) GetSharedAddresses(ctx context.Context, since time.Time, minUsers int) ([]*SharedAddress, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"last_seen_at": bson.M{"$gte": since}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":      "$address",
+			"user_ids": bson.M{"$addToSet": "$user_id"},
+		}}},
+		{{Key: "$match", Value: bson.M{
+			fmt.Sprintf("user_ids.%d", minUsers-1): bson.M{"$exists": true},
+		}}},
+	}
+
+	cursor, err := m.database.Collection("client_addresses").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var addresses []*SharedAddress
+	if err := cursor.All(ctx, &addresses); err != nil {
+		return nil, err
+	}
+	return addresses, nil
+}
@@ -0,0 +1,173 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// TopicCount is a query topic and how often it was asked about
+type TopicCount struct {
+	Topic string `bson:"_id" json:"topic"`
+	Count int    `bson:"count" json:"count"`
+}
+
+// UngroundedQuery is a query that found nothing relevant in the user's saved data
+type UngroundedQuery struct {
+	Query     string    `bson:"query" json:"query"`
+	Count     int       `bson:"count" json:"count"`
+	LastAsked time.Time `bson:"last_asked" json:"last_asked"`
+}
+
+// maxGapQueries is the number of example queries returned for a knowledge gap
+const maxGapQueries = 3
+
+// KnowledgeGap is a query topic the user's saved data answered with low confidence
+type KnowledgeGap struct {
+	Topic     string    `bson:"_id" json:"topic"`
+	Count     int       `bson:"count" json:"count"`
+	Queries   []string  `bson:"queries" json:"example_queries"`
+	LastAsked time.Time `bson:"last_asked" json:"last_asked"`
+}
+
+// QueryCounts summarizes how many queries a user made and how many went ungrounded
+type QueryCounts struct {
+	Total      int `bson:"total" json:"total"`
+	Ungrounded int `bson:"ungrounded" json:"ungrounded"`
+}
+
+// queryEventFilter matches a user's query audit events since the given time
+func queryEventFilter(userID string, since time.Time) bson.M {
+	return bson.M{
+		"user_id":    userID,
+		"action":     AuditActionQuery,
+		"created_at": bson.M{"$gte": since},
+	}
+}
+
+// GetQueryCounts counts a user's queries since the given time
+func (m *MongoDB) GetQueryCounts(ctx context.Context, userID string, since time.Time) (*QueryCounts, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: queryEventFilter(userID, since)}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   nil,
+			"total": bson.M{"$sum": 1},
+			"ungrounded": bson.M{"$sum": bson.M{
+				"$cond": bson.A{bson.M{"$eq": bson.A{"$details.grounded", false}}, 1, 0},
+			}},
+		}}},
+	}
+
+	cursor, err := m.database.Collection("audit_log").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	counts := &QueryCounts{}
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(counts); err != nil {
+			return nil, err
+		}
+	}
+	return counts, cursor.Err()
+}
+
+// GetTopQueryTopics gets the topics a user asks about most since the given time
+func (m *MongoDB) GetTopQueryTopics(ctx context.Context, userID string, since time.Time, limit int64) ([]*TopicCount, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: queryEventFilter(userID, since)}},
+		{{Key: "$unwind", Value: "$details.topics"}},
+		{{Key: "$group", Value: bson.M{"_id": "$details.topics", "count": bson.M{"$sum": 1}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: limit}},
+	}
+
+	cursor, err := m.database.Collection("audit_log").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var topics []*TopicCount
+	if err := cursor.All(ctx, &topics); err != nil {
+		return nil, err
+	}
+	return topics, nil
+}
+
+// GetUngroundedQueries gets a user's queries that found no relevant saved data since the
+// given time, grouped case-insensitively, most recently asked first
+func (m *MongoDB) GetUngroundedQueries(ctx context.Context, userID string, since time.Time, limit int64) ([]*UngroundedQuery, error) {
+	match := queryEventFilter(userID, since)
+	match["details.grounded"] = false
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$sort", Value: bson.D{{Key: "created_at", Value: -1}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":        bson.M{"$toLower": "$summary"},
+			"query":      bson.M{"$first": "$summary"},
+			"count":      bson.M{"$sum": 1},
+			"last_asked": bson.M{"$first": "$created_at"},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "last_asked", Value: -1}}}},
+		{{Key: "$limit", Value: limit}},
+	}
+
+	cursor, err := m.database.Collection("audit_log").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var queries []*UngroundedQuery
+	if err := cursor.All(ctx, &queries); err != nil {
+		return nil, err
+	}
+	return queries, nil
+}
+
+// GetKnowledgeGaps gets the topics of a user's queries since the given time that found
+// nothing relevant or whose best match scored below maxScore, most frequent first.
+// Queries recorded before scores were audited count only when ungrounded.
+func (m *MongoDB) GetKnowledgeGaps(ctx context.Context, userID string, since time.Time, maxScore float32, limit int64) ([]*KnowledgeGap, error) {
+	match := queryEventFilter(userID, since)
+	match["$or"] = bson.A{
+		bson.M{"details.grounded": false},
+		bson.M{"details.top_score": bson.M{"$lt": maxScore}},
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$sort", Value: bson.D{{Key: "created_at", Value: -1}}}},
+		{{Key: "$unwind", Value: "$details.topics"}},
+		{{Key: "$group", Value: bson.M{
+			"_id":        "$details.topics",
+			"count":      bson.M{"$sum": 1},
+			"queries":    bson.M{"$addToSet": "$summary"},
+			"last_asked": bson.M{"$first": "$created_at"},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "last_asked", Value: -1}}}},
+		{{Key: "$limit", Value: limit}},
+		{{Key: "$project", Value: bson.M{
+			"count":      1,
+			"last_asked": 1,
+			"queries":    bson.M{"$slice": bson.A{"$queries", maxGapQueries}},
+		}}},
+	}
+
+	cursor, err := m.database.Collection("audit_log").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var gaps []*KnowledgeGap
+	if err := cursor.All(ctx, &gaps); err != nil {
+		return nil, err
+	}
+	return gaps, nil
+}
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Audit actions recorded for user operations
+const (
+	AuditActionSave   = "save"
+	AuditActionDelete = "delete"
+	AuditActionQuery  = "query"
+
+	// Support access: an impersonation token being issued and each request made with it
+	AuditActionImpersonate         = "impersonate"
+	AuditActionImpersonatedRequest = "impersonated_request"
+)
+
+// AuditEvent represents a single user action recorded in the audit log
+type AuditEvent struct {
+	ID        primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	UserID    string                 `bson:"user_id" json:"user_id"`
+	Action    string                 `bson:"action" json:"action"`
+	ItemID    string                 `bson:"item_id,omitempty" json:"item_id,omitempty"`
+	ItemType  string                 `bson:"item_type,omitempty" json:"item_type,omitempty"`
+	Summary   string                 `bson:"summary,omitempty" json:"summary,omitempty"`
+	Details   map[string]interface{} `bson:"details,omitempty" json:"details,omitempty"`
+	CreatedAt time.Time              `bson:"created_at" json:"created_at"`
+}
+
+// CreateAuditEvent records an audit event
+func (m *MongoDB) CreateAuditEvent(ctx context.Context, event *AuditEvent) error {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	result, err := m.database.Collection("audit_log").InsertOne(ctx, event)
+	if err != nil {
+		return err
+	}
+
+	event.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// GetAuditEvents gets a user's audit events, newest first, created before the given time
+func (m *MongoDB) GetAuditEvents(ctx context.Context, userID string, before time.Time, limit int64) ([]*AuditEvent, error) {
+	filter := bson.M{"user_id": userID}
+	if !before.IsZero() {
+		filter["created_at"] = bson.M{"$lt": before}
+	}
+
+	cursor, err := m.database.Collection("audit_log").Find(
+		ctx,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(limit),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var events []*AuditEvent
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
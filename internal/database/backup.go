@@ -0,0 +1,59 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ExportUserData calls fn with each raw user data document in _id order, optionally only
+// for one user. Raw documents keep every field, including ones UserData doesn't model.
+func (m *MongoDB) ExportUserData(ctx context.Context, userID string, fn func(bson.Raw) error) error {
+	filter := bson.M{}
+	if userID != "" {
+		filter["user_id"] = userID
+	}
+
+	cursor, err := m.database.Collection("user_data").Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		if err := fn(cursor.Current); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// RestoreUserData writes exported user data documents back, replacing documents with the
+// same _id so a restore can safely be repeated. It returns how many were inserted and
+// how many replaced.
+func (m *MongoDB) RestoreUserData(ctx context.Context, docs []bson.Raw) (int64, int64, error) {
+	if len(docs) == 0 {
+		return 0, 0, nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(docs))
+	for _, doc := range docs {
+		id, err := doc.LookupErr("_id")
+		if err != nil {
+			return 0, 0, fmt.Errorf("document without _id: %w", err)
+		}
+		models = append(models, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"_id": id}).
+			SetReplacement(doc).
+			SetUpsert(true))
+	}
+
+	result, err := m.database.Collection("user_data").BulkWrite(ctx, models)
+	if err != nil {
+		return 0, 0, err
+	}
+	return result.UpsertedCount, result.MatchedCount, nil
+}
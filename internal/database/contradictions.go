@@ -0,0 +1,158 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Contradiction statuses
+const (
+	ContradictionOpen      = "open"
+	ContradictionDismissed = "dismissed"
+)
+
+// ContradictionSide is one of the two conflicting pieces of saved text
+type ContradictionSide struct {
+	ItemID   string `bson:"item_id" json:"item_id"`     // top-level item the text belongs to
+	RecordID string `bson:"record_id" json:"record_id"` // item or chunk holding the text
+	ItemType string `bson:"item_type" json:"item_type"` // type of the top-level item
+	Text     string `bson:"text" json:"text"`
+}
+
+// Contradiction is a pair of a user's saved items that conflict with each other
+type Contradiction struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID      string              `bson:"user_id" json:"user_id"`
+	PairKey     string              `bson:"pair_key" json:"-"` // the two record IDs in order, so a pair is stored once
+	ItemIDs     []string            `bson:"item_ids" json:"-"`
+	Sides       []ContradictionSide `bson:"sides" json:"sides"`
+	Explanation string              `bson:"explanation" json:"explanation"`
+	Status      string              `bson:"status" json:"status"`
+	DetectedAt  time.Time           `bson:"detected_at" json:"detected_at"`
+	UpdatedAt   time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+// ContradictionPairKey returns the key identifying a pair of records regardless of order
+func ContradictionPairKey(recordA, recordB string) string {
+	if recordB < recordA {
+		recordA, recordB = recordB, recordA
+	}
+	return recordA + ":" + recordB
+}
+
+// CreateContradiction stores a contradiction unless the pair is already recorded, so
+// dismissed pairs stay dismissed. It reports whether the contradiction is new.
+func (m *MongoDB) CreateContradiction(ctx context.Context, contradiction *Contradiction) (bool, error) {
+	now := time.Now()
+	contradiction.Status = ContradictionOpen
+	contradiction.DetectedAt = now
+	contradiction.UpdatedAt = now
+
+	result, err := m.database.Collection("contradictions").UpdateOne(
+		ctx,
+		bson.M{"user_id": contradiction.UserID, "pair_key": contradiction.PairKey},
+		bson.M{"$setOnInsert": contradiction},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.UpsertedCount > 0, nil
+}
+
+// GetContradictionPairs returns which of the given pair keys are already recorded for a user
+func (m *MongoDB) GetContradictionPairs(ctx context.Context, userID string, pairKeys []string) (map[string]bool, error) {
+	cursor, err := m.database.Collection("contradictions").Find(
+		ctx,
+		bson.M{"user_id": userID, "pair_key": bson.M{"$in": pairKeys}},
+		options.Find().SetProjection(bson.M{"pair_key": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		PairKey string `bson:"pair_key"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	pairs := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		pairs[row.PairKey] = true
+	}
+	return pairs, nil
+}
+
+// ListContradictions gets a user's contradictions with the given status ("" for any), newest first
+func (m *MongoDB) ListContradictions(ctx context.Context, userID, status string, limit int64) ([]*Contradiction, error) {
+	filter := bson.M{"user_id": userID}
+	if status != "" {
+		filter["status"] = status
+	}
+
+	cursor, err := m.database.Collection("contradictions").Find(
+		ctx,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "detected_at", Value: -1}}).SetLimit(limit),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	contradictions := []*Contradiction{}
+	if err := cursor.All(ctx, &contradictions); err != nil {
+		return nil, err
+	}
+
+	return contradictions, nil
+}
+
+// SetContradictionStatus sets the status of a user's contradiction. It returns
+// mongo.ErrNoDocuments if the user has no such contradiction.
+func (m *MongoDB) SetContradictionStatus(ctx context.Context, id, userID, status string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid object ID: %w", err)
+	}
+
+	result, err := m.database.Collection("contradictions").UpdateOne(ctx, bson.M{
+		"_id":     objID,
+		"user_id": userID,
+	}, bson.M{"$set": bson.M{"status": status, "updated_at": time.Now()}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// DeleteContradictionsForItem removes the contradictions involving a deleted item
+func (m *MongoDB) DeleteContradictionsForItem(ctx context.Context, userID, itemID string) error {
+	_, err := m.database.Collection("contradictions").DeleteMany(ctx, bson.M{
+		"user_id":  userID,
+		"item_ids": itemID,
+	})
+	return err
+}
+
+// DeleteContradictionsForRecord removes the contradictions involving a deleted chunk
+func (m *MongoDB) DeleteContradictionsForRecord(ctx context.Context, userID, recordID string) error {
+	_, err := m.database.Collection("contradictions").DeleteMany(ctx, bson.M{
+		"user_id":         userID,
+		"sides.record_id": recordID,
+	})
+	return err
+}
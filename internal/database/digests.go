@@ -0,0 +1,115 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Digest frequencies
+const (
+	DigestOff    = "off"
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// DigestPeriod returns how much time a digest of the given frequency covers, or 0 for none
+func DigestPeriod(frequency string) time.Duration {
+	switch frequency {
+	case DigestDaily:
+		return 24 * time.Hour
+	case DigestWeekly:
+		return 7 * 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// DigestSchedule is how often a user is sent a digest of their recent saves
+type DigestSchedule struct {
+	UserID     string     `bson:"user_id" json:"user_id"`
+	Frequency  string     `bson:"frequency" json:"frequency"`
+	NextAt     *time.Time `bson:"next_at,omitempty" json:"next_at,omitempty"` // when the next digest is due; unset when off
+	LastSentAt *time.Time `bson:"last_sent_at,omitempty" json:"last_sent_at,omitempty"`
+	UpdatedAt  time.Time  `bson:"updated_at" json:"updated_at"`
+}
+
+// GetDigestSchedule gets a user's digest schedule, returning nil if none is stored
+func (m *MongoDB) GetDigestSchedule(ctx context.Context, userID string) (*DigestSchedule, error) {
+	var schedule DigestSchedule
+	err := m.database.Collection("digest_schedules").FindOne(ctx, bson.M{"user_id": userID}).Decode(&schedule)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// UpsertDigestSchedule creates or replaces a user's digest schedule
+func (m *MongoDB) UpsertDigestSchedule(ctx context.Context, schedule *DigestSchedule) error {
+	schedule.UpdatedAt = time.Now()
+
+	_, err := m.database.Collection("digest_schedules").ReplaceOne(
+		ctx,
+		bson.M{"user_id": schedule.UserID},
+		schedule,
+		options.Replace().SetUpsert(true),
+	)
+	return err
+}
+
+// ClaimDueDigest claims a schedule whose digest is due, moving its next digest one period
+// on, so only one instance sends each digest. It returns the schedule as it was before
+// the claim, or nil when no digest is due.
+func (m *MongoDB) ClaimDueDigest(ctx context.Context, now time.Time) (*DigestSchedule, error) {
+	collection := m.database.Collection("digest_schedules")
+	for _, frequency := range []string{DigestDaily, DigestWeekly} {
+		var schedule DigestSchedule
+		err := collection.FindOneAndUpdate(
+			ctx,
+			bson.M{"frequency": frequency, "next_at": bson.M{"$lte": now}},
+			bson.M{"$set": bson.M{"next_at": now.Add(DigestPeriod(frequency)), "last_sent_at": now}},
+			options.FindOneAndUpdate().SetSort(bson.D{{Key: "next_at", Value: 1}}),
+		).Decode(&schedule)
+		if err == mongo.ErrNoDocuments {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &schedule, nil
+	}
+	return nil, nil
+}
+
+// GetRecentItems gets a user's items (not chunks) created since the given time, newest
+// first, without their full text
+func (m *MongoDB) GetRecentItems(ctx context.Context, userID string, since time.Time, limit int64) ([]*UserData, error) {
+	cursor, err := m.database.Collection("user_data").Find(
+		ctx,
+		bson.M{
+			"user_id":    userID,
+			"parent_id":  bson.M{"$exists": false},
+			"created_at": bson.M{"$gte": since},
+		},
+		options.Find().
+			SetSort(bson.D{{Key: "created_at", Value: -1}}).
+			SetLimit(limit).
+			SetProjection(bson.M{"full_text": 0}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var items []*UserData
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
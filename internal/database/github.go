@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GitHubIntegration represents a user's connected GitHub account, whose starred
+// repositories are synced, and its sync state
+type GitHubIntegration struct {
+	UserID       string    `bson:"user_id" json:"user_id"`
+	Login        string    `bson:"login" json:"login"`
+	AccessToken  string    `bson:"access_token" json:"-"`
+	Plan         string    `bson:"plan,omitempty" json:"-"`      // user's plan when connected, for background sync quotas
+	StarCount    int       `bson:"star_count" json:"star_count"` // repositories starred at the last sync
+	LastSyncedAt time.Time `bson:"last_synced_at,omitempty" json:"last_synced_at,omitempty"`
+	LastError    string    `bson:"last_error,omitempty" json:"last_error,omitempty"`
+	CreatedAt    time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt    time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// GetGitHubIntegration gets a user's GitHub integration, returning nil if none is connected
+func (m *MongoDB) GetGitHubIntegration(ctx context.Context, userID string) (*GitHubIntegration, error) {
+	var integration GitHubIntegration
+	err := m.database.Collection("github_integrations").FindOne(ctx, bson.M{"user_id": userID}).Decode(&integration)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &integration, nil
+}
+
+// ListGitHubIntegrations gets every connected GitHub integration
+func (m *MongoDB) ListGitHubIntegrations(ctx context.Context) ([]*GitHubIntegration, error) {
+	cursor, err := m.database.Collection("github_integrations").Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var integrations []*GitHubIntegration
+	if err := cursor.All(ctx, &integrations); err != nil {
+		return nil, err
+	}
+
+	return integrations, nil
+}
+
+// UpsertGitHubIntegration creates or replaces a user's GitHub integration
+func (m *MongoDB) UpsertGitHubIntegration(ctx context.Context, integration *GitHubIntegration) error {
+	now := time.Now()
+	if integration.CreatedAt.IsZero() {
+		integration.CreatedAt = now
+	}
+	integration.UpdatedAt = now
+
+	_, err := m.database.Collection("github_integrations").ReplaceOne(
+		ctx,
+		bson.M{"user_id": integration.UserID},
+		integration,
+		options.Replace().SetUpsert(true),
+	)
+	return err
+}
+
+// UpdateGitHubSyncState records the outcome of a sync: the number of starred repositories
+// and the sync's error, if any. A negative star count leaves it unchanged.
+func (m *MongoDB) UpdateGitHubSyncState(ctx context.Context, userID string, starCount int, lastError string) error {
+	set := bson.M{
+		"last_synced_at": time.Now(),
+		"last_error":     lastError,
+		"updated_at":     time.Now(),
+	}
+	if starCount >= 0 {
+		set["star_count"] = starCount
+	}
+
+	_, err := m.database.Collection("github_integrations").UpdateOne(ctx, bson.M{"user_id": userID}, bson.M{"$set": set})
+	return err
+}
+
+// DeleteGitHubIntegration removes a user's GitHub integration, reporting whether one existed
+func (m *MongoDB) DeleteGitHubIntegration(ctx context.Context, userID string) (bool, error) {
+	result, err := m.database.Collection("github_integrations").DeleteOne(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
@@ -0,0 +1,150 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Job statuses
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobFailed    = "failed"
+)
+
+// Job item results
+const (
+	JobItemSucceeded = "succeeded"
+	JobItemFailed    = "failed"
+	JobItemSkipped   = "skipped"
+)
+
+// Stages of a document processing job, before its chunks' vectors are written in the
+// background
+const (
+	JobStageExtracting = "extracting" // reading the document's text
+	JobStageChunking   = "chunking"
+	JobStageStoring    = "storing" // storing the chunks and queueing their vector writes
+)
+
+// JobItem is the result of one item of a job, such as one bookmark of an import batch
+type JobItem struct {
+	Index  int    `bson:"index" json:"index"` // position of the item in the request
+	Key    string `bson:"key" json:"key"`     // identifies the item to the user, e.g. a bookmark's URL
+	Status string `bson:"status" json:"status"`
+	Reason string `bson:"reason,omitempty" json:"reason,omitempty"` // why the item failed or was skipped
+	ItemID string `bson:"item_id,omitempty" json:"item_id,omitempty"`
+}
+
+// Job is a background task started by a user, such as a bulk import, and its progress
+type Job struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID      string             `bson:"user_id" json:"user_id"`
+	Kind        string             `bson:"kind" json:"kind"` // e.g. "bookmarks"
+	Status      string             `bson:"status" json:"status"`
+	Total       int                `bson:"total" json:"total"`
+	Succeeded   int                `bson:"succeeded" json:"succeeded"`
+	Failed      int                `bson:"failed" json:"failed"`
+	Skipped     int                `bson:"skipped" json:"skipped"` // e.g. already saved
+	Error       string             `bson:"error,omitempty" json:"error,omitempty"`
+	Stage       string             `bson:"stage,omitempty" json:"stage,omitempty"` // e.g. JobStageChunking
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+	CompletedAt *time.Time         `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+	Items       []JobItem          `bson:"items,omitempty" json:"items,omitempty"` // in the order they finished
+}
+
+// CreateJob stores a new queued job, setting its ID
+func (m *MongoDB) CreateJob(ctx context.Context, job *Job) error {
+	now := time.Now()
+	job.Status = JobQueued
+	job.CreatedAt = now
+	job.UpdatedAt = now
+
+	result, err := m.database.Collection("jobs").InsertOne(ctx, job)
+	if err != nil {
+		return err
+	}
+	job.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// GetJob gets one of a user's jobs. It returns mongo.ErrNoDocuments if the user has no
+// such job.
+func (m *MongoDB) GetJob(ctx context.Context, id, userID string) (*Job, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid object ID: %w", err)
+	}
+
+	var job Job
+	if err := m.database.Collection("jobs").FindOne(ctx, bson.M{"_id": objID, "user_id": userID}).Decode(&job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// SetJobStatus sets a job's status and error, recording when it finished for completed
+// and failed jobs
+func (m *MongoDB) SetJobStatus(ctx context.Context, id primitive.ObjectID, status, jobError string) error {
+	now := time.Now()
+	set := bson.M{"status": status, "error": jobError, "updated_at": now}
+	if status == JobCompleted || status == JobFailed {
+		set["completed_at"] = now
+	}
+
+	_, err := m.database.Collection("jobs").UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": set})
+	return err
+}
+
+// SetJobStage records which stage of its work a running job has reached
+func (m *MongoDB) SetJobStage(ctx context.Context, id primitive.ObjectID, stage string) error {
+	_, err := m.database.Collection("jobs").UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{"stage": stage, "updated_at": time.Now()},
+	})
+	return err
+}
+
+// ListJobs lists a user's most recent jobs, newest first, without their item results
+func (m *MongoDB) ListJobs(ctx context.Context, userID string, limit int64) ([]Job, error) {
+	cursor, err := m.database.Collection("jobs").Find(ctx,
+		bson.M{"user_id": userID},
+		options.Find().
+			SetSort(bson.D{{Key: "created_at", Value: -1}}).
+			SetLimit(limit).
+			SetProjection(bson.M{"items": 0}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	jobs := []Job{}
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// RecordJobItem adds an item's result to a job and counts it as succeeded, failed or
+// skipped, so progress and the reasons for failures are visible while the job runs
+func (m *MongoDB) RecordJobItem(ctx context.Context, id primitive.ObjectID, item JobItem) error {
+	switch item.Status {
+	case JobItemSucceeded, JobItemFailed, JobItemSkipped:
+	default:
+		return fmt.Errorf("unknown job item status %q", item.Status)
+	}
+
+	_, err := m.database.Collection("jobs").UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$inc":  bson.M{item.Status: 1},
+		"$push": bson.M{"items": item},
+		"$set":  bson.M{"updated_at": time.Now()},
+	})
+	return err
+}
@@ -0,0 +1,244 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MetricsDayFormat is the layout of the day key metrics are bucketed by (UTC)
+const MetricsDayFormat = "2006-01-02"
+
+// RouteMetrics holds one day's request counts and latency histogram for a route
+type RouteMetrics struct {
+	Day            string           `bson:"day" json:"day"`
+	Route          string           `bson:"route" json:"route"`
+	Count          int64            `bson:"count" json:"count"`
+	ServerErrors   int64            `bson:"server_errors" json:"server_errors"`
+	ClientErrors   int64            `bson:"client_errors" json:"client_errors"`
+	LatencyTotalMS int64            `bson:"latency_total_ms" json:"latency_total_ms"`
+	Buckets        map[string]int64 `bson:"buckets" json:"buckets"` // request counts by latency bucket name
+}
+
+// UsageMetrics holds one day's usage of an external provider by unit, e.g. tokens
+type UsageMetrics struct {
+	Day      string           `bson:"day" json:"day"`
+	Provider string           `bson:"provider" json:"provider"`
+	Units    map[string]int64 `bson:"units" json:"units"`
+}
+
+// UserUsage holds one user's usage for a day: calls of each rate-limited endpoint and
+// API tokens used on their behalf by unit
+type UserUsage struct {
+	Day       string           `bson:"day" json:"day"`
+	UserID    string           `bson:"user_id" json:"-"`
+	Endpoints map[string]int64 `bson:"endpoints" json:"endpoints"`
+	Tokens    map[string]int64 `bson:"tokens" json:"tokens"`
+}
+
+// EventMetrics holds one day's counts of operational events by name, e.g. malformed vectors
+type EventMetrics struct {
+	Day    string           `bson:"day" json:"day"`
+	Counts map[string]int64 `bson:"counts" json:"counts"`
+}
+
+// DailyActivity holds one day's distinct active users and action counts from the audit log
+type DailyActivity struct {
+	Day         string `bson:"_id" json:"day"`
+	ActiveUsers int    `bson:"active_users" json:"active_users"`
+	Saves       int    `bson:"saves" json:"saves"`
+	Queries     int    `bson:"queries" json:"queries"`
+	Deletes     int    `bson:"deletes" json:"deletes"`
+}
+
+// IncrementRouteMetrics adds request counts to a route's metrics for a day
+func (m *MongoDB) IncrementRouteMetrics(ctx context.Context, metrics *RouteMetrics) error {
+	inc := bson.M{
+		"count":            metrics.Count,
+		"server_errors":    metrics.ServerErrors,
+		"client_errors":    metrics.ClientErrors,
+		"latency_total_ms": metrics.LatencyTotalMS,
+	}
+	for bucket, count := range metrics.Buckets {
+		inc["buckets."+bucket] = count
+	}
+
+	_, err := m.database.Collection("route_metrics").UpdateOne(
+		ctx,
+		bson.M{"day": metrics.Day, "route": metrics.Route},
+		bson.M{"$inc": inc},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// IncrementUsageMetrics adds provider usage to a day's metrics
+func (m *MongoDB) IncrementUsageMetrics(ctx context.Context, day, provider string, units map[string]int64) error {
+	inc := bson.M{}
+	for unit, amount := range units {
+		inc["units."+unit] = amount
+	}
+
+	_, err := m.database.Collection("usage_metrics").UpdateOne(
+		ctx,
+		bson.M{"day": day, "provider": provider},
+		bson.M{"$inc": inc},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// IncrementEventMetrics adds event counts to a day's metrics
+func (m *MongoDB) IncrementEventMetrics(ctx context.Context, day string, events map[string]int64) error {
+	inc := bson.M{}
+	for event, count := range events {
+		inc["counts."+event] = count
+	}
+
+	_, err := m.database.Collection("event_metrics").UpdateOne(
+		ctx,
+		bson.M{"day": day},
+		bson.M{"$inc": inc},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// IncrementUserUsage adds endpoint calls and tokens to a user's usage for a day
+func (m *MongoDB) IncrementUserUsage(ctx context.Context, usage *UserUsage) error {
+	inc := bson.M{}
+	for endpoint, count := range usage.Endpoints {
+		inc["endpoints."+endpoint] = count
+	}
+	for unit, amount := range usage.Tokens {
+		inc["tokens."+unit] = amount
+	}
+	if len(inc) == 0 {
+		return nil
+	}
+
+	_, err := m.database.Collection("user_usage").UpdateOne(
+		ctx,
+		bson.M{"user_id": usage.UserID, "day": usage.Day},
+		bson.M{"$inc": inc},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// GetUserUsageHistory gets a user's daily usage for the days from since (inclusive),
+// oldest first. Days without usage are missing.
+func (m *MongoDB) GetUserUsageHistory(ctx context.Context, userID, since string) ([]*UserUsage, error) {
+	cursor, err := m.database.Collection("user_usage").Find(
+		ctx,
+		bson.M{"user_id": userID, "day": bson.M{"$gte": since}},
+		options.Find().SetSort(bson.D{{Key: "day", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var usage []*UserUsage
+	if err := cursor.All(ctx, &usage); err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// GetRouteMetrics gets route metrics for the days from since (inclusive)
+func (m *MongoDB) GetRouteMetrics(ctx context.Context, since string) ([]*RouteMetrics, error) {
+	cursor, err := m.database.Collection("route_metrics").Find(
+		ctx,
+		bson.M{"day": bson.M{"$gte": since}},
+		options.Find().SetSort(bson.D{{Key: "day", Value: 1}, {Key: "route", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var metrics []*RouteMetrics
+	if err := cursor.All(ctx, &metrics); err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+// GetUsageMetrics gets provider usage for the days from since (inclusive)
+func (m *MongoDB) GetUsageMetrics(ctx context.Context, since string) ([]*UsageMetrics, error) {
+	cursor, err := m.database.Collection("usage_metrics").Find(
+		ctx,
+		bson.M{"day": bson.M{"$gte": since}},
+		options.Find().SetSort(bson.D{{Key: "day", Value: 1}, {Key: "provider", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var metrics []*UsageMetrics
+	if err := cursor.All(ctx, &metrics); err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+// GetEventMetrics gets event counts for the days from since (inclusive)
+func (m *MongoDB) GetEventMetrics(ctx context.Context, since string) ([]*EventMetrics, error) {
+	cursor, err := m.database.Collection("event_metrics").Find(
+		ctx,
+		bson.M{"day": bson.M{"$gte": since}},
+		options.Find().SetSort(bson.D{{Key: "day", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var metrics []*EventMetrics
+	if err := cursor.All(ctx, &metrics); err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+// GetDailyActivity counts active users and actions per UTC day from the audit log since the given time
+func (m *MongoDB) GetDailyActivity(ctx context.Context, since time.Time) ([]*DailyActivity, error) {
+	countAction := func(action string) bson.M {
+		return bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$action", action}}, 1, 0}}}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"created_at": bson.M{"$gte": since}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":     bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$created_at"}},
+			"users":   bson.M{"$addToSet": "$user_id"},
+			"saves":   countAction(AuditActionSave),
+			"queries": countAction(AuditActionQuery),
+			"deletes": countAction(AuditActionDelete),
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"active_users": bson.M{"$size": "$users"},
+			"saves":        1,
+			"queries":      1,
+			"deletes":      1,
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	}
+
+	cursor, err := m.database.Collection("audit_log").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var days []*DailyActivity
+	if err := cursor.All(ctx, &days); err != nil {
+		return nil, err
+	}
+	return days, nil
+}
@@ -2,13 +2,17 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/siddhantgupta/forgetai-backend/internal/timing"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
 // MongoDB represents a MongoDB connection
@@ -19,36 +23,162 @@ type MongoDB struct {
 
 // UserData represents a user data document in MongoDB
 type UserData struct {
-	ID         primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
-	UserID     string              `bson:"user_id" json:"user_id"`
-	VectorID   string              `bson:"vector_id" json:"vector_id"`
-	DataType   string              `bson:"data_type" json:"data_type"`
-	DataValue  string              `bson:"data_value" json:"data_value"`
-	ParentID   *primitive.ObjectID `bson:"parent_id,omitempty" json:"parent_id,omitempty"`
-	ChunkIndex int                 `bson:"chunk_index" json:"chunk_index"`
-	CreatedAt  time.Time           `bson:"created_at" json:"created_at"`
+	ID         primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	UserID     string                 `bson:"user_id" json:"user_id"`
+	VectorID   string                 `bson:"vector_id" json:"vector_id"`
+	DataType   string                 `bson:"data_type" json:"data_type"`
+	DataValue  string                 `bson:"data_value" json:"data_value"`
+	ParentID   *primitive.ObjectID    `bson:"parent_id,omitempty" json:"parent_id,omitempty"`
+	ChunkIndex int                    `bson:"chunk_index" json:"chunk_index"`
+	Metadata   map[string]interface{} `bson:"metadata,omitempty" json:"metadata,omitempty"`
+	Status     string                 `bson:"status,omitempty" json:"status,omitempty"` // empty for items saved before statuses
+	Visibility string                 `bson:"visibility,omitempty" json:"visibility,omitempty"`
+	FullText   string                 `bson:"full_text,omitempty" json:"-"`   // extracted text of a document, when stored inline
+	TextObject string                 `bson:"text_object,omitempty" json:"-"` // backup bucket object holding the text when too large to store inline
+	FileObject string                 `bson:"file_object,omitempty" json:"-"` // file bucket object holding the original upload, if it was kept
+	CreatedAt  time.Time              `bson:"created_at" json:"created_at"`
+}
+
+// Item visibility levels. Items are private unless their owner makes them visible to others.
+const (
+	VisibilityPrivate = "private"
+	VisibilityShared  = "shared" // visible to people the owner shares with
+	VisibilityPublic  = "public" // visible to anyone, e.g. through the owner's public profile
+)
+
+// IsValidVisibility reports whether visibility is a known visibility level
+func IsValidVisibility(visibility string) bool {
+	return visibility == VisibilityPrivate || visibility == VisibilityShared || visibility == VisibilityPublic
+}
+
+// ItemVisibility returns the visibility of an item, treating unset visibility as private
+func ItemVisibility(userData *UserData) string {
+	if userData.Visibility == "" {
+		return VisibilityPrivate
+	}
+	return userData.Visibility
+}
+
+// Options holds MongoDB client tuning applied on top of the connection string. Unset
+// options leave the connection string's settings in place.
+type Options struct {
+	MaxPoolSize            uint64        // 0 for unset
+	MinPoolSize            uint64        // 0 for unset
+	MaxConnIdleTime        time.Duration // 0 for unset
+	ConnectTimeout         time.Duration // 0 for unset
+	ServerSelectionTimeout time.Duration // 0 for unset
+	ReadPreference         string        // "" for unset
+	RetryWrites            *bool         // nil for unset
+}
+
+// commandTimingMonitor records each command's duration on the request that issued it
+var commandTimingMonitor = &event.CommandMonitor{
+	Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+		timing.Record(ctx, timing.MongoDB, e.CommandName, e.Duration)
+	},
+	Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+		timing.Record(ctx, timing.MongoDB, e.CommandName, e.Duration)
+	},
+}
+
+// clientOptions builds the driver client options from the connection string and tuning options
+func (o Options) clientOptions(connectionString string) (*options.ClientOptions, error) {
+	clientOpts := options.Client().ApplyURI(connectionString).
+		SetMonitor(commandTimingMonitor)
+
+	if o.MaxPoolSize > 0 {
+		clientOpts.SetMaxPoolSize(o.MaxPoolSize)
+	}
+	if o.MinPoolSize > 0 {
+		clientOpts.SetMinPoolSize(o.MinPoolSize)
+	}
+	if o.RetryWrites != nil {
+		clientOpts.SetRetryWrites(*o.RetryWrites)
+	}
+
+	if o.MaxConnIdleTime > 0 {
+		clientOpts.SetMaxConnIdleTime(o.MaxConnIdleTime)
+	}
+	if o.ConnectTimeout > 0 {
+		clientOpts.SetConnectTimeout(o.ConnectTimeout)
+	}
+	if o.ServerSelectionTimeout > 0 {
+		clientOpts.SetServerSelectionTimeout(o.ServerSelectionTimeout)
+	}
+
+	if o.ReadPreference != "" {
+		mode, err := readpref.ModeFromString(o.ReadPreference)
+		if err != nil {
+			return nil, fmt.Errorf("invalid read preference: %w", err)
+		}
+		rp, err := readpref.New(mode)
+		if err != nil {
+			return nil, fmt.Errorf("invalid read preference: %w", err)
+		}
+		clientOpts.SetReadPreference(rp)
+	}
+
+	return clientOpts, nil
 }
 
 // NewMongoDB creates a new MongoDB connection
-func NewMongoDB(connectionString string) (*MongoDB, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func NewMongoDB(connectionString string, opts Options) (*MongoDB, error) {
+	m, err := NewLazyMongoDB(connectionString, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	connectTimeout := opts.ConnectTimeout
+	if connectTimeout <= 0 {
+		connectTimeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
 	defer cancel()
 
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(connectionString))
+	if err := m.Prepare(ctx); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// NewLazyMongoDB creates a MongoDB client without waiting for the server. The driver
+// connects in the background; Prepare verifies the connection and creates indexes.
+func NewLazyMongoDB(connectionString string, opts Options) (*MongoDB, error) {
+	clientOpts, err := opts.clientOptions(connectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := mongo.Connect(context.Background(), clientOpts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
 	}
 
+	return &MongoDB{
+		client:   client,
+		database: client.Database("forgetai"),
+	}, nil
+}
+
+// Prepare pings the server to verify the connection and creates the collections' indexes
+func (m *MongoDB) Prepare(ctx context.Context) error {
 	// Ping the database to verify connection
-	if err := client.Ping(ctx, nil); err != nil {
-		return nil, fmt.Errorf("failed to ping MongoDB: %w", err)
+	if err := m.client.Ping(ctx, nil); err != nil {
+		return fmt.Errorf("failed to ping MongoDB: %w", err)
 	}
 
 	// Create indexes
-	database := client.Database("forgetai")
-	collection := database.Collection("user_data")
+	if err := m.createIndexes(ctx); err != nil {
+		return fmt.Errorf("failed to create indexes: %w", err)
+	}
 
-	_, err = collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+	fmt.Println("Successfully connected to MongoDB")
+	return nil
+}
+
+// collectionIndexes lists the indexes created on startup for each collection
+var collectionIndexes = map[string][]mongo.IndexModel{
+	"user_data": {
 		{
 			Keys:    bson.D{{Key: "user_id", Value: 1}},
 			Options: options.Index().SetBackground(true),
@@ -61,17 +191,217 @@ func NewMongoDB(connectionString string) (*MongoDB, error) {
 			Keys:    bson.D{{Key: "parent_id", Value: 1}},
 			Options: options.Index().SetBackground(true).SetSparse(true),
 		},
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to create indexes: %w", err)
-	}
-
-	fmt.Println("Successfully connected to MongoDB")
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "metadata.zotero_key", Value: 1}},
+			Options: options.Index().SetBackground(true).SetSparse(true),
+		},
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "metadata.notion_page_id", Value: 1}},
+			Options: options.Index().SetBackground(true).SetSparse(true),
+		},
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "metadata.people_keys", Value: 1}},
+			Options: options.Index().SetBackground(true).SetSparse(true),
+		},
+	},
+	"audit_log": {
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetBackground(true),
+		},
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "action", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetBackground(true),
+		},
+		{
+			Keys:    bson.D{{Key: "created_at", Value: -1}},
+			Options: options.Index().SetBackground(true),
+		},
+	},
+	"notification_preferences": {
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetBackground(true).SetUnique(true),
+		},
+	},
+	"zotero_integrations": {
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetBackground(true).SetUnique(true),
+		},
+	},
+	"jobs": {
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetBackground(true),
+		},
+	},
+	"notion_integrations": {
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetBackground(true).SetUnique(true),
+		},
+	},
+	"github_integrations": {
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetBackground(true).SetUnique(true),
+		},
+	},
+	"telegram_links": {
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetBackground(true).SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "chat_id", Value: 1}},
+			Options: options.Index().SetBackground(true).SetSparse(true),
+		},
+		{
+			Keys:    bson.D{{Key: "link_code", Value: 1}},
+			Options: options.Index().SetBackground(true).SetSparse(true),
+		},
+	},
+	"slack_integrations": {
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetBackground(true).SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "team_id", Value: 1}, {Key: "slack_user_id", Value: 1}},
+			Options: options.Index().SetBackground(true),
+		},
+	},
+	"route_metrics": {
+		{
+			Keys:    bson.D{{Key: "day", Value: 1}, {Key: "route", Value: 1}},
+			Options: options.Index().SetBackground(true).SetUnique(true),
+		},
+	},
+	"usage_metrics": {
+		{
+			Keys:    bson.D{{Key: "day", Value: 1}, {Key: "provider", Value: 1}},
+			Options: options.Index().SetBackground(true).SetUnique(true),
+		},
+	},
+	"event_metrics": {
+		{
+			Keys:    bson.D{{Key: "day", Value: 1}},
+			Options: options.Index().SetBackground(true).SetUnique(true),
+		},
+	},
+	"user_usage": {
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "day", Value: 1}},
+			Options: options.Index().SetBackground(true).SetUnique(true),
+		},
+	},
+	"digest_schedules": {
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetBackground(true).SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "frequency", Value: 1}, {Key: "next_at", Value: 1}},
+			Options: options.Index().SetBackground(true),
+		},
+	},
+	"abuse_flags": {
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "kind", Value: 1}, {Key: "status", Value: 1}},
+			Options: options.Index().SetBackground(true),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "detected_at", Value: -1}},
+			Options: options.Index().SetBackground(true),
+		},
+	},
+	"client_addresses": {
+		{
+			Keys:    bson.D{{Key: "address", Value: 1}, {Key: "user_id", Value: 1}},
+			Options: options.Index().SetBackground(true).SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "last_seen_at", Value: -1}},
+			Options: options.Index().SetBackground(true),
+		},
+	},
+	"vector_outbox": {
+		{
+			Keys:    bson.D{{Key: "next_attempt_at", Value: 1}, {Key: "locked_until", Value: 1}},
+			Options: options.Index().SetBackground(true),
+		},
+		{
+			Keys:    bson.D{{Key: "user_data_id", Value: 1}},
+			Options: options.Index().SetBackground(true),
+		},
+		{
+			Keys:    bson.D{{Key: "parent_id", Value: 1}},
+			Options: options.Index().SetBackground(true).SetSparse(true),
+		},
+	},
+	"vector_dead_letters": {
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "failed_at", Value: -1}},
+			Options: options.Index().SetBackground(true),
+		},
+		{
+			Keys:    bson.D{{Key: "failed_at", Value: -1}},
+			Options: options.Index().SetBackground(true),
+		},
+		{
+			Keys:    bson.D{{Key: "user_data_id", Value: 1}},
+			Options: options.Index().SetBackground(true),
+		},
+		{
+			Keys:    bson.D{{Key: "parent_id", Value: 1}},
+			Options: options.Index().SetBackground(true).SetSparse(true),
+		},
+	},
+	"contradictions": {
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "pair_key", Value: 1}},
+			Options: options.Index().SetBackground(true).SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "status", Value: 1}, {Key: "detected_at", Value: -1}},
+			Options: options.Index().SetBackground(true),
+		},
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "item_ids", Value: 1}},
+			Options: options.Index().SetBackground(true),
+		},
+	},
+	"public_profiles": {
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetBackground(true).SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "username", Value: 1}},
+			Options: options.Index().SetBackground(true).SetUnique(true),
+		},
+	},
+	"push_subscriptions": {
+		{
+			Keys:    bson.D{{Key: "endpoint", Value: 1}},
+			Options: options.Index().SetBackground(true).SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetBackground(true),
+		},
+	},
+}
 
-	return &MongoDB{
-		client:   client,
-		database: database,
-	}, nil
+// createIndexes creates the indexes used by every collection
+func (m *MongoDB) createIndexes(ctx context.Context) error {
+	for name, indexes := range collectionIndexes {
+		if _, err := m.database.Collection(name).Indexes().CreateMany(ctx, indexes); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
 }
 
 // Close closes the MongoDB connection
@@ -110,6 +440,63 @@ func (m *MongoDB) GetUserDataByID(ctx context.Context, id string) (*UserData, er
 	return &userData, nil
 }
 
+// GetUserDataByVectorIDs gets a user's data documents matching any of the given vector IDs
+func (m *MongoDB) GetUserDataByVectorIDs(ctx context.Context, userID string, vectorIDs []string) ([]*UserData, error) {
+	cursor, err := m.database.Collection("user_data").Find(ctx, bson.M{
+		"user_id":   userID,
+		"vector_id": bson.M{"$in": vectorIDs},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var items []*UserData
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// GetUserDataByIDs gets those of the given data items that belong to a user, without their
+// full text
+func (m *MongoDB) GetUserDataByIDs(ctx context.Context, userID string, ids []primitive.ObjectID) ([]*UserData, error) {
+	cursor, err := m.database.Collection("user_data").Find(ctx, bson.M{
+		"user_id": userID,
+		"_id":     bson.M{"$in": ids},
+	}, options.Find().SetProjection(bson.M{"full_text": 0}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var items []*UserData
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// GetUserDataByMetadata gets a user's top-level data document of the given type whose
+// metadata field matches value (or contains it, for array fields). It returns
+// mongo.ErrNoDocuments if there is none.
+func (m *MongoDB) GetUserDataByMetadata(ctx context.Context, userID, dataType, field string, value interface{}) (*UserData, error) {
+	var userData UserData
+	err := m.database.Collection("user_data").FindOne(ctx, bson.M{
+		"user_id":           userID,
+		"data_type":         dataType,
+		"parent_id":         bson.M{"$exists": false},
+		"metadata." + field: value,
+	}).Decode(&userData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &userData, nil
+}
+
 // GetAllUserData gets all user data documents for a user (excluding chunks)
 func (m *MongoDB) GetAllUserData(ctx context.Context, userID string) ([]*UserData, error) {
 	cursor, err := m.database.Collection("user_data").Find(
@@ -157,6 +544,53 @@ func (m *MongoDB) GetUserDataByType(ctx context.Context, userID, dataType string
 	return items, nil
 }
 
+// GetRecentRecords gets a user's records holding saved text (items and chunks) created
+// since the given time, newest first, skipping records of the given data types
+func (m *MongoDB) GetRecentRecords(ctx context.Context, userID string, since time.Time, skipTypes []string, limit int64) ([]*UserData, error) {
+	cursor, err := m.database.Collection("user_data").Find(
+		ctx,
+		bson.M{
+			"user_id":    userID,
+			"data_type":  bson.M{"$nin": skipTypes},
+			"created_at": bson.M{"$gte": since},
+		},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(limit),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var items []*UserData
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// SetUserDataMetadata sets the given metadata fields on a user's data document
+func (m *MongoDB) SetUserDataMetadata(ctx context.Context, id primitive.ObjectID, userID string, fields map[string]interface{}) error {
+	set := bson.M{}
+	for key, value := range fields {
+		set["metadata."+key] = value
+	}
+
+	result, err := m.database.Collection("user_data").UpdateOne(ctx, bson.M{
+		"_id":     id,
+		"user_id": userID,
+	}, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no document found with ID %s for user %s", id.Hex(), userID)
+	}
+
+	return nil
+}
+
 // DeleteUserData deletes a user data document
 func (m *MongoDB) DeleteUserData(ctx context.Context, id, userID string) error {
 	objID, err := primitive.ObjectIDFromHex(id)
@@ -176,11 +610,43 @@ func (m *MongoDB) DeleteUserData(ctx context.Context, id, userID string) error {
 		return fmt.Errorf("no document found with ID %s for user %s", id, userID)
 	}
 
+	// A pending vector write must not recreate the vector of a deleted item
+	if err := m.deleteOutboxEntries(ctx, objID); err != nil {
+		return fmt.Errorf("failed to remove pending vector writes: %w", err)
+	}
+
 	return nil
 }
 
-// GetPDFChunks gets all chunks for a PDF
-func (m *MongoDB) GetPDFChunks(ctx context.Context, parentID string) ([]*UserData, error) {
+// SetVisibility sets the visibility of a user's item and its chunks, including the vector
+// metadata of their pending and dead-lettered vector writes
+func (m *MongoDB) SetVisibility(ctx context.Context, id primitive.ObjectID, userID, visibility string) error {
+	filter := bson.M{
+		"user_id": userID,
+		"$or":     bson.A{bson.M{"_id": id}, bson.M{"parent_id": id}},
+	}
+	result, err := m.database.Collection("user_data").UpdateMany(ctx, filter, bson.M{"$set": bson.M{"visibility": visibility}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no document found with ID %s for user %s", id.Hex(), userID)
+	}
+
+	// Vectors written later must carry the new visibility too
+	outboxFilter := bson.M{
+		"$or": bson.A{bson.M{"user_data_id": id}, bson.M{"parent_id": id}},
+	}
+	update := bson.M{"$set": bson.M{"metadata.visibility": visibility}}
+	if _, err := m.database.Collection("vector_outbox").UpdateMany(ctx, outboxFilter, update); err != nil {
+		return err
+	}
+	_, err = m.database.Collection("vector_dead_letters").UpdateMany(ctx, outboxFilter, update)
+	return err
+}
+
+// GetChunks gets all chunks for a parent document (PDF, code, ...)
+func (m *MongoDB) GetChunks(ctx context.Context, parentID string) ([]*UserData, error) {
 	objID, err := primitive.ObjectIDFromHex(parentID)
 	if err != nil {
 		return nil, fmt.Errorf("invalid object ID: %w", err)
@@ -204,8 +670,57 @@ func (m *MongoDB) GetPDFChunks(ctx context.Context, parentID string) ([]*UserDat
 	return items, nil
 }
 
-// DeletePDFWithChunks deletes a PDF and all its chunks
-func (m *MongoDB) DeletePDFWithChunks(ctx context.Context, id, userID string) error {
+// GetChunksByIndex gets the chunks of a parent document at the given chunk indexes
+func (m *MongoDB) GetChunksByIndex(ctx context.Context, parentID primitive.ObjectID, indexes []int) ([]*UserData, error) {
+	cursor, err := m.database.Collection("user_data").Find(
+		ctx,
+		bson.M{"parent_id": parentID, "chunk_index": bson.M{"$in": indexes}},
+		options.Find().SetSort(bson.D{{Key: "chunk_index", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var items []*UserData
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// CountChunksByStatus counts the chunks of a parent document by status, e.g. how many
+// are still waiting for their vectors to be written
+func (m *MongoDB) CountChunksByStatus(ctx context.Context, parentID primitive.ObjectID) (map[string]int, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"parent_id": parentID}}},
+		{{Key: "$group", Value: bson.M{"_id": "$status", "count": bson.M{"$sum": 1}}}},
+	}
+
+	cursor, err := m.database.Collection("user_data").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Status string `bson:"_id"`
+		Count  int    `bson:"count"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(results))
+	for _, result := range results {
+		counts[result.Status] += result.Count
+	}
+	return counts, nil
+}
+
+// DeleteWithChunks deletes a parent document and all its chunks
+func (m *MongoDB) DeleteWithChunks(ctx context.Context, id, userID string) error {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return fmt.Errorf("invalid object ID: %w", err)
@@ -242,7 +757,8 @@ func (m *MongoDB) DeletePDFWithChunks(ctx context.Context, id, userID string) er
 			return fmt.Errorf("no document found with ID %s for user %s", id, userID)
 		}
 
-		return nil
+		// A pending vector write must not recreate the vector of a deleted chunk
+		return m.deleteOutboxEntries(sc, objID)
 	})
 
 	return err
@@ -272,3 +788,74 @@ func (m *MongoDB) GetVectorIDByDataID(ctx context.Context, id string) (string, e
 func (m *MongoDB) Ping(ctx context.Context) error {
 	return m.client.Ping(ctx, nil)
 }
+
+// IsUnauthorized reports whether err is the server refusing an operation the connected
+// user isn't permitted to run
+func IsUnauthorized(err error) bool {
+	var serverErr mongo.ServerError
+	if !errors.As(err, &serverErr) {
+		return false
+	}
+	// 13 is MongoDB's Unauthorized; Atlas reports unpermitted actions as 8000
+	return serverErr.HasErrorCode(13) || serverErr.HasErrorCode(8000)
+}
+
+// CheckReadWrite inserts and deletes a throwaway document to verify writes work
+func (m *MongoDB) CheckReadWrite(ctx context.Context) error {
+	collection := m.database.Collection("selftest")
+	result, err := collection.InsertOne(ctx, bson.M{"created_at": time.Now()})
+	if err != nil {
+		return fmt.Errorf("insert failed: %w", err)
+	}
+	deleted, err := collection.DeleteOne(ctx, bson.M{"_id": result.InsertedID})
+	if err != nil {
+		return fmt.Errorf("delete failed: %w", err)
+	}
+	if deleted.DeletedCount != 1 {
+		return fmt.Errorf("inserted document was not found for deletion")
+	}
+	return nil
+}
+
+// StorageUsage is how much a user has stored: top-level items, characters of embedded
+// text and vectors
+type StorageUsage struct {
+	Items      int64 `bson:"items" json:"items"`
+	Characters int64 `bson:"characters" json:"characters"`
+	Vectors    int64 `bson:"vectors" json:"vectors"`
+}
+
+// GetStorageUsage measures a user's stored items, characters and vectors. Parent records
+// count as items but have no vector of their own; their chunks hold the text.
+func (m *MongoDB) GetStorageUsage(ctx context.Context, userID string) (*StorageUsage, error) {
+	isParent := bson.M{"$eq": bson.A{bson.M{"$substrCP": bson.A{"$vector_id", 0, 7}}, "parent-"}}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"user_id": userID}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": nil,
+			"items": bson.M{"$sum": bson.M{
+				"$cond": bson.A{bson.M{"$gt": bson.A{"$parent_id", nil}}, 0, 1},
+			}},
+			"characters": bson.M{"$sum": bson.M{
+				"$cond": bson.A{isParent, 0, bson.M{"$strLenCP": "$data_value"}},
+			}},
+			"vectors": bson.M{"$sum": bson.M{
+				"$cond": bson.A{isParent, 0, 1},
+			}},
+		}}},
+	}
+
+	cursor, err := m.database.Collection("user_data").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	usage := &StorageUsage{}
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(usage); err != nil {
+			return nil, err
+		}
+	}
+	return usage, cursor.Err()
+}
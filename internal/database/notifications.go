@@ -0,0 +1,46 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// NotificationPreferences represents a user's notification settings
+type NotificationPreferences struct {
+	UserID    string          `bson:"user_id" json:"user_id"`
+	Email     string          `bson:"email,omitempty" json:"email,omitempty"`
+	Channels  map[string]bool `bson:"channels" json:"channels"`
+	Types     map[string]bool `bson:"types" json:"types"`
+	UpdatedAt time.Time       `bson:"updated_at" json:"updated_at"`
+}
+
+// GetNotificationPreferences gets a user's notification preferences, returning nil if none are stored
+func (m *MongoDB) GetNotificationPreferences(ctx context.Context, userID string) (*NotificationPreferences, error) {
+	var prefs NotificationPreferences
+	err := m.database.Collection("notification_preferences").FindOne(ctx, bson.M{"user_id": userID}).Decode(&prefs)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &prefs, nil
+}
+
+// UpsertNotificationPreferences creates or replaces a user's notification preferences
+func (m *MongoDB) UpsertNotificationPreferences(ctx context.Context, prefs *NotificationPreferences) error {
+	prefs.UpdatedAt = time.Now()
+
+	_, err := m.database.Collection("notification_preferences").ReplaceOne(
+		ctx,
+		bson.M{"user_id": prefs.UserID},
+		prefs,
+		options.Replace().SetUpsert(true),
+	)
+	return err
+}
@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// NotionIntegration represents a user's connected Notion workspace and its sync state
+type NotionIntegration struct {
+	UserID        string    `bson:"user_id" json:"user_id"`
+	BotID         string    `bson:"bot_id" json:"bot_id"`
+	WorkspaceName string    `bson:"workspace_name" json:"workspace_name"`
+	AccessToken   string    `bson:"access_token" json:"-"`
+	Plan          string    `bson:"plan,omitempty" json:"-"`      // user's plan when connected, for background sync quotas
+	PageCount     int       `bson:"page_count" json:"page_count"` // pages shared with the integration at the last sync
+	LastSyncedAt  time.Time `bson:"last_synced_at,omitempty" json:"last_synced_at,omitempty"`
+	LastError     string    `bson:"last_error,omitempty" json:"last_error,omitempty"`
+	CreatedAt     time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// GetNotionIntegration gets a user's Notion integration, returning nil if none is connected
+func (m *MongoDB) GetNotionIntegration(ctx context.Context, userID string) (*NotionIntegration, error) {
+	var integration NotionIntegration
+	err := m.database.Collection("notion_integrations").FindOne(ctx, bson.M{"user_id": userID}).Decode(&integration)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &integration, nil
+}
+
+// ListNotionIntegrations gets every connected Notion integration
+func (m *MongoDB) ListNotionIntegrations(ctx context.Context) ([]*NotionIntegration, error) {
+	cursor, err := m.database.Collection("notion_integrations").Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var integrations []*NotionIntegration
+	if err := cursor.All(ctx, &integrations); err != nil {
+		return nil, err
+	}
+
+	return integrations, nil
+}
+
+// UpsertNotionIntegration creates or replaces a user's Notion integration
+func (m *MongoDB) UpsertNotionIntegration(ctx context.Context, integration *NotionIntegration) error {
+	now := time.Now()
+	if integration.CreatedAt.IsZero() {
+		integration.CreatedAt = now
+	}
+	integration.UpdatedAt = now
+
+	_, err := m.database.Collection("notion_integrations").ReplaceOne(
+		ctx,
+		bson.M{"user_id": integration.UserID},
+		integration,
+		options.Replace().SetUpsert(true),
+	)
+	return err
+}
+
+// UpdateNotionSyncState records the outcome of a sync: the number of pages shared with the
+// integration and the sync's error, if any. A negative page count leaves it unchanged.
+func (m *MongoDB) UpdateNotionSyncState(ctx context.Context, userID string, pageCount int, lastError string) error {
+	set := bson.M{
+		"last_synced_at": time.Now(),
+		"last_error":     lastError,
+		"updated_at":     time.Now(),
+	}
+	if pageCount >= 0 {
+		set["page_count"] = pageCount
+	}
+
+	_, err := m.database.Collection("notion_integrations").UpdateOne(ctx, bson.M{"user_id": userID}, bson.M{"$set": set})
+	return err
+}
+
+// DeleteNotionIntegration removes a user's Notion integration, reporting whether one existed
+func (m *MongoDB) DeleteNotionIntegration(ctx context.Context, userID string) (bool, error) {
+	result, err := m.database.Collection("notion_integrations").DeleteOne(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
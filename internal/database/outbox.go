@@ -0,0 +1,297 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// User data statuses. Items saved before statuses existed have none and are ready.
+const (
+	UserDataStatusPending = "pending" // waiting for its vector to be written
+	UserDataStatusReady   = "ready"
+	UserDataStatusFailed  = "failed" // its vector could not be written
+)
+
+// OutboxEntry is a pending vector write for a user data record. The record and its entry
+// are created together; the publisher embeds the text, upserts the vector, then marks the
+// record ready and removes the entry.
+type OutboxEntry struct {
+	ID            primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	UserDataID    primitive.ObjectID     `bson:"user_data_id" json:"user_data_id"`
+	ParentID      *primitive.ObjectID    `bson:"parent_id,omitempty" json:"parent_id,omitempty"`
+	UserID        string                 `bson:"user_id" json:"user_id"`
+	VectorID      string                 `bson:"vector_id" json:"vector_id"`
+	DataType      string                 `bson:"data_type" json:"data_type"` // type stored with the vector
+	VectorText    string                 `bson:"vector_text" json:"vector_text"`
+	EmbedText     string                 `bson:"embed_text" json:"embed_text"`
+	Metadata      map[string]interface{} `bson:"metadata,omitempty" json:"metadata,omitempty"` // extra vector metadata
+	Timestamp     time.Time              `bson:"timestamp" json:"timestamp"`
+	Attempts      int                    `bson:"attempts" json:"attempts"`
+	LastError     string                 `bson:"last_error,omitempty" json:"last_error,omitempty"`
+	NextAttemptAt time.Time              `bson:"next_attempt_at" json:"next_attempt_at"`
+	LockedUntil   time.Time              `bson:"locked_until" json:"locked_until"`
+	CreatedAt     time.Time              `bson:"created_at" json:"created_at"`
+	Additional    bool                   `bson:"additional,omitempty" json:"additional,omitempty"` // e.g. a title vector; doesn't change the record's status
+}
+
+// DeadLetter is an outbox entry whose vector write permanently failed, kept with its
+// error details so it can be inspected and retried
+type DeadLetter struct {
+	OutboxEntry `bson:",inline"`
+	FailedAt    time.Time `bson:"failed_at" json:"failed_at"`
+}
+
+// CreateUserDataWithOutbox creates a pending user data record and its outbox entry in one transaction
+func (m *MongoDB) CreateUserDataWithOutbox(ctx context.Context, userData *UserData, entry *OutboxEntry) (*UserData, error) {
+	now := time.Now()
+	if userData.CreatedAt.IsZero() {
+		userData.CreatedAt = now
+	}
+	userData.ID = primitive.NewObjectID()
+	userData.Status = UserDataStatusPending
+
+	entry.UserDataID = userData.ID
+	entry.ParentID = userData.ParentID
+	entry.UserID = userData.UserID
+	entry.VectorID = userData.VectorID
+	entry.CreatedAt = now
+	entry.NextAttemptAt = now
+
+	session, err := m.client.StartSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		if _, err := m.database.Collection("user_data").InsertOne(sc, userData); err != nil {
+			return nil, err
+		}
+		result, err := m.database.Collection("vector_outbox").InsertOne(sc, entry)
+		if err != nil {
+			return nil, err
+		}
+		entry.ID = result.InsertedID.(primitive.ObjectID)
+		return nil, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return userData, nil
+}
+
+// CreateOutboxEntry enqueues an additional vector write for an existing record, such as a
+// document's title vector. The record's status only follows its own vector's write, so
+// this one's outcome leaves it unchanged. entry.VectorID must be set by the caller.
+func (m *MongoDB) CreateOutboxEntry(ctx context.Context, userData *UserData, entry *OutboxEntry) error {
+	now := time.Now()
+	entry.Additional = true
+	entry.UserDataID = userData.ID
+	entry.ParentID = userData.ParentID
+	entry.UserID = userData.UserID
+	entry.CreatedAt = now
+	entry.NextAttemptAt = now
+
+	result, err := m.database.Collection("vector_outbox").InsertOne(ctx, entry)
+	if err != nil {
+		return err
+	}
+	entry.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// ClaimOutboxEntry leases the next due outbox entry so no other publisher processes it
+// until the lease expires. It returns mongo.ErrNoDocuments when nothing is due.
+func (m *MongoDB) ClaimOutboxEntry(ctx context.Context, lease time.Duration) (*OutboxEntry, error) {
+	now := time.Now()
+	var entry OutboxEntry
+	err := m.database.Collection("vector_outbox").FindOneAndUpdate(
+		ctx,
+		bson.M{
+			"next_attempt_at": bson.M{"$lte": now},
+			"locked_until":    bson.M{"$lte": now},
+		},
+		bson.M{"$set": bson.M{"locked_until": now.Add(lease)}},
+		options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+			SetReturnDocument(options.After),
+	).Decode(&entry)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// CompleteOutboxEntry marks an entry's record ready, unless the entry is an additional
+// vector of the record, and removes the entry. It reports whether the record still exists;
+// when it was deleted meanwhile the caller should remove the vector it just wrote.
+func (m *MongoDB) CompleteOutboxEntry(ctx context.Context, entry *OutboxEntry) (bool, error) {
+	exists, err := m.setOutboxRecordStatus(ctx, entry, UserDataStatusReady)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := m.database.Collection("vector_outbox").DeleteOne(ctx, bson.M{"_id": entry.ID}); err != nil {
+		return exists, fmt.Errorf("failed to remove outbox entry: %w", err)
+	}
+
+	return exists, nil
+}
+
+// setOutboxRecordStatus sets the status of an entry's record, reporting whether the record
+// exists. Additional vectors of a record leave its status unchanged.
+func (m *MongoDB) setOutboxRecordStatus(ctx context.Context, entry *OutboxEntry, status string) (bool, error) {
+	collection := m.database.Collection("user_data")
+	if entry.Additional {
+		count, err := collection.CountDocuments(ctx, bson.M{"_id": entry.UserDataID})
+		return count > 0, err
+	}
+
+	result, err := collection.UpdateOne(
+		ctx,
+		bson.M{"_id": entry.UserDataID},
+		bson.M{"$set": bson.M{"status": status}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// RetryOutboxEntry records a failed attempt and schedules the entry's next attempt
+func (m *MongoDB) RetryOutboxEntry(ctx context.Context, id primitive.ObjectID, lastError string, nextAttemptAt time.Time) error {
+	_, err := m.database.Collection("vector_outbox").UpdateOne(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{
+			"$inc": bson.M{"attempts": 1},
+			"$set": bson.M{
+				"last_error":      lastError,
+				"next_attempt_at": nextAttemptAt,
+				"locked_until":    time.Time{},
+			},
+		},
+	)
+	return err
+}
+
+// DeadLetterOutboxEntry marks an entry's record failed, unless the entry is an additional
+// vector of the record, and moves the entry, with the final attempt's error, to the
+// dead-letter collection
+func (m *MongoDB) DeadLetterOutboxEntry(ctx context.Context, entry *OutboxEntry, attempts int, lastError string) error {
+	deadLetter := &DeadLetter{OutboxEntry: *entry, FailedAt: time.Now()}
+	deadLetter.Attempts = attempts
+	deadLetter.LastError = lastError
+	deadLetter.LockedUntil = time.Time{}
+
+	session, err := m.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		if _, err := m.setOutboxRecordStatus(sc, entry, UserDataStatusFailed); err != nil {
+			return nil, err
+		}
+		if _, err := m.database.Collection("vector_dead_letters").InsertOne(sc, deadLetter); err != nil {
+			return nil, err
+		}
+		_, err := m.database.Collection("vector_outbox").DeleteOne(sc, bson.M{"_id": entry.ID})
+		return nil, err
+	})
+	return err
+}
+
+// GetDeadLetters gets dead-lettered vector writes, newest first, optionally for one user
+func (m *MongoDB) GetDeadLetters(ctx context.Context, userID string, limit int64) ([]*DeadLetter, error) {
+	filter := bson.M{}
+	if userID != "" {
+		filter["user_id"] = userID
+	}
+
+	cursor, err := m.database.Collection("vector_dead_letters").Find(
+		ctx,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "failed_at", Value: -1}}).SetLimit(limit),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var deadLetters []*DeadLetter
+	if err := cursor.All(ctx, &deadLetters); err != nil {
+		return nil, err
+	}
+	return deadLetters, nil
+}
+
+// RetryDeadLetter moves a dead-lettered vector write back to the outbox with a fresh
+// attempt count and marks its record pending, unless it's an additional vector of the
+// record. It returns mongo.ErrNoDocuments when the
+// dead letter doesn't exist, and removes dead letters whose record has since been deleted.
+func (m *MongoDB) RetryDeadLetter(ctx context.Context, id string) (*OutboxEntry, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid object ID: %w", err)
+	}
+
+	session, err := m.client.StartSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.EndSession(ctx)
+
+	var entry OutboxEntry
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		var deadLetter DeadLetter
+		if err := m.database.Collection("vector_dead_letters").FindOneAndDelete(sc, bson.M{"_id": objID}).Decode(&deadLetter); err != nil {
+			return nil, err
+		}
+
+		exists, err := m.setOutboxRecordStatus(sc, &deadLetter.OutboxEntry, UserDataStatusPending)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			// The item is gone; dropping the dead letter is all that's left to do
+			return nil, nil
+		}
+
+		entry = deadLetter.OutboxEntry
+		entry.Attempts = 0
+		entry.NextAttemptAt = time.Now()
+		entry.LockedUntil = time.Time{}
+		if _, err := m.database.Collection("vector_outbox").InsertOne(sc, entry); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if entry.ID.IsZero() {
+		return nil, mongo.ErrNoDocuments
+	}
+	return &entry, nil
+}
+
+// deleteOutboxEntries removes the pending and dead-lettered vector writes of a record and its chunks
+func (m *MongoDB) deleteOutboxEntries(ctx context.Context, id primitive.ObjectID) error {
+	filter := bson.M{
+		"$or": bson.A{bson.M{"user_data_id": id}, bson.M{"parent_id": id}},
+	}
+	if _, err := m.database.Collection("vector_outbox").DeleteMany(ctx, filter); err != nil {
+		return err
+	}
+	_, err := m.database.Collection("vector_dead_letters").DeleteMany(ctx, filter)
+	return err
+}
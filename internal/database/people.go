@@ -0,0 +1,72 @@
+package database
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PersonSummary is a person mentioned in a user's items and how many items mention them
+type PersonSummary struct {
+	Name      string `bson:"name" json:"name"`
+	Key       string `bson:"_id" json:"key"`
+	ItemCount int    `bson:"item_count" json:"item_count"`
+}
+
+// ListPeople gets the people mentioned in a user's items, most mentioned first
+func (m *MongoDB) ListPeople(ctx context.Context, userID string, limit int64) ([]*PersonSummary, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"user_id":         userID,
+			"parent_id":       bson.M{"$exists": false},
+			"metadata.people": bson.M{"$exists": true},
+		}}},
+		{{Key: "$unwind", Value: "$metadata.people"}},
+		{{Key: "$group", Value: bson.M{
+			"_id":        bson.M{"$toLower": "$metadata.people"},
+			"name":       bson.M{"$first": "$metadata.people"},
+			"item_count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "item_count", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: limit}},
+	}
+
+	cursor, err := m.database.Collection("user_data").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var people []*PersonSummary
+	if err := cursor.All(ctx, &people); err != nil {
+		return nil, err
+	}
+
+	return people, nil
+}
+
+// GetUserDataByPerson gets a user's items (excluding chunks) that mention the person with the given key
+func (m *MongoDB) GetUserDataByPerson(ctx context.Context, userID, personKey string) ([]*UserData, error) {
+	cursor, err := m.database.Collection("user_data").Find(
+		ctx,
+		bson.M{
+			"user_id":              userID,
+			"parent_id":            bson.M{"$exists": false},
+			"metadata.people_keys": personKey,
+		},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var items []*UserData
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PublicProfile is a user's public "brain profile": the collections of saved items that
+// anyone may query by username
+type PublicProfile struct {
+	UserID      string    `bson:"user_id" json:"-"`
+	Username    string    `bson:"username" json:"username"`
+	DisplayName string    `bson:"display_name,omitempty" json:"display_name,omitempty"`
+	Bio         string    `bson:"bio,omitempty" json:"bio,omitempty"`
+	Collections []string  `bson:"collections" json:"collections"` // content types that are public
+	CreatedAt   time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// GetPublicProfile gets a user's public profile, returning nil if they have none
+func (m *MongoDB) GetPublicProfile(ctx context.Context, userID string) (*PublicProfile, error) {
+	return m.findPublicProfile(ctx, bson.M{"user_id": userID})
+}
+
+// GetPublicProfileByUsername gets the public profile with the given username, returning
+// nil if there is none
+func (m *MongoDB) GetPublicProfileByUsername(ctx context.Context, username string) (*PublicProfile, error) {
+	return m.findPublicProfile(ctx, bson.M{"username": username})
+}
+
+// findPublicProfile gets the public profile matching filter, returning nil if there is none
+func (m *MongoDB) findPublicProfile(ctx context.Context, filter bson.M) (*PublicProfile, error) {
+	var profile PublicProfile
+	err := m.database.Collection("public_profiles").FindOne(ctx, filter).Decode(&profile)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &profile, nil
+}
+
+// UpsertPublicProfile creates or replaces a user's public profile. A username taken by
+// another user fails with a duplicate key error.
+func (m *MongoDB) UpsertPublicProfile(ctx context.Context, profile *PublicProfile) error {
+	now := time.Now()
+	profile.UpdatedAt = now
+
+	_, err := m.database.Collection("public_profiles").UpdateOne(
+		ctx,
+		bson.M{"user_id": profile.UserID},
+		bson.M{
+			"$set": bson.M{
+				"username":     profile.Username,
+				"display_name": profile.DisplayName,
+				"bio":          profile.Bio,
+				"collections":  profile.Collections,
+				"updated_at":   now,
+			},
+			"$setOnInsert": bson.M{"created_at": now},
+		},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// DeletePublicProfile removes a user's public profile, reporting whether there was one
+func (m *MongoDB) DeletePublicProfile(ctx context.Context, userID string) (bool, error) {
+	result, err := m.database.Collection("public_profiles").DeleteOne(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PushSubscription represents a browser Web Push subscription registered by a user
+type PushSubscription struct {
+	UserID    string    `bson:"user_id" json:"user_id"`
+	Endpoint  string    `bson:"endpoint" json:"endpoint"`
+	P256dh    string    `bson:"p256dh" json:"p256dh"`
+	Auth      string    `bson:"auth" json:"auth"`
+	UserAgent string    `bson:"user_agent,omitempty" json:"user_agent,omitempty"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+// UpsertPushSubscription registers a push subscription, replacing any existing one with the same endpoint
+func (m *MongoDB) UpsertPushSubscription(ctx context.Context, sub *PushSubscription) error {
+	if sub.CreatedAt.IsZero() {
+		sub.CreatedAt = time.Now()
+	}
+
+	_, err := m.database.Collection("push_subscriptions").ReplaceOne(
+		ctx,
+		bson.M{"endpoint": sub.Endpoint},
+		sub,
+		options.Replace().SetUpsert(true),
+	)
+	return err
+}
+
+// GetPushSubscriptions gets all push subscriptions for a user
+func (m *MongoDB) GetPushSubscriptions(ctx context.Context, userID string) ([]*PushSubscription, error) {
+	cursor, err := m.database.Collection("push_subscriptions").Find(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var subs []*PushSubscription
+	if err := cursor.All(ctx, &subs); err != nil {
+		return nil, err
+	}
+
+	return subs, nil
+}
+
+// DeletePushSubscription removes a user's push subscription by endpoint, reporting whether one was removed
+func (m *MongoDB) DeletePushSubscription(ctx context.Context, userID, endpoint string) (bool, error) {
+	result, err := m.database.Collection("push_subscriptions").DeleteOne(ctx, bson.M{
+		"user_id":  userID,
+		"endpoint": endpoint,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return result.DeletedCount > 0, nil
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SlackIntegration connects a user to their account in a Slack workspace where the app is
+// installed. Messages the Slack user saves with the message action or the save reaction
+// are stored for the user.
+type SlackIntegration struct {
+	UserID      string     `bson:"user_id" json:"user_id"`
+	TeamID      string     `bson:"team_id" json:"team_id"`
+	TeamName    string     `bson:"team_name" json:"team_name"`
+	SlackUserID string     `bson:"slack_user_id" json:"slack_user_id"`
+	BotToken    string     `bson:"bot_token" json:"-"`
+	UserToken   string     `bson:"user_token" json:"-"`            // reads messages the Slack user can see
+	Plan        string     `bson:"plan,omitempty" json:"-"`        // user's plan when installed, for quotas of saved messages
+	SavedCount  int        `bson:"saved_count" json:"saved_count"` // messages saved from Slack
+	LastSavedAt *time.Time `bson:"last_saved_at,omitempty" json:"last_saved_at,omitempty"`
+	CreatedAt   time.Time  `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time  `bson:"updated_at" json:"updated_at"`
+}
+
+// GetSlackIntegration gets a user's Slack integration, returning nil if none is installed
+func (m *MongoDB) GetSlackIntegration(ctx context.Context, userID string) (*SlackIntegration, error) {
+	var integration SlackIntegration
+	err := m.database.Collection("slack_integrations").FindOne(ctx, bson.M{"user_id": userID}).Decode(&integration)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &integration, nil
+}
+
+// GetSlackIntegrationBySlackUser gets the integration of a Slack user in a workspace,
+// returning nil if they haven't connected ForgetAI
+func (m *MongoDB) GetSlackIntegrationBySlackUser(ctx context.Context, teamID, slackUserID string) (*SlackIntegration, error) {
+	var integration SlackIntegration
+	err := m.database.Collection("slack_integrations").FindOne(ctx, bson.M{
+		"team_id":       teamID,
+		"slack_user_id": slackUserID,
+	}).Decode(&integration)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &integration, nil
+}
+
+// UpsertSlackIntegration creates or replaces a user's Slack integration. A Slack user can
+// be connected to one user, so their connection to any other user is removed.
+func (m *MongoDB) UpsertSlackIntegration(ctx context.Context, integration *SlackIntegration) error {
+	collection := m.database.Collection("slack_integrations")
+	now := time.Now()
+	if integration.CreatedAt.IsZero() {
+		integration.CreatedAt = now
+	}
+	integration.UpdatedAt = now
+
+	if _, err := collection.DeleteMany(ctx, bson.M{
+		"team_id":       integration.TeamID,
+		"slack_user_id": integration.SlackUserID,
+		"user_id":       bson.M{"$ne": integration.UserID},
+	}); err != nil {
+		return err
+	}
+
+	_, err := collection.ReplaceOne(
+		ctx,
+		bson.M{"user_id": integration.UserID},
+		integration,
+		options.Replace().SetUpsert(true),
+	)
+	return err
+}
+
+// RecordSlackSave counts a message saved through a user's Slack integration
+func (m *MongoDB) RecordSlackSave(ctx context.Context, userID string) error {
+	now := time.Now()
+	_, err := m.database.Collection("slack_integrations").UpdateOne(ctx,
+		bson.M{"user_id": userID},
+		bson.M{
+			"$inc": bson.M{"saved_count": 1},
+			"$set": bson.M{"last_saved_at": now, "updated_at": now},
+		},
+	)
+	return err
+}
+
+// DeleteSlackIntegration removes a user's Slack integration, reporting whether one existed
+func (m *MongoDB) DeleteSlackIntegration(ctx context.Context, userID string) (bool, error) {
+	result, err := m.database.Collection("slack_integrations").DeleteOne(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
+
+// DeleteSlackTeamIntegrations removes every integration with a workspace, after the app is
+// uninstalled from it, returning how many were removed
+func (m *MongoDB) DeleteSlackTeamIntegrations(ctx context.Context, teamID string) (int64, error) {
+	result, err := m.database.Collection("slack_integrations").DeleteMany(ctx, bson.M{"team_id": teamID})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
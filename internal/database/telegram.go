@@ -0,0 +1,137 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TelegramLink connects a user to the Telegram chat whose messages to the bot are saved
+// for them. Until a chat is linked it holds the one-time code that links one.
+type TelegramLink struct {
+	UserID          string     `bson:"user_id" json:"user_id"`
+	ChatID          int64      `bson:"chat_id,omitempty" json:"-"`
+	Username        string     `bson:"username,omitempty" json:"username,omitempty"` // Telegram username of the linked chat's user
+	Plan            string     `bson:"plan,omitempty" json:"-"`                      // user's plan when linked, for quotas of saved messages
+	LinkCode        string     `bson:"link_code,omitempty" json:"-"`
+	LinkCodeExpires *time.Time `bson:"link_code_expires_at,omitempty" json:"-"`
+	LinkedAt        *time.Time `bson:"linked_at,omitempty" json:"linked_at,omitempty"`
+	LastMessageAt   *time.Time `bson:"last_message_at,omitempty" json:"last_message_at,omitempty"`
+	CreatedAt       time.Time  `bson:"created_at" json:"created_at"`
+	UpdatedAt       time.Time  `bson:"updated_at" json:"updated_at"`
+}
+
+// GetTelegramLink gets a user's Telegram link, returning nil if they have none
+func (m *MongoDB) GetTelegramLink(ctx context.Context, userID string) (*TelegramLink, error) {
+	var link TelegramLink
+	err := m.database.Collection("telegram_links").FindOne(ctx, bson.M{"user_id": userID}).Decode(&link)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// GetTelegramLinkByChat gets the link of a Telegram chat, returning nil if the chat isn't
+// linked to a user
+func (m *MongoDB) GetTelegramLinkByChat(ctx context.Context, chatID int64) (*TelegramLink, error) {
+	var link TelegramLink
+	err := m.database.Collection("telegram_links").FindOne(ctx, bson.M{"chat_id": chatID}).Decode(&link)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// SetTelegramLinkCode stores a new one-time link code for a user, replacing any earlier
+// code. A chat already linked stays linked until the code is used.
+func (m *MongoDB) SetTelegramLinkCode(ctx context.Context, userID, plan, code string, expiresAt time.Time) error {
+	now := time.Now()
+	_, err := m.database.Collection("telegram_links").UpdateOne(
+		ctx,
+		bson.M{"user_id": userID},
+		bson.M{
+			"$set": bson.M{
+				"plan":                 plan,
+				"link_code":            code,
+				"link_code_expires_at": expiresAt,
+				"updated_at":           now,
+			},
+			"$setOnInsert": bson.M{"created_at": now},
+		},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// LinkTelegramChat links a chat to the user holding an unexpired link code, using up the
+// code. The chat is first unlinked from any other user, so each chat saves for one user.
+// It returns nil if no user holds the code.
+func (m *MongoDB) LinkTelegramChat(ctx context.Context, code string, chatID int64, username string) (*TelegramLink, error) {
+	collection := m.database.Collection("telegram_links")
+	now := time.Now()
+
+	var pending TelegramLink
+	err := collection.FindOne(ctx, bson.M{
+		"link_code":            code,
+		"link_code_expires_at": bson.M{"$gt": now},
+	}).Decode(&pending)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := collection.UpdateMany(ctx,
+		bson.M{"chat_id": chatID, "user_id": bson.M{"$ne": pending.UserID}},
+		bson.M{"$unset": bson.M{"chat_id": "", "username": "", "linked_at": ""}, "$set": bson.M{"updated_at": now}},
+	); err != nil {
+		return nil, err
+	}
+
+	var link TelegramLink
+	err = collection.FindOneAndUpdate(ctx,
+		bson.M{"user_id": pending.UserID, "link_code": code},
+		bson.M{
+			"$set":   bson.M{"chat_id": chatID, "username": username, "linked_at": now, "updated_at": now},
+			"$unset": bson.M{"link_code": "", "link_code_expires_at": ""},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&link)
+	if err == mongo.ErrNoDocuments {
+		// The code was used or replaced in the meantime
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// TouchTelegramLink records that a linked chat sent a message
+func (m *MongoDB) TouchTelegramLink(ctx context.Context, userID string) error {
+	now := time.Now()
+	_, err := m.database.Collection("telegram_links").UpdateOne(ctx,
+		bson.M{"user_id": userID},
+		bson.M{"$set": bson.M{"last_message_at": now, "updated_at": now}},
+	)
+	return err
+}
+
+// DeleteTelegramLink removes a user's Telegram link, reporting whether one existed
+func (m *MongoDB) DeleteTelegramLink(ctx context.Context, userID string) (bool, error) {
+	result, err := m.database.Collection("telegram_links").DeleteOne(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
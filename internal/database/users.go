@@ -0,0 +1,101 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// UserSummary is a user with saved data and how much they have saved
+type UserSummary struct {
+	UserID       string    `bson:"_id" json:"user_id"`
+	ItemCount    int64     `bson:"item_count" json:"item_count"`
+	FirstSavedAt time.Time `bson:"first_saved_at" json:"first_saved_at"`
+	LastSavedAt  time.Time `bson:"last_saved_at" json:"last_saved_at"`
+}
+
+// ListUserSummaries gets users with saved data in user ID order, starting after the given
+// user ID so results can be paged through
+func (m *MongoDB) ListUserSummaries(ctx context.Context, afterUserID string, limit int64) ([]*UserSummary, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"user_id": bson.M{"$gt": afterUserID}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": "$user_id",
+			"item_count": bson.M{"$sum": bson.M{
+				"$cond": bson.A{bson.M{"$gt": bson.A{"$parent_id", nil}}, 0, 1},
+			}},
+			"first_saved_at": bson.M{"$min": "$created_at"},
+			"last_saved_at":  bson.M{"$max": "$created_at"},
+		}}},
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+		{{Key: "$limit", Value: limit}},
+	}
+
+	cursor, err := m.database.Collection("user_data").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []*UserSummary
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// GetUserSummary gets a user's saved data summary and item counts by type, returning nil
+// if they have no data
+func (m *MongoDB) GetUserSummary(ctx context.Context, userID string) (*UserSummary, map[string]int64, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"user_id": userID}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": "$data_type",
+			"item_count": bson.M{"$sum": bson.M{
+				"$cond": bson.A{bson.M{"$gt": bson.A{"$parent_id", nil}}, 0, 1},
+			}},
+			"first_saved_at": bson.M{"$min": "$created_at"},
+			"last_saved_at":  bson.M{"$max": "$created_at"},
+		}}},
+	}
+
+	cursor, err := m.database.Collection("user_data").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer cursor.Close(ctx)
+
+	// Each group is one data type; chunk types contribute no items
+	var byType []struct {
+		DataType     string    `bson:"_id"`
+		ItemCount    int64     `bson:"item_count"`
+		FirstSavedAt time.Time `bson:"first_saved_at"`
+		LastSavedAt  time.Time `bson:"last_saved_at"`
+	}
+	if err := cursor.All(ctx, &byType); err != nil {
+		return nil, nil, err
+	}
+	if len(byType) == 0 {
+		return nil, nil, nil
+	}
+
+	summary := &UserSummary{UserID: userID}
+	counts := make(map[string]int64)
+	for _, group := range byType {
+		if group.ItemCount > 0 {
+			counts[group.DataType] = group.ItemCount
+		}
+		summary.ItemCount += group.ItemCount
+		if summary.FirstSavedAt.IsZero() || group.FirstSavedAt.Before(summary.FirstSavedAt) {
+			summary.FirstSavedAt = group.FirstSavedAt
+		}
+		if group.LastSavedAt.After(summary.LastSavedAt) {
+			summary.LastSavedAt = group.LastSavedAt
+		}
+	}
+
+	return summary, counts, nil
+}
@@ -0,0 +1,94 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ZoteroIntegration represents a user's connected Zotero library and its sync state
+type ZoteroIntegration struct {
+	UserID         string    `bson:"user_id" json:"user_id"`
+	ZoteroUserID   int64     `bson:"zotero_user_id" json:"zotero_user_id"`
+	ZoteroUsername string    `bson:"zotero_username" json:"zotero_username"`
+	APIKey         string    `bson:"api_key" json:"-"`
+	Plan           string    `bson:"plan,omitempty" json:"-"`                // user's plan when connected, for background sync quotas
+	LibraryVersion int64     `bson:"library_version" json:"library_version"` // last fully synced library version
+	LastSyncedAt   time.Time `bson:"last_synced_at,omitempty" json:"last_synced_at,omitempty"`
+	LastError      string    `bson:"last_error,omitempty" json:"last_error,omitempty"`
+	CreatedAt      time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt      time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// GetZoteroIntegration gets a user's Zotero integration, returning nil if none is connected
+func (m *MongoDB) GetZoteroIntegration(ctx context.Context, userID string) (*ZoteroIntegration, error) {
+	var integration ZoteroIntegration
+	err := m.database.Collection("zotero_integrations").FindOne(ctx, bson.M{"user_id": userID}).Decode(&integration)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &integration, nil
+}
+
+// ListZoteroIntegrations gets every connected Zotero integration
+func (m *MongoDB) ListZoteroIntegrations(ctx context.Context) ([]*ZoteroIntegration, error) {
+	cursor, err := m.database.Collection("zotero_integrations").Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var integrations []*ZoteroIntegration
+	if err := cursor.All(ctx, &integrations); err != nil {
+		return nil, err
+	}
+
+	return integrations, nil
+}
+
+// UpsertZoteroIntegration creates or replaces a user's Zotero integration
+func (m *MongoDB) UpsertZoteroIntegration(ctx context.Context, integration *ZoteroIntegration) error {
+	now := time.Now()
+	if integration.CreatedAt.IsZero() {
+		integration.CreatedAt = now
+	}
+	integration.UpdatedAt = now
+
+	_, err := m.database.Collection("zotero_integrations").ReplaceOne(
+		ctx,
+		bson.M{"user_id": integration.UserID},
+		integration,
+		options.Replace().SetUpsert(true),
+	)
+	return err
+}
+
+// UpdateZoteroSyncState records the outcome of a sync: the library version synced up to
+// and the sync's error, if any
+func (m *MongoDB) UpdateZoteroSyncState(ctx context.Context, userID string, libraryVersion int64, lastError string) error {
+	set := bson.M{
+		"library_version": libraryVersion,
+		"last_synced_at":  time.Now(),
+		"last_error":      lastError,
+		"updated_at":      time.Now(),
+	}
+
+	_, err := m.database.Collection("zotero_integrations").UpdateOne(ctx, bson.M{"user_id": userID}, bson.M{"$set": set})
+	return err
+}
+
+// DeleteZoteroIntegration removes a user's Zotero integration, reporting whether one existed
+func (m *MongoDB) DeleteZoteroIntegration(ctx context.Context, userID string) (bool, error) {
+	result, err := m.database.Collection("zotero_integrations").DeleteOne(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
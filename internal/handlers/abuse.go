@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/auth"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/models"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Windows and thresholds of the usage patterns flagged as abuse
+const (
+	// repeatedSavesThreshold is how often one text may be saved within repeatedSavesWindow
+	repeatedSavesWindow    = 24 * time.Hour
+	repeatedSavesThreshold = 200
+	// queryStormThreshold is how many queries a user may make within queryStormWindow
+	queryStormWindow    = 10 * time.Minute
+	queryStormThreshold = 100
+	// sharedAddressAccounts is how many accounts may use one client address within
+	// sharedAddressWindow
+	sharedAddressWindow   = 24 * time.Hour
+	sharedAddressAccounts = 5
+)
+
+const (
+	// abuseThrottleDuration is how long flagged users are throttled while awaiting review
+	abuseThrottleDuration = 24 * time.Hour
+	// abuseDetectionTimeout bounds one run of the abuse analyzer
+	abuseDetectionTimeout = 5 * time.Minute
+)
+
+// RecordClientAddress records which client addresses users make requests from, so the
+// abuse analyzer can find many accounts used from one address. Requests made by support
+// while impersonating a user aren't the user's.
+func (h *Handlers) RecordClientAddress(c *gin.Context) {
+	userID := c.GetString("userId")
+	address := c.ClientIP()
+	if userID == "" || address == "" || auth.GetImpersonation(c) != nil {
+		c.Next()
+		return
+	}
+
+	if h.Redis.FirstClientAddressUse(c.Request.Context(), userID, address) {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := h.DB.RecordClientAddress(ctx, userID, address); err != nil {
+				fmt.Printf("Warning: Failed to record client address of user %s: %v\n", userID, err)
+			}
+		}()
+	}
+	c.Next()
+}
+
+// EnforceAbuseThrottle limits users flagged for anomalous usage to ThrottledHourlyLimit
+// rate-limited requests per hour until their throttling ends or an admin dismisses the flag
+func (h *Handlers) EnforceAbuseThrottle(c *gin.Context) {
+	userID := c.GetString("userId")
+	if userID == "" || auth.GetImpersonation(c) != nil {
+		c.Next()
+		return
+	}
+
+	ctx := c.Request.Context()
+	until := h.Redis.ThrottledUntil(ctx, userID)
+	if until.IsZero() {
+		c.Next()
+		return
+	}
+
+	if _, exceeded := h.Redis.CheckThrottledRateLimit(ctx, userID); exceeded {
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error":           i18n.T(c, "Requests are throttled after unusual activity. Maximum %d requests per hour.", services.ThrottledHourlyLimit),
+			"code":            i18n.ErrorCode(http.StatusTooManyRequests),
+			"limit":           services.ThrottledHourlyLimit,
+			"throttled_until": until.Format(time.RFC3339),
+			"retry_after":     i18n.T(c, "Try again later"),
+		})
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
+// ListAbuseFlags handles admin requests for the abuse review queue, newest first. Open
+// flags are listed unless another status, or "all", is given.
+func (h *Handlers) ListAbuseFlags(c *gin.Context) {
+	if !h.requireAdmin(c) {
+		return
+	}
+
+	status := c.DefaultQuery("status", database.AbuseFlagOpen)
+	switch status {
+	case "all":
+		status = ""
+	case database.AbuseFlagOpen, database.AbuseFlagConfirmed, database.AbuseFlagDismissed:
+	default:
+		i18n.RespondError(c, http.StatusBadRequest, nil, "Unknown abuse flag status: %s", status)
+		return
+	}
+
+	limit := defaultActivityLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		n, err := strconv.Atoi(limitStr)
+		if err != nil || n <= 0 {
+			i18n.RespondError(c, http.StatusBadRequest, nil, "limit must be a positive integer")
+			return
+		}
+		if n > maxActivityLimit {
+			n = maxActivityLimit
+		}
+		limit = n
+	}
+
+	flags, err := h.DB.ListAbuseFlags(c.Request.Context(), status, int64(limit))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch abuse flags")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"flags": flags,
+		"count": len(flags),
+	})
+}
+
+// ReviewAbuseFlag handles admin verdicts on flagged usage. Dismissing a flag ends the
+// user's throttling; confirmed flags stay throttled until the throttling expires.
+func (h *Handlers) ReviewAbuseFlag(c *gin.Context) {
+	if !h.requireAdmin(c) {
+		return
+	}
+
+	var req models.AbuseFlagReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+		return
+	}
+	if req.Status != database.AbuseFlagConfirmed && req.Status != database.AbuseFlagDismissed {
+		i18n.RespondError(c, http.StatusBadRequest, nil, "Unknown abuse flag status: %s", req.Status)
+		return
+	}
+
+	ctx := c.Request.Context()
+	flag, err := h.DB.ReviewAbuseFlag(ctx, c.Param("id"), req.Status)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		i18n.RespondError(c, http.StatusNotFound, nil, "Abuse flag not found")
+		return
+	}
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to review abuse flag")
+		return
+	}
+
+	if flag.Status == database.AbuseFlagDismissed {
+		if err := h.Redis.UnthrottleUser(ctx, flag.UserID); err != nil {
+			fmt.Printf("Warning: Failed to end throttling of user %s: %v\n", flag.UserID, err)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(c, "Abuse flag reviewed"),
+		"flag":    flag,
+	})
+}
+
+// RunAbuseDetection looks for anomalous usage every interval until ctx is cancelled,
+// queueing it for review and throttling the users involved
+func (h *Handlers) RunAbuseDetection(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		runCtx, cancel := context.WithTimeout(ctx, abuseDetectionTimeout)
+		h.detectAbuse(runCtx)
+		cancel()
+	}
+}
+
+// detectAbuse flags the users whose recent usage matches each anomalous pattern
+func (h *Handlers) detectAbuse(ctx context.Context) {
+	now := time.Now()
+
+	since := now.Add(-repeatedSavesWindow)
+	saves, err := h.DB.GetRepeatedSaves(ctx, since, repeatedSavesThreshold)
+	if err != nil {
+		fmt.Printf("Warning: Failed to find repeated saves: %v\n", err)
+	}
+	for _, save := range saves {
+		h.flagAbuse(ctx, &database.AbuseFlag{
+			UserID: save.UserID,
+			Kind:   database.AbuseRepeatedSaves,
+			Details: map[string]interface{}{
+				"text":   save.Text,
+				"count":  save.Count,
+				"window": repeatedSavesWindow.String(),
+			},
+		}, since)
+	}
+
+	since = now.Add(-queryStormWindow)
+	bursts, err := h.DB.GetQueryBursts(ctx, since, queryStormThreshold)
+	if err != nil {
+		fmt.Printf("Warning: Failed to find query storms: %v\n", err)
+	}
+	for _, burst := range bursts {
+		h.flagAbuse(ctx, &database.AbuseFlag{
+			UserID: burst.UserID,
+			Kind:   database.AbuseQueryStorm,
+			Details: map[string]interface{}{
+				"count":  burst.Count,
+				"window": queryStormWindow.String(),
+			},
+		}, since)
+	}
+
+	since = now.Add(-sharedAddressWindow)
+	addresses, err := h.DB.GetSharedAddresses(ctx, since, sharedAddressAccounts)
+	if err != nil {
+		fmt.Printf("Warning: Failed to find shared client addresses: %v\n", err)
+	}
+	for _, address := range addresses {
+		for _, userID := range address.UserIDs {
+			h.flagAbuse(ctx, &database.AbuseFlag{
+				UserID: userID,
+				Kind:   database.AbuseSharedAddress,
+				Details: map[string]interface{}{
+					"address":  address.Address,
+					"accounts": address.UserIDs,
+					"window":   sharedAddressWindow.String(),
+				},
+			}, since)
+		}
+	}
+}
+
+// flagAbuse queues a user's anomalous usage since the given time for review and throttles
+// them. Each detection while the usage continues extends the throttling.
+func (h *Handlers) flagAbuse(ctx context.Context, flag *database.AbuseFlag, since time.Time) {
+	flag.ThrottledUntil = time.Now().Add(abuseThrottleDuration)
+	flagged, err := h.DB.FlagAbuse(ctx, flag, since)
+	if err != nil {
+		fmt.Printf("Warning: Failed to flag %s by user %s: %v\n", flag.Kind, flag.UserID, err)
+		return
+	}
+	if !flagged {
+		return
+	}
+
+	h.Redis.ThrottleUser(ctx, flag.UserID, flag.ThrottledUntil)
+}
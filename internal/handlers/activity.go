@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+)
+
+const (
+	defaultActivityLimit = 50
+	maxActivityLimit     = 200
+)
+
+// recordAudit records a user action in the audit log. Failures are logged and
+// never fail the request that triggered them.
+func (h *Handlers) recordAudit(ctx context.Context, event *database.AuditEvent) {
+	if err := h.DB.CreateAuditEvent(ctx, event); err != nil {
+		fmt.Printf("Warning: Failed to record audit event %s for user %s: %v\n", event.Action, event.UserID, err)
+	}
+
+	// Saves and queries are metered from the same events the activity feed shows
+	switch event.Action {
+	case database.AuditActionSave:
+		h.Billing.Emit(event.UserID, services.BillingMetricSaves, 1, map[string]interface{}{"type": event.ItemType})
+	case database.AuditActionQuery:
+		h.Billing.Emit(event.UserID, services.BillingMetricQueries, 1, nil)
+	}
+}
+
+// GetActivity handles activity feed requests, returning the user's own actions newest first
+func (h *Handlers) GetActivity(c *gin.Context) {
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	limit := defaultActivityLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		n, err := strconv.Atoi(limitStr)
+		if err != nil || n <= 0 {
+			i18n.RespondError(c, http.StatusBadRequest, nil, "limit must be a positive integer")
+			return
+		}
+		if n > maxActivityLimit {
+			n = maxActivityLimit
+		}
+		limit = n
+	}
+
+	var before time.Time
+	if beforeStr := c.Query("before"); beforeStr != "" {
+		t, err := time.Parse(time.RFC3339, beforeStr)
+		if err != nil {
+			i18n.RespondError(c, http.StatusBadRequest, nil, "before must be an RFC3339 timestamp")
+			return
+		}
+		before = t
+	}
+
+	events, err := h.DB.GetAuditEvents(c.Request.Context(), userID.(string), before, int64(limit))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch activity")
+		return
+	}
+
+	response := gin.H{
+		"user_id": userID,
+		"events":  events,
+		"count":   len(events),
+	}
+	if len(events) == limit {
+		response["next_before"] = events[len(events)-1].CreatedAt.Format(time.RFC3339Nano)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,343 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/models"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+	"github.com/siddhantgupta/forgetai-backend/internal/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// maxAgentSteps is how many rounds of tool calls the agent may make before it must answer
+	maxAgentSteps = 5
+	// maxAgentSearchResults is the number of matches a search_memory call returns
+	maxAgentSearchResults = 8
+	// maxAgentListItems is the number of items a list_items call returns
+	maxAgentListItems = 20
+	// maxAgentDocumentLength is the most characters of a document get_document returns
+	maxAgentDocumentLength = 8000
+	// agentTitleLength is the length items are shortened to in list_items results
+	agentTitleLength = 120
+)
+
+// agentGuideline tells the model what the agent mode tools are for
+const agentGuideline = "You can call tools to search the user's saved data again with a refined query, read a full saved document, or list items by type or by person. When the context above is not enough or the question spans several saved items, use them before answering"
+
+// agentTools are the internal tools available in agent mode
+var agentTools = []openai.Tool{
+	{
+		Type: openai.ToolTypeFunction,
+		Function: &openai.FunctionDefinition{
+			Name:        "search_memory",
+			Description: "Search the user's saved data by meaning. Returns the best matching passages with the ID of the item each belongs to.",
+			Parameters: json.RawMessage(`{
+				"type": "object",
+				"properties": {
+					"query": {"type": "string", "description": "What to search for, phrased as a question or keywords"},
+					"source_types": {"type": "array", "items": {"type": "string"}, "description": "Optional content types to restrict the search to, e.g. pdf, tweet, meeting"}
+				},
+				"required": ["query"]
+			}`),
+		},
+	},
+	{
+		Type: openai.ToolTypeFunction,
+		Function: &openai.FunctionDefinition{
+			Name:        "get_document",
+			Description: "Read the full text of a saved item, given an item ID from search_memory or list_items.",
+			Parameters: json.RawMessage(`{
+				"type": "object",
+				"properties": {
+					"item_id": {"type": "string"}
+				},
+				"required": ["item_id"]
+			}`),
+		},
+	},
+	{
+		Type: openai.ToolTypeFunction,
+		Function: &openai.FunctionDefinition{
+			Name:        "list_items",
+			Description: "List the user's saved items of a content type or involving a person, newest first.",
+			Parameters: json.RawMessage(`{
+				"type": "object",
+				"properties": {
+					"type": {"type": "string", "description": "Content type, e.g. note, pdf, meeting, github"},
+					"person": {"type": "string", "description": "Name of a person the items mention or involve"}
+				}
+			}`),
+		},
+	},
+}
+
+// agentItem is an item as reported to the model by the agent tools
+type agentItem struct {
+	ItemID    string    `json:"item_id"`
+	Type      string    `json:"type"`
+	Score     float32   `json:"score,omitempty"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// runAgent answers a query by letting the model call tools over the user's saved data
+// until it answers or runs out of steps. Answers in a requested format or schema are
+// generated afterwards from what the tools found.
+func (h *Handlers) runAgent(ctx context.Context, userID string, messages []openai.ChatCompletionMessage, filter services.QueryFilter, format string, responseSchema json.RawMessage) (string, json.RawMessage, []models.AgentStep, error) {
+	var steps []models.AgentStep
+	var notes strings.Builder
+	answer := ""
+
+	for step := 0; ; step++ {
+		message, err := h.OpenAI.GetToolCompletionContext(ctx, messages, agentTools, step < maxAgentSteps)
+		if err != nil {
+			return "", nil, steps, err
+		}
+		messages = append(messages, message)
+		if len(message.ToolCalls) == 0 {
+			answer = message.Content
+			break
+		}
+
+		for _, call := range message.ToolCalls {
+			result, err := h.runAgentTool(ctx, userID, filter, call.Function.Name, call.Function.Arguments)
+			agentStep := models.AgentStep{Tool: call.Function.Name, Arguments: json.RawMessage(call.Function.Arguments)}
+			if !json.Valid(agentStep.Arguments) {
+				agentStep.Arguments = nil
+			}
+			if err != nil {
+				agentStep.Error = err.Error()
+				result = "Error: " + err.Error()
+			} else {
+				fmt.Fprintf(&notes, "%s(%s): %s\n\n", call.Function.Name, call.Function.Arguments, result)
+			}
+			steps = append(steps, agentStep)
+			messages = append(messages, openai.ChatCompletionMessage{
+				Role:       openai.ChatMessageRoleTool,
+				Content:    result,
+				ToolCallID: call.ID,
+			})
+		}
+	}
+
+	if format == "" && responseSchema == nil {
+		return answer, nil, steps, nil
+	}
+
+	// The formatting stage doesn't offer tools, so it sees the tool results as notes
+	formatted := append([]openai.ChatCompletionMessage{}, messages[0])
+	if notes.Len() > 0 {
+		formatted = append(formatted, openai.ChatCompletionMessage{
+			Role:    openai.ChatMessageRoleSystem,
+			Content: "Further context found with tools:\n" + notes.String(),
+		})
+	}
+	for _, message := range messages[1:] {
+		if message.Role == openai.ChatMessageRoleUser || (message.Role == openai.ChatMessageRoleAssistant && len(message.ToolCalls) == 0) {
+			formatted = append(formatted, message)
+		}
+	}
+	answer, structured, err := h.completeAnswer(ctx, formatted[:len(formatted)-1], format, responseSchema)
+	return answer, structured, steps, err
+}
+
+// runAgentTool runs one tool call and returns its result as JSON for the model
+func (h *Handlers) runAgentTool(ctx context.Context, userID string, filter services.QueryFilter, name, arguments string) (string, error) {
+	var result interface{}
+	var err error
+	switch name {
+	case "search_memory":
+		var args struct {
+			Query       string   `json:"query"`
+			SourceTypes []string `json:"source_types"`
+		}
+		if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+			return "", fmt.Errorf("invalid arguments: %v", err)
+		}
+		result, err = h.agentSearch(ctx, filter, args.Query, args.SourceTypes)
+	case "get_document":
+		var args struct {
+			ItemID string `json:"item_id"`
+		}
+		if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+			return "", fmt.Errorf("invalid arguments: %v", err)
+		}
+		result, err = h.agentDocument(ctx, userID, args.ItemID)
+	case "list_items":
+		var args struct {
+			Type   string `json:"type"`
+			Person string `json:"person"`
+		}
+		if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+			return "", fmt.Errorf("invalid arguments: %v", err)
+		}
+		result, err = h.agentList(ctx, userID, args.Type, args.Person)
+	default:
+		return "", fmt.Errorf("unknown tool %q", name)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// agentSearch runs a retrieval for a refined query within the original query's filters
+func (h *Handlers) agentSearch(ctx context.Context, filter services.QueryFilter, query string, sourceTypes []string) ([]agentItem, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, fmt.Errorf("query must not be empty")
+	}
+	if len(sourceTypes) > 0 {
+		types, err := normalizeSourceTypes(sourceTypes)
+		if err != nil {
+			return nil, err
+		}
+		filter.Types = types
+	}
+
+	embedding, err := h.queryEmbedding(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get embedding: %v", err)
+	}
+	opts := h.defaultRetrievalOptions()
+	opts.MaxMatches = maxAgentSearchResults
+	retrieval, err := h.retrieve(ctx, filter, embedding, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %v", err)
+	}
+	included := retrieval.Included()
+
+	// Report the item each match belongs to, so the model can read the whole document.
+	// Title vectors have no record, so they're resolved to their document directly.
+	vectorIDs := make([]string, 0, len(included))
+	var titleParentIDs []primitive.ObjectID
+	for _, candidate := range included {
+		if parentID, ok := titleVectorParent(candidate.ID); ok {
+			titleParentIDs = append(titleParentIDs, parentID)
+			continue
+		}
+		vectorIDs = append(vectorIDs, candidate.ID)
+	}
+	records, err := h.DB.GetUserDataByVectorIDs(ctx, filter.UserID, vectorIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up items: %v", err)
+	}
+	byVectorID := make(map[string]*database.UserData, len(records))
+	for _, record := range records {
+		byVectorID[record.VectorID] = record
+	}
+	byParentID := make(map[primitive.ObjectID]*database.UserData, len(titleParentIDs))
+	if len(titleParentIDs) > 0 {
+		parents, err := h.DB.GetUserDataByIDs(ctx, filter.UserID, titleParentIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up items: %v", err)
+		}
+		for _, parent := range parents {
+			byParentID[parent.ID] = parent
+		}
+	}
+
+	items := make([]agentItem, 0, len(included))
+	for _, candidate := range included {
+		item := agentItem{Type: candidate.Type, Score: candidate.Score, Text: candidate.Text}
+		if record, ok := byVectorID[candidate.ID]; ok {
+			item.ItemID = record.ID.Hex()
+			if record.ParentID != nil {
+				item.ItemID = record.ParentID.Hex()
+			}
+			item.CreatedAt = record.CreatedAt
+		} else if parentID, ok := titleVectorParent(candidate.ID); ok {
+			if parent, ok := byParentID[parentID]; ok {
+				item.ItemID = parent.ID.Hex()
+				item.CreatedAt = parent.CreatedAt
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// agentDocument returns a saved item with the text of all its chunks
+func (h *Handlers) agentDocument(ctx context.Context, userID, itemID string) (*agentItem, error) {
+	record, err := h.DB.GetUserDataByID(ctx, itemID)
+	if err == mongo.ErrNoDocuments || (err == nil && record.UserID != userID) {
+		return nil, fmt.Errorf("item not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch item: %v", err)
+	}
+	if record.ParentID != nil {
+		if record, err = h.DB.GetUserDataByID(ctx, record.ParentID.Hex()); err != nil {
+			return nil, fmt.Errorf("failed to fetch item: %v", err)
+		}
+	}
+
+	text := record.DataValue
+	chunks, err := h.DB.GetChunks(ctx, record.ID.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch item content: %v", err)
+	}
+	if len(chunks) > 0 {
+		sort.Slice(chunks, func(i, j int) bool { return chunks[i].ChunkIndex < chunks[j].ChunkIndex })
+		parts := []string{record.DataValue}
+		for _, chunk := range chunks {
+			parts = append(parts, chunk.DataValue)
+		}
+		text = strings.Join(parts, "\n\n")
+	}
+
+	return &agentItem{
+		ItemID:    record.ID.Hex(),
+		Type:      record.DataType,
+		Text:      utils.Truncate(text, maxAgentDocumentLength),
+		CreatedAt: record.CreatedAt,
+	}, nil
+}
+
+// agentList returns the user's newest items of a type or involving a person
+func (h *Handlers) agentList(ctx context.Context, userID, dataType, person string) ([]agentItem, error) {
+	var records []*database.UserData
+	var err error
+	switch {
+	case person != "":
+		records, err = h.DB.GetUserDataByPerson(ctx, userID, services.PersonKey(person))
+	case dataType != "":
+		records, err = h.DB.GetUserDataByType(ctx, userID, strings.ToLower(strings.TrimSpace(dataType)))
+	default:
+		return nil, fmt.Errorf("type or person is required")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list items: %v", err)
+	}
+
+	sort.Slice(records, func(i, j int) bool { return records[i].CreatedAt.After(records[j].CreatedAt) })
+	items := make([]agentItem, 0, maxAgentListItems)
+	for _, record := range records {
+		if record.ParentID != nil || (dataType != "" && person != "" && record.DataType != dataType) {
+			continue
+		}
+		items = append(items, agentItem{
+			ItemID:    record.ID.Hex(),
+			Type:      record.DataType,
+			Text:      utils.Truncate(record.DataValue, agentTitleLength),
+			CreatedAt: record.CreatedAt,
+		})
+		if len(items) >= maxAgentListItems {
+			break
+		}
+	}
+	return items, nil
+}
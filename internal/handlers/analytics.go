@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+)
+
+const (
+	// groundedMinScore is the best match score below which a query is treated as having
+	// found nothing relevant in the user's saved data
+	groundedMinScore = 0.3
+	// maxQueryTopics is the number of topics recorded for one query
+	maxQueryTopics = 5
+	// minTopicLength is the shortest word recorded as a query topic
+	minTopicLength = 3
+
+	defaultAnalyticsDays = 30
+	maxAnalyticsDays     = 365
+	analyticsListLimit   = 20
+)
+
+// topicStopWords are common words that never make a useful query topic
+var topicStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "are": true, "was": true, "were": true, "what": true,
+	"when": true, "where": true, "which": true, "who": true, "whom": true, "why": true, "how": true,
+	"did": true, "does": true, "doing": true, "have": true, "has": true, "had": true, "with": true,
+	"about": true, "from": true, "that": true, "this": true, "those": true, "these": true, "there": true,
+	"their": true, "they": true, "them": true, "you": true, "your": true, "can": true, "could": true,
+	"would": true, "should": true, "will": true, "any": true, "all": true, "some": true, "saved": true,
+	"save": true, "remember": true, "tell": true, "find": true, "show": true, "give": true, "know": true,
+	"said": true, "say": true, "into": true, "over": true, "than": true, "then": true, "also": true,
+	"just": true, "like": true, "not": true, "but": true, "our": true, "out": true, "get": true,
+	"got": true, "been": true, "being": true, "its": true, "his": true, "her": true, "she": true,
+	"him": true, "mine": true, "notes": true, "note": true, "last": true, "ever": true,
+}
+
+// queryTopics returns the significant words of a query, used to group similar queries
+func queryTopics(query string) []string {
+	topics := queryTerms(query)
+	if len(topics) > maxQueryTopics {
+		topics = topics[:maxQueryTopics]
+	}
+	return topics
+}
+
+// queryTerms returns the distinct significant words of a query in order
+func queryTerms(query string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(query), isWordSeparator) {
+		if len([]rune(word)) < minTopicLength || topicStopWords[word] || seen[word] {
+			continue
+		}
+		seen[word] = true
+		terms = append(terms, word)
+	}
+	return terms
+}
+
+// isWordSeparator reports whether a rune separates words
+func isWordSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// isGrounded reports whether retrieval found saved data relevant enough to answer from
+func isGrounded(included []*retrievalCandidate) bool {
+	return len(included) > 0 && included[0].Score >= groundedMinScore
+}
+
+// topScore returns the score of the best included match, or 0 if there is none
+func topScore(included []*retrievalCandidate) float32 {
+	if len(included) == 0 {
+		return 0
+	}
+	return included[0].Score
+}
+
+// GetQueryAnalytics handles requests for the user's search analytics: what they ask about
+// most and which questions their saved data could not answer
+func (h *Handlers) GetQueryAnalytics(c *gin.Context) {
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	days := defaultAnalyticsDays
+	if daysStr := c.Query("days"); daysStr != "" {
+		n, err := strconv.Atoi(daysStr)
+		if err != nil || n <= 0 {
+			i18n.RespondError(c, http.StatusBadRequest, nil, "days must be a positive integer")
+			return
+		}
+		if n > maxAnalyticsDays {
+			n = maxAnalyticsDays
+		}
+		days = n
+	}
+	since := time.Now().AddDate(0, 0, -days)
+
+	ctx := c.Request.Context()
+	counts, err := h.DB.GetQueryCounts(ctx, userID.(string), since)
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch query analytics")
+		return
+	}
+	topics, err := h.DB.GetTopQueryTopics(ctx, userID.(string), since, analyticsListLimit)
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch query analytics")
+		return
+	}
+	ungrounded, err := h.DB.GetUngroundedQueries(ctx, userID.(string), since, analyticsListLimit)
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch query analytics")
+		return
+	}
+	if topics == nil {
+		topics = []*database.TopicCount{}
+	}
+	if ungrounded == nil {
+		ungrounded = []*database.UngroundedQuery{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user_id":            userID,
+		"days":               days,
+		"total_queries":      counts.Total,
+		"ungrounded_count":   counts.Ungrounded,
+		"top_topics":         topics,
+		"ungrounded_queries": ungrounded,
+	})
+}
@@ -0,0 +1,267 @@
+package handlers
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	// backupPrefix is where user data backups are stored in the bucket; per-user backups
+	// go under backupPrefix + "users/<user ID>/"
+	backupPrefix = "backups/user_data/"
+	// backupTimeout bounds one backup or restore
+	backupTimeout = 6 * time.Hour
+	// restoreBatchSize is how many documents are written back per bulk write
+	restoreBatchSize = 500
+	// maxBackupLineSize fits the largest possible MongoDB document as extended JSON
+	maxBackupLineSize = 64 << 20
+)
+
+// backupObjectPrefix returns the object prefix for full backups or one user's backups
+func backupObjectPrefix(userID string) string {
+	if userID == "" {
+		return backupPrefix
+	}
+	return backupPrefix + "users/" + userID + "/"
+}
+
+// StartBackup handles admin requests to export user data, optionally for one user, to the
+// backup bucket as gzipped JSONL. The export runs in the background; the object appears
+// in the backup list once it finishes.
+func (h *Handlers) StartBackup(c *gin.Context) {
+	if !h.requireAdmin(c) {
+		return
+	}
+
+	if h.Backups == nil {
+		i18n.RespondError(c, http.StatusServiceUnavailable, nil, "Backups are not configured")
+		return
+	}
+
+	var req struct {
+		UserID string `json:"user_id"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+		return
+	}
+
+	if !h.backupMu.TryLock() {
+		i18n.RespondError(c, http.StatusConflict, nil, "A backup or restore is already running")
+		return
+	}
+
+	object := backupObjectPrefix(req.UserID) + time.Now().UTC().Format("20060102T150405Z") + ".jsonl.gz"
+	go func() {
+		defer h.backupMu.Unlock()
+		ctx, cancel := context.WithTimeout(context.Background(), backupTimeout)
+		defer cancel()
+		h.runBackup(ctx, object, req.UserID)
+	}()
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": i18n.T(c, "Backup started"),
+		"object":  object,
+	})
+}
+
+// ListBackups handles admin requests for stored backups, optionally only one user's
+func (h *Handlers) ListBackups(c *gin.Context) {
+	if !h.requireAdmin(c) {
+		return
+	}
+
+	if h.Backups == nil {
+		i18n.RespondError(c, http.StatusServiceUnavailable, nil, "Backups are not configured")
+		return
+	}
+
+	objects, err := h.Backups.List(c.Request.Context(), backupObjectPrefix(c.Query("user_id")))
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadGateway, err, "Failed to list backups")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"backups": objects,
+		"count":   len(objects),
+	})
+}
+
+// RestoreBackup handles admin requests to write a backup back into MongoDB, optionally
+// only one user's documents. Documents are replaced by ID, so restoring twice is safe.
+// Vectors are not part of backups; restored documents keep their vector IDs.
+func (h *Handlers) RestoreBackup(c *gin.Context) {
+	if !h.requireAdmin(c) {
+		return
+	}
+
+	if h.Backups == nil {
+		i18n.RespondError(c, http.StatusServiceUnavailable, nil, "Backups are not configured")
+		return
+	}
+
+	var req struct {
+		Object string `json:"object" binding:"required"`
+		UserID string `json:"user_id"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+		return
+	}
+	if !strings.HasPrefix(req.Object, backupPrefix) {
+		i18n.RespondError(c, http.StatusBadRequest, nil, "object is not a backup")
+		return
+	}
+
+	if !h.backupMu.TryLock() {
+		i18n.RespondError(c, http.StatusConflict, nil, "A backup or restore is already running")
+		return
+	}
+
+	go func() {
+		defer h.backupMu.Unlock()
+		ctx, cancel := context.WithTimeout(context.Background(), backupTimeout)
+		defer cancel()
+		h.runRestore(ctx, req.Object, req.UserID)
+	}()
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": i18n.T(c, "Restore started"),
+		"object":  req.Object,
+	})
+}
+
+// RunBackups exports all user data every interval until ctx is cancelled. Scheduled
+// backups are skipped while an admin-triggered backup or restore is running.
+func (h *Handlers) RunBackups(ctx context.Context, interval time.Duration) {
+	if h.Backups == nil || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !h.backupMu.TryLock() {
+			continue
+		}
+		backupCtx, cancel := context.WithTimeout(ctx, backupTimeout)
+		h.runBackup(backupCtx, backupPrefix+time.Now().UTC().Format("20060102T150405Z")+".jsonl.gz", "")
+		cancel()
+		h.backupMu.Unlock()
+	}
+}
+
+// runBackup streams user data as gzipped extended JSON lines into a backup object
+func (h *Handlers) runBackup(ctx context.Context, object, userID string) {
+	start := time.Now()
+	reader, writer := io.Pipe()
+
+	count := 0
+	go func() {
+		gz := gzip.NewWriter(writer)
+		err := h.DB.ExportUserData(ctx, userID, func(doc bson.Raw) error {
+			// Canonical extended JSON keeps ObjectIDs, dates and number types intact
+			line, err := bson.MarshalExtJSON(doc, true, false)
+			if err != nil {
+				return err
+			}
+			if _, err := gz.Write(append(line, '\n')); err != nil {
+				return err
+			}
+			count++
+			return nil
+		})
+		if err == nil {
+			err = gz.Close()
+		}
+		writer.CloseWithError(err)
+	}()
+
+	if err := h.Backups.Upload(ctx, object, "application/gzip", reader); err != nil {
+		reader.CloseWithError(err)
+		fmt.Printf("Warning: Backup %s failed: %v\n", object, err)
+		return
+	}
+	fmt.Printf("Backup %s: exported %d documents in %v\n", object, count, time.Since(start).Round(time.Second))
+}
+
+// runRestore writes the documents in a backup object back into MongoDB in batches
+func (h *Handlers) runRestore(ctx context.Context, object, userID string) {
+	inserted, replaced, err := h.restoreBackup(ctx, object, userID)
+	if err != nil {
+		fmt.Printf("Warning: Restore of %s failed after %d inserted and %d replaced documents: %v\n", object, inserted, replaced, err)
+		return
+	}
+	fmt.Printf("Restore %s: inserted %d and replaced %d documents\n", object, inserted, replaced)
+}
+
+// restoreBackup reads a backup object and writes its documents back, returning how many
+// were inserted and replaced so far
+func (h *Handlers) restoreBackup(ctx context.Context, object, userID string) (int64, int64, error) {
+	body, err := h.Backups.Download(ctx, object)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer body.Close()
+
+	gz, err := gzip.NewReader(body)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to decompress backup: %w", err)
+	}
+	defer gz.Close()
+
+	var inserted, replaced int64
+	var batch []bson.Raw
+	flush := func() error {
+		i, r, err := h.DB.RestoreUserData(ctx, batch)
+		inserted += i
+		replaced += r
+		batch = batch[:0]
+		return err
+	}
+
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxBackupLineSize)
+	for scanner.Scan() {
+		var doc bson.Raw
+		if err := bson.UnmarshalExtJSON(scanner.Bytes(), true, &doc); err != nil {
+			return inserted, replaced, fmt.Errorf("failed to decode backup line: %w", err)
+		}
+		if userID != "" {
+			if owner, _ := doc.Lookup("user_id").StringValueOK(); owner != userID {
+				continue
+			}
+		}
+		batch = append(batch, doc)
+		if len(batch) >= restoreBatchSize {
+			if err := flush(); err != nil {
+				return inserted, replaced, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return inserted, replaced, fmt.Errorf("failed to read backup: %w", err)
+	}
+	if err := flush(); err != nil {
+		return inserted, replaced, err
+	}
+	return inserted, replaced, nil
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+)
+
+// storageMeteringPageSize is how many users are read per page when metering storage
+const storageMeteringPageSize = 500
+
+// RunStorageMetering emits a storage snapshot for every user with saved data every
+// interval until ctx is cancelled
+func (h *Handlers) RunStorageMetering(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		h.meterStorage(ctx)
+	}
+}
+
+// meterStorage emits each user's stored characters, with item and vector counts
+func (h *Handlers) meterStorage(ctx context.Context) {
+	after := ""
+	for {
+		users, err := h.DB.ListUserSummaries(ctx, after, storageMeteringPageSize)
+		if err != nil {
+			fmt.Printf("Warning: Failed to list users for storage metering: %v\n", err)
+			return
+		}
+
+		for _, user := range users {
+			if ctx.Err() != nil {
+				return
+			}
+			usage, err := h.DB.GetStorageUsage(ctx, user.UserID)
+			if err != nil {
+				fmt.Printf("Warning: Failed to get storage usage of %s: %v\n", user.UserID, err)
+				continue
+			}
+			h.Billing.Emit(user.UserID, services.BillingMetricStorage, usage.Characters, map[string]interface{}{
+				"items":   usage.Items,
+				"vectors": usage.Vectors,
+			})
+		}
+
+		if len(users) < storageMeteringPageSize {
+			return
+		}
+		after = users[len(users)-1].UserID
+	}
+}
@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/chunking"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+	"github.com/siddhantgupta/forgetai-backend/internal/textnorm"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// maxBookmarksPerBatch is the most bookmarks accepted by one import request
+	maxBookmarksPerBatch = 1000
+	// bookmarkImportWorkers is how many bookmarks of a batch are fetched and embedded at once
+	bookmarkImportWorkers = 4
+	// bookmarkImportTimeout bounds one batch import
+	bookmarkImportTimeout = 2 * time.Hour
+	// maxListedJobs is how many of the user's most recent jobs are listed
+	maxListedJobs = 50
+	// jobNotificationTimeout bounds telling a user their background job finished
+	jobNotificationTimeout = 30 * time.Second
+)
+
+// bookmarkItem is one bookmark of an import batch
+type bookmarkItem struct {
+	URL       string    `json:"url"`
+	Title     string    `json:"title"`
+	Note      string    `json:"note"`
+	Timestamp time.Time `json:"-"` // when the bookmark was made, for imports; defaults to now
+}
+
+// SaveBookmarks handles importing a batch of bookmarks, e.g. a browser's bookmarks bar.
+// Each bookmark's page is fetched and stored with its title and note in the background;
+// the response carries the ID of a job reporting the import's progress.
+func (h *Handlers) SaveBookmarks(c *gin.Context) {
+	var req struct {
+		Bookmarks []bookmarkItem `json:"bookmarks" binding:"required"`
+		chunkingFields
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+		return
+	}
+	options, err := req.options(h.Chunking)
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid chunking parameters")
+		return
+	}
+	if len(req.Bookmarks) == 0 || len(req.Bookmarks) > maxBookmarksPerBatch {
+		i18n.RespondError(c, http.StatusBadRequest, nil, "bookmarks must have 1 to %d items", maxBookmarksPerBatch)
+		return
+	}
+
+	userId, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusInternalServerError, nil, "User ID not found in request context")
+		return
+	}
+
+	job := &database.Job{
+		UserID: userId.(string),
+		Kind:   "bookmarks",
+		Total:  len(req.Bookmarks),
+	}
+	if err := h.DB.CreateJob(c.Request.Context(), job); err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to start bookmark import")
+		return
+	}
+
+	plan := requestPlan(c)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), bookmarkImportTimeout)
+		defer cancel()
+		h.runBookmarkImport(ctx, job, plan, options, req.Bookmarks)
+	}()
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": i18n.T(c, "Bookmark import started"),
+		"job_id":  job.ID.Hex(),
+		"total":   job.Total,
+	})
+}
+
+// GetJob handles retrieving the progress of one of the user's background jobs, with the
+// result of each item finished so far. The results can be filtered by status, e.g.
+// ?status=failed to list only the items that failed and why. Document jobs also report how
+// many of the stored document's chunks have had their vectors written.
+func (h *Handlers) GetJob(c *gin.Context) {
+	userId, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusInternalServerError, nil, "User ID not found in request context")
+		return
+	}
+
+	id := c.Param("id")
+	if !primitive.IsValidObjectID(id) {
+		i18n.RespondError(c, http.StatusNotFound, nil, "Job not found")
+		return
+	}
+	status := c.Query("status")
+	switch status {
+	case "", database.JobItemSucceeded, database.JobItemFailed, database.JobItemSkipped:
+	default:
+		i18n.RespondError(c, http.StatusBadRequest, nil, "status must be succeeded, failed or skipped")
+		return
+	}
+
+	job, err := h.DB.GetJob(c.Request.Context(), id, userId.(string))
+	if err == mongo.ErrNoDocuments {
+		i18n.RespondError(c, http.StatusNotFound, nil, "Job not found")
+		return
+	}
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch job")
+		return
+	}
+
+	response := gin.H{}
+	if job.Kind == "document" {
+		if progress := h.documentEmbeddingProgress(c.Request.Context(), job); progress != nil {
+			response["embedding"] = progress
+		}
+	}
+
+	if status != "" {
+		items := make([]database.JobItem, 0, len(job.Items))
+		for _, item := range job.Items {
+			if item.Status == status {
+				items = append(items, item)
+			}
+		}
+		job.Items = items
+	}
+
+	response["job"] = job
+	c.JSON(http.StatusOK, response)
+}
+
+// ListJobs handles listing the user's most recent background jobs, without their item
+// results
+func (h *Handlers) ListJobs(c *gin.Context) {
+	userId, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusInternalServerError, nil, "User ID not found in request context")
+		return
+	}
+
+	jobs, err := h.DB.ListJobs(c.Request.Context(), userId.(string), maxListedJobs)
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch jobs")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"jobs": jobs,
+	})
+}
+
+// runBookmarkImport imports a batch of bookmarks, recording each on the job as succeeded,
+// failed or skipped, with the reason, as it finishes, and notifies the user when it's
+// done. A failed bookmark doesn't stop the rest of the batch. Bookmarks repeated in the
+// batch or already saved are skipped, as are URLs that aren't web pages, such as
+// bookmarklets.
+func (h *Handlers) runBookmarkImport(ctx context.Context, job *database.Job, plan string, options chunking.Options, bookmarks []bookmarkItem) {
+	run := h.startJob(ctx, job)
+
+	type queuedBookmark struct {
+		index    int
+		bookmark bookmarkItem
+	}
+	var queued []queuedBookmark
+	seen := make(map[string]bool, len(bookmarks))
+	for i, bookmark := range bookmarks {
+		key := strings.TrimSpace(bookmark.URL)
+		bookmark.URL = textnorm.StripTracking(key)
+		if !isPageURL(bookmark.URL) {
+			run.record(ctx, database.JobItem{Index: i, Key: key, Status: database.JobItemSkipped, Reason: "not a web page URL"})
+			continue
+		}
+		if seen[bookmark.URL] {
+			run.record(ctx, database.JobItem{Index: i, Key: bookmark.URL, Status: database.JobItemSkipped, Reason: "repeated in the batch"})
+			continue
+		}
+		seen[bookmark.URL] = true
+		queued = append(queued, queuedBookmark{index: i, bookmark: bookmark})
+	}
+
+	details := map[string]interface{}{"job_id": job.ID.Hex()}
+	runConcurrently(ctx, bookmarkImportWorkers, len(queued), func(i int) {
+		bookmark := queued[i].bookmark
+		item := database.JobItem{Index: queued[i].index, Key: bookmark.URL}
+		saved, err := h.importBookmark(ctx, job.UserID, plan, options, bookmark, "bookmark_import", details)
+		switch {
+		case err != nil:
+			fmt.Printf("Warning: Failed to import bookmark %s: %v\n", bookmark.URL, err)
+			item.Status, item.Reason = database.JobItemFailed, err.Error()
+		case saved == nil:
+			item.Status, item.Reason = database.JobItemSkipped, "already saved"
+		default:
+			item.Status, item.ItemID = database.JobItemSucceeded, saved.ID.Hex()
+		}
+		run.record(ctx, item)
+	})
+
+	run.finish(ctx, "import timed out")
+	h.notifyImportComplete(job, "Bookmark import finished", "bookmarks")
+}
+
+// notifyImportComplete tells a user that a bulk import job finished and how its items
+// fared, e.g. "40 of 42 bookmarks imported, 2 skipped."
+func (h *Handlers) notifyImportComplete(job *database.Job, title, items string) {
+	ctx, cancel := context.WithTimeout(context.Background(), jobNotificationTimeout)
+	defer cancel()
+
+	finished, err := h.DB.GetJob(ctx, job.ID.Hex(), job.UserID)
+	if err != nil {
+		fmt.Printf("Warning: Failed to fetch job %s: %v\n", job.ID.Hex(), err)
+		return
+	}
+	body := fmt.Sprintf("%d of %d %s imported", finished.Succeeded, finished.Total, items)
+	if finished.Skipped > 0 {
+		body += fmt.Sprintf(", %d skipped", finished.Skipped)
+	}
+	if finished.Failed > 0 {
+		body += fmt.Sprintf(", %d failed", finished.Failed)
+	}
+	body += "."
+	if finished.Error != "" {
+		body += fmt.Sprintf(" The import stopped early: %s.", finished.Error)
+	}
+
+	if err := h.Notifier.Notify(ctx, job.UserID, services.Notification{
+		Type:  services.NotificationImportComplete,
+		Title: title,
+		Body:  body,
+	}); err != nil {
+		fmt.Printf("Warning: Failed to send import notification: %v\n", err)
+	}
+}
+
+// isPageURL reports whether a URL is a web page that can be fetched and bookmarked
+func isPageURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// importBookmark stores a bookmark with its page's text, returning nil if the URL is
+// already saved. A page that can't be fetched is still saved by its title, URL and note.
+// details, such as the import's job ID, are added to the bookmark's metadata and to the
+// audit event, which names the source of the save.
+func (h *Handlers) importBookmark(ctx context.Context, userID, plan string, options chunking.Options, bookmark bookmarkItem, source string, details map[string]interface{}) (*database.UserData, error) {
+	if _, err := h.DB.GetUserDataByMetadata(ctx, userID, "bookmark", "url", bookmark.URL); err == nil {
+		return nil, nil
+	} else if err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	var pageText, pageTitle string
+	page, err := h.WebPages.Fetch(ctx, bookmark.URL)
+	if err != nil {
+		fmt.Printf("Warning: Failed to fetch bookmarked page %s: %v\n", bookmark.URL, err)
+	} else {
+		pageText, pageTitle = page.Text, page.Title
+	}
+
+	title := strings.TrimSpace(bookmark.Title)
+	if title == "" {
+		title = strings.TrimSpace(pageTitle)
+	}
+	if title == "" {
+		title = bookmark.URL
+	}
+	note := textnorm.Normalize(bookmark.Note)
+
+	doc := &parentDocument{
+		UserID: userID,
+		Plan:   plan,
+		Type:   "bookmark",
+		Title:  title,
+		Metadata: map[string]interface{}{
+			"url":          bookmark.URL,
+			"note":         note,
+			"page_fetched": err == nil,
+		},
+		Timestamp: bookmark.Timestamp,
+	}
+	auditDetails := map[string]interface{}{"source": source, "url": bookmark.URL}
+	for key, value := range details {
+		doc.Metadata[key] = value
+		auditDetails[key] = value
+	}
+	// Chunks are stored as written but embedded without URLs; emoji-only chunks are dropped
+	options = sourceChunking(options)
+	doc.recordChunking(options)
+	addChunks := func(section, text string) {
+		splitProse(text, options, func(chunk, withContext string) {
+			if textnorm.EmbeddingText(chunk) == "" {
+				return
+			}
+			doc.Chunks = append(doc.Chunks, documentChunk{
+				Text:       chunk,
+				VectorText: fmt.Sprintf("Bookmark: %s (%s): %s", title, section, chunk),
+				EmbedText:  fmt.Sprintf("Bookmark: %s (%s): %s", title, section, textnorm.EmbeddingText(withContext)),
+				Metadata:   map[string]interface{}{"section": section, "url": bookmark.URL},
+			})
+		})
+	}
+
+	summary := title + "\n" + bookmark.URL
+	if note != "" {
+		summary += "\n\n" + note
+	}
+	addChunks("bookmark", summary)
+	addChunks("page", pageText)
+
+	record, _, err := h.ingestDocument(ctx, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	h.recordAudit(ctx, &database.AuditEvent{
+		UserID:   userID,
+		Action:   database.AuditActionSave,
+		ItemID:   record.ID.Hex(),
+		ItemType: "bookmark",
+		Summary:  title,
+		Details:  auditDetails,
+	})
+	return record, nil
+}
@@ -0,0 +1,459 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/chunking"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/utils"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// DeleteChunk handles removing a single chunk (e.g. a noisy appendix section) of a
+// document from retrieval, keeping the rest of the document
+func (h *Handlers) DeleteChunk(c *gin.Context) {
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	chunkIndex, err := strconv.Atoi(c.Param("chunkIndex"))
+	if err != nil || chunkIndex < 0 {
+		i18n.RespondError(c, http.StatusBadRequest, nil, "Invalid chunk index")
+		return
+	}
+
+	ctx := c.Request.Context()
+	idStr := c.Param("id")
+	userData, err := h.DB.GetUserDataByID(ctx, idStr)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			i18n.RespondError(c, http.StatusNotFound, nil, "Item not found")
+		} else {
+			i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch item")
+		}
+		return
+	}
+	if userData.UserID != userID.(string) {
+		i18n.RespondError(c, http.StatusForbidden, nil, "Not authorized to delete this item")
+		return
+	}
+	if !isParentType(userData.DataType) {
+		i18n.RespondError(c, http.StatusBadRequest, nil, "Item has no chunks")
+		return
+	}
+
+	chunks, err := h.DB.GetChunks(ctx, idStr)
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch item")
+		return
+	}
+	var chunk *database.UserData
+	for _, candidate := range chunks {
+		if candidate.ChunkIndex == chunkIndex {
+			chunk = candidate
+			break
+		}
+	}
+	if chunk == nil {
+		i18n.RespondError(c, http.StatusNotFound, nil, "Chunk not found")
+		return
+	}
+	// The last chunk goes with its document, so no empty document remains
+	if len(chunks) == 1 {
+		i18n.RespondError(c, http.StatusBadRequest, nil, "Cannot delete the only chunk of a document")
+		return
+	}
+
+	// The record goes first so no pending vector write recreates the vector
+	if err := h.DB.DeleteUserData(ctx, chunk.ID.Hex(), chunk.UserID); err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to delete chunk")
+		return
+	}
+	if err := h.Pinecone.DeleteVector(ctx, chunk.VectorID); err != nil {
+		// Log error but continue
+		fmt.Printf("Warning: Failed to delete vector %s from Pinecone: %v\n", chunk.VectorID, err)
+	}
+	if err := h.DB.DeleteContradictionsForRecord(ctx, chunk.UserID, chunk.ID.Hex()); err != nil {
+		fmt.Printf("Warning: Failed to remove contradictions of %s: %v\n", chunk.ID.Hex(), err)
+	}
+	h.invalidateHotDocument(ctx, userData)
+
+	h.recordAudit(ctx, &database.AuditEvent{
+		UserID:   userData.UserID,
+		Action:   database.AuditActionDelete,
+		ItemID:   idStr,
+		ItemType: userData.DataType,
+		Summary:  utils.Truncate(chunk.DataValue, auditSummaryLength),
+		Details:  map[string]interface{}{"chunk_index": chunkIndex},
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          i18n.T(c, "Chunk deleted successfully"),
+		"id":               idStr,
+		"chunk_index":      chunkIndex,
+		"remaining_chunks": len(chunks) - 1,
+	})
+}
+
+// rechunkableTypes are the parent types whose chunks are cut from a single stored text
+// and can be rebuilt from it. Other imports carry per-chunk metadata (file paths,
+// sections, speakers) that is only known at import time.
+var rechunkableTypes = map[string]bool{
+	"pdf":      true,
+	"docx":     true,
+	"epub":     true,
+	"markdown": true,
+	"code":     true,
+}
+
+// chunkingMetadata records the chunking parameters a document was split with, so its
+// chunks can be joined back into the original text
+func chunkingMetadata(dataType string, options chunking.Options) map[string]interface{} {
+	strategy := options.Strategy
+	if strategy == "" {
+		strategy = chunking.StrategyCharacters
+		if options.SplitsHeadings(dataType) {
+			strategy = chunking.StrategyHeadings
+		}
+	}
+	size, overlap := options.Size, options.Overlap
+	if dataType == "code" {
+		strategy, size, overlap = "code", chunking.DefaultCodeChunkSize, 0
+	}
+	return map[string]interface{}{
+		"chunking": map[string]interface{}{
+			"strategy": strategy,
+			"size":     size,
+			"overlap":  overlap,
+		},
+	}
+}
+
+// chunkingOptions returns the chunking parameters a request asks for with the optional
+// chunk_strategy, chunk_size and chunk_overlap fields, in place of the configured defaults.
+// Sizes are in tokens for the tokens strategy and characters otherwise.
+func (h *Handlers) chunkingOptions(c *gin.Context) (chunking.Options, error) {
+	field := func(name string) string {
+		if value := c.PostForm(name); value != "" {
+			return value
+		}
+		return c.Query(name)
+	}
+
+	fields := chunkingFields{ChunkStrategy: field("chunk_strategy")}
+	for name, value := range map[string]**int{"chunk_size": &fields.ChunkSize, "chunk_overlap": &fields.ChunkOverlap} {
+		if str := field(name); str != "" {
+			n, err := strconv.Atoi(str)
+			if err != nil {
+				return h.Chunking, fmt.Errorf("%s must be an integer", name)
+			}
+			*value = &n
+		}
+	}
+	return fields.options(h.Chunking)
+}
+
+// chunkingFields are the optional chunking parameters of JSON ingestion requests, named
+// like the form fields of uploads
+type chunkingFields struct {
+	ChunkStrategy string `json:"chunk_strategy"`
+	ChunkSize     *int   `json:"chunk_size"`
+	ChunkOverlap  *int   `json:"chunk_overlap"`
+}
+
+// options returns the chunking parameters the fields ask for in place of defaults
+func (f chunkingFields) options(defaults chunking.Options) (chunking.Options, error) {
+	options := defaults
+	if f.ChunkStrategy != "" {
+		options = options.WithStrategy(strings.ToLower(f.ChunkStrategy))
+	}
+	if f.ChunkSize != nil {
+		options.Size = *f.ChunkSize
+	}
+	if f.ChunkOverlap != nil {
+		options.Overlap = *f.ChunkOverlap
+	}
+	return options, options.Validate()
+}
+
+// sourceChunking returns the options prose fetched from a source, such as a Reddit post or
+// a Slack thread, is chunked with. It isn't markdown, so it's never split at headings.
+func sourceChunking(options chunking.Options) chunking.Options {
+	if options.Strategy == chunking.StrategyHeadings {
+		options.Strategy = chunking.StrategyCharacters
+	}
+	return options
+}
+
+// recordChunking records the chunking parameters on the document's parent record
+func (doc *parentDocument) recordChunking(options chunking.Options) {
+	if doc.Metadata == nil {
+		doc.Metadata = make(map[string]interface{})
+	}
+	for key, value := range chunkingMetadata(doc.Type, options) {
+		doc.Metadata[key] = value
+	}
+}
+
+// splitProse splits prose into chunks of at most the options' size, calling add with each
+// chunk and the text it's embedded from: the chunk with the end of the previous chunk as
+// context
+func splitProse(text string, options chunking.Options, add func(chunk, withContext string)) {
+	previous := ""
+	for _, chunk := range chunking.ChunkText(text, options.MaxChars()) {
+		add(chunk, withOverlap(previous, chunk, options))
+		previous = chunk
+	}
+}
+
+// withOverlap prefixes a chunk with the end of the previous chunk, as context to embed it with
+func withOverlap(previous, chunk string, options chunking.Options) string {
+	if overlap := chunking.Overlap(previous, options.OverlapChars()); overlap != "" {
+		return overlap + "\n\n" + chunk
+	}
+	return chunk
+}
+
+// documentChunks splits an uploaded document's text into sentence-aware chunks. Each chunk
+// is embedded with the end of the previous chunk as context, while the stored text doesn't
+// overlap.
+func documentChunks(dataType, filename, text string, options chunking.Options) []documentChunk {
+	if options.SplitsHeadings(dataType) {
+		return markdownChunks(documentFormats[dataType].label, filename, text, options)
+	}
+
+	var chunks []documentChunk
+	splitProse(text, options, func(chunk, withContext string) {
+		chunks = append(chunks, documentChunk{
+			Text:       chunk,
+			VectorText: fmt.Sprintf("%s (%s): %s", documentFormats[dataType].label, filename, chunk),
+			EmbedText:  withContext,
+		})
+	})
+	return chunks
+}
+
+// markdownChunks splits a markdown document into chunks within its sections, stored with
+// the document's label and title. Each chunk records the headings it falls under, which
+// are stored and embedded with it, and only overlaps the previous chunk of the same
+// section when embedded.
+func markdownChunks(label, title, text string, options chunking.Options) []documentChunk {
+	var chunks []documentChunk
+	previous, previousSection := "", ""
+	for _, chunk := range chunking.ChunkMarkdown(text, options.MaxChars()) {
+		section := strings.Join(chunk.Headings, " > ")
+		embedText := chunk.Text
+		if overlap := chunking.Overlap(previous, options.OverlapChars()); overlap != "" && section == previousSection {
+			embedText = overlap + "\n\n" + chunk.Text
+		}
+
+		documentChunk := documentChunk{
+			Text:       chunk.Text,
+			VectorText: fmt.Sprintf("%s (%s): %s", label, title, chunk.Text),
+			EmbedText:  embedText,
+		}
+		if section != "" {
+			headings := make([]interface{}, len(chunk.Headings))
+			for i, heading := range chunk.Headings {
+				headings[i] = heading
+			}
+			documentChunk.VectorText = fmt.Sprintf("%s (%s, Section: %s): %s", label, title, section, chunk.Text)
+			documentChunk.EmbedText = fmt.Sprintf("Section: %s\n\n%s", section, embedText)
+			documentChunk.Metadata = map[string]interface{}{"section": section, "heading_path": headings}
+		}
+		chunks = append(chunks, documentChunk)
+		previous, previousSection = chunk.Text, section
+	}
+	return chunks
+}
+
+// codeChunks splits stored code into block chunks, embedded with its title and language
+func codeChunks(title, language, code string) []documentChunk {
+	var chunks []documentChunk
+	for _, chunk := range chunking.ChunkCode(code, language, chunking.DefaultCodeChunkSize) {
+		chunks = append(chunks, documentChunk{
+			Text:      chunk,
+			EmbedText: fmt.Sprintf("%s (%s):\n%s", title, language, chunk),
+			Metadata:  map[string]interface{}{"language": language},
+		})
+	}
+	return chunks
+}
+
+// storedDocumentText joins a document's chunks back into its text. PDFs imported before
+// chunking was recorded were cut into fixed-size pieces and are joined without separators.
+func storedDocumentText(parent *database.UserData, chunks []*database.UserData) string {
+	separator := "\n\n"
+	if _, recorded := parent.Metadata["chunking"]; parent.DataType == "pdf" && !recorded {
+		separator = ""
+	}
+	texts := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		texts = append(texts, chunk.DataValue)
+	}
+	return strings.Join(texts, separator)
+}
+
+// RechunkData handles re-processing a document's stored text with the configured chunking
+// parameters, or those the request asks for, replacing its chunks and their vectors.
+// Documents saved without their full text are rebuilt from their chunks.
+func (h *Handlers) RechunkData(c *gin.Context) {
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	ctx := c.Request.Context()
+	idStr := c.Param("id")
+	userData, err := h.DB.GetUserDataByID(ctx, idStr)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			i18n.RespondError(c, http.StatusNotFound, nil, "Item not found")
+		} else {
+			i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch item")
+		}
+		return
+	}
+	if userData.UserID != userID.(string) {
+		i18n.RespondError(c, http.StatusForbidden, nil, "Not authorized to modify this item")
+		return
+	}
+	if !isParentType(userData.DataType) {
+		i18n.RespondError(c, http.StatusBadRequest, nil, "Item has no chunks")
+		return
+	}
+	if !rechunkableTypes[userData.DataType] {
+		i18n.RespondError(c, http.StatusBadRequest, nil, "Item type cannot be re-chunked")
+		return
+	}
+	options, err := h.chunkingOptions(c)
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid chunking parameters")
+		return
+	}
+
+	oldChunks, err := h.DB.GetChunks(ctx, idStr)
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch item")
+		return
+	}
+	text, stored, err := h.storedFullText(ctx, userData)
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to load document text")
+		return
+	}
+	if !stored {
+		text = storedDocumentText(userData, oldChunks)
+	}
+
+	doc := &parentDocument{
+		UserID:    userData.UserID,
+		Plan:      requestPlan(c),
+		Type:      userData.DataType,
+		Title:     userData.DataValue,
+		Timestamp: userData.CreatedAt,
+	}
+	switch userData.DataType {
+	case "pdf", "docx", "epub", "markdown":
+		doc.Chunks = documentChunks(userData.DataType, userData.DataValue, text, options)
+	case "code":
+		language, _ := userData.Metadata["language"].(string)
+		doc.Chunks = codeChunks(userData.DataValue, language, text)
+	}
+	if len(doc.Chunks) == 0 {
+		i18n.RespondError(c, http.StatusBadRequest, nil, "Item has no chunks")
+		return
+	}
+
+	newChunks, err := h.replaceChunks(ctx, doc, userData, oldChunks)
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to re-chunk item")
+		return
+	}
+
+	metadata := chunkingMetadata(userData.DataType, options)
+	metadata["rechunked_at"] = time.Now().Format(time.RFC3339)
+	if err := h.DB.SetUserDataMetadata(ctx, userData.ID, userData.UserID, metadata); err != nil {
+		fmt.Printf("Warning: Failed to record chunking of %s: %v\n", idStr, err)
+	}
+
+	h.recordAudit(ctx, &database.AuditEvent{
+		UserID:   userData.UserID,
+		Action:   database.AuditActionSave,
+		ItemID:   idStr,
+		ItemType: userData.DataType,
+		Summary:  userData.DataValue,
+		Details:  map[string]interface{}{"rechunked": true, "previous_chunks": len(oldChunks), "chunk_count": newChunks},
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         i18n.T(c, "Item re-chunked successfully"),
+		"id":              idStr,
+		"previous_chunks": len(oldChunks),
+		"chunk_count":     newChunks,
+	})
+}
+
+// replaceChunks stores a document's new chunks under its parent, then removes the old
+// chunks with their vectors. If a new chunk fails, the new chunks are removed and the
+// old ones are kept. It returns the number of chunks stored.
+func (h *Handlers) replaceChunks(ctx context.Context, doc *parentDocument, parent *database.UserData, oldChunks []*database.UserData) (int, error) {
+	var vectorIds []string
+	for chunkIdx, chunk := range doc.Chunks {
+		if err := h.ingestChunk(ctx, doc, parent, chunkIdx, chunk, &vectorIds); err != nil {
+			written := make(map[string]bool, len(vectorIds))
+			for _, vectorId := range vectorIds {
+				written[vectorId] = true
+			}
+			h.removeChunks(ctx, parent, func(record *database.UserData) bool {
+				return written[record.VectorID]
+			})
+			return 0, err
+		}
+	}
+
+	old := make(map[string]bool, len(oldChunks))
+	for _, chunk := range oldChunks {
+		old[chunk.ID.Hex()] = true
+	}
+	h.removeChunks(ctx, parent, func(record *database.UserData) bool {
+		return old[record.ID.Hex()]
+	})
+	return len(vectorIds), nil
+}
+
+// removeChunks deletes the parent's chunks selected by match, records first so no pending
+// vector write recreates a vector
+func (h *Handlers) removeChunks(ctx context.Context, parent *database.UserData, match func(*database.UserData) bool) {
+	chunks, err := h.DB.GetChunks(ctx, parent.ID.Hex())
+	if err != nil {
+		fmt.Printf("Warning: Failed to fetch chunks of %s: %v\n", parent.ID.Hex(), err)
+		return
+	}
+	for _, chunk := range chunks {
+		if !match(chunk) {
+			continue
+		}
+		if err := h.DB.DeleteUserData(ctx, chunk.ID.Hex(), chunk.UserID); err != nil {
+			fmt.Printf("Warning: Failed to delete chunk %s: %v\n", chunk.ID.Hex(), err)
+			continue
+		}
+		if err := h.Pinecone.DeleteVector(ctx, chunk.VectorID); err != nil {
+			fmt.Printf("Warning: Failed to delete vector %s from Pinecone: %v\n", chunk.VectorID, err)
+		}
+		if err := h.DB.DeleteContradictionsForRecord(ctx, chunk.UserID, chunk.ID.Hex()); err != nil {
+			fmt.Printf("Warning: Failed to remove contradictions of %s: %v\n", chunk.ID.Hex(), err)
+		}
+	}
+	h.invalidateHotDocument(ctx, parent)
+}
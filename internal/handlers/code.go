@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/chunking"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+)
+
+// maxCodeLength is the largest code snippet accepted in characters
+const maxCodeLength = 200000
+
+// SaveCode handles code snippet saving requests. Code is chunked on function/block
+// boundaries and each chunk is stored with its language so it can be fenced in prompts.
+func (h *Handlers) SaveCode(c *gin.Context) {
+	var req struct {
+		Code     string `json:"code" binding:"required"`
+		Language string `json:"language" binding:"required"`
+		Title    string `json:"title"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+		return
+	}
+
+	userId, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusInternalServerError, nil, "User ID not found in request context")
+		return
+	}
+
+	if strings.TrimSpace(req.Code) == "" {
+		i18n.RespondError(c, http.StatusBadRequest, nil, "Code must not be empty")
+		return
+	}
+	if len(req.Code) > maxCodeLength {
+		i18n.RespondError(c, http.StatusBadRequest, nil, "Code must be at most %d characters", maxCodeLength)
+		return
+	}
+
+	language := chunking.NormalizeLanguage(req.Language)
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = fmt.Sprintf("%s snippet", language)
+	}
+
+	doc := &parentDocument{
+		UserID:   userId.(string),
+		Plan:     requestPlan(c),
+		Type:     "code",
+		Title:    title,
+		Metadata: map[string]interface{}{"language": language},
+		// The stored text is the raw code so it can be fenced verbatim, while the
+		// embedding includes the title and language for better recall
+		Chunks: codeChunks(title, language, req.Code),
+	}
+
+	codeRecord, vectorIds, err := h.ingestDocument(c.Request.Context(), doc)
+	if err != nil {
+		respondSaveError(c, err, "Failed to save code")
+		return
+	}
+
+	h.recordAudit(c.Request.Context(), &database.AuditEvent{
+		UserID:   userId.(string),
+		Action:   database.AuditActionSave,
+		ItemID:   codeRecord.ID.Hex(),
+		ItemType: "code",
+		Summary:  title,
+		Details:  map[string]interface{}{"language": language, "chunk_count": len(doc.Chunks)},
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     i18n.T(c, "Code saved successfully"),
+		"user_id":     userId.(string),
+		"type":        "code",
+		"language":    language,
+		"title":       title,
+		"chunk_count": len(doc.Chunks),
+		"vector_ids":  vectorIds,
+		"timestamp":   time.Now().Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,384 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+	"github.com/siddhantgupta/forgetai-backend/internal/utils"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// contradictionNeighbors is how many similar saved texts each item is compared with
+	contradictionNeighbors = 5
+	// contradictionMinScore is the similarity below which saved texts are taken to be on other topics
+	contradictionMinScore = 0.5
+	// contradictionScanConcurrency bounds the items checked in parallel during a scan
+	contradictionScanConcurrency = 4
+	// defaultContradictionScanItems is how many recent texts an on-demand scan checks
+	defaultContradictionScanItems = 20
+	// maxContradictionScanItems bounds the texts checked by one scan
+	maxContradictionScanItems = 50
+	// defaultContradictionLimit is the default number of contradictions listed
+	defaultContradictionLimit = 50
+	// maxContradictionLimit bounds the contradictions listed by one request
+	maxContradictionLimit = 200
+	// contradictionSideLength is the length of the text kept for each side of a contradiction
+	contradictionSideLength = 500
+	// contradictionScanPageSize is how many users are read per page by scheduled scans
+	contradictionScanPageSize = 500
+)
+
+// ListContradictions handles listing the user's contradicting items. The status query
+// parameter is "open" (the default), "dismissed" or "all".
+func (h *Handlers) ListContradictions(c *gin.Context) {
+	userId, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusInternalServerError, nil, "User ID not found in request context")
+		return
+	}
+
+	status := c.DefaultQuery("status", database.ContradictionOpen)
+	switch status {
+	case database.ContradictionOpen, database.ContradictionDismissed:
+	case "all":
+		status = ""
+	default:
+		i18n.RespondError(c, http.StatusBadRequest, nil, "Invalid status")
+		return
+	}
+
+	limit := defaultContradictionLimit
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			i18n.RespondError(c, http.StatusBadRequest, nil, "limit must be a positive integer")
+			return
+		}
+		if n > maxContradictionLimit {
+			n = maxContradictionLimit
+		}
+		limit = n
+	}
+
+	contradictions, err := h.DB.ListContradictions(c.Request.Context(), userId.(string), status, int64(limit))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to list contradictions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"contradictions": contradictions,
+		"count":          len(contradictions),
+	})
+}
+
+// ScanContradictions handles on-demand contradiction checks of the user's most recently
+// saved texts against the rest of their saved data
+func (h *Handlers) ScanContradictions(c *gin.Context) {
+	var req struct {
+		Limit int `json:"limit"`
+	}
+	// The body is optional
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+			return
+		}
+	}
+	switch {
+	case req.Limit == 0:
+		req.Limit = defaultContradictionScanItems
+	case req.Limit < 0:
+		i18n.RespondError(c, http.StatusBadRequest, nil, "limit must be a positive integer")
+		return
+	case req.Limit > maxContradictionScanItems:
+		req.Limit = maxContradictionScanItems
+	}
+
+	userId, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusInternalServerError, nil, "User ID not found in request context")
+		return
+	}
+
+	scanned, found, err := h.scanContradictions(c.Request.Context(), userId.(string), time.Time{}, req.Limit)
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to scan for contradictions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        i18n.T(c, "Contradiction scan complete"),
+		"scanned":        scanned,
+		"found":          len(found),
+		"contradictions": found,
+		"timestamp":      time.Now(),
+	})
+}
+
+// DismissContradiction handles marking a contradiction as not worth resolving. Dismissed
+// pairs are not reported again by later scans.
+func (h *Handlers) DismissContradiction(c *gin.Context) {
+	userId, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusInternalServerError, nil, "User ID not found in request context")
+		return
+	}
+
+	id := c.Param("id")
+	err := h.DB.SetContradictionStatus(c.Request.Context(), id, userId.(string), database.ContradictionDismissed)
+	if err == mongo.ErrNoDocuments {
+		i18n.RespondError(c, http.StatusNotFound, nil, "Contradiction not found")
+		return
+	}
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to dismiss contradiction")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(c, "Contradiction dismissed"),
+		"id":      id,
+		"status":  database.ContradictionDismissed,
+	})
+}
+
+// RunContradictionScans checks the texts users saved since the previous run for
+// contradictions every interval until ctx is cancelled, notifying users of new ones
+func (h *Handlers) RunContradictionScans(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	since := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		started := time.Now()
+		h.scanAllContradictions(ctx, since)
+		since = started
+	}
+}
+
+// scanAllContradictions scans the texts every user saved since the given time
+func (h *Handlers) scanAllContradictions(ctx context.Context, since time.Time) {
+	after := ""
+	for {
+		users, err := h.DB.ListUserSummaries(ctx, after, contradictionScanPageSize)
+		if err != nil {
+			fmt.Printf("Warning: Failed to list users for contradiction scans: %v\n", err)
+			return
+		}
+
+		for _, user := range users {
+			if ctx.Err() != nil {
+				return
+			}
+			if user.LastSavedAt.Before(since) {
+				continue
+			}
+
+			userCtx := services.WithBillingUser(ctx, user.UserID)
+			_, found, err := h.scanContradictions(userCtx, user.UserID, since, maxContradictionScanItems)
+			if err != nil {
+				fmt.Printf("Warning: Failed to scan %s for contradictions: %v\n", user.UserID, err)
+				continue
+			}
+			if len(found) == 0 {
+				continue
+			}
+
+			if err := h.Notifier.Notify(userCtx, user.UserID, services.Notification{
+				Type:  services.NotificationContradiction,
+				Title: "Conflicting notes found",
+				Body:  fmt.Sprintf("%d of your recently saved items conflict with other saved items.", len(found)),
+			}); err != nil {
+				fmt.Printf("Warning: Failed to send contradiction notification: %v\n", err)
+			}
+		}
+
+		if len(users) < contradictionScanPageSize {
+			return
+		}
+		after = users[len(users)-1].UserID
+	}
+}
+
+// scanContradictions compares up to limit of the user's texts saved since the given time
+// with their most similar saved texts, recording new contradictions. It returns how many
+// texts were checked and the contradictions found.
+func (h *Handlers) scanContradictions(ctx context.Context, userID string, since time.Time, limit int) (int, []*database.Contradiction, error) {
+	skipTypes := make([]string, 0, len(parentTypes))
+	for dataType := range parentTypes {
+		skipTypes = append(skipTypes, dataType)
+	}
+	records, err := h.DB.GetRecentRecords(ctx, userID, since, skipTypes, int64(limit))
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to get recent items: %w", err)
+	}
+
+	var mu sync.Mutex
+	var found []*database.Contradiction
+	var scanned int
+	var firstErr error
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, contradictionScanConcurrency)
+	for _, record := range records {
+		if record.Status == database.UserDataStatusFailed || strings.TrimSpace(record.DataValue) == "" {
+			continue
+		}
+
+		wg.Add(1)
+		go func(record *database.UserData) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			contradictions, err := h.checkContradictions(ctx, record)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			scanned++
+			found = append(found, contradictions...)
+		}(record)
+	}
+	wg.Wait()
+
+	// A partial scan still records what it found; it fails only if nothing could be checked
+	if scanned == 0 && firstErr != nil {
+		return 0, nil, firstErr
+	}
+	if firstErr != nil {
+		fmt.Printf("Warning: Contradiction scan of %s was incomplete: %v\n", userID, firstErr)
+	}
+	return scanned, found, nil
+}
+
+// checkContradictions compares one saved text with its nearest saved neighbors from other
+// items and records any new contradictions
+func (h *Handlers) checkContradictions(ctx context.Context, record *database.UserData) ([]*database.Contradiction, error) {
+	// The text is searched for among other saved items, so it's embedded as a query
+	embedding, err := h.OpenAI.GetQueryEmbedding(ctx, record.DataValue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get embedding: %v", err)
+	}
+
+	opts := h.defaultRetrievalOptions()
+	opts.MinScore = contradictionMinScore
+	retrieval, err := h.retrieve(ctx, services.QueryFilter{UserID: record.UserID}, embedding, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query similar items: %v", err)
+	}
+
+	var vectorIDs []string
+	for _, candidate := range retrieval.Included() {
+		if candidate.ID != record.VectorID {
+			vectorIDs = append(vectorIDs, candidate.ID)
+		}
+	}
+	if len(vectorIDs) == 0 {
+		return nil, nil
+	}
+	neighbors, err := h.DB.GetUserDataByVectorIDs(ctx, record.UserID, vectorIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up similar items: %v", err)
+	}
+	byVectorID := make(map[string]*database.UserData, len(neighbors))
+	pairKeys := make([]string, 0, len(neighbors))
+	for _, neighbor := range neighbors {
+		byVectorID[neighbor.VectorID] = neighbor
+		pairKeys = append(pairKeys, database.ContradictionPairKey(record.ID.Hex(), neighbor.ID.Hex()))
+	}
+	known, err := h.DB.GetContradictionPairs(ctx, record.UserID, pairKeys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up known contradictions: %v", err)
+	}
+
+	// Compare with other items only, in similarity order, skipping pairs already recorded
+	itemID := contradictionItemID(record)
+	var candidates []*database.UserData
+	for _, vectorID := range vectorIDs {
+		neighbor, ok := byVectorID[vectorID]
+		if !ok || contradictionItemID(neighbor) == itemID || known[database.ContradictionPairKey(record.ID.Hex(), neighbor.ID.Hex())] {
+			continue
+		}
+		candidates = append(candidates, neighbor)
+		if len(candidates) >= contradictionNeighbors {
+			break
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	texts := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		texts[i] = candidate.DataValue
+	}
+	findings, err := h.Conflicts.FindContradictions(ctx, record.DataValue, texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for contradictions: %v", err)
+	}
+
+	var created []*database.Contradiction
+	for _, finding := range findings {
+		neighbor := candidates[finding.Candidate]
+		contradiction := &database.Contradiction{
+			UserID:      record.UserID,
+			PairKey:     database.ContradictionPairKey(record.ID.Hex(), neighbor.ID.Hex()),
+			ItemIDs:     []string{itemID, contradictionItemID(neighbor)},
+			Sides:       []database.ContradictionSide{contradictionSide(record), contradictionSide(neighbor)},
+			Explanation: finding.Explanation,
+		}
+		isNew, err := h.DB.CreateContradiction(ctx, contradiction)
+		if err != nil {
+			return created, fmt.Errorf("failed to save contradiction: %w", err)
+		}
+		if isNew {
+			created = append(created, contradiction)
+		}
+	}
+	return created, nil
+}
+
+// contradictionItemID returns the ID of the top-level item a record belongs to
+func contradictionItemID(record *database.UserData) string {
+	if record.ParentID != nil {
+		return record.ParentID.Hex()
+	}
+	return record.ID.Hex()
+}
+
+// contradictionSide describes a record as one side of a contradiction
+func contradictionSide(record *database.UserData) database.ContradictionSide {
+	return database.ContradictionSide{
+		ItemID:   contradictionItemID(record),
+		RecordID: record.ID.Hex(),
+		ItemType: strings.TrimSuffix(record.DataType, "-chunk"),
+		Text:     utils.Truncate(record.DataValue, contradictionSideLength),
+	}
+}
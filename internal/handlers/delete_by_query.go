@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/models"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+	"github.com/siddhantgupta/forgetai-backend/internal/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// deleteByQueryMinScore is the default similarity required for an item to be deleted by description
+	deleteByQueryMinScore = 0.4
+	// deletionPreviewTTL is how long a preview token can be used to confirm a deletion
+	deletionPreviewTTL = 10 * time.Minute
+)
+
+// deletionPreview is the pending deletion stored in Redis under a preview token
+type deletionPreview struct {
+	UserID  string   `json:"user_id"`
+	ItemIDs []string `json:"item_ids"`
+}
+
+// DeleteByQuery handles natural-language bulk deletion. The first call embeds the
+// description and returns a preview with a token; the second call with that token
+// deletes exactly the previewed items.
+func (h *Handlers) DeleteByQuery(c *gin.Context) {
+	var req models.DeleteByQueryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+		return
+	}
+
+	// Get authenticated user ID from context
+	userId, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusInternalServerError, nil, "User ID not found in request context")
+		return
+	}
+
+	if req.PreviewToken != "" {
+		h.confirmDeleteByQuery(c, userId.(string), req.PreviewToken)
+		return
+	}
+
+	if req.Query == "" {
+		i18n.RespondError(c, http.StatusBadRequest, nil, "Missing required parameter: query")
+		return
+	}
+
+	h.previewDeleteByQuery(c, userId.(string), req)
+}
+
+// previewDeleteByQuery finds the items matching the description and stores them under a preview token
+func (h *Handlers) previewDeleteByQuery(c *gin.Context, userId string, req models.DeleteByQueryRequest) {
+	ctx := c.Request.Context()
+
+	embedding, err := h.queryEmbedding(ctx, req.Query)
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to get embedding")
+		return
+	}
+
+	opts := retrievalOptions{MinScore: deleteByQueryMinScore, MaxMatches: 50}
+	if req.MinScore != nil {
+		opts.MinScore = *req.MinScore
+	}
+
+	retrieval, err := h.retrieve(ctx, services.QueryFilter{UserID: userId}, embedding, opts)
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to query database")
+		return
+	}
+
+	// Title vectors have no record, so they're resolved to their document directly
+	included := retrieval.Included()
+	scores := make(map[string]float32, len(included))
+	vectorIDs := make([]string, 0, len(included))
+	titleScores := make(map[primitive.ObjectID]float32)
+	var titleParentIDs []primitive.ObjectID
+	for _, candidate := range included {
+		if parentID, ok := titleVectorParent(candidate.ID); ok {
+			if _, seen := titleScores[parentID]; !seen {
+				titleParentIDs = append(titleParentIDs, parentID)
+			}
+			if candidate.Score > titleScores[parentID] {
+				titleScores[parentID] = candidate.Score
+			}
+			continue
+		}
+		scores[candidate.ID] = candidate.Score
+		vectorIDs = append(vectorIDs, candidate.ID)
+	}
+
+	var docs []*database.UserData
+	if len(vectorIDs) > 0 {
+		docs, err = h.DB.GetUserDataByVectorIDs(ctx, userId, vectorIDs)
+		if err != nil {
+			i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch matching items")
+			return
+		}
+	}
+	var titleParents []*database.UserData
+	if len(titleParentIDs) > 0 {
+		titleParents, err = h.DB.GetUserDataByIDs(ctx, userId, titleParentIDs)
+		if err != nil {
+			i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch matching items")
+			return
+		}
+	}
+
+	items := make(map[string]*models.DeletionPreviewItem)
+	var itemIDs []string
+	add := func(item *database.UserData, score float32) {
+		id := item.ID.Hex()
+		if existing, ok := items[id]; ok {
+			if score > existing.Score {
+				existing.Score = score
+			}
+			return
+		}
+
+		items[id] = &models.DeletionPreviewItem{
+			ID:      id,
+			Type:    item.DataType,
+			Snippet: utils.Truncate(item.DataValue, matchSnippetLength),
+			Score:   score,
+		}
+		itemIDs = append(itemIDs, id)
+	}
+
+	// Resolve chunks to their parent document so whole items are deleted
+	for _, doc := range docs {
+		item := doc
+		if doc.ParentID != nil {
+			parent, err := h.DB.GetUserDataByID(ctx, doc.ParentID.Hex())
+			if err != nil {
+				if err != mongo.ErrNoDocuments {
+					fmt.Printf("Warning: Failed to fetch parent %s: %v\n", doc.ParentID.Hex(), err)
+				}
+				continue
+			}
+			item = parent
+		}
+		add(item, scores[doc.VectorID])
+	}
+	for _, parent := range titleParents {
+		add(parent, titleScores[parent.ID])
+	}
+
+	previewItems := make([]models.DeletionPreviewItem, 0, len(itemIDs))
+	for _, id := range itemIDs {
+		previewItems = append(previewItems, *items[id])
+	}
+
+	if len(itemIDs) == 0 {
+		c.JSON(http.StatusOK, gin.H{
+			"message": i18n.T(c, "No items match the description"),
+			"items":   previewItems,
+			"count":   0,
+		})
+		return
+	}
+
+	token := uuid.New().String()
+	preview, err := json.Marshal(deletionPreview{UserID: userId, ItemIDs: itemIDs})
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to create preview")
+		return
+	}
+	if err := h.Redis.StoreDeletionPreview(ctx, token, preview, deletionPreviewTTL); err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to store preview")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       i18n.T(c, "Review the items below and confirm with the preview token to delete them"),
+		"items":         previewItems,
+		"count":         len(previewItems),
+		"preview_token": token,
+		"expires_at":    time.Now().Add(deletionPreviewTTL).Format(time.RFC3339),
+	})
+}
+
+// confirmDeleteByQuery deletes the items stored under a preview token
+func (h *Handlers) confirmDeleteByQuery(c *gin.Context, userId, token string) {
+	ctx := c.Request.Context()
+
+	data, err := h.Redis.GetDeletionPreview(ctx, token)
+	if err == redis.Nil {
+		i18n.RespondError(c, http.StatusNotFound, nil, "Preview token not found or expired")
+		return
+	} else if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to load preview")
+		return
+	}
+
+	var preview deletionPreview
+	if err := json.Unmarshal(data, &preview); err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to parse preview")
+		return
+	}
+
+	if preview.UserID != userId {
+		i18n.RespondError(c, http.StatusForbidden, nil, "Preview token does not belong to authenticated user")
+		return
+	}
+
+	// Tokens are single-use
+	if err := h.Redis.DeleteDeletionPreview(ctx, token); err != nil {
+		fmt.Printf("Warning: Failed to remove deletion preview %s: %v\n", token, err)
+	}
+
+	var deleted []string
+	failed := make(map[string]string)
+	for _, id := range preview.ItemIDs {
+		userData, err := h.DB.GetUserDataByID(ctx, id)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				failed[id] = "item not found"
+			} else {
+				failed[id] = err.Error()
+			}
+			continue
+		}
+		if userData.UserID != userId {
+			failed[id] = "not authorized"
+			continue
+		}
+
+		if err := h.deleteItem(ctx, userData); err != nil {
+			failed[id] = err.Error()
+			continue
+		}
+
+		h.recordAudit(ctx, &database.AuditEvent{
+			UserID:   userId,
+			Action:   database.AuditActionDelete,
+			ItemID:   id,
+			ItemType: userData.DataType,
+			Summary:  utils.Truncate(userData.DataValue, auditSummaryLength),
+			Details:  map[string]interface{}{"bulk": true},
+		})
+		deleted = append(deleted, id)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       i18n.T(c, "Deleted %d item(s)", len(deleted)),
+		"deleted_ids":   deleted,
+		"deleted_count": len(deleted),
+		"failed":        failed,
+	})
+}
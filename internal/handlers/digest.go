@@ -0,0 +1,337 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/models"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+	"github.com/siddhantgupta/forgetai-backend/internal/utils"
+)
+
+const (
+	// digestPageSize is how many users are read per page when sending digests
+	digestPageSize = 500
+	// digestGapTopics is the number of knowledge-gap topics named in a digest
+	digestGapTopics = 3
+)
+
+const (
+	// savesDigestItems is the most recent saves summarized in one digest
+	savesDigestItems = 100
+	// savesDigestItemLength is how much of each save's text is summarized
+	savesDigestItemLength = 500
+	// defaultSavesDigestDays and maxSavesDigestDays bound the period of requested digests
+	defaultSavesDigestDays = 7
+	maxSavesDigestDays     = 31
+	// savesDigestTimeout bounds building and sending one scheduled digest
+	savesDigestTimeout = 2 * time.Minute
+)
+
+// savesDigestInstructions guide the summary of a user's recent saves
+const savesDigestInstructions = "These are items the user saved recently, each labelled with its type. " +
+	"Write a short digest addressed to the user: group related saves into themes, mention the most " +
+	"notable items, and keep it under 200 words."
+
+// RunDigests sends every user with saved data a digest of the past interval every
+// interval until ctx is cancelled
+func (h *Handlers) RunDigests(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		h.sendDigests(ctx, interval)
+	}
+}
+
+// sendDigests sends each user's digest covering the given period
+func (h *Handlers) sendDigests(ctx context.Context, period time.Duration) {
+	days := int(period.Hours() / 24)
+	if days < 1 {
+		days = 1
+	}
+
+	after := ""
+	for {
+		users, err := h.DB.ListUserSummaries(ctx, after, digestPageSize)
+		if err != nil {
+			fmt.Printf("Warning: Failed to list users for digests: %v\n", err)
+			return
+		}
+
+		for _, user := range users {
+			if ctx.Err() != nil {
+				return
+			}
+			notification, err := h.buildDigest(ctx, user.UserID, days)
+			if err != nil {
+				fmt.Printf("Warning: Failed to build digest for %s: %v\n", user.UserID, err)
+				continue
+			}
+			if notification == nil {
+				continue
+			}
+			if err := h.Notifier.Notify(ctx, user.UserID, *notification); err != nil {
+				fmt.Printf("Warning: Failed to send digest: %v\n", err)
+			}
+		}
+
+		if len(users) < digestPageSize {
+			return
+		}
+		after = users[len(users)-1].UserID
+	}
+}
+
+// buildDigest builds a user's digest notification, or nil if there is nothing to report
+func (h *Handlers) buildDigest(ctx context.Context, userID string, days int) (*services.Notification, error) {
+	report, err := h.buildGapReport(ctx, userID, days)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build gap report: %v", err)
+	}
+	if len(report.Gaps) == 0 {
+		return nil, nil
+	}
+
+	var topics []string
+	for _, gap := range report.Gaps {
+		topics = append(topics, fmt.Sprintf("%q", gap.Topic))
+		if len(topics) >= digestGapTopics {
+			break
+		}
+	}
+
+	return &services.Notification{
+		Type:  services.NotificationDigest,
+		Title: "Your knowledge gaps this week",
+		Body: fmt.Sprintf("Your saved data didn't answer questions about %s well. Saving notes on them will help next time.",
+			strings.Join(topics, ", ")),
+	}, nil
+}
+
+// savesDigest summarizes what a user saved over a period
+type savesDigest struct {
+	Since   time.Time      `json:"since"`
+	Items   int            `json:"item_count"` // at most savesDigestItems, the newest of the period
+	Types   map[string]int `json:"types"`
+	Summary string         `json:"summary"` // "" when nothing was saved
+}
+
+// GetDigest handles requests for a digest of the user's saves over the last days (7 by
+// default), summarized by the LLM
+func (h *Handlers) GetDigest(c *gin.Context) {
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	days := defaultSavesDigestDays
+	if daysStr := c.Query("days"); daysStr != "" {
+		n, err := strconv.Atoi(daysStr)
+		if err != nil || n <= 0 {
+			i18n.RespondError(c, http.StatusBadRequest, nil, "days must be a positive integer")
+			return
+		}
+		if n > maxSavesDigestDays {
+			n = maxSavesDigestDays
+		}
+		days = n
+	}
+
+	digest, err := h.buildSavesDigest(c.Request.Context(), userID.(string), time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to build digest")
+		return
+	}
+
+	c.JSON(http.StatusOK, digest)
+}
+
+// GetDigestSchedule handles retrieving how often the user is sent a digest of their saves
+func (h *Handlers) GetDigestSchedule(c *gin.Context) {
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	schedule, err := h.loadDigestSchedule(c.Request.Context(), userID.(string))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch digest schedule")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"schedule":              schedule,
+		"available_frequencies": []string{database.DigestOff, database.DigestDaily, database.DigestWeekly},
+	})
+}
+
+// UpdateDigestSchedule handles setting how often the user is sent a digest of their saves.
+// The first digest of a new frequency is sent one period later, covering that period.
+func (h *Handlers) UpdateDigestSchedule(c *gin.Context) {
+	var req models.DigestScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+		return
+	}
+
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	frequency := strings.ToLower(strings.TrimSpace(req.Frequency))
+	if frequency != database.DigestOff && database.DigestPeriod(frequency) == 0 {
+		i18n.RespondError(c, http.StatusBadRequest, nil, "Unknown digest frequency: %s", req.Frequency)
+		return
+	}
+
+	ctx := c.Request.Context()
+	schedule, err := h.loadDigestSchedule(ctx, userID.(string))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch digest schedule")
+		return
+	}
+
+	if frequency != schedule.Frequency {
+		schedule.Frequency = frequency
+		schedule.NextAt = nil
+		if period := database.DigestPeriod(frequency); period > 0 {
+			next := time.Now().Add(period)
+			schedule.NextAt = &next
+		}
+	}
+	if err := h.DB.UpsertDigestSchedule(ctx, schedule); err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to save digest schedule")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  i18n.T(c, "Digest schedule updated"),
+		"schedule": schedule,
+	})
+}
+
+// loadDigestSchedule returns the stored digest schedule, or one with digests off if none
+// is stored
+func (h *Handlers) loadDigestSchedule(ctx context.Context, userID string) (*database.DigestSchedule, error) {
+	schedule, err := h.DB.GetDigestSchedule(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule == nil {
+		return &database.DigestSchedule{UserID: userID, Frequency: database.DigestOff}, nil
+	}
+	return schedule, nil
+}
+
+// buildSavesDigest summarizes the user's saves since the given time. A digest of a period
+// without saves has no summary.
+func (h *Handlers) buildSavesDigest(ctx context.Context, userID string, since time.Time) (*savesDigest, error) {
+	items, err := h.DB.GetRecentItems(ctx, userID, since, savesDigestItems)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch recent saves: %v", err)
+	}
+
+	digest := &savesDigest{Since: since, Items: len(items), Types: make(map[string]int)}
+	if len(items) == 0 {
+		return digest, nil
+	}
+
+	texts := make([]string, 0, len(items))
+	for _, item := range items {
+		digest.Types[item.DataType]++
+		texts = append(texts, contentTypeLabel(item.DataType)+utils.Truncate(item.DataValue, savesDigestItemLength))
+	}
+	if digest.Summary, err = h.Summarizer.Summarize(ctx, "Recent saves", texts, savesDigestInstructions); err != nil {
+		return nil, fmt.Errorf("failed to summarize recent saves: %v", err)
+	}
+	return digest, nil
+}
+
+// RunSavesDigests sends the digests of recent saves that are due on users' schedules,
+// checking every interval until ctx is cancelled
+func (h *Handlers) RunSavesDigests(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for ctx.Err() == nil {
+			schedule, err := h.DB.ClaimDueDigest(ctx, time.Now())
+			if err != nil {
+				fmt.Printf("Warning: Failed to claim due digests: %v\n", err)
+				break
+			}
+			if schedule == nil {
+				break
+			}
+			h.sendSavesDigest(ctx, schedule)
+		}
+	}
+}
+
+// sendSavesDigest sends a user the digest of their saves over their schedule's period.
+// Nothing is sent for a period without saves, or while the user is over the spending cap.
+func (h *Handlers) sendSavesDigest(ctx context.Context, schedule *database.DigestSchedule) {
+	ctx, cancel := context.WithTimeout(services.WithBillingUser(ctx, schedule.UserID), savesDigestTimeout)
+	defer cancel()
+
+	if h.Spending != nil {
+		if err := h.Spending.Check(ctx, schedule.UserID); err != nil {
+			fmt.Printf("Warning: Skipping digest for %s: %v\n", schedule.UserID, err)
+			return
+		}
+	}
+
+	digest, err := h.buildSavesDigest(ctx, schedule.UserID, time.Now().Add(-database.DigestPeriod(schedule.Frequency)))
+	if err != nil {
+		fmt.Printf("Warning: Failed to build digest for %s: %v\n", schedule.UserID, err)
+		return
+	}
+	if digest.Summary == "" {
+		return
+	}
+
+	title := "Your saves this week"
+	if schedule.Frequency == database.DigestDaily {
+		title = "Your saves today"
+	}
+	notification := services.Notification{
+		Type:  services.NotificationSavesDigest,
+		Title: title,
+		Body:  digest.Summary,
+	}
+	if err := h.Notifier.Notify(ctx, schedule.UserID, notification); err != nil {
+		fmt.Printf("Warning: Failed to send digest: %v\n", err)
+	}
+}
@@ -0,0 +1,582 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/chunking"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/models"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+	"github.com/siddhantgupta/forgetai-backend/internal/textnorm"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// maxArchiveEntrySize is the most text read from one file inside a DOCX or EPUB, so a
+	// small upload can't expand into an unbounded amount of text
+	maxArchiveEntrySize = 64 << 20
+	// asyncDocumentSize is the size above which PDFs are processed in the background
+	asyncDocumentSize = 5 << 20
+	// documentJobTimeout bounds the background processing of one document
+	documentJobTimeout = 30 * time.Minute
+)
+
+// documentFormat is an uploadable document format, stored as its own parent type
+type documentFormat struct {
+	label   string // how chunks name the document type
+	mime    string // content type of the uploaded file
+	extract func(r io.ReaderAt, size int64) (string, error)
+}
+
+// documentFormats are the document formats SaveDocument accepts, by data type
+var documentFormats = map[string]documentFormat{
+	"pdf":      {label: "PDF Document", mime: "application/pdf", extract: extractPDFText},
+	"docx":     {label: "Word Document", mime: "application/vnd.openxmlformats-officedocument.wordprocessingml.document", extract: extractDOCXText},
+	"epub":     {label: "EPUB Book", mime: "application/epub+zip", extract: extractEPUBText},
+	"markdown": {label: "Markdown Document", mime: "text/markdown; charset=utf-8", extract: extractMarkdownText},
+}
+
+// markdownExtensions are the file extensions of uploads read as markdown
+var markdownExtensions = map[string]bool{
+	".md":       true,
+	".markdown": true,
+}
+
+// SaveDocument handles document uploads. PDF, DOCX and EPUB files are recognized by their
+// content and markdown files by their extension, and their text is stored as a parent item
+// with embedded chunks. With dry_run=true the document is only read and chunked, and the
+// chunks, embedding tokens and processing time storing it would take are returned. PDFs
+// over asyncDocumentSize are processed in the background instead, and the response
+// carries the ID of a job reporting their progress.
+func (h *Handlers) SaveDocument(c *gin.Context) {
+	// Get authenticated user ID from context
+	userId, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusInternalServerError, nil, "User ID not found in request context")
+		return
+	}
+
+	// Retrieve the uploaded file from the form-data; /save-pdf clients send it as "pdf"
+	file, err := c.FormFile("document")
+	if err == http.ErrMissingFile {
+		file, err = c.FormFile("pdf")
+	}
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Failed to retrieve document file")
+		return
+	}
+
+	// Open the uploaded file
+	docFile, err := file.Open()
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to open document file")
+		return
+	}
+	defer docFile.Close()
+
+	dataType, err := detectDocumentType(docFile, file.Size, file.Filename)
+	if err != nil {
+		i18n.RespondError(c, http.StatusUnsupportedMediaType, err, "Document must be PDF, DOCX, EPUB or Markdown")
+		return
+	}
+	options, err := h.chunkingOptions(c)
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid chunking parameters")
+		return
+	}
+
+	// Long PDFs are turned away before their text is extracted
+	pages := 0
+	if dataType == "pdf" {
+		if pages, err = pdfPageCount(docFile, file.Size); err != nil {
+			i18n.RespondError(c, http.StatusBadRequest, err, "Failed to read document")
+			return
+		}
+		if err := h.Quota.CheckDocument(requestPlan(c), pages, 0); err != nil {
+			respondSaveError(c, err, "Failed to save document metadata")
+			return
+		}
+	}
+
+	doc := &uploadedDocument{Name: file.Filename, Type: dataType, Size: file.Size, Pages: pages, File: docFile}
+
+	// Large PDFs are read and stored in the background, so their uploads aren't cut off by
+	// the request timeout
+	if dataType == "pdf" && file.Size > asyncDocumentSize && !isDryRun(c) {
+		h.startDocumentJob(c, userId.(string), doc, options)
+		return
+	}
+
+	extractStart := time.Now()
+	fullText, err := documentFormats[dataType].extract(docFile, file.Size)
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Failed to read document")
+		return
+	}
+	if fullText == "" {
+		i18n.RespondError(c, http.StatusBadRequest, nil, "No readable text found in document")
+		return
+	}
+
+	chunks := documentChunks(dataType, file.Filename, fullText, options)
+	if err := h.Quota.CheckDocument(requestPlan(c), pages, len(chunks)); err != nil {
+		respondSaveError(c, err, "Failed to save document metadata")
+		return
+	}
+	if err := h.checkQuota(c.Request.Context(), userId.(string), requestPlan(c), documentTexts(&parentDocument{Chunks: chunks})...); err != nil {
+		respondSaveError(c, err, "Failed to save document metadata")
+		return
+	}
+
+	// Dry runs report what storing the document would take without storing or embedding it
+	if isDryRun(c) {
+		estimate := h.estimateIngestion(&parentDocument{Title: file.Filename, Chunks: chunks}, time.Since(extractStart))
+		c.JSON(http.StatusOK, gin.H{
+			"message":  i18n.T(c, "Dry run: the document was not stored"),
+			"dry_run":  true,
+			"type":     dataType,
+			"pages":    pages,
+			"estimate": estimate,
+		})
+		return
+	}
+
+	_, vectorIds, err := h.storeDocument(c.Request.Context(), userId.(string), doc, fullText, chunks, options)
+	var chunkErr *chunkSaveError
+	switch {
+	case errors.As(err, &chunkErr):
+		i18n.RespondError(c, http.StatusInternalServerError, chunkErr.Err, "Failed to save chunk %d", chunkErr.Index)
+		return
+	case err != nil:
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to save document metadata")
+		return
+	}
+
+	// Return success response
+	message := "Document processed and stored successfully"
+	if dataType == "pdf" {
+		message = "PDF processed and stored successfully"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":     i18n.T(c, message),
+		"user_id":     userId.(string),
+		"type":        dataType,
+		"chunk_count": len(chunks),
+		"vector_ids":  vectorIds,
+		"timestamp":   time.Now().Format(time.RFC3339),
+	})
+}
+
+// uploadedDocument is a document file uploaded to SaveDocument
+type uploadedDocument struct {
+	Name  string
+	Type  string // data type, e.g. "pdf"
+	Size  int64
+	Pages int // only counted for PDFs
+	File  interface {
+		io.ReaderAt
+		io.ReadSeeker
+	}
+}
+
+// chunkSaveError reports that a chunk of a document could not be stored
+type chunkSaveError struct {
+	Index int
+	Err   error
+}
+
+func (e *chunkSaveError) Error() string {
+	return fmt.Sprintf("failed to save chunk %d: %v", e.Index, e.Err)
+}
+
+// storeDocument stores an uploaded document's text as a parent record with its original
+// file, then each chunk with its vector write enqueued, returning the parent record and
+// the chunks' vector IDs. If a chunk fails, a *chunkSaveError is returned and everything
+// stored so far is removed.
+func (h *Handlers) storeDocument(ctx context.Context, userID string, doc *uploadedDocument, fullText string, chunks []documentChunk, options chunking.Options) (*database.UserData, []string, error) {
+	// Create parent record for the document
+	parentData := &database.UserData{
+		UserID:     userID,
+		VectorID:   "parent-" + fmt.Sprintf("%d", time.Now().UnixNano()),
+		DataType:   doc.Type,
+		DataValue:  doc.Name,
+		ChunkIndex: 0,
+		Metadata:   chunkingMetadata(doc.Type, options),
+		CreatedAt:  time.Now(),
+	}
+	h.attachFullText(ctx, parentData, fullText)
+	h.attachOriginalFile(ctx, parentData, &originalFile{
+		Name:        doc.Name,
+		ContentType: documentFormats[doc.Type].mime,
+		Size:        doc.Size,
+		Body:        doc.File,
+	})
+
+	parentRecord, err := h.DB.CreateUserData(ctx, parentData)
+	if err != nil {
+		h.deleteOriginalFile(ctx, parentData)
+		return nil, nil, err
+	}
+
+	// Store each chunk; vectors are written in the background
+	var vectorIds []string
+	for chunkIdx, chunk := range chunks {
+		// Create a unique vector ID
+		vectorId := fmt.Sprintf("%s-%s-%d-%d", userID, doc.Type, time.Now().UnixNano(), chunkIdx)
+		vectorIds = append(vectorIds, vectorId)
+
+		// Prepare data for storage
+		data := models.Data{
+			Selected_type: doc.Type,
+			Text:          chunk.VectorText,
+			UserId:        userID,
+			Metadata:      chunk.Metadata,
+		}
+
+		// Store chunk in MongoDB
+		chunkData := &database.UserData{
+			UserID:     userID,
+			VectorID:   vectorId,
+			DataType:   doc.Type + "-chunk",
+			DataValue:  chunk.Text,
+			ParentID:   &parentRecord.ID, // Reference to parent
+			ChunkIndex: chunkIdx,
+			CreatedAt:  time.Now(),
+		}
+
+		if _, err := h.enqueueVector(ctx, chunkData, data, chunk.EmbedText); err != nil {
+			h.rollbackDocument(ctx, parentRecord, vectorIds[:chunkIdx])
+			return nil, nil, &chunkSaveError{Index: chunkIdx, Err: err}
+		}
+	}
+	h.enqueueTitleVector(ctx, &parentDocument{
+		UserID: userID,
+		Type:   doc.Type,
+		Title:  doc.Name,
+		Chunks: chunks,
+	}, parentRecord)
+
+	h.recordAudit(ctx, &database.AuditEvent{
+		UserID:   userID,
+		Action:   database.AuditActionSave,
+		ItemID:   parentRecord.ID.Hex(),
+		ItemType: doc.Type,
+		Summary:  doc.Name,
+		Details:  map[string]interface{}{"chunk_count": len(chunks)},
+	})
+	return parentRecord, vectorIds, nil
+}
+
+// startDocumentJob processes an uploaded document in the background and responds with the
+// ID of a job reporting its progress. The upload is read into memory first, as its file is
+// removed once the request ends.
+func (h *Handlers) startDocumentJob(c *gin.Context, userID string, doc *uploadedDocument, options chunking.Options) {
+	body, err := io.ReadAll(io.NewSectionReader(doc.File, 0, doc.Size))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to open document file")
+		return
+	}
+	doc.File = bytes.NewReader(body)
+
+	job := &database.Job{
+		UserID: userID,
+		Kind:   "document",
+		Total:  1,
+	}
+	if err := h.DB.CreateJob(c.Request.Context(), job); err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to start document processing")
+		return
+	}
+
+	plan := requestPlan(c)
+	go func() {
+		ctx, cancel := context.WithTimeout(services.WithBillingUser(context.Background(), userID), documentJobTimeout)
+		defer cancel()
+		h.runDocumentJob(ctx, job, plan, doc, options)
+	}()
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": i18n.T(c, "Document processing started"),
+		"job_id":  job.ID.Hex(),
+		"type":    doc.Type,
+		"pages":   doc.Pages,
+	})
+}
+
+// runDocumentJob reads, chunks and stores a document, recording on the job which stage
+// it's in and finally the stored document, or why it failed, and notifies the user
+// whether it succeeded. The chunks' vectors are written afterwards by the outbox
+// publisher; GetJob reports how many are written.
+func (h *Handlers) runDocumentJob(ctx context.Context, job *database.Job, plan string, doc *uploadedDocument, options chunking.Options) {
+	run := h.startJob(ctx, job)
+
+	item := database.JobItem{Key: doc.Name, Status: database.JobItemFailed}
+	parent, err := func() (*database.UserData, error) {
+		run.setStage(ctx, database.JobStageExtracting)
+		fullText, err := documentFormats[doc.Type].extract(doc.File, doc.Size)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read document: %v", err)
+		}
+		if fullText == "" {
+			return nil, fmt.Errorf("no readable text found in document")
+		}
+
+		run.setStage(ctx, database.JobStageChunking)
+		chunks := documentChunks(doc.Type, doc.Name, fullText, options)
+		if err := h.Quota.CheckDocument(plan, doc.Pages, len(chunks)); err != nil {
+			return nil, err
+		}
+		if err := h.checkQuota(ctx, job.UserID, plan, documentTexts(&parentDocument{Chunks: chunks})...); err != nil {
+			return nil, err
+		}
+
+		run.setStage(ctx, database.JobStageStoring)
+		parent, _, err := h.storeDocument(ctx, job.UserID, doc, fullText, chunks, options)
+		return parent, err
+	}()
+	if err != nil {
+		fmt.Printf("Warning: Failed to process document %s: %v\n", doc.Name, err)
+		item.Reason = err.Error()
+	} else {
+		item.Status, item.ItemID = database.JobItemSucceeded, parent.ID.Hex()
+	}
+
+	run.finish(ctx, "document processing timed out", item)
+
+	notification := services.Notification{
+		Type:  services.NotificationImportComplete,
+		Title: "Document processed",
+		Body:  fmt.Sprintf("%q is ready to search.", doc.Name),
+	}
+	if item.Status == database.JobItemFailed {
+		notification.Title = "Document processing failed"
+		notification.Body = fmt.Sprintf("%q could not be processed: %s.", doc.Name, item.Reason)
+	}
+	notifyCtx, notifyCancel := context.WithTimeout(context.Background(), jobNotificationTimeout)
+	defer notifyCancel()
+	if err := h.Notifier.Notify(notifyCtx, job.UserID, notification); err != nil {
+		fmt.Printf("Warning: Failed to send document notification: %v\n", err)
+	}
+}
+
+// documentEmbeddingProgress counts the chunks of a document job's stored document by
+// whether their vectors are written yet, or returns nil until the document is stored
+func (h *Handlers) documentEmbeddingProgress(ctx context.Context, job *database.Job) gin.H {
+	for _, item := range job.Items {
+		if item.Status != database.JobItemSucceeded {
+			continue
+		}
+		parentID, err := primitive.ObjectIDFromHex(item.ItemID)
+		if err != nil {
+			return nil
+		}
+		counts, err := h.DB.CountChunksByStatus(ctx, parentID)
+		if err != nil {
+			fmt.Printf("Warning: Failed to count chunks of document %s: %v\n", item.ItemID, err)
+			return nil
+		}
+		total := 0
+		for _, count := range counts {
+			total += count
+		}
+		return gin.H{
+			"total":   total,
+			"written": counts[database.UserDataStatusReady],
+			"pending": counts[database.UserDataStatusPending],
+			"failed":  counts[database.UserDataStatusFailed],
+		}
+	}
+	return nil
+}
+
+// detectDocumentType returns the data type of an uploaded document from its content:
+// PDFs by their header, and DOCX and EPUB files, which are both zip archives, by the
+// files they contain. Markdown is plain text, so it's recognized by the file's extension.
+func detectDocumentType(r io.ReaderAt, size int64, filename string) (string, error) {
+	if markdownExtensions[strings.ToLower(path.Ext(filename))] {
+		return "markdown", nil
+	}
+
+	header := make([]byte, 5)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		return "", fmt.Errorf("failed to read document: %w", err)
+	}
+	if bytes.Equal(header, []byte("%PDF-")) {
+		return "pdf", nil
+	}
+	if !bytes.HasPrefix(header, []byte("PK\x03\x04")) {
+		return "", fmt.Errorf("unrecognized document format")
+	}
+
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return "", fmt.Errorf("invalid zip archive: %w", err)
+	}
+	for _, f := range archive.File {
+		switch f.Name {
+		case "word/document.xml":
+			return "docx", nil
+		case "META-INF/container.xml":
+			return "epub", nil
+		}
+	}
+	return "", fmt.Errorf("zip archive is neither a DOCX nor an EPUB file")
+}
+
+// extractDOCXText extracts the text of a Word document's body, one line per paragraph
+func extractDOCXText(r io.ReaderAt, size int64) (string, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return "", fmt.Errorf("invalid DOCX file: %w", err)
+	}
+	body, err := readArchiveFile(archive, "word/document.xml")
+	if err != nil {
+		return "", err
+	}
+
+	// Text runs are <w:t> elements within <w:p> paragraphs; tabs and breaks are elements too
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	var sb strings.Builder
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("malformed DOCX document: %w", err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				sb.WriteString("\t")
+			case "br", "cr":
+				sb.WriteString("\n")
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				sb.WriteString("\n\n")
+			}
+		case xml.CharData:
+			if inText {
+				sb.Write(t)
+			}
+		}
+	}
+	return textnorm.Normalize(sb.String()), nil
+}
+
+// extractEPUBText extracts the text of an EPUB book's chapters in reading order
+func extractEPUBText(r io.ReaderAt, size int64) (string, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return "", fmt.Errorf("invalid EPUB file: %w", err)
+	}
+
+	// The container names the package file, which lists the chapters and their order
+	containerXML, err := readArchiveFile(archive, "META-INF/container.xml")
+	if err != nil {
+		return "", err
+	}
+	var container struct {
+		Rootfiles []struct {
+			FullPath string `xml:"full-path,attr"`
+		} `xml:"rootfiles>rootfile"`
+	}
+	if err := xml.Unmarshal(containerXML, &container); err != nil || len(container.Rootfiles) == 0 {
+		return "", fmt.Errorf("EPUB container has no package file")
+	}
+	packagePath := container.Rootfiles[0].FullPath
+
+	packageXML, err := readArchiveFile(archive, packagePath)
+	if err != nil {
+		return "", err
+	}
+	var pkg struct {
+		Manifest []struct {
+			ID        string `xml:"id,attr"`
+			Href      string `xml:"href,attr"`
+			MediaType string `xml:"media-type,attr"`
+		} `xml:"manifest>item"`
+		Spine []struct {
+			IDRef string `xml:"idref,attr"`
+		} `xml:"spine>itemref"`
+	}
+	if err := xml.Unmarshal(packageXML, &pkg); err != nil {
+		return "", fmt.Errorf("malformed EPUB package: %w", err)
+	}
+	hrefs := make(map[string]string, len(pkg.Manifest))
+	for _, item := range pkg.Manifest {
+		if strings.Contains(item.MediaType, "html") {
+			hrefs[item.ID] = item.Href
+		}
+	}
+
+	var chapters []string
+	for _, ref := range pkg.Spine {
+		href, ok := hrefs[ref.IDRef]
+		if !ok {
+			continue
+		}
+		if unescaped, err := url.PathUnescape(href); err == nil {
+			href = unescaped
+		}
+		chapter, err := readArchiveFile(archive, path.Join(path.Dir(packagePath), href))
+		if err != nil {
+			fmt.Printf("Warning: Skipping EPUB chapter %s: %v\n", href, err)
+			continue
+		}
+		if text := services.HTMLToText(string(chapter)); strings.TrimSpace(text) != "" {
+			chapters = append(chapters, text)
+		}
+	}
+	return textnorm.Normalize(strings.Join(chapters, "\n\n")), nil
+}
+
+// extractMarkdownText reads a markdown file's text, keeping its markup so the text can be
+// chunked on its headings
+func extractMarkdownText(r io.ReaderAt, size int64) (string, error) {
+	data, err := io.ReadAll(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return "", fmt.Errorf("failed to read markdown file: %w", err)
+	}
+	if !utf8.Valid(data) {
+		return "", fmt.Errorf("markdown file is not valid UTF-8 text")
+	}
+	return textnorm.Normalize(strings.TrimPrefix(string(data), "\uFEFF")), nil
+}
+
+// readArchiveFile reads a file from a zip archive, up to maxArchiveEntrySize
+func readArchiveFile(archive *zip.Reader, name string) ([]byte, error) {
+	f, err := archive.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("missing %s: %w", name, err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxArchiveEntrySize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return data, nil
+}
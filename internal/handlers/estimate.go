@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"math"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/chunking"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+)
+
+// estimatedVectorWriteTime is how long the outbox publisher takes to embed and upsert one
+// vector
+const estimatedVectorWriteTime = 300 * time.Millisecond
+
+// ingestionEstimate is what storing a document would take, returned by dry runs so
+// clients can warn before expensive imports
+type ingestionEstimate struct {
+	Chunks            int     `json:"chunk_count"`
+	Vectors           int     `json:"vector_count"` // chunks plus the title vector of long documents
+	Characters        int64   `json:"characters"`
+	EmbeddingTokens   int64   `json:"embedding_tokens"`
+	EmbeddingCostUSD  float64 `json:"embedding_cost_usd"`
+	ProcessingSeconds float64 `json:"processing_seconds"` // until every vector is searchable, with an empty outbox
+}
+
+// isDryRun reports whether the request asks for an estimate instead of storing anything
+func isDryRun(c *gin.Context) bool {
+	return c.Query("dry_run") == "true" || c.PostForm("dry_run") == "true"
+}
+
+// estimateIngestion estimates the vectors, embedding tokens and time needed to store a
+// document. extraction is how long reading the document's text already took.
+func (h *Handlers) estimateIngestion(doc *parentDocument, extraction time.Duration) ingestionEstimate {
+	embedTexts := make([]string, 0, len(doc.Chunks)+1)
+	for _, chunk := range doc.Chunks {
+		embedTexts = append(embedTexts, chunk.embedText())
+	}
+	if text := titleVectorText(doc); text != "" {
+		embedTexts = append(embedTexts, text)
+	}
+
+	estimate := ingestionEstimate{
+		Chunks:  len(doc.Chunks),
+		Vectors: len(embedTexts),
+	}
+	for _, chunk := range doc.Chunks {
+		estimate.Characters += int64(utf8.RuneCountInString(chunk.Text))
+	}
+	for _, text := range embedTexts {
+		estimate.EmbeddingTokens += int64(math.Ceil(float64(utf8.RuneCountInString(text)) / chunking.CharsPerToken))
+	}
+
+	estimate.EmbeddingCostUSD = float64(estimate.EmbeddingTokens) * services.UsagePricesUSD[services.ProviderOpenAI][h.OpenAI.EmbeddingUnit()]
+	processing := extraction + time.Duration(estimate.Vectors)*estimatedVectorWriteTime
+	estimate.ProcessingSeconds = math.Round(processing.Seconds()*10) / 10
+	return estimate
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/models"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+	"github.com/siddhantgupta/forgetai-backend/internal/utils"
+)
+
+// ExplainQuery handles retrieval explanation requests. It runs retrieval only
+// (no generation) and reports every candidate with the reason it was excluded.
+func (h *Handlers) ExplainQuery(c *gin.Context) {
+	var req models.ExplainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+		return
+	}
+
+	// Get authenticated user ID from context
+	userId, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusInternalServerError, nil, "User ID not found in request context")
+		return
+	}
+
+	sourceTypes, err := normalizeSourceTypes(req.SourceTypes)
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid source_types")
+		return
+	}
+
+	if err := validateDateRange(req.After, req.Before); err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid date range")
+		return
+	}
+
+	if err := validateMinScore(req.MinScore); err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid min_score")
+		return
+	}
+
+	embedding, err := h.queryEmbedding(c.Request.Context(), req.Text)
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to get embedding")
+		return
+	}
+
+	opts := h.defaultRetrievalOptions()
+	if req.MinScore != nil {
+		opts.MinScore = *req.MinScore
+	}
+	opts.BoostPeople = h.mentionedPeople(c.Request.Context(), userId.(string), req.Text)
+	filter := services.QueryFilter{
+		UserID: userId.(string),
+		Types:  sourceTypes,
+		After:  req.After,
+		Before: req.Before,
+	}
+	retrieval, err := h.retrieve(c.Request.Context(), filter, embedding, opts)
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to query database")
+		return
+	}
+
+	terms := queryTerms(req.Text)
+	candidates := make([]models.RetrievalCandidate, 0, len(retrieval.Candidates))
+	includedCount := 0
+	for i, candidate := range retrieval.Candidates {
+		included := candidate.ExclusionReason == ""
+		if included {
+			includedCount++
+		}
+		snippet := utils.Truncate(candidate.Text, matchSnippetLength)
+		candidates = append(candidates, models.RetrievalCandidate{
+			ID:              candidate.ID,
+			Rank:            i + 1,
+			Score:           candidate.Score,
+			Type:            candidate.Type,
+			Snippet:         snippet,
+			Highlights:      highlightSpans(snippet, terms),
+			Included:        included,
+			PersonBoosted:   candidate.Boosted,
+			ExclusionReason: candidate.ExclusionReason,
+		})
+	}
+
+	c.JSON(http.StatusOK, models.ExplainResponse{
+		Query:          req.Text,
+		Filters:        retrieval.Filters,
+		MinScore:       opts.MinScore,
+		MaxMatches:     opts.MaxMatches,
+		BoostedPeople:  opts.BoostPeople,
+		CandidateCount: len(candidates),
+		IncludedCount:  includedCount,
+		Candidates:     candidates,
+		Timestamp:      time.Now(),
+	})
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/utils"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// exportTitleLength is how much of a note's first line titles its Markdown file
+const exportTitleLength = 80
+
+// ExportData handles exporting all of a user's data, so they can back it up or move it
+// elsewhere. The response streams a zip holding every item record in items.json, plus
+// Markdown renderings of notes under notes/ and of document text under documents/.
+// Errors once the zip has started can only be logged, and leave it truncated.
+func (h *Handlers) ExportData(c *gin.Context) {
+	userId, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusInternalServerError, nil, "User ID not found in request context")
+		return
+	}
+	userID := userId.(string)
+
+	name := fmt.Sprintf("forgetai-export-%s.zip", time.Now().UTC().Format("20060102"))
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	c.Status(http.StatusOK)
+	// The headers go out before the first item is read, so the download starts at once and
+	// nothing in front of the handler holds the zip back
+	c.Writer.Flush()
+
+	archive := zip.NewWriter(c.Writer)
+	if err := h.writeExport(c.Request.Context(), archive, userID); err != nil {
+		fmt.Printf("Warning: Export for user %s failed: %v\n", userID, err)
+		return
+	}
+	if err := archive.Close(); err != nil {
+		fmt.Printf("Warning: Export for user %s failed: %v\n", userID, err)
+	}
+}
+
+// writeExport writes a user's items into the export zip: first items.json, streamed as a
+// JSON array, then a second pass over the items for the Markdown files, so no more than
+// one document's text is held in memory
+func (h *Handlers) writeExport(ctx context.Context, archive *zip.Writer, userID string) error {
+	items, err := archive.Create("items.json")
+	if err != nil {
+		return err
+	}
+	if _, err := items.Write([]byte("[")); err != nil {
+		return err
+	}
+	count := 0
+	err = h.DB.ExportUserData(ctx, userID, func(doc bson.Raw) error {
+		var item database.UserData
+		if err := bson.Unmarshal(doc, &item); err != nil {
+			return err
+		}
+		line, err := json.Marshal(&item)
+		if err != nil {
+			return err
+		}
+		separator := ",\n"
+		if count == 0 {
+			separator = "\n"
+		}
+		count++
+		_, err = items.Write(append([]byte(separator), line...))
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export items: %w", err)
+	}
+	if _, err := items.Write([]byte("\n]\n")); err != nil {
+		return err
+	}
+
+	return h.DB.ExportUserData(ctx, userID, func(doc bson.Raw) error {
+		var item database.UserData
+		if err := bson.Unmarshal(doc, &item); err != nil {
+			return err
+		}
+		if item.DataType != "note" && !isParentType(item.DataType) {
+			return nil
+		}
+
+		path, title, text := "", item.DataValue, item.DataValue
+		if item.DataType == "note" {
+			title = strings.TrimSpace(strings.SplitN(item.DataValue, "\n", 2)[0])
+			path = fmt.Sprintf("notes/%s-%s.md", item.CreatedAt.UTC().Format("2006-01-02"), item.ID.Hex())
+		} else {
+			if text, err = h.exportDocumentText(ctx, &item); err != nil {
+				return fmt.Errorf("failed to export %s: %w", item.ID.Hex(), err)
+			}
+			path = fmt.Sprintf("documents/%s/%s.md", item.DataType, item.ID.Hex())
+		}
+
+		file, err := archive.CreateHeader(&zip.FileHeader{Name: path, Method: zip.Deflate, Modified: item.CreatedAt})
+		if err != nil {
+			return err
+		}
+		_, err = file.Write([]byte(exportMarkdown(&item, utils.Truncate(title, exportTitleLength), text)))
+		return err
+	})
+}
+
+// exportDocumentText returns a document's full text, rebuilt from its chunks when it was
+// saved without it
+func (h *Handlers) exportDocumentText(ctx context.Context, parent *database.UserData) (string, error) {
+	text, stored, err := h.storedFullText(ctx, parent)
+	if err != nil || stored {
+		return text, err
+	}
+	chunks, err := h.DB.GetChunks(ctx, parent.ID.Hex())
+	if err != nil {
+		return "", err
+	}
+	return storedDocumentText(parent, chunks), nil
+}
+
+// exportMarkdown renders an exported note or document: a title heading, a line with the
+// item's type, save date and source URL, and its text
+func exportMarkdown(item *database.UserData, title, text string) string {
+	var b strings.Builder
+	if title == "" {
+		title = item.DataType
+	}
+	fmt.Fprintf(&b, "# %s\n\n", title)
+
+	details := []string{"Type: " + item.DataType, "Saved: " + item.CreatedAt.UTC().Format(time.RFC3339)}
+	if url, ok := item.Metadata["url"].(string); ok && url != "" {
+		details = append(details, "Source: "+url)
+	}
+	fmt.Fprintf(&b, "%s\n\n", strings.Join(details, " · "))
+
+	b.WriteString(strings.TrimSpace(text))
+	b.WriteString("\n")
+	return b.String()
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// originalFile is an uploaded file an item is saved from, kept for download
+type originalFile struct {
+	Name        string
+	ContentType string
+	Size        int64
+	Body        io.ReadSeeker
+}
+
+// attachOriginalFile uploads the file an item is saved from to the file bucket before the
+// item is created, recording the object and the file's name, type and size on the item.
+// Without a file bucket the file is discarded; a failed upload is only logged, so the item
+// is still saved without it.
+func (h *Handlers) attachOriginalFile(ctx context.Context, item *database.UserData, file *originalFile) {
+	if h.Files == nil || file == nil {
+		return
+	}
+	if _, err := file.Body.Seek(0, io.SeekStart); err != nil {
+		fmt.Printf("Warning: Failed to read original file %s: %v\n", file.Name, err)
+		return
+	}
+
+	name := path.Base(file.Name)
+	if name == "." || name == "/" {
+		name = "file"
+	}
+	contentType := file.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	object := fmt.Sprintf("files/%s/%s/%s", item.UserID, item.VectorID, name)
+	if err := h.Files.Upload(ctx, object, contentType, file.Body); err != nil {
+		fmt.Printf("Warning: Failed to store original file %s: %v\n", file.Name, err)
+		return
+	}
+
+	item.FileObject = object
+	if item.Metadata == nil {
+		item.Metadata = map[string]interface{}{}
+	}
+	item.Metadata["file_name"] = file.Name
+	item.Metadata["file_content_type"] = contentType
+	item.Metadata["file_size"] = file.Size
+}
+
+// deleteOriginalFile removes a deleted item's original file from the file bucket
+func (h *Handlers) deleteOriginalFile(ctx context.Context, item *database.UserData) {
+	if item.FileObject == "" || h.Files == nil {
+		return
+	}
+	if err := h.Files.Delete(ctx, item.FileObject); err != nil {
+		fmt.Printf("Warning: Failed to delete original file %s: %v\n", item.FileObject, err)
+	}
+}
+
+// DownloadFile handles downloading the original file an item was saved from, such as an
+// uploaded PDF, image or meeting recording. Chunks download their document's file.
+func (h *Handlers) DownloadFile(c *gin.Context) {
+	if h.Files == nil {
+		i18n.RespondError(c, http.StatusServiceUnavailable, nil, "File storage is not configured")
+		return
+	}
+
+	userId, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusInternalServerError, nil, "User ID not found in request context")
+		return
+	}
+
+	id := c.Param("id")
+	if !primitive.IsValidObjectID(id) {
+		i18n.RespondError(c, http.StatusNotFound, nil, "Item not found")
+		return
+	}
+
+	ctx := c.Request.Context()
+	item, err := h.DB.GetUserDataByID(ctx, id)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			i18n.RespondError(c, http.StatusNotFound, nil, "Item not found")
+		} else {
+			i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch item")
+		}
+		return
+	}
+	if item.UserID != userId.(string) {
+		i18n.RespondError(c, http.StatusForbidden, nil, "Not authorized to access this item")
+		return
+	}
+	if item.ParentID != nil {
+		if item, err = h.DB.GetUserDataByID(ctx, item.ParentID.Hex()); err != nil {
+			i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch item")
+			return
+		}
+	}
+	if item.FileObject == "" {
+		i18n.RespondError(c, http.StatusNotFound, nil, "No original file is stored for this item")
+		return
+	}
+
+	body, err := h.Files.Download(ctx, item.FileObject)
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadGateway, err, "Failed to download file")
+		return
+	}
+	defer body.Close()
+
+	name, _ := item.Metadata["file_name"].(string)
+	if name == "" {
+		name = path.Base(item.FileObject)
+	}
+	contentType, _ := item.Metadata["file_content_type"].(string)
+	size, ok := item.Metadata["file_size"].(int64)
+	if !ok {
+		size = -1
+	}
+
+	c.DataFromReader(http.StatusOK, size, contentType, body, map[string]string{
+		"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{"filename": name}),
+	})
+}
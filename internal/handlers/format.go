@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// Answer formats clients can request
+const (
+	// formatPlain is text without Markdown syntax, e.g. for SMS or notifications
+	formatPlain = "plain"
+	// formatMarkdown is Markdown with balanced code fences
+	formatMarkdown = "markdown"
+	// formatBullets is a Markdown bullet list summary
+	formatBullets = "bullets"
+	// formatJSON is a structured answer object, also returned as the response's structured field
+	formatJSON = "json"
+)
+
+// isValidFormat reports whether format is empty or a known answer format
+func isValidFormat(format string) bool {
+	switch format {
+	case "", formatPlain, formatMarkdown, formatBullets, formatJSON:
+		return true
+	}
+	return false
+}
+
+// formatGuideline returns the system prompt guideline for an answer format, or "" when the
+// client did not ask for one
+func formatGuideline(format string) string {
+	switch format {
+	case formatPlain:
+		return "Answer in plain text without any Markdown: no headings, emphasis, lists, links or code fences"
+	case formatMarkdown:
+		return "Answer in Markdown, using headings, lists and fenced code blocks where they help"
+	case formatBullets:
+		return "Answer as a short list of bullet points, one self-contained point each"
+	case formatJSON:
+		return "Answer with a short answer, its key points and an optional follow-up suggestion"
+	}
+	return ""
+}
+
+// maxResponseSchemaSize is the largest response_schema accepted in bytes
+const maxResponseSchemaSize = 16 << 10
+
+// validateResponseSchema checks that a client's response schema describes a JSON object,
+// which the chat API requires at the root
+func validateResponseSchema(schema json.RawMessage) error {
+	if len(schema) > maxResponseSchemaSize {
+		return fmt.Errorf("response_schema must be at most %d KB", maxResponseSchemaSize>>10)
+	}
+	var root struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return fmt.Errorf("response_schema must be a JSON schema object: %w", err)
+	}
+	if root.Type != "object" {
+		return fmt.Errorf("response_schema must have type \"object\" at the root")
+	}
+	return nil
+}
+
+// responseSchemaGuideline asks for answers that fill the client's schema from saved data only
+const responseSchemaGuideline = "Answer with JSON matching the requested schema, filling fields only from the provided context and using null or empty values for anything it does not contain"
+
+// bulletsSchema constrains bullet summaries to a list of points
+var bulletsSchema = json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"bullets": {"type": "array", "items": {"type": "string"}}
+	},
+	"required": ["bullets"],
+	"additionalProperties": false
+}`)
+
+// structuredAnswerSchema constrains JSON answers to structuredAnswer
+var structuredAnswerSchema = json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"answer": {"type": "string"},
+		"key_points": {"type": "array", "items": {"type": "string"}},
+		"follow_up": {"type": "string"}
+	},
+	"required": ["answer", "key_points", "follow_up"],
+	"additionalProperties": false
+}`)
+
+// structuredAnswer is the answer object returned for the JSON format
+type structuredAnswer struct {
+	Answer    string   `json:"answer"`
+	KeyPoints []string `json:"key_points"`
+	FollowUp  string   `json:"follow_up"`
+}
+
+// completeAnswer generates an answer in the requested format, or following the client's
+// response schema when one is given. Bullet and JSON answers are generated against a
+// schema so their structure is guaranteed; plain and Markdown answers are cleaned up
+// afterwards. For JSON and response schemas, the structured answer is returned as well.
+func (h *Handlers) completeAnswer(ctx context.Context, messages []openai.ChatCompletionMessage, format string, responseSchema json.RawMessage) (string, json.RawMessage, error) {
+	if len(responseSchema) > 0 {
+		// Client schemas needn't meet the strict mode rules, so the output is only checked to be JSON
+		raw, err := h.OpenAI.GetStructuredCompletionContext(ctx, messages, "response", responseSchema, false)
+		if err != nil {
+			return "", nil, err
+		}
+		if !json.Valid([]byte(raw)) {
+			return "", nil, fmt.Errorf("model returned invalid JSON for the response schema")
+		}
+		return raw, json.RawMessage(raw), nil
+	}
+
+	switch format {
+	case formatBullets:
+		raw, err := h.OpenAI.GetStructuredCompletionContext(ctx, messages, "bullet_summary", bulletsSchema, true)
+		if err != nil {
+			return "", nil, err
+		}
+		var summary struct {
+			Bullets []string `json:"bullets"`
+		}
+		if err := json.Unmarshal([]byte(raw), &summary); err != nil {
+			return "", nil, fmt.Errorf("failed to decode bullet summary: %w", err)
+		}
+		var lines []string
+		for _, bullet := range summary.Bullets {
+			if bullet = strings.TrimSpace(bullet); bullet != "" {
+				lines = append(lines, "- "+bullet)
+			}
+		}
+		return strings.Join(lines, "\n"), nil, nil
+
+	case formatJSON:
+		raw, err := h.OpenAI.GetStructuredCompletionContext(ctx, messages, "answer", structuredAnswerSchema, true)
+		if err != nil {
+			return "", nil, err
+		}
+		var answer structuredAnswer
+		if err := json.Unmarshal([]byte(raw), &answer); err != nil {
+			return "", nil, fmt.Errorf("failed to decode structured answer: %w", err)
+		}
+		return answer.Answer, json.RawMessage(raw), nil
+	}
+
+	answer, err := h.OpenAI.GetChatCompletionContext(ctx, messages)
+	if err != nil {
+		return "", nil, err
+	}
+	switch format {
+	case formatPlain:
+		answer = stripMarkdown(answer)
+	case formatMarkdown:
+		answer = closeCodeFences(answer)
+	}
+	return answer, nil, nil
+}
+
+var (
+	markdownHeading = regexp.MustCompile(`(?m)^\s{0,3}#{1,6}\s+`)
+	markdownQuote   = regexp.MustCompile(`(?m)^\s{0,3}>\s?`)
+	markdownBullet  = regexp.MustCompile(`(?m)^(\s*)[*+-]\s+`)
+	markdownFence   = regexp.MustCompile("(?m)^\\s*```.*\\n?")
+	markdownImage   = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	markdownLink    = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
+	// Emphasis and inline code, outermost first; underscores are left alone since they
+	// are far more common in identifiers than as emphasis
+	markdownEmphasis = []*regexp.Regexp{
+		regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*`),
+		regexp.MustCompile(`~~(\S(?:.*?\S)?)~~`),
+		regexp.MustCompile(`\*(\S(?:[^*]*?\S)?)\*`),
+		regexp.MustCompile("`([^`]+)`"),
+	}
+)
+
+// stripMarkdown removes Markdown syntax the model used despite being asked not to,
+// keeping the text. Bullets become "• " so lists still read as lists.
+func stripMarkdown(text string) string {
+	text = markdownFence.ReplaceAllString(text, "")
+	text = markdownHeading.ReplaceAllString(text, "")
+	text = markdownQuote.ReplaceAllString(text, "")
+	text = markdownBullet.ReplaceAllString(text, "${1}• ")
+	text = markdownImage.ReplaceAllString(text, "$1")
+	text = markdownLink.ReplaceAllString(text, "$1 ($2)")
+	for _, emphasis := range markdownEmphasis {
+		text = emphasis.ReplaceAllString(text, "$1")
+	}
+	return strings.TrimSpace(text)
+}
+
+// closeCodeFences closes a code block left open, e.g. by a truncated answer, so the
+// rest of the client's page doesn't render as code
+func closeCodeFences(text string) string {
+	open := false
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			open = !open
+		}
+	}
+	if open {
+		text = strings.TrimRight(text, "\n") + "\n```"
+	}
+	return text
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+)
+
+// maxInlineFullText is the largest extracted text stored on a parent record; MongoDB
+// documents are limited to 16 MB
+const maxInlineFullText = 4 << 20
+
+// attachFullText stores a document's extracted text on its parent record before it is
+// created. Text too large to store inline is uploaded to the backup bucket instead, or
+// left out when no bucket is configured, in which case it is rebuilt from the chunks.
+func (h *Handlers) attachFullText(ctx context.Context, parent *database.UserData, text string) {
+	if len(text) <= maxInlineFullText {
+		parent.FullText = text
+		return
+	}
+	if h.Backups == nil {
+		return
+	}
+
+	object := fmt.Sprintf("documents/%s/%s.txt", parent.UserID, parent.VectorID)
+	if err := h.Backups.Upload(ctx, object, "text/plain; charset=utf-8", strings.NewReader(text)); err != nil {
+		fmt.Printf("Warning: Failed to store full text of %s: %v\n", parent.DataValue, err)
+		return
+	}
+	parent.TextObject = object
+}
+
+// storedFullText returns a parent record's extracted text, reporting false for documents
+// saved without it
+func (h *Handlers) storedFullText(ctx context.Context, parent *database.UserData) (string, bool, error) {
+	if parent.FullText != "" {
+		return parent.FullText, true, nil
+	}
+	if parent.TextObject == "" || h.Backups == nil {
+		return "", false, nil
+	}
+
+	body, err := h.Backups.Download(ctx, parent.TextObject)
+	if err != nil {
+		return "", false, err
+	}
+	defer body.Close()
+
+	text, err := io.ReadAll(body)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read full text: %w", err)
+	}
+	return string(text), true, nil
+}
+
+// deleteFullText removes a deleted document's text from the backup bucket
+func (h *Handlers) deleteFullText(ctx context.Context, parent *database.UserData) {
+	if parent.TextObject == "" || h.Backups == nil {
+		return
+	}
+	if err := h.Backups.Delete(ctx, parent.TextObject); err != nil {
+		fmt.Printf("Warning: Failed to delete full text %s: %v\n", parent.TextObject, err)
+	}
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+)
+
+const (
+	// lowConfidenceScore is the best match score below which an answer is treated as low
+	// confidence for knowledge-gap reports
+	lowConfidenceScore = 0.5
+	// defaultGapReportDays is the period covered by a gap report when none is given
+	defaultGapReportDays = 7
+	// gapReportLimit is the number of topics in a gap report
+	gapReportLimit = 10
+)
+
+// knowledgeGap is a reported gap with a suggestion of what to save
+type knowledgeGap struct {
+	*database.KnowledgeGap
+	Suggestion string `json:"suggestion"`
+}
+
+// gapReport lists the topics a user asked about that their saved data answered poorly
+type gapReport struct {
+	UserID       string         `json:"user_id"`
+	Days         int            `json:"days"`
+	Since        time.Time      `json:"since"`
+	TotalQueries int            `json:"total_queries"`
+	Gaps         []knowledgeGap `json:"gaps"`
+}
+
+// GetGapReport handles requests for the user's knowledge-gap report: topics they queried
+// that got low-confidence answers, with suggestions of what to save next
+func (h *Handlers) GetGapReport(c *gin.Context) {
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	days := defaultGapReportDays
+	if daysStr := c.Query("days"); daysStr != "" {
+		n, err := strconv.Atoi(daysStr)
+		if err != nil || n <= 0 {
+			i18n.RespondError(c, http.StatusBadRequest, nil, "days must be a positive integer")
+			return
+		}
+		if n > maxAnalyticsDays {
+			n = maxAnalyticsDays
+		}
+		days = n
+	}
+
+	report, err := h.buildGapReport(c.Request.Context(), userID.(string), days)
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to build gap report")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// buildGapReport builds a user's knowledge-gap report over the last days
+func (h *Handlers) buildGapReport(ctx context.Context, userID string, days int) (*gapReport, error) {
+	since := time.Now().AddDate(0, 0, -days)
+
+	counts, err := h.DB.GetQueryCounts(ctx, userID, since)
+	if err != nil {
+		return nil, err
+	}
+	gaps, err := h.DB.GetKnowledgeGaps(ctx, userID, since, lowConfidenceScore, gapReportLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &gapReport{
+		UserID:       userID,
+		Days:         days,
+		Since:        since,
+		TotalQueries: counts.Total,
+		Gaps:         make([]knowledgeGap, 0, len(gaps)),
+	}
+	for _, gap := range gaps {
+		report.Gaps = append(report.Gaps, knowledgeGap{KnowledgeGap: gap, Suggestion: gapSuggestion(gap)})
+	}
+	return report, nil
+}
+
+// gapSuggestion suggests what to save to close a knowledge gap
+func gapSuggestion(gap *database.KnowledgeGap) string {
+	if gap.Count == 1 {
+		return fmt.Sprintf("Save notes or links about %q; a question about it wasn't answered well by your saved data.", gap.Topic)
+	}
+	return fmt.Sprintf("Save notes or links about %q; %d questions about it weren't answered well by your saved data.", gap.Topic, gap.Count)
+}
@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/chunking"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+)
+
+// maxGitHubDocFiles is the maximum number of docs/ files ingested per repository
+const maxGitHubDocFiles = 25
+
+// githubDocExtensions are the docs/ file extensions ingested as markdown
+var githubDocExtensions = []string{".md", ".markdown", ".mdx"}
+
+// SaveGitHub handles GitHub repository saving requests. The README and markdown files
+// under docs/ are fetched and stored as one parent item with per-file chunks.
+func (h *Handlers) SaveGitHub(c *gin.Context) {
+	var req struct {
+		RepoURL string `json:"repoUrl" binding:"required"`
+		chunkingFields
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+		return
+	}
+	options, err := req.options(h.Chunking)
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid chunking parameters")
+		return
+	}
+	options = sourceChunking(options)
+
+	userId, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusInternalServerError, nil, "User ID not found in request context")
+		return
+	}
+
+	owner, name, err := services.ParseRepoURL(req.RepoURL)
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid GitHub repository URL")
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	repo, err := h.GitHub.GetRepository(ctx, owner, name)
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadGateway, err, "Failed to fetch GitHub repository")
+		return
+	}
+
+	var files []*services.GitHubFile
+	readme, err := h.GitHub.GetReadme(ctx, owner, name)
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadGateway, err, "Failed to fetch GitHub repository")
+		return
+	}
+	if readme != nil {
+		files = append(files, readme)
+	}
+
+	docPaths, err := h.GitHub.ListFiles(ctx, owner, name, repo.DefaultBranch, "docs", githubDocExtensions)
+	if err != nil {
+		// The README alone is still worth saving
+		fmt.Printf("Warning: Failed to list docs for %s: %v\n", repo.FullName, err)
+	}
+	if len(docPaths) > maxGitHubDocFiles {
+		docPaths = docPaths[:maxGitHubDocFiles]
+	}
+	for _, docPath := range docPaths {
+		file, err := h.GitHub.GetFile(ctx, owner, name, repo.DefaultBranch, docPath)
+		if err != nil {
+			fmt.Printf("Warning: Failed to fetch %s from %s: %v\n", docPath, repo.FullName, err)
+			continue
+		}
+		files = append(files, file)
+	}
+
+	doc := &parentDocument{
+		UserID: userId.(string),
+		Plan:   requestPlan(c),
+		Type:   "github",
+		Title:  repo.FullName,
+		Metadata: map[string]interface{}{
+			"url":         repo.HTMLURL,
+			"description": repo.Description,
+			"stars":       repo.Stars,
+		},
+	}
+	var paths []string
+	for _, file := range files {
+		if strings.TrimSpace(file.Content) == "" {
+			continue
+		}
+		paths = append(paths, file.Path)
+		splitProse(file.Content, options, func(chunk, withContext string) {
+			doc.Chunks = append(doc.Chunks, documentChunk{
+				Text:       chunk,
+				VectorText: fmt.Sprintf("GitHub repository %s (%s): %s", repo.FullName, file.Path, chunk),
+				EmbedText:  fmt.Sprintf("GitHub repository %s (%s): %s", repo.FullName, file.Path, withContext),
+				Metadata: map[string]interface{}{
+					"path": file.Path,
+					"url":  repo.HTMLURL,
+				},
+			})
+		})
+	}
+	if len(doc.Chunks) == 0 {
+		i18n.RespondError(c, http.StatusBadRequest, nil, "No README or docs found in repository")
+		return
+	}
+	doc.Metadata["files"] = paths
+	doc.recordChunking(options)
+
+	record, vectorIds, err := h.ingestDocument(ctx, doc)
+	if err != nil {
+		respondSaveError(c, err, "Failed to save GitHub repository")
+		return
+	}
+
+	h.recordAudit(ctx, &database.AuditEvent{
+		UserID:   userId.(string),
+		Action:   database.AuditActionSave,
+		ItemID:   record.ID.Hex(),
+		ItemType: "github",
+		Summary:  repo.FullName,
+		Details:  map[string]interface{}{"repo_url": repo.HTMLURL, "file_count": len(paths), "chunk_count": len(doc.Chunks)},
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     i18n.T(c, "GitHub repository saved successfully"),
+		"user_id":     userId.(string),
+		"type":        "github",
+		"repository":  repo.FullName,
+		"files":       paths,
+		"chunk_count": len(doc.Chunks),
+		"vector_ids":  vectorIds,
+		"timestamp":   time.Now().Format(time.RFC3339),
+	})
+}
+
+// gistProseLanguages are gist languages chunked as prose rather than code
+var gistProseLanguages = map[string]bool{
+	"":         true,
+	"markdown": true,
+	"text":     true,
+}
+
+// SaveGist handles gist saving requests. Every file of the gist is stored as chunks of
+// one parent item, code files with their language and all with their filename.
+func (h *Handlers) SaveGist(c *gin.Context) {
+	var req struct {
+		GistURL string `json:"gistUrl" binding:"required"`
+		chunkingFields
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+		return
+	}
+	options, err := req.options(h.Chunking)
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid chunking parameters")
+		return
+	}
+	options = sourceChunking(options)
+
+	userId, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusInternalServerError, nil, "User ID not found in request context")
+		return
+	}
+
+	gistID, err := services.ParseGistURL(req.GistURL)
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid gist URL")
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	gist, err := h.GitHub.GetGist(ctx, gistID)
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadGateway, err, "Failed to fetch gist")
+		return
+	}
+
+	title := strings.TrimSpace(gist.Description)
+	if title == "" && len(gist.Files) > 0 {
+		title = gist.Files[0].Filename
+	}
+
+	doc := &parentDocument{
+		UserID: userId.(string),
+		Plan:   requestPlan(c),
+		Type:   "gist",
+		Title:  title,
+		Metadata: map[string]interface{}{
+			"url":   gist.HTMLURL,
+			"owner": gist.Owner,
+		},
+	}
+	var filenames []string
+	for _, file := range gist.Files {
+		if strings.TrimSpace(file.Content) == "" {
+			continue
+		}
+		filenames = append(filenames, file.Filename)
+
+		language := chunking.NormalizeLanguage(file.Language)
+		if gistProseLanguages[language] {
+			splitProse(file.Content, options, func(chunk, withContext string) {
+				doc.Chunks = append(doc.Chunks, documentChunk{
+					Text:       chunk,
+					VectorText: fmt.Sprintf("Gist %s (%s): %s", title, file.Filename, chunk),
+					EmbedText:  fmt.Sprintf("Gist %s (%s): %s", title, file.Filename, withContext),
+					Metadata:   map[string]interface{}{"filename": file.Filename, "url": gist.HTMLURL},
+				})
+			})
+			continue
+		}
+
+		for _, chunk := range chunking.ChunkCode(file.Content, language, chunking.DefaultCodeChunkSize) {
+			doc.Chunks = append(doc.Chunks, documentChunk{
+				Text:      chunk,
+				EmbedText: fmt.Sprintf("%s (%s):\n%s", file.Filename, language, chunk),
+				Metadata: map[string]interface{}{
+					"filename": file.Filename,
+					"language": language,
+					"url":      gist.HTMLURL,
+				},
+			})
+		}
+	}
+	if len(doc.Chunks) == 0 {
+		i18n.RespondError(c, http.StatusBadRequest, nil, "Gist has no content")
+		return
+	}
+	doc.Metadata["files"] = filenames
+	doc.recordChunking(options)
+
+	record, vectorIds, err := h.ingestDocument(ctx, doc)
+	if err != nil {
+		respondSaveError(c, err, "Failed to save gist")
+		return
+	}
+
+	h.recordAudit(ctx, &database.AuditEvent{
+		UserID:   userId.(string),
+		Action:   database.AuditActionSave,
+		ItemID:   record.ID.Hex(),
+		ItemType: "gist",
+		Summary:  title,
+		Details:  map[string]interface{}{"gist_url": gist.HTMLURL, "file_count": len(filenames), "chunk_count": len(doc.Chunks)},
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     i18n.T(c, "Gist saved successfully"),
+		"user_id":     userId.(string),
+		"type":        "gist",
+		"title":       title,
+		"url":         gist.HTMLURL,
+		"files":       filenames,
+		"chunk_count": len(doc.Chunks),
+		"vector_ids":  vectorIds,
+		"timestamp":   time.Now().Format(time.RFC3339),
+	})
+}
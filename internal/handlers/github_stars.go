@@ -0,0 +1,392 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/chunking"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+)
+
+const (
+	// maxGitHubStarChunks caps the chunks stored for one starred repository
+	maxGitHubStarChunks = 50
+	// maxGitHubStarImports caps the repositories imported by one sync; later syncs import
+	// the rest, so a first sync of many stars doesn't run for hours
+	maxGitHubStarImports = 200
+	// githubStarLabel is how chunks of starred repositories name their type
+	githubStarLabel = "Starred GitHub repository"
+)
+
+// githubSyncResult counts the changes applied by a GitHub stars sync
+type githubSyncResult struct {
+	Imported  int `json:"imported"`
+	Unchanged int `json:"unchanged"`
+	Removed   int `json:"removed"`
+	Failed    int `json:"failed"`
+	Deferred  int `json:"deferred"` // left for the next sync by maxGitHubStarImports
+}
+
+// ConnectGitHub handles connecting a GitHub account with a user's token and starts an
+// initial sync of their starred repositories
+func (h *Handlers) ConnectGitHub(c *gin.Context) {
+	var req struct {
+		Token string `json:"token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+		return
+	}
+
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	token := strings.TrimSpace(req.Token)
+	login, err := h.GitHub.WithToken(token).GetAuthenticatedUser(c.Request.Context())
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid GitHub token")
+		return
+	}
+
+	existing, err := h.DB.GetGitHubIntegration(c.Request.Context(), userID.(string))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to save GitHub integration")
+		return
+	}
+
+	integration := &database.GitHubIntegration{
+		UserID:      userID.(string),
+		Login:       login,
+		AccessToken: token,
+		Plan:        requestPlan(c),
+	}
+	if existing != nil {
+		integration.StarCount = existing.StarCount
+		integration.CreatedAt = existing.CreatedAt
+	}
+
+	if err := h.DB.UpsertGitHubIntegration(c.Request.Context(), integration); err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to save GitHub integration")
+		return
+	}
+
+	go h.syncGitHubIntegration(context.Background(), integration)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     i18n.T(c, "GitHub connected, syncing starred repositories in the background"),
+		"integration": integration,
+	})
+}
+
+// GetGitHubStatus handles retrieving the user's GitHub integration and sync state
+func (h *Handlers) GetGitHubStatus(c *gin.Context) {
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	integration, err := h.DB.GetGitHubIntegration(c.Request.Context(), userID.(string))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch GitHub integration")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"connected":   integration != nil,
+		"integration": integration,
+	})
+}
+
+// DisconnectGitHub handles removing the user's GitHub integration. Starred repositories
+// already imported are kept.
+func (h *Handlers) DisconnectGitHub(c *gin.Context) {
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	removed, err := h.DB.DeleteGitHubIntegration(c.Request.Context(), userID.(string))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to remove GitHub integration")
+		return
+	}
+	if !removed {
+		i18n.RespondError(c, http.StatusNotFound, nil, "GitHub is not connected")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(c, "GitHub account disconnected"),
+	})
+}
+
+// SyncGitHub handles on-demand syncs of starred repositories
+func (h *Handlers) SyncGitHub(c *gin.Context) {
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+	h.syncGitHubForUser(c, userID.(string))
+}
+
+// syncGitHubForUser syncs a user's starred repositories and responds with the result
+func (h *Handlers) syncGitHubForUser(c *gin.Context, userID string) {
+	integration, err := h.DB.GetGitHubIntegration(c.Request.Context(), userID)
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch GitHub integration")
+		return
+	}
+	if integration == nil {
+		i18n.RespondError(c, http.StatusNotFound, nil, "GitHub is not connected")
+		return
+	}
+
+	result, ok, err := h.syncGitHubIntegration(c.Request.Context(), integration)
+	if !ok {
+		i18n.RespondError(c, http.StatusConflict, nil, "A GitHub sync is already running")
+		return
+	}
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadGateway, err, "GitHub sync failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(c, "GitHub starred repositories synced"),
+		"result":  result,
+	})
+}
+
+// RunGitHubSync syncs the starred repositories of every connected GitHub account on the
+// given interval until ctx is cancelled. A non-positive interval disables scheduled syncs.
+func (h *Handlers) RunGitHubSync(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		integrations, err := h.DB.ListGitHubIntegrations(ctx)
+		if err != nil {
+			fmt.Printf("Warning: Failed to list GitHub integrations: %v\n", err)
+			continue
+		}
+		for _, integration := range integrations {
+			if ctx.Err() != nil {
+				return
+			}
+			h.syncGitHubIntegration(ctx, integration)
+		}
+	}
+}
+
+// syncGitHubIntegration runs a sync and records its outcome. It reports false without
+// syncing if a sync for the same user is already running.
+func (h *Handlers) syncGitHubIntegration(ctx context.Context, integration *database.GitHubIntegration) (*githubSyncResult, bool, error) {
+	if _, running := h.githubSyncs.LoadOrStore(integration.UserID, true); running {
+		return nil, false, nil
+	}
+	defer h.githubSyncs.Delete(integration.UserID)
+
+	ctx = services.WithBillingUser(ctx, integration.UserID)
+	result, starCount, err := h.syncGitHubStars(ctx, integration)
+
+	lastError := ""
+	switch {
+	case err != nil:
+		lastError = err.Error()
+		starCount = -1
+	case result.Failed > 0:
+		lastError = fmt.Sprintf("%d starred repositories failed to import", result.Failed)
+	}
+	if stateErr := h.DB.UpdateGitHubSyncState(ctx, integration.UserID, starCount, lastError); stateErr != nil {
+		fmt.Printf("Warning: Failed to record GitHub sync state for %s: %v\n", integration.UserID, stateErr)
+	}
+	if err != nil {
+		fmt.Printf("Warning: GitHub sync failed for %s: %v\n", integration.UserID, err)
+	}
+
+	return result, true, err
+}
+
+// syncGitHubStars imports starred repositories that are new or were pushed to or
+// described differently since they were imported, and removes imports of repositories
+// no longer starred, returning the number of starred repositories
+func (h *Handlers) syncGitHubStars(ctx context.Context, integration *database.GitHubIntegration) (*githubSyncResult, int, error) {
+	github := h.GitHub.WithToken(integration.AccessToken)
+	starred, err := github.ListStarred(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	imports, err := h.DB.GetUserDataByType(ctx, integration.UserID, "githubstar")
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list imported starred repositories: %v", err)
+	}
+
+	imported := make(map[string]*database.UserData, len(imports))
+	for _, item := range imports {
+		if repo, ok := item.Metadata["github_repo"].(string); ok {
+			imported[repo] = item
+		}
+	}
+
+	result := &githubSyncResult{}
+	for _, repo := range starred {
+		previous := imported[repo.FullName]
+		delete(imported, repo.FullName)
+		if previous != nil &&
+			previous.Metadata["github_pushed_at"] == repo.PushedAt.UTC().Format(time.RFC3339) &&
+			previous.Metadata["description"] == repo.Description {
+			result.Unchanged++
+			continue
+		}
+		if result.Imported+result.Failed >= maxGitHubStarImports {
+			result.Deferred++
+			continue
+		}
+		if err := h.importGitHubStar(ctx, github, integration, repo, previous); err != nil {
+			fmt.Printf("Warning: Failed to import starred repository %s: %v\n", repo.FullName, err)
+			result.Failed++
+			continue
+		}
+		result.Imported++
+	}
+
+	// Whatever is left was unstarred
+	for repo, item := range imported {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := h.deleteItem(ctx, item); err != nil {
+			fmt.Printf("Warning: Failed to remove unstarred repository %s: %v\n", repo, err)
+			result.Failed++
+			continue
+		}
+		h.recordAudit(ctx, &database.AuditEvent{
+			UserID:   integration.UserID,
+			Action:   database.AuditActionDelete,
+			ItemID:   item.ID.Hex(),
+			ItemType: "githubstar",
+			Summary:  item.DataValue,
+			Details:  map[string]interface{}{"source": "github_sync", "github_repo": repo},
+		})
+		result.Removed++
+	}
+
+	return result, len(starred), nil
+}
+
+// importGitHubStar imports a starred repository's details and README, replacing its
+// previous import if any
+func (h *Handlers) importGitHubStar(ctx context.Context, github *services.GitHubService, integration *database.GitHubIntegration, repo *services.GitHubStarredRepository, previous *database.UserData) error {
+	owner, name, ok := strings.Cut(repo.FullName, "/")
+	if !ok {
+		return fmt.Errorf("unexpected repository name %q", repo.FullName)
+	}
+	readme, err := github.GetReadme(ctx, owner, name)
+	if err != nil {
+		// The name and description are still worth saving
+		fmt.Printf("Warning: Failed to fetch README of %s: %v\n", repo.FullName, err)
+	}
+
+	doc := buildGitHubStarDocument(integration, repo, readme)
+	if len(doc.Chunks) > maxGitHubStarChunks {
+		doc.Chunks = doc.Chunks[:maxGitHubStarChunks]
+	}
+
+	// Import the new version before removing the old one so the repository is never missing
+	record, _, err := h.ingestDocument(ctx, doc)
+	if err != nil {
+		return err
+	}
+	if previous != nil {
+		if err := h.deleteItem(ctx, previous); err != nil {
+			fmt.Printf("Warning: Failed to remove previous import of %s: %v\n", repo.FullName, err)
+		}
+	}
+
+	h.recordAudit(ctx, &database.AuditEvent{
+		UserID:   integration.UserID,
+		Action:   database.AuditActionSave,
+		ItemID:   record.ID.Hex(),
+		ItemType: "githubstar",
+		Summary:  repo.FullName,
+		Details:  map[string]interface{}{"source": "github_sync", "github_repo": repo.FullName, "chunk_count": len(doc.Chunks)},
+	})
+	return nil
+}
+
+// buildGitHubStarDocument creates the parent document for a starred repository: a chunk
+// describing the repository, followed by its README chunked within its sections
+func buildGitHubStarDocument(integration *database.GitHubIntegration, repo *services.GitHubStarredRepository, readme *services.GitHubFile) *parentDocument {
+	summary := repo.FullName
+	if repo.Description != "" {
+		summary += ": " + repo.Description
+	}
+	if repo.Language != "" {
+		summary += "\nLanguage: " + repo.Language
+	}
+	if len(repo.Topics) > 0 {
+		summary += "\nTopics: " + strings.Join(repo.Topics, ", ")
+	}
+	if repo.Homepage != "" {
+		summary += "\nHomepage: " + repo.Homepage
+	}
+
+	topics := make([]interface{}, len(repo.Topics))
+	for i, topic := range repo.Topics {
+		topics[i] = topic
+	}
+	doc := &parentDocument{
+		UserID:    integration.UserID,
+		Type:      "githubstar",
+		Title:     repo.FullName,
+		Timestamp: repo.StarredAt,
+		Plan:      integration.Plan,
+		Metadata: map[string]interface{}{
+			"url":              repo.HTMLURL,
+			"github_repo":      repo.FullName,
+			"github_pushed_at": repo.PushedAt.UTC().Format(time.RFC3339),
+			"description":      repo.Description,
+			"language":         repo.Language,
+			"topics":           topics,
+			"stars":            repo.Stars,
+			"archived":         repo.Archived,
+		},
+		Chunks: []documentChunk{{
+			Text:       summary,
+			VectorText: fmt.Sprintf("%s (%s): %s", githubStarLabel, repo.FullName, summary),
+			Metadata:   map[string]interface{}{"section": "summary"},
+		}},
+	}
+	if readme != nil && strings.TrimSpace(readme.Content) != "" {
+		doc.Chunks = append(doc.Chunks, markdownChunks(githubStarLabel, repo.FullName, readme.Content, chunking.DefaultOptions)...)
+	}
+	for i := range doc.Chunks {
+		if doc.Chunks[i].Metadata == nil {
+			doc.Chunks[i].Metadata = map[string]interface{}{}
+		}
+		doc.Chunks[i].Metadata["url"] = repo.HTMLURL
+	}
+	return doc
+}
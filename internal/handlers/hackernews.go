@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+	"github.com/siddhantgupta/forgetai-backend/internal/textnorm"
+)
+
+// maxHackerNewsComments is the maximum number of top comments saved with a story
+const maxHackerNewsComments = 10
+
+// SaveHackerNews handles Hacker News item saving requests. The story, the article it
+// links to and optionally its top comments are stored as one parent item.
+func (h *Handlers) SaveHackerNews(c *gin.Context) {
+	var req struct {
+		ItemURL         string `json:"itemUrl" binding:"required"`
+		IncludeComments bool   `json:"includeComments"`
+		chunkingFields
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+		return
+	}
+	options, err := req.options(h.Chunking)
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid chunking parameters")
+		return
+	}
+	options = sourceChunking(options)
+
+	userId, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusInternalServerError, nil, "User ID not found in request context")
+		return
+	}
+
+	itemID, err := services.ParseHackerNewsURL(req.ItemURL)
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid Hacker News URL")
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	item, err := h.HackerNews.GetItem(ctx, itemID)
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadGateway, err, "Failed to fetch Hacker News item")
+		return
+	}
+	if item.Deleted || item.Dead {
+		i18n.RespondError(c, http.StatusNotFound, nil, "Hacker News item not found")
+		return
+	}
+
+	itemURL := fmt.Sprintf("https://news.ycombinator.com/item?id=%d", item.ID)
+	title := strings.TrimSpace(item.Title)
+	if title == "" {
+		title = fmt.Sprintf("Hacker News item %d", item.ID)
+	}
+
+	doc := &parentDocument{
+		UserID: userId.(string),
+		Plan:   requestPlan(c),
+		Type:   "hackernews",
+		Title:  title,
+		Metadata: map[string]interface{}{
+			"url":        itemURL,
+			"link":       textnorm.StripTracking(item.URL),
+			"author":     item.By,
+			"score":      item.Score,
+			"hn_item_id": item.ID,
+		},
+	}
+	doc.recordChunking(options)
+	// Chunks are stored as written but embedded without URLs; emoji-only chunks are dropped
+	addChunks := func(section, text string) {
+		splitProse(text, options, func(chunk, withContext string) {
+			if textnorm.EmbeddingText(chunk) == "" {
+				return
+			}
+			doc.Chunks = append(doc.Chunks, documentChunk{
+				Text:       chunk,
+				VectorText: fmt.Sprintf("Hacker News: %s (%s): %s", title, section, chunk),
+				EmbedText:  fmt.Sprintf("Hacker News: %s (%s): %s", title, section, textnorm.EmbeddingText(withContext)),
+				Metadata:   map[string]interface{}{"section": section, "url": itemURL},
+			})
+		})
+	}
+
+	// The story itself, with its text for Ask/Show HN posts
+	story := title
+	if item.Text != "" {
+		story += "\n\n" + services.HTMLToText(item.Text)
+	}
+	addChunks("story", story)
+
+	articleSaved := false
+	if item.URL != "" {
+		page, err := h.WebPages.Fetch(ctx, item.URL)
+		if err != nil {
+			// The story and comments are still worth saving without the article
+			fmt.Printf("Warning: Failed to fetch article %s: %v\n", item.URL, err)
+		} else if page.Text != "" {
+			addChunks("article", page.Text)
+			articleSaved = true
+		}
+	}
+
+	commentCount := 0
+	if req.IncludeComments {
+		comments, err := h.HackerNews.GetTopComments(ctx, item, maxHackerNewsComments)
+		if err != nil {
+			fmt.Printf("Warning: Failed to fetch comments for item %d: %v\n", item.ID, err)
+		}
+		for _, comment := range comments {
+			addChunks("comment", fmt.Sprintf("%s: %s", comment.By, services.HTMLToText(comment.Text)))
+		}
+		commentCount = len(comments)
+	}
+
+	record, vectorIds, err := h.ingestDocument(ctx, doc)
+	if err != nil {
+		respondSaveError(c, err, "Failed to save Hacker News item")
+		return
+	}
+
+	h.recordAudit(ctx, &database.AuditEvent{
+		UserID:   userId.(string),
+		Action:   database.AuditActionSave,
+		ItemID:   record.ID.Hex(),
+		ItemType: "hackernews",
+		Summary:  title,
+		Details:  map[string]interface{}{"item_url": itemURL, "article_saved": articleSaved, "comment_count": commentCount},
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       i18n.T(c, "Hacker News item saved successfully"),
+		"user_id":       userId.(string),
+		"type":          "hackernews",
+		"title":         title,
+		"url":           itemURL,
+		"article_saved": articleSaved,
+		"comment_count": commentCount,
+		"chunk_count":   len(doc.Chunks),
+		"vector_ids":    vectorIds,
+		"timestamp":     time.Now().Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// healthCacheTTL is how long dependency check results are reused, so frequent health
+	// polling doesn't add load to MongoDB and Redis
+	healthCacheTTL = 5 * time.Second
+	// healthCheckTimeout bounds each dependency check
+	healthCheckTimeout = 5 * time.Second
+)
+
+// healthResult is the outcome of checking one dependency
+type healthResult struct {
+	Status      string     `json:"status"` // "ok" or the check's error
+	LastSuccess *time.Time `json:"last_success"`
+}
+
+// healthCache holds the latest dependency check results
+type healthCache struct {
+	mu        sync.Mutex // held while checks run, so concurrent requests share one round
+	checkedAt time.Time
+	results   map[string]healthResult
+}
+
+// HealthCheck handles health check requests. MongoDB and Redis are checked concurrently
+// and the results reused for a few seconds. While Redis is down, the response also says
+// since when and what the server is using instead.
+func (h *Handlers) HealthCheck(c *gin.Context) {
+	checkedAt, results := h.checkHealth()
+
+	status := "ok"
+	statuses := gin.H{}
+	lastSuccess := gin.H{}
+	for name, result := range results {
+		if result.Status != "ok" {
+			status = "degraded"
+		}
+		statuses[name] = result.Status
+		lastSuccess[name] = result.LastSuccess
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":         status,
+		"services":       statuses,
+		"last_success":   lastSuccess,
+		"checked_at":     checkedAt,
+		"redis_fallback": h.Redis.Degradation(),
+	})
+}
+
+// checkHealth returns the latest dependency check results, checking again once they're
+// older than healthCacheTTL
+func (h *Handlers) checkHealth() (time.Time, map[string]healthResult) {
+	h.health.mu.Lock()
+	defer h.health.mu.Unlock()
+
+	if h.health.results == nil || time.Since(h.health.checkedAt) >= healthCacheTTL {
+		h.runHealthChecks()
+	}
+	return h.health.checkedAt, h.health.results
+}
+
+// RunKeepalive pings MongoDB and Redis every interval until ctx is cancelled, so pooled
+// connections don't sit idle long enough to be dropped by the server or network, and a
+// dead connection is replaced before a request needs it. The results are the health
+// check's, and a dependency going down or coming back is logged.
+func (h *Handlers) RunKeepalive(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		h.health.mu.Lock()
+		previous := h.health.results
+		h.runHealthChecks()
+		results := h.health.results
+		h.health.mu.Unlock()
+
+		for name, result := range results {
+			wasOK := previous == nil || previous[name].Status == "ok"
+			switch {
+			case result.Status != "ok" && wasOK:
+				fmt.Printf("Warning: Keepalive ping of %s failed: %s\n", name, result.Status)
+			case result.Status == "ok" && !wasOK:
+				fmt.Printf("Keepalive: %s is reachable again\n", name)
+			}
+		}
+	}
+}
+
+// runHealthChecks checks every dependency concurrently and stores the results. A failed
+// check is retried once, since after an idle period the first command can get a pooled
+// connection that was already closed; the driver replaces it for the retry. The caller
+// holds h.health.mu.
+func (h *Handlers) runHealthChecks() {
+	// Checks aren't bound to the request, since their results are shared
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+
+	checks := map[string]func(context.Context) error{
+		"mongodb": h.DB.Ping,
+		"redis": func(ctx context.Context) error {
+			_, err := h.Redis.Ping(ctx)
+			return err
+		},
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := make(map[string]healthResult, len(checks))
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func(context.Context) error) {
+			defer wg.Done()
+			err := check(ctx)
+			if err != nil && ctx.Err() == nil {
+				err = check(ctx)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			result := healthResult{Status: "ok", LastSuccess: h.health.results[name].LastSuccess}
+			if err != nil {
+				result.Status = fmt.Sprintf("error: %v", err)
+			} else {
+				now := time.Now()
+				result.LastSuccess = &now
+			}
+			results[name] = result
+		}(name, check)
+	}
+	wg.Wait()
+
+	h.health.checkedAt = time.Now()
+	h.health.results = results
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/siddhantgupta/forgetai-backend/internal/models"
+)
+
+// maxStemSuffix is the longest suffix by which a snippet word and a query term may differ
+// and still match, so "meeting" highlights "meetings" and "deploy" highlights "deployed"
+const maxStemSuffix = 3
+
+// matchesTerm reports whether a lowercase word is a query term or differs from one only
+// by a short suffix
+func matchesTerm(word string, terms []string) bool {
+	for _, term := range terms {
+		shorter, longer := word, term
+		if len(shorter) > len(longer) {
+			shorter, longer = longer, shorter
+		}
+		if len([]rune(shorter)) >= minTopicLength &&
+			strings.HasPrefix(longer, shorter) &&
+			len([]rune(longer))-len([]rune(shorter)) <= maxStemSuffix {
+			return true
+		}
+	}
+	return false
+}
+
+// highlightSpans returns the spans of text that match query terms, in code point offsets.
+// Matching words separated only by spaces are merged into one span.
+func highlightSpans(text string, terms []string) []models.Highlight {
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var spans []models.Highlight
+	runes := []rune(text)
+	for start := 0; start < len(runes); {
+		if isWordSeparator(runes[start]) {
+			start++
+			continue
+		}
+		end := start
+		for end < len(runes) && !isWordSeparator(runes[end]) {
+			end++
+		}
+
+		if matchesTerm(strings.ToLower(string(runes[start:end])), terms) {
+			if n := len(spans); n > 0 && strings.TrimSpace(string(runes[spans[n-1].End:start])) == "" {
+				spans[n-1].End = end
+			} else {
+				spans = append(spans, models.Highlight{Start: start, End: end})
+			}
+		}
+		start = end
+	}
+	return spans
+}
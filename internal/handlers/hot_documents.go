@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// maxHotDocumentChunks is the most chunks a document may have to be cached when hot
+	maxHotDocumentChunks = 500
+	// hotDocumentTimeout bounds counting one query's retrievals and caching hot documents
+	hotDocumentTimeout = 30 * time.Second
+)
+
+// countDocumentRetrievals counts a query retrieving chunks of each of the given documents,
+// caching the chunk text of any that just became hot. It runs after the lookup it's called
+// from, so counting and caching don't slow the query.
+func (h *Handlers) countDocumentRetrievals(userID string, parentIDs []primitive.ObjectID) {
+	ctx, cancel := context.WithTimeout(context.Background(), hotDocumentTimeout)
+	defer cancel()
+
+	for _, parentID := range parentIDs {
+		if h.Redis.CountDocumentRetrieval(ctx, userID, parentID.Hex()) != services.HotDocumentRetrievals {
+			continue
+		}
+
+		chunks, err := h.DB.GetChunks(ctx, parentID.Hex())
+		if err != nil {
+			fmt.Printf("Warning: Failed to fetch chunks of hot document %s: %v\n", parentID.Hex(), err)
+			continue
+		}
+		if len(chunks) == 0 || len(chunks) > maxHotDocumentChunks {
+			continue
+		}
+		texts := make(map[string]string, len(chunks))
+		for _, chunk := range chunks {
+			texts[chunk.VectorID] = chunk.DataValue
+		}
+		if err := h.Redis.CacheHotDocument(ctx, userID, parentID.Hex(), texts); err != nil {
+			fmt.Printf("Warning: Failed to cache hot document %s: %v\n", parentID.Hex(), err)
+		}
+	}
+}
+
+// invalidateHotDocument drops a document's cached chunk text once its chunks change or it's
+// deleted. Removed chunks' vectors are deleted too, but a vector that failed to delete
+// would otherwise still be answered from the cache.
+func (h *Handlers) invalidateHotDocument(ctx context.Context, parent *database.UserData) {
+	if err := h.Redis.InvalidateHotDocument(ctx, parent.UserID, parent.ID.Hex()); err != nil {
+		fmt.Printf("Warning: Failed to invalidate cached chunks of %s: %v\n", parent.ID.Hex(), err)
+	}
+}
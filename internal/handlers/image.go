@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/models"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+	"github.com/siddhantgupta/forgetai-backend/internal/textnorm"
+	"github.com/siddhantgupta/forgetai-backend/internal/utils"
+)
+
+// maxImageFileSize is the largest image accepted in bytes, the vision API's limit
+const maxImageFileSize = 20 << 20
+
+// imageTypes are the image formats the vision model reads
+var imageTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// SaveImage handles image uploads. The image's visible text and a description of what it
+// shows are read by a vision model and saved as one searchable item.
+func (h *Handlers) SaveImage(c *gin.Context) {
+	userId, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusInternalServerError, nil, "User ID not found in request context")
+		return
+	}
+
+	file, err := c.FormFile("image")
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Failed to retrieve image file")
+		return
+	}
+	if file.Size > maxImageFileSize {
+		i18n.RespondError(c, http.StatusRequestEntityTooLarge, nil, "Image must be at most %d MB", maxImageFileSize>>20)
+		return
+	}
+
+	imageFile, err := file.Open()
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to open image file")
+		return
+	}
+	defer imageFile.Close()
+
+	image, err := io.ReadAll(io.LimitReader(imageFile, maxImageFileSize))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to open image file")
+		return
+	}
+	mimeType := http.DetectContentType(image)
+	if !imageTypes[mimeType] {
+		i18n.RespondError(c, http.StatusUnsupportedMediaType, fmt.Errorf("unsupported image type %s", mimeType), "Image must be PNG, JPEG, GIF or WebP")
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	content, err := h.Images.Read(ctx, mimeType, image)
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadGateway, err, "Failed to read image")
+		return
+	}
+	content.Text = textnorm.Normalize(content.Text)
+
+	// An optional caption from the user is searchable along with what the model saw
+	caption := textnorm.Normalize(c.PostForm("caption"))
+	userData, err := h.storeImage(ctx, userId.(string), requestPlan(c), file.Filename, mimeType, image, content, caption, nil)
+	if err != nil {
+		respondSaveError(c, err, "Failed to save image")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     i18n.T(c, "Image saved successfully"),
+		"id":          userData.ID.Hex(),
+		"user_id":     userId.(string),
+		"type":        "image",
+		"description": content.Description,
+		"text":        content.Text,
+		"vector_id":   userData.VectorID,
+		"status":      userData.Status,
+		"timestamp":   time.Now().Format(time.RFC3339),
+	})
+}
+
+// storeImage saves an image read by the vision model as one searchable item of its
+// description, caption and visible text, keeping the image itself when a file bucket is
+// configured. details are added to the item's metadata and audit event. Saves that would exceed the user's quota are rejected with a
+// *services.QuotaExceededError.
+func (h *Handlers) storeImage(ctx context.Context, userID, plan, filename, mimeType string, image []byte, content *services.ImageContent, caption string, details map[string]interface{}) (*database.UserData, error) {
+	imageText := fmt.Sprintf("Image (%s): %s", filename, content.Description)
+	if caption != "" {
+		imageText += "\n\nCaption: " + caption
+	}
+	if content.Text != "" {
+		imageText += "\n\nText in image:\n" + content.Text
+	}
+
+	if err := h.checkQuota(ctx, userID, plan, imageText); err != nil {
+		return nil, err
+	}
+
+	metadata := map[string]interface{}{
+		"filename":  filename,
+		"mime_type": mimeType,
+		"has_text":  content.Text != "",
+	}
+	auditDetails := map[string]interface{}{"filename": filename, "has_text": content.Text != ""}
+	for key, value := range details {
+		metadata[key] = value
+		auditDetails[key] = value
+	}
+	setPeopleMetadata(metadata, h.extractPeople(ctx, strings.Join([]string{caption, content.Description, content.Text}, "\n"), nil))
+
+	data := models.Data{
+		Selected_type: "image",
+		Text:          imageText,
+		UserId:        userID,
+		Metadata:      map[string]interface{}{"filename": filename},
+	}
+	if keys, ok := metadata["people_keys"]; ok {
+		data.Metadata["people_keys"] = keys
+	}
+
+	vectorId := fmt.Sprintf("%s-image-%d", userID, time.Now().UnixNano())
+	userData := &database.UserData{
+		UserID:     userID,
+		VectorID:   vectorId,
+		DataType:   "image",
+		DataValue:  imageText,
+		ChunkIndex: 0,
+		Metadata:   metadata,
+		CreatedAt:  time.Now(),
+	}
+
+	h.attachOriginalFile(ctx, userData, &originalFile{
+		Name:        filename,
+		ContentType: mimeType,
+		Size:        int64(len(image)),
+		Body:        bytes.NewReader(image),
+	})
+
+	// The vector is written in the background
+	if _, err := h.enqueueVector(ctx, userData, data, ""); err != nil {
+		h.deleteOriginalFile(ctx, userData)
+		return nil, err
+	}
+
+	h.recordAudit(ctx, &database.AuditEvent{
+		UserID:   userID,
+		Action:   database.AuditActionSave,
+		ItemID:   userData.ID.Hex(),
+		ItemType: "image",
+		Summary:  utils.Truncate(content.Description, auditSummaryLength),
+		Details:  auditDetails,
+	})
+	return userData, nil
+}
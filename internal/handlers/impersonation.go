@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/auth"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+)
+
+const (
+	// defaultImpersonationTTL is how long impersonation tokens last when no TTL is given
+	defaultImpersonationTTL = 30 * time.Minute
+	// maxImpersonationMinutes is the longest impersonation token that can be issued
+	maxImpersonationMinutes = 120
+)
+
+// ImpersonateUser handles admin requests for a read-only impersonation token, so support
+// can reproduce a user's results without their credentials. Issuing the token and every
+// request made with it are recorded in the user's audit log.
+func (h *Handlers) ImpersonateUser(c *gin.Context) {
+	if !h.requireAdmin(c) {
+		return
+	}
+
+	if h.Impersonator == nil {
+		i18n.RespondError(c, http.StatusServiceUnavailable, nil, "Impersonation is not configured")
+		return
+	}
+
+	var req struct {
+		Admin      string `json:"admin"`  // who is impersonating, e.g. a support email
+		Reason     string `json:"reason"` // why, e.g. a ticket reference
+		TTLMinutes int    `json:"ttl_minutes"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+		return
+	}
+	req.Admin = strings.TrimSpace(req.Admin)
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Admin == "" || req.Reason == "" {
+		i18n.RespondError(c, http.StatusBadRequest, nil, "admin and reason are required")
+		return
+	}
+	ttl := defaultImpersonationTTL
+	if req.TTLMinutes != 0 {
+		if req.TTLMinutes < 0 || req.TTLMinutes > maxImpersonationMinutes {
+			i18n.RespondError(c, http.StatusBadRequest, nil, "ttl_minutes must be 1 to %d", maxImpersonationMinutes)
+			return
+		}
+		ttl = time.Duration(req.TTLMinutes) * time.Minute
+	}
+
+	ctx := c.Request.Context()
+	userID := c.Param("id")
+
+	// Carry the user's plan so quotas read the same as for the user themselves
+	plan := ""
+	if h.ClerkUsers != nil {
+		profile, err := h.ClerkUsers.GetUser(ctx, userID)
+		if err != nil {
+			i18n.RespondError(c, http.StatusBadGateway, err, "Failed to look up users in Clerk")
+			return
+		}
+		if profile != nil {
+			plan = profile.Plan
+		}
+	}
+
+	token, impersonation, err := h.Impersonator.SignToken(userID, req.Admin, req.Reason, plan, ttl)
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to create impersonation token")
+		return
+	}
+
+	h.recordAudit(ctx, &database.AuditEvent{
+		UserID:  userID,
+		Action:  database.AuditActionImpersonate,
+		Summary: fmt.Sprintf("Read-only access granted to %s", req.Admin),
+		Details: map[string]interface{}{
+			"token_id":   impersonation.TokenID,
+			"admin":      req.Admin,
+			"reason":     req.Reason,
+			"expires_at": impersonation.ExpiresAt,
+		},
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"token":         token,
+		"header":        auth.ImpersonationHeader,
+		"impersonation": impersonation,
+		"read_only":     true,
+	})
+}
+
+// AuditImpersonation records requests made with an impersonation token in the
+// impersonated user's audit log
+func (h *Handlers) AuditImpersonation(c *gin.Context) {
+	impersonation := auth.GetImpersonation(c)
+	if impersonation == nil {
+		c.Next()
+		return
+	}
+
+	c.Next()
+
+	h.recordAudit(c.Request.Context(), &database.AuditEvent{
+		UserID:  impersonation.UserID,
+		Action:  database.AuditActionImpersonatedRequest,
+		Summary: c.Request.Method + " " + c.Request.URL.Path,
+		Details: map[string]interface{}{
+			"token_id": impersonation.TokenID,
+			"admin":    impersonation.Admin,
+			"status":   c.Writer.Status(),
+		},
+	})
+}
@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/models"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+	"github.com/siddhantgupta/forgetai-backend/internal/textnorm"
+	"github.com/siddhantgupta/forgetai-backend/internal/utils"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// maxImportItems is the most notes and links accepted by one import request
+	maxImportItems = 5000
+	// importBatchSize is how many items are quota-checked and stored together
+	importBatchSize = 100
+	// importWorkers is how many items of a batch are stored at once
+	importWorkers = 4
+	// importTimeout bounds one import
+	importTimeout = 6 * time.Hour
+	// importKeyLength is how much of a note's text names it in the job report
+	importKeyLength = 80
+)
+
+// importItem is one note or link of a bulk import, e.g. exported from another notes app
+type importItem struct {
+	ID         string `json:"id"`   // the item's ID in the source tool; items already imported with it are skipped
+	Type       string `json:"type"` // "note" or "link"
+	Text       string `json:"text"` // a note's text, or a link's note
+	URL        string `json:"url"`  // a link's URL
+	Title      string `json:"title"`
+	Timestamp  string `json:"timestamp"`  // RFC3339; when the item was created in the source tool
+	Visibility string `json:"visibility"` // of notes; links are stored as private bookmarks
+}
+
+// validImportItem is an import item that passed validation, with its position in the request
+type validImportItem struct {
+	index     int
+	item      importItem
+	timestamp time.Time
+}
+
+// ImportItems handles bulk imports of notes and links, e.g. when migrating from another
+// tool. The body is a JSON array of items. Items are validated up front; valid ones are
+// stored in batches in the background, with notes embedded like saved text and links
+// stored as bookmarks. The response carries the ID of a job reporting each item's result.
+func (h *Handlers) ImportItems(c *gin.Context) {
+	var items []importItem
+	if err := c.ShouldBindJSON(&items); err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+		return
+	}
+	if len(items) == 0 || len(items) > maxImportItems {
+		i18n.RespondError(c, http.StatusBadRequest, nil, "imports must have 1 to %d items", maxImportItems)
+		return
+	}
+
+	userId, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusInternalServerError, nil, "User ID not found in request context")
+		return
+	}
+
+	var valid []validImportItem
+	var invalid []database.JobItem
+	for i, item := range items {
+		item.Type = strings.ToLower(strings.TrimSpace(item.Type))
+		item.Text = textnorm.Normalize(item.Text)
+		item.URL = textnorm.StripTracking(strings.TrimSpace(item.URL))
+		timestamp, err := validateImportItem(item)
+		if err != nil {
+			invalid = append(invalid, database.JobItem{Index: i, Key: importItemKey(item), Status: database.JobItemFailed, Reason: err.Error()})
+			continue
+		}
+		valid = append(valid, validImportItem{index: i, item: item, timestamp: timestamp})
+	}
+
+	job := &database.Job{
+		UserID: userId.(string),
+		Kind:   "import",
+		Total:  len(items),
+	}
+	if err := h.DB.CreateJob(c.Request.Context(), job); err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to start import")
+		return
+	}
+
+	plan := requestPlan(c)
+	go func() {
+		ctx, cancel := context.WithTimeout(services.WithBillingUser(context.Background(), job.UserID), importTimeout)
+		defer cancel()
+		h.runImport(ctx, job, plan, valid, invalid)
+	}()
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": i18n.T(c, "Import started"),
+		"job_id":  job.ID.Hex(),
+		"total":   job.Total,
+		"invalid": len(invalid),
+	})
+}
+
+// validateImportItem checks an import item, returning when it was created, or the zero
+// time if it doesn't say
+func validateImportItem(item importItem) (time.Time, error) {
+	switch item.Type {
+	case "note":
+		if item.Text == "" {
+			return time.Time{}, fmt.Errorf("note has no text")
+		}
+	case "link":
+		if !isPageURL(item.URL) {
+			return time.Time{}, fmt.Errorf("link URL must be an http or https URL")
+		}
+	default:
+		return time.Time{}, fmt.Errorf("type must be note or link")
+	}
+	if item.Visibility != "" && !database.IsValidVisibility(item.Visibility) {
+		return time.Time{}, fmt.Errorf("invalid visibility %q", item.Visibility)
+	}
+
+	if item.Timestamp == "" {
+		return time.Time{}, nil
+	}
+	timestamp, err := time.Parse(time.RFC3339, item.Timestamp)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("timestamp must be RFC3339")
+	}
+	return timestamp, nil
+}
+
+// importItemKey names an import item in the job report
+func importItemKey(item importItem) string {
+	switch {
+	case item.ID != "":
+		return item.ID
+	case item.URL != "":
+		return item.URL
+	default:
+		return utils.Truncate(item.Text, importKeyLength)
+	}
+}
+
+// runImport stores the valid items of an import in batches, recording each item's result
+// on the job as it finishes; items that failed validation are recorded first, and items
+// repeated in the import are skipped. A batch's notes are quota-checked together, so a
+// batch that would exceed the quota fails as a whole, while a failed item doesn't stop the
+// rest of the import. The user is notified when it's done.
+func (h *Handlers) runImport(ctx context.Context, job *database.Job, plan string, items []validImportItem, invalid []database.JobItem) {
+	run := h.startJob(ctx, job)
+	for _, item := range invalid {
+		run.record(ctx, item)
+	}
+
+	// Items are checked against earlier imports concurrently, so repeats within the
+	// import are caught here: notes by their source ID, links by URL
+	unique := make([]validImportItem, 0, len(items))
+	seen := make(map[string]bool, len(items))
+	for _, valid := range items {
+		key := ""
+		switch {
+		case valid.item.Type == "link":
+			key = "link:" + valid.item.URL
+		case valid.item.ID != "":
+			key = "note:" + valid.item.ID
+		}
+		if key != "" {
+			if seen[key] {
+				run.record(ctx, database.JobItem{Index: valid.index, Key: importItemKey(valid.item), Status: database.JobItemSkipped, Reason: "repeated in the import"})
+				continue
+			}
+			seen[key] = true
+		}
+		unique = append(unique, valid)
+	}
+
+	details := map[string]interface{}{"job_id": job.ID.Hex()}
+	for start := 0; start < len(unique) && ctx.Err() == nil; start += importBatchSize {
+		end := start + importBatchSize
+		if end > len(unique) {
+			end = len(unique)
+		}
+		batch := unique[start:end]
+
+		var noteTexts []string
+		for _, valid := range batch {
+			if valid.item.Type == "note" {
+				noteTexts = append(noteTexts, valid.item.Text)
+			}
+		}
+		quotaErr := h.checkQuota(ctx, job.UserID, plan, noteTexts...)
+
+		runConcurrently(ctx, importWorkers, len(batch), func(i int) {
+			run.record(ctx, h.storeImportItem(ctx, job.UserID, plan, batch[i], quotaErr, details))
+		})
+	}
+
+	run.finish(ctx, "import timed out")
+	h.notifyImportComplete(job, "Import finished", "items")
+}
+
+// storeImportItem stores one item of an import, returning its result for the job report.
+// Notes fail with quotaErr when their batch is over the user's quota.
+func (h *Handlers) storeImportItem(ctx context.Context, userID, plan string, valid validImportItem, quotaErr error, details map[string]interface{}) database.JobItem {
+	item := valid.item
+	result := database.JobItem{Index: valid.index, Key: importItemKey(item)}
+
+	itemDetails := details
+	if item.ID != "" {
+		itemDetails = map[string]interface{}{"import_id": item.ID}
+		for key, value := range details {
+			itemDetails[key] = value
+		}
+	}
+
+	var saved *database.UserData
+	var err error
+	if item.Type == "link" {
+		bookmark := bookmarkItem{URL: item.URL, Title: item.Title, Note: item.Text, Timestamp: valid.timestamp}
+		saved, err = h.importBookmark(ctx, userID, plan, h.Chunking, bookmark, "import", itemDetails)
+	} else if quotaErr != nil {
+		err = quotaErr
+	} else {
+		saved, err = h.importNote(ctx, userID, item, valid.timestamp, itemDetails)
+	}
+
+	switch {
+	case err != nil:
+		fmt.Printf("Warning: Failed to import %s: %v\n", result.Key, err)
+		result.Status, result.Reason = database.JobItemFailed, err.Error()
+	case saved == nil:
+		result.Status, result.Reason = database.JobItemSkipped, "already saved"
+	default:
+		result.Status, result.ItemID = database.JobItemSucceeded, saved.ID.Hex()
+	}
+	return result
+}
+
+// importNote stores an imported note like saved text, returning nil if a note with the
+// same source ID was already imported. The quota is checked by the caller.
+func (h *Handlers) importNote(ctx context.Context, userID string, item importItem, timestamp time.Time, details map[string]interface{}) (*database.UserData, error) {
+	if item.ID != "" {
+		if _, err := h.DB.GetUserDataByMetadata(ctx, userID, "note", "import_id", item.ID); err == nil {
+			return nil, nil
+		} else if err != mongo.ErrNoDocuments {
+			return nil, err
+		}
+	}
+
+	text := item.Text
+	if title := strings.TrimSpace(item.Title); title != "" && !strings.HasPrefix(text, title) {
+		text = title + "\n\n" + text
+	}
+
+	metadata := make(map[string]interface{}, len(details)+2)
+	for key, value := range details {
+		metadata[key] = value
+	}
+	setPeopleMetadata(metadata, h.extractPeople(ctx, text, nil))
+
+	data := models.Data{
+		Selected_type: "note",
+		Text:          text,
+		UserId:        userID,
+		Visibility:    item.Visibility,
+		Timestamp:     timestamp,
+	}
+	if keys, ok := metadata["people_keys"]; ok {
+		data.Metadata = map[string]interface{}{"people_keys": keys}
+	}
+
+	userData := &database.UserData{
+		UserID:     userID,
+		VectorID:   fmt.Sprintf("%s-%d", userID, time.Now().UnixNano()),
+		DataType:   "note",
+		DataValue:  text,
+		ChunkIndex: 0,
+		Metadata:   metadata,
+		Visibility: item.Visibility,
+		CreatedAt:  time.Now(),
+	}
+
+	// The vector is written in the background
+	if _, err := h.enqueueVector(ctx, userData, data, ""); err != nil {
+		return nil, err
+	}
+
+	auditDetails := map[string]interface{}{"source": "import"}
+	for key, value := range details {
+		auditDetails[key] = value
+	}
+	h.recordAudit(ctx, &database.AuditEvent{
+		UserID:   userID,
+		Action:   database.AuditActionSave,
+		ItemID:   userData.ID.Hex(),
+		ItemType: "note",
+		Summary:  utils.Truncate(text, auditSummaryLength),
+		Details:  auditDetails,
+	})
+	return userData, nil
+}
@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/models"
+	"github.com/siddhantgupta/forgetai-backend/internal/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// minTitleVectorChunks is the fewest chunks a document needs for a separate title vector
+	minTitleVectorChunks = 3
+	// titleVectorOpeningChars is how much of a document's opening is embedded with its title
+	titleVectorOpeningChars = 600
+)
+
+// documentChunk is one separately embedded piece of a parent document
+type documentChunk struct {
+	Text       string                 // chunk text stored in MongoDB
+	VectorText string                 // text stored with the vector; defaults to Text
+	EmbedText  string                 // text that is embedded; defaults to VectorText
+	Metadata   map[string]interface{} // extra vector metadata (strings, numbers, bools and []interface{} lists)
+}
+
+// parentDocument is a document stored as a parent record with embedded chunks
+type parentDocument struct {
+	UserID    string
+	Type      string // parent data type; chunks are stored as "<type>-chunk"
+	Title     string
+	Metadata  map[string]interface{} // stored on the parent record
+	Timestamp time.Time              // when the content was created; defaults to now
+	People    []string               // people known to be involved, e.g. authors; extracted people are added
+	Plan      string                 // user's plan for quota checks; "" for the default plan
+	Pages     int                    // pages of the source file, for plan limits; 0 if it has none
+	FullText  string                 // extracted text kept with the parent; "" to rebuild it from the chunks
+	File      *originalFile          // uploaded file the text was read from, kept for download; nil if none
+	Chunks    []documentChunk
+}
+
+// ingestDocument stores a parent record, then stores each of its chunks with their vector
+// writes enqueued. If any chunk fails, everything stored so far is removed so no partial
+// document remains. Documents that would exceed the user's quota or their plan's document
+// size limits are rejected with a *services.QuotaExceededError.
+func (h *Handlers) ingestDocument(ctx context.Context, doc *parentDocument) (*database.UserData, []string, error) {
+	if len(doc.Chunks) == 0 {
+		return nil, nil, fmt.Errorf("document has no content")
+	}
+	if err := h.Quota.CheckDocument(doc.Plan, doc.Pages, len(doc.Chunks)); err != nil {
+		return nil, nil, err
+	}
+	if err := h.checkQuota(ctx, doc.UserID, doc.Plan, documentTexts(doc)...); err != nil {
+		return nil, nil, err
+	}
+
+	h.tagPeople(ctx, doc, doc.People)
+
+	parentData := &database.UserData{
+		UserID:     doc.UserID,
+		VectorID:   "parent-" + fmt.Sprintf("%d", time.Now().UnixNano()),
+		DataType:   doc.Type,
+		DataValue:  doc.Title,
+		ChunkIndex: 0,
+		Metadata:   doc.Metadata,
+		CreatedAt:  time.Now(),
+	}
+	if doc.FullText != "" {
+		h.attachFullText(ctx, parentData, doc.FullText)
+	}
+	h.attachOriginalFile(ctx, parentData, doc.File)
+
+	parent, err := h.DB.CreateUserData(ctx, parentData)
+	if err != nil {
+		h.deleteOriginalFile(ctx, parentData)
+		return nil, nil, fmt.Errorf("failed to save document metadata: %w", err)
+	}
+
+	vectorIds, err := h.ingestChunks(ctx, doc, parent)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return parent, vectorIds, nil
+}
+
+// ingestChunks stores a document's chunks under an existing parent record.
+// If any chunk fails, the parent and everything stored so far are removed.
+func (h *Handlers) ingestChunks(ctx context.Context, doc *parentDocument, parent *database.UserData) ([]string, error) {
+	var vectorIds []string
+	for chunkIdx, chunk := range doc.Chunks {
+		if err := h.ingestChunk(ctx, doc, parent, chunkIdx, chunk, &vectorIds); err != nil {
+			h.rollbackDocument(ctx, parent, vectorIds)
+			return nil, err
+		}
+	}
+	h.enqueueTitleVector(ctx, doc, parent)
+	return vectorIds, nil
+}
+
+// titleVectorID returns the ID of the vector embedding a document's title and opening
+func titleVectorID(parent *database.UserData) string {
+	return fmt.Sprintf("%s-%s-title-%s", parent.UserID, parent.DataType, parent.ID.Hex())
+}
+
+// titleVectorParent returns the ID of the document a title vector belongs to, or false if
+// the vector ID isn't a title vector's. Title vectors have no record of their own.
+func titleVectorParent(vectorID string) (primitive.ObjectID, bool) {
+	i := strings.LastIndex(vectorID, "-title-")
+	if i < 0 {
+		return primitive.NilObjectID, false
+	}
+	parentID, err := primitive.ObjectIDFromHex(vectorID[i+len("-title-"):])
+	return parentID, err == nil
+}
+
+// enqueueTitleVector enqueues a vector for a long document's title and opening next to its
+// chunk vectors. Queries search both, so short high-level questions match documents whose
+// chunks are all low-level detail. Failures are only logged since the chunks stay searchable.
+func (h *Handlers) enqueueTitleVector(ctx context.Context, doc *parentDocument, parent *database.UserData) {
+	text := titleVectorText(doc)
+	if text == "" {
+		return
+	}
+
+	metadata := map[string]interface{}{"title": doc.Title, "title_vector": true}
+	if parent.Visibility != "" {
+		metadata["visibility"] = parent.Visibility
+	}
+	timestamp := doc.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	if err := h.DB.CreateOutboxEntry(ctx, parent, &database.OutboxEntry{
+		VectorID:   titleVectorID(parent),
+		DataType:   doc.Type,
+		VectorText: text,
+		EmbedText:  text,
+		Metadata:   metadata,
+		Timestamp:  timestamp,
+	}); err != nil {
+		fmt.Printf("Warning: Failed to enqueue title vector of %s: %v\n", parent.ID.Hex(), err)
+		return
+	}
+	h.wakeOutbox()
+}
+
+// titleVectorText is the text of a document's title vector, or "" if the document is too
+// short to get one
+func titleVectorText(doc *parentDocument) string {
+	if len(doc.Chunks) < minTitleVectorChunks {
+		return ""
+	}
+	return fmt.Sprintf("%s (overview): %s", doc.Title, utils.Truncate(doc.Chunks[0].Text, titleVectorOpeningChars))
+}
+
+// vectorText is the text stored with the chunk's vector
+func (chunk documentChunk) vectorText() string {
+	if chunk.VectorText == "" {
+		return chunk.Text
+	}
+	return chunk.VectorText
+}
+
+// embedText is the chunk's text that is embedded
+func (chunk documentChunk) embedText() string {
+	if chunk.EmbedText == "" {
+		return chunk.vectorText()
+	}
+	return chunk.EmbedText
+}
+
+// ingestChunk stores a chunk under the parent record and enqueues its vector write
+func (h *Handlers) ingestChunk(ctx context.Context, doc *parentDocument, parent *database.UserData, chunkIdx int, chunk documentChunk, vectorIds *[]string) error {
+	vectorText, embedText := chunk.vectorText(), chunk.embedText()
+
+	vectorId := fmt.Sprintf("%s-%s-%d-%d", doc.UserID, doc.Type, time.Now().UnixNano(), chunkIdx)
+
+	metadata := map[string]interface{}{"title": doc.Title}
+	for key, value := range chunk.Metadata {
+		metadata[key] = value
+	}
+
+	data := models.Data{
+		Selected_type: doc.Type,
+		Text:          vectorText,
+		UserId:        doc.UserID,
+		Metadata:      metadata,
+		Timestamp:     doc.Timestamp,
+	}
+
+	chunkData := &database.UserData{
+		UserID:     doc.UserID,
+		VectorID:   vectorId,
+		DataType:   doc.Type + "-chunk",
+		DataValue:  chunk.Text,
+		ParentID:   &parent.ID,
+		ChunkIndex: chunkIdx,
+		Visibility: parent.Visibility, // chunks added later keep the document's visibility
+		CreatedAt:  time.Now(),
+	}
+	if _, err := h.enqueueVector(ctx, chunkData, data, embedText); err != nil {
+		return fmt.Errorf("failed to save chunk %d: %w", chunkIdx, err)
+	}
+	*vectorIds = append(*vectorIds, vectorId)
+
+	return nil
+}
+
+// rollbackDocument removes the records and any already written vectors of a partially
+// ingested document. Records go first so no pending vector write is published afterwards.
+func (h *Handlers) rollbackDocument(ctx context.Context, parent *database.UserData, vectorIds []string) {
+	if err := h.DB.DeleteWithChunks(ctx, parent.ID.Hex(), parent.UserID); err != nil {
+		fmt.Printf("Warning: Failed to remove partial document %s: %v\n", parent.ID.Hex(), err)
+	}
+	h.deleteFullText(ctx, parent)
+	h.deleteOriginalFile(ctx, parent)
+	for _, vectorId := range vectorIds {
+		if err := h.Pinecone.DeleteVector(ctx, vectorId); err != nil {
+			fmt.Printf("Warning: Failed to delete vector %s from Pinecone: %v\n", vectorId, err)
+		}
+	}
+	if err := h.Pinecone.DeleteVector(ctx, titleVectorID(parent)); err != nil {
+		fmt.Printf("Warning: Failed to delete vector %s from Pinecone: %v\n", titleVectorID(parent), err)
+	}
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+)
+
+// DrainOutbox handles worker requests to publish pending vector writes now rather than
+// on the publisher's next tick
+func (h *Handlers) DrainOutbox(c *gin.Context) {
+	h.wakeOutbox()
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": i18n.T(c, "Outbox drain requested"),
+	})
+}
+
+// SyncZoteroForUser handles worker requests to sync one user's Zotero library, e.g. from
+// a task queue fanning out scheduled syncs
+func (h *Handlers) SyncZoteroForUser(c *gin.Context) {
+	var req struct {
+		UserID string `json:"userId" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+		return
+	}
+
+	integration, err := h.DB.GetZoteroIntegration(c.Request.Context(), req.UserID)
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch Zotero integration")
+		return
+	}
+	if integration == nil {
+		i18n.RespondError(c, http.StatusNotFound, nil, "Zotero is not connected")
+		return
+	}
+
+	result, ok, err := h.syncZoteroIntegration(c.Request.Context(), integration)
+	if !ok {
+		i18n.RespondError(c, http.StatusConflict, nil, "A Zotero sync is already running")
+		return
+	}
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadGateway, err, "Zotero sync failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(c, "Zotero library synced"),
+		"result":  result,
+	})
+}
+
+// SyncGitHubForUser handles worker requests to sync one user's starred GitHub repositories
+func (h *Handlers) SyncGitHubForUser(c *gin.Context) {
+	var req struct {
+		UserID string `json:"userId" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+		return
+	}
+	h.syncGitHubForUser(c, req.UserID)
+}
+
+// SyncNotionForUser handles worker requests to sync one user's Notion workspace
+func (h *Handlers) SyncNotionForUser(c *gin.Context) {
+	var req struct {
+		UserID string `json:"userId" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+		return
+	}
+
+	integration, err := h.DB.GetNotionIntegration(c.Request.Context(), req.UserID)
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch Notion integration")
+		return
+	}
+	if integration == nil {
+		i18n.RespondError(c, http.StatusNotFound, nil, "Notion is not connected")
+		return
+	}
+
+	result, ok, err := h.syncNotionIntegration(c.Request.Context(), integration)
+	if !ok {
+		i18n.RespondError(c, http.StatusConflict, nil, "A Notion sync is already running")
+		return
+	}
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadGateway, err, "Notion sync failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(c, "Notion workspace synced"),
+		"result":  result,
+	})
+}
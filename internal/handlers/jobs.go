@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+)
+
+// jobStatusTimeout bounds writing a background job's final status
+const jobStatusTimeout = 10 * time.Second
+
+// jobRun records the progress of a background job. Failed writes are only logged, so a
+// lost update doesn't stop the job.
+type jobRun struct {
+	h   *Handlers
+	job *database.Job
+}
+
+// startJob marks a background job as running
+func (h *Handlers) startJob(ctx context.Context, job *database.Job) *jobRun {
+	run := &jobRun{h: h, job: job}
+	run.warn(h.DB.SetJobStatus(ctx, job.ID, database.JobRunning, ""))
+	return run
+}
+
+// record records the result of one of the job's items
+func (r *jobRun) record(ctx context.Context, item database.JobItem) {
+	r.warn(r.h.DB.RecordJobItem(ctx, r.job.ID, item))
+}
+
+// setStage records which stage the job is in
+func (r *jobRun) setStage(ctx context.Context, stage string) {
+	r.warn(r.h.DB.SetJobStage(ctx, r.job.ID, stage))
+}
+
+// finish records the job's last items and marks it completed, or failed with timeoutErr
+// if ctx expired first. The job's own context may have expired, so they're written
+// without it.
+func (r *jobRun) finish(ctx context.Context, timeoutErr string, items ...database.JobItem) {
+	status, jobError := database.JobCompleted, ""
+	if ctx.Err() != nil {
+		status, jobError = database.JobFailed, timeoutErr
+	}
+
+	statusCtx, cancel := context.WithTimeout(context.Background(), jobStatusTimeout)
+	defer cancel()
+	for _, item := range items {
+		r.record(statusCtx, item)
+	}
+	r.warn(r.h.DB.SetJobStatus(statusCtx, r.job.ID, status, jobError))
+}
+
+func (r *jobRun) warn(err error) {
+	if err != nil {
+		fmt.Printf("Warning: Failed to update job %s: %v\n", r.job.ID.Hex(), err)
+	}
+}
+
+// runConcurrently calls process with each index below n on up to workers goroutines at
+// once, handing out no more indexes once ctx is done
+func runConcurrently(ctx context.Context, workers, n int, process func(i int)) {
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				process(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		select {
+		case queue <- i:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(queue)
+	wg.Wait()
+}
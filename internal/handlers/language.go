@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/siddhantgupta/forgetai-backend/internal/langdetect"
+)
+
+// contentLanguageKey is the metadata key holding the detected language of saved content.
+// "language" already holds the programming language of code.
+const contentLanguageKey = "content_language"
+
+// retrievalText returns the text to embed for content or a query in the given language.
+// With translation enabled, non-English text is embedded together with its English
+// translation so it matches items and queries written in other languages.
+func (h *Handlers) retrievalText(ctx context.Context, text, language string) string {
+	if h.Translator == nil || language == "" || language == langdetect.English {
+		return text
+	}
+
+	translation, err := h.Translator.ToEnglish(ctx, text)
+	if err != nil {
+		// Embedding the original text only still finds same-language matches
+		fmt.Printf("Warning: Failed to translate %s text for retrieval: %v\n", language, err)
+		return text
+	}
+	if translation == "" {
+		return text
+	}
+	return text + "\n\nEnglish translation: " + translation
+}
+
+// queryEmbedding embeds a search query, translating it first when it isn't in English
+// and translation is enabled
+func (h *Handlers) queryEmbedding(ctx context.Context, query string) ([]float32, error) {
+	return h.OpenAI.GetQueryEmbedding(ctx, h.retrievalText(ctx, query, langdetect.Detect(query)))
+}
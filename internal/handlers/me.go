@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/siddhantgupta/forgetai-backend/internal/auth"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+)
+
+// GetMe handles requests to introspect the caller's token: its safe claims, the resolved
+// user ID and plan, and current quota state. It helps debug auth without server logs.
+func (h *Handlers) GetMe(c *gin.Context) {
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusInternalServerError, nil, "User ID not found in request context")
+		return
+	}
+
+	var claims jwt.MapClaims
+	if value, ok := c.Get("claims"); ok {
+		claims, _ = value.(jwt.MapClaims)
+	}
+
+	ctx := c.Request.Context()
+	quota, err := h.quotaState(ctx, userID.(string), requestPlan(c))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to get storage usage")
+		return
+	}
+
+	token := gin.H{}
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		token["expires_at"] = exp.Time
+		token["expires_in_seconds"] = int64(time.Until(exp.Time).Seconds())
+	}
+	if iat, err := claims.GetIssuedAt(); err == nil && iat != nil {
+		token["issued_at"] = iat.Time
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user_id": userID.(string),
+		"plan":    quota["plan"],
+		"claims":  auth.SafeClaims(claims),
+		"token":   token,
+		"quota":   quota,
+		"rate_limits": gin.H{
+			"usage":              h.rateLimitUsage(ctx, userID.(string)),
+			"limit_per_endpoint": services.DailyRateLimit,
+		},
+	})
+}
@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/chunking"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+)
+
+const (
+	// maxMeetingFileSize is the largest meeting recording accepted in bytes
+	maxMeetingFileSize = 1 << 30
+	// meetingProcessingTimeout bounds transcription and ingestion of one recording
+	meetingProcessingTimeout = 3 * time.Hour
+)
+
+// Meeting processing states recorded in the parent item's metadata
+const (
+	meetingStatusProcessing = "processing"
+	meetingStatusReady      = "ready"
+	meetingStatusFailed     = "failed"
+)
+
+// SaveMeeting handles meeting recording uploads. The recording is uploaded for diarized
+// transcription and the transcript is stored in the background as speaker-labeled chunks.
+// Clients follow progress through the item's metadata status.
+func (h *Handlers) SaveMeeting(c *gin.Context) {
+	if h.Diarizer == nil {
+		i18n.RespondError(c, http.StatusServiceUnavailable, nil, "Meeting transcription is not configured")
+		return
+	}
+
+	userId, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusInternalServerError, nil, "User ID not found in request context")
+		return
+	}
+
+	file, err := c.FormFile("recording")
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Failed to retrieve recording file")
+		return
+	}
+	if file.Size > maxMeetingFileSize {
+		i18n.RespondError(c, http.StatusRequestEntityTooLarge, nil, "Recording must be at most %d MB", maxMeetingFileSize>>20)
+		return
+	}
+
+	// Optional speaker names by diarization label, e.g. {"A": "Priya", "B": "Sam"}
+	speakerNames := map[string]string{}
+	if raw := c.PostForm("speakers"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &speakerNames); err != nil {
+			i18n.RespondError(c, http.StatusBadRequest, err, "Invalid speakers")
+			return
+		}
+	}
+	speakersExpected := 0
+	if raw := c.PostForm("speakers_expected"); raw != "" {
+		if speakersExpected, err = strconv.Atoi(raw); err != nil || speakersExpected < 1 {
+			i18n.RespondError(c, http.StatusBadRequest, err, "Invalid speakers_expected")
+			return
+		}
+	}
+	meetingDate := time.Now()
+	if raw := c.PostForm("meeting_date"); raw != "" {
+		if meetingDate, err = time.Parse(time.RFC3339, raw); err != nil {
+			i18n.RespondError(c, http.StatusBadRequest, err, "Invalid meeting_date, expected RFC3339")
+			return
+		}
+	}
+	options, err := h.chunkingOptions(c)
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid chunking parameters")
+		return
+	}
+	options = sourceChunking(options)
+	title := strings.TrimSpace(c.PostForm("title"))
+	if title == "" {
+		title = fmt.Sprintf("Meeting on %s", meetingDate.Format("Monday, January 2, 2006"))
+	}
+
+	recording, err := file.Open()
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to open recording file")
+		return
+	}
+	defer recording.Close()
+
+	// The transcript's size is only known later, so only the item count can be checked now
+	if err := h.Quota.Check(c.Request.Context(), userId.(string), requestPlan(c), database.StorageUsage{Items: 1}); err != nil {
+		respondSaveError(c, err, "Failed to save meeting metadata")
+		return
+	}
+
+	// Upload while the request's temporary file still exists; transcription continues after the response
+	audioURL, err := h.Diarizer.Upload(c.Request.Context(), recording)
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadGateway, err, "Failed to upload recording for transcription")
+		return
+	}
+
+	doc := &parentDocument{
+		UserID: userId.(string),
+		Plan:   requestPlan(c),
+		Type:   "meeting",
+		Title:  title,
+		Metadata: map[string]interface{}{
+			"status":       meetingStatusProcessing,
+			"filename":     file.Filename,
+			"meeting_date": meetingDate.Format(time.RFC3339),
+		},
+		Timestamp: meetingDate,
+	}
+	doc.recordChunking(options)
+	parentData := &database.UserData{
+		UserID:     doc.UserID,
+		VectorID:   "parent-" + fmt.Sprintf("%d", time.Now().UnixNano()),
+		DataType:   doc.Type,
+		DataValue:  doc.Title,
+		ChunkIndex: 0,
+		Metadata:   doc.Metadata,
+		CreatedAt:  time.Now(),
+	}
+	h.attachOriginalFile(c.Request.Context(), parentData, &originalFile{
+		Name:        file.Filename,
+		ContentType: file.Header.Get("Content-Type"),
+		Size:        file.Size,
+		Body:        recording,
+	})
+	record, err := h.DB.CreateUserData(c.Request.Context(), parentData)
+	if err != nil {
+		h.deleteOriginalFile(c.Request.Context(), parentData)
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to save meeting metadata")
+		return
+	}
+
+	go h.processMeeting(doc, record, audioURL, speakerNames, speakersExpected, options)
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": i18n.T(c, "Meeting recording uploaded, transcription in progress"),
+		"id":      record.ID.Hex(),
+		"type":    "meeting",
+		"title":   title,
+		"status":  meetingStatusProcessing,
+	})
+}
+
+// processMeeting transcribes an uploaded recording and stores its speaker-labeled chunks,
+// recording the outcome on the parent item and notifying the user
+func (h *Handlers) processMeeting(doc *parentDocument, record *database.UserData, audioURL string, speakerNames map[string]string, speakersExpected int, options chunking.Options) {
+	ctx, cancel := context.WithTimeout(services.WithBillingUser(context.Background(), doc.UserID), meetingProcessingTimeout)
+	defer cancel()
+
+	fail := func(err error) {
+		fmt.Printf("Warning: Failed to process meeting %s: %v\n", record.ID.Hex(), err)
+		if err := h.DB.SetUserDataMetadata(ctx, record.ID, record.UserID, map[string]interface{}{
+			"status": meetingStatusFailed,
+			"error":  err.Error(),
+		}); err != nil {
+			fmt.Printf("Warning: Failed to record meeting status: %v\n", err)
+		}
+	}
+
+	transcript, err := h.Diarizer.Transcribe(ctx, audioURL, speakersExpected)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	speakers := buildMeetingChunks(doc, transcript.Utterances, speakerNames, options)
+	if len(doc.Chunks) == 0 {
+		fail(fmt.Errorf("no speech found in recording"))
+		return
+	}
+
+	// The parent record is already stored and counted
+	usage := itemUsage(documentTexts(doc)...)
+	usage.Items = 0
+	if err := h.Quota.Check(ctx, doc.UserID, doc.Plan, usage); err != nil {
+		fail(err)
+		return
+	}
+
+	// Named speakers are people in the meeting even if nobody says their name
+	var named []string
+	for _, speaker := range speakers {
+		if !strings.HasPrefix(speaker, "Speaker ") {
+			named = append(named, speaker)
+		}
+	}
+	people := h.tagPeople(ctx, doc, named)
+
+	// On failure the partial meeting is removed entirely
+	if _, err := h.ingestChunks(ctx, doc, record); err != nil {
+		fmt.Printf("Warning: Failed to store meeting %s: %v\n", record.ID.Hex(), err)
+		return
+	}
+
+	status := map[string]interface{}{
+		"status":      meetingStatusReady,
+		"speakers":    speakers,
+		"duration_ms": transcript.DurationMS,
+	}
+	setPeopleMetadata(status, people)
+	if err := h.DB.SetUserDataMetadata(ctx, record.ID, record.UserID, status); err != nil {
+		fmt.Printf("Warning: Failed to record meeting status: %v\n", err)
+	}
+
+	h.recordAudit(ctx, &database.AuditEvent{
+		UserID:   record.UserID,
+		Action:   database.AuditActionSave,
+		ItemID:   record.ID.Hex(),
+		ItemType: "meeting",
+		Summary:  doc.Title,
+		Details:  map[string]interface{}{"speakers": speakers, "chunk_count": len(doc.Chunks)},
+	})
+
+	if err := h.Notifier.Notify(ctx, record.UserID, services.Notification{
+		Type:  services.NotificationImportComplete,
+		Title: "Meeting transcribed",
+		Body:  fmt.Sprintf("%q is ready to search.", doc.Title),
+	}); err != nil {
+		fmt.Printf("Warning: Failed to send meeting notification: %v\n", err)
+	}
+}
+
+// buildMeetingChunks groups consecutive utterances into speaker-labeled chunks and returns
+// the names of everyone who spoke
+func buildMeetingChunks(doc *parentDocument, utterances []services.Utterance, speakerNames map[string]string, options chunking.Options) []string {
+	speakerName := func(label string) string {
+		if name := strings.TrimSpace(speakerNames[label]); name != "" {
+			return name
+		}
+		return "Speaker " + label
+	}
+	meetingDate, _ := doc.Metadata["meeting_date"].(string)
+
+	var speakers []string
+	seenSpeakers := make(map[string]bool)
+
+	var current strings.Builder
+	var chunkSpeakers []interface{}
+	chunkSeen := make(map[string]bool)
+	var start, end int64
+	previous := ""
+	flush := func() {
+		if current.Len() == 0 {
+			return
+		}
+		doc.Chunks = append(doc.Chunks, documentChunk{
+			Text:       current.String(),
+			VectorText: fmt.Sprintf("Meeting %s (%s): %s", doc.Title, meetingDate, current.String()),
+			EmbedText:  fmt.Sprintf("Meeting %s (%s): %s", doc.Title, meetingDate, withOverlap(previous, current.String(), options)),
+			Metadata: map[string]interface{}{
+				"speakers":     chunkSpeakers,
+				"start_ms":     start,
+				"end_ms":       end,
+				"meeting_date": meetingDate,
+			},
+		})
+		previous = current.String()
+		current.Reset()
+		chunkSpeakers = nil
+		chunkSeen = make(map[string]bool)
+	}
+
+	for _, utterance := range utterances {
+		name := speakerName(utterance.Speaker)
+		if !seenSpeakers[name] {
+			seenSpeakers[name] = true
+			speakers = append(speakers, name)
+		}
+
+		// A single long utterance is split, keeping the speaker on every piece
+		for _, piece := range chunking.ChunkText(utterance.Text, options.MaxChars()) {
+			line := fmt.Sprintf("%s: %s", name, piece)
+			if current.Len() > 0 && current.Len()+len(line)+1 > options.MaxChars() {
+				flush()
+			}
+			if current.Len() == 0 {
+				start = utterance.Start
+			} else {
+				current.WriteString("\n")
+			}
+			current.WriteString(line)
+			end = utterance.End
+			if !chunkSeen[name] {
+				chunkSeen[name] = true
+				chunkSpeakers = append(chunkSpeakers, name)
+			}
+		}
+	}
+	flush()
+
+	return speakers
+}
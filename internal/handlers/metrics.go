@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+)
+
+const (
+	defaultAdminAnalyticsDays = 7
+	maxAdminAnalyticsDays     = 90
+)
+
+// routeStats summarizes a route's requests over the analytics window
+type routeStats struct {
+	Route           string  `json:"route"`
+	Requests        int64   `json:"requests"`
+	ServerErrors    int64   `json:"server_errors"`
+	ClientErrors    int64   `json:"client_errors"`
+	ServerErrorRate float64 `json:"server_error_rate"`
+	MeanLatencyMS   int64   `json:"mean_latency_ms"`
+	P95LatencyMS    int64   `json:"p95_latency_ms"` // -1 when beyond the largest histogram bucket
+}
+
+// MetricsMiddleware records every routed request's status and latency, and each call of
+// a rate-limited endpoint in its user's usage history
+func MetricsMiddleware(metrics *services.MetricsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		// Unmatched paths are not recorded so scanners can't create unbounded routes
+		if c.FullPath() == "" {
+			return
+		}
+		metrics.RecordRequest(c.Request.Method+" "+c.FullPath(), c.Writer.Status(), time.Since(start))
+		if endpoint := c.GetString("rateLimitEndpoint"); endpoint != "" {
+			metrics.RecordUserRequest(c.GetString("userId"), endpoint)
+		}
+	}
+}
+
+// GetAdminAnalytics handles requests for service-wide operational analytics: daily active
+// users and actions, estimated provider spend, and error rates and latencies by route
+func (h *Handlers) GetAdminAnalytics(c *gin.Context) {
+	if !h.requireAdmin(c) {
+		return
+	}
+
+	days := defaultAdminAnalyticsDays
+	if daysStr := c.Query("days"); daysStr != "" {
+		n, err := strconv.Atoi(daysStr)
+		if err != nil || n <= 0 {
+			i18n.RespondError(c, http.StatusBadRequest, nil, "days must be a positive integer")
+			return
+		}
+		if n > maxAdminAnalyticsDays {
+			n = maxAdminAnalyticsDays
+		}
+		days = n
+	}
+	// Days are UTC, counting today as the first
+	sinceDay := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1-days)
+
+	ctx := c.Request.Context()
+	activity, err := h.DB.GetDailyActivity(ctx, sinceDay)
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch analytics")
+		return
+	}
+	routeMetrics, err := h.DB.GetRouteMetrics(ctx, sinceDay.Format(database.MetricsDayFormat))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch analytics")
+		return
+	}
+	usageMetrics, err := h.DB.GetUsageMetrics(ctx, sinceDay.Format(database.MetricsDayFormat))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch analytics")
+		return
+	}
+	eventMetrics, err := h.DB.GetEventMetrics(ctx, sinceDay.Format(database.MetricsDayFormat))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch analytics")
+		return
+	}
+	if activity == nil {
+		activity = []*database.DailyActivity{}
+	}
+
+	routes := summarizeRoutes(routeMetrics)
+	var totalRequests, totalServerErrors int64
+	for _, route := range routes {
+		totalRequests += route.Requests
+		totalServerErrors += route.ServerErrors
+	}
+
+	usage, spend, totalSpend := estimateSpend(usageMetrics)
+
+	c.JSON(http.StatusOK, gin.H{
+		"days":                days,
+		"since":               sinceDay.Format(database.MetricsDayFormat),
+		"daily":               activity,
+		"requests":            totalRequests,
+		"server_errors":       totalServerErrors,
+		"server_error_rate":   ratio(totalServerErrors, totalRequests),
+		"routes":              routes,
+		"usage":               usage,
+		"estimated_spend":     spend,
+		"estimated_spend_usd": totalSpend,
+		"events":              totalEvents(eventMetrics),
+	})
+}
+
+// summarizeRoutes combines daily route metrics into per-route stats, busiest first
+func summarizeRoutes(metrics []*database.RouteMetrics) []*routeStats {
+	byRoute := make(map[string]*database.RouteMetrics)
+	for _, m := range metrics {
+		total, ok := byRoute[m.Route]
+		if !ok {
+			total = &database.RouteMetrics{Route: m.Route, Buckets: make(map[string]int64)}
+			byRoute[m.Route] = total
+		}
+		total.Count += m.Count
+		total.ServerErrors += m.ServerErrors
+		total.ClientErrors += m.ClientErrors
+		total.LatencyTotalMS += m.LatencyTotalMS
+		for bucket, count := range m.Buckets {
+			total.Buckets[bucket] += count
+		}
+	}
+
+	routes := make([]*routeStats, 0, len(byRoute))
+	for _, m := range byRoute {
+		stats := &routeStats{
+			Route:           m.Route,
+			Requests:        m.Count,
+			ServerErrors:    m.ServerErrors,
+			ClientErrors:    m.ClientErrors,
+			ServerErrorRate: ratio(m.ServerErrors, m.Count),
+			P95LatencyMS:    services.LatencyPercentile(m.Buckets, 95),
+		}
+		if m.Count > 0 {
+			stats.MeanLatencyMS = m.LatencyTotalMS / m.Count
+		}
+		routes = append(routes, stats)
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Requests != routes[j].Requests {
+			return routes[i].Requests > routes[j].Requests
+		}
+		return routes[i].Route < routes[j].Route
+	})
+	return routes
+}
+
+// estimateSpend totals provider usage over the window and estimates its cost in USD
+// per provider and overall
+func estimateSpend(metrics []*database.UsageMetrics) (map[string]map[string]int64, map[string]float64, float64) {
+	usage := make(map[string]map[string]int64)
+	spend := make(map[string]float64)
+	var total float64
+	for _, m := range metrics {
+		if usage[m.Provider] == nil {
+			usage[m.Provider] = make(map[string]int64)
+		}
+		for unit, amount := range m.Units {
+			usage[m.Provider][unit] += amount
+			cost := float64(amount) * services.UsagePricesUSD[m.Provider][unit]
+			spend[m.Provider] += cost
+			total += cost
+		}
+	}
+	return usage, spend, total
+}
+
+// totalEvents totals event counts over the window
+func totalEvents(metrics []*database.EventMetrics) map[string]int64 {
+	events := make(map[string]int64)
+	for _, m := range metrics {
+		for event, count := range m.Counts {
+			events[event] += count
+		}
+	}
+	return events
+}
+
+// ratio returns n/total, or 0 when total is 0
+func ratio(n, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/models"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+)
+
+// GetNotificationPreferences handles retrieving the user's notification preferences
+func (h *Handlers) GetNotificationPreferences(c *gin.Context) {
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	prefs, err := h.loadNotificationPreferences(c, userID.(string))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch notification preferences")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"preferences":        prefs,
+		"available_channels": h.Notifier.Channels(),
+		"available_types":    services.NotificationTypes,
+	})
+}
+
+// UpdateNotificationPreferences handles updating the user's notification preferences
+func (h *Handlers) UpdateNotificationPreferences(c *gin.Context) {
+	var req models.NotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+		return
+	}
+
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	for channel := range req.Channels {
+		if channel != services.ChannelEmail && channel != services.ChannelPush {
+			i18n.RespondError(c, http.StatusBadRequest, nil, "Unknown notification channel: %s", channel)
+			return
+		}
+	}
+	for notificationType := range req.Types {
+		if !isNotificationType(notificationType) {
+			i18n.RespondError(c, http.StatusBadRequest, nil, "Unknown notification type: %s", notificationType)
+			return
+		}
+	}
+
+	prefs, err := h.loadNotificationPreferences(c, userID.(string))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch notification preferences")
+		return
+	}
+
+	if req.Email != nil {
+		prefs.Email = *req.Email
+	}
+	for channel, enabled := range req.Channels {
+		prefs.Channels[channel] = enabled
+	}
+	for notificationType, enabled := range req.Types {
+		prefs.Types[notificationType] = enabled
+	}
+
+	if err := h.DB.UpsertNotificationPreferences(c.Request.Context(), prefs); err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to save notification preferences")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     i18n.T(c, "Notification preferences updated"),
+		"preferences": prefs,
+	})
+}
+
+// loadNotificationPreferences returns the stored preferences, or the defaults if none are stored
+func (h *Handlers) loadNotificationPreferences(c *gin.Context, userID string) (*database.NotificationPreferences, error) {
+	prefs, err := h.DB.GetNotificationPreferences(c.Request.Context(), userID)
+	if err != nil {
+		return nil, err
+	}
+	if prefs == nil {
+		return services.DefaultNotificationPreferences(userID), nil
+	}
+	if prefs.Channels == nil {
+		prefs.Channels = make(map[string]bool)
+	}
+	if prefs.Types == nil {
+		prefs.Types = make(map[string]bool)
+	}
+	return prefs, nil
+}
+
+// isNotificationType reports whether t is a known notification type
+func isNotificationType(t string) bool {
+	for _, known := range services.NotificationTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
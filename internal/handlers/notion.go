@@ -0,0 +1,362 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/chunking"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+)
+
+// maxNotionChunksPerPage caps the chunks stored for one Notion page
+const maxNotionChunksPerPage = 200
+
+// notionSyncResult counts the changes applied by a Notion sync
+type notionSyncResult struct {
+	Imported  int `json:"imported"`
+	Unchanged int `json:"unchanged"`
+	Removed   int `json:"removed"`
+	Failed    int `json:"failed"`
+}
+
+// ConnectNotion handles connecting a Notion workspace with the access token from the
+// user's OAuth authorization and starts an initial sync
+func (h *Handlers) ConnectNotion(c *gin.Context) {
+	var req struct {
+		AccessToken string `json:"accessToken" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+		return
+	}
+
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	token := strings.TrimSpace(req.AccessToken)
+	bot, err := h.Notion.GetBot(c.Request.Context(), token)
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid Notion access token")
+		return
+	}
+
+	existing, err := h.DB.GetNotionIntegration(c.Request.Context(), userID.(string))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to save Notion integration")
+		return
+	}
+
+	integration := &database.NotionIntegration{
+		UserID:        userID.(string),
+		BotID:         bot.ID,
+		WorkspaceName: bot.WorkspaceName,
+		AccessToken:   token,
+		Plan:          requestPlan(c),
+	}
+	if existing != nil {
+		integration.PageCount = existing.PageCount
+		integration.CreatedAt = existing.CreatedAt
+	}
+
+	if err := h.DB.UpsertNotionIntegration(c.Request.Context(), integration); err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to save Notion integration")
+		return
+	}
+
+	go h.syncNotionIntegration(context.Background(), integration)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     i18n.T(c, "Notion workspace connected, syncing in the background"),
+		"integration": integration,
+	})
+}
+
+// GetNotionStatus handles retrieving the user's Notion integration and sync state
+func (h *Handlers) GetNotionStatus(c *gin.Context) {
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	integration, err := h.DB.GetNotionIntegration(c.Request.Context(), userID.(string))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch Notion integration")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"connected":   integration != nil,
+		"integration": integration,
+	})
+}
+
+// DisconnectNotion handles removing the user's Notion integration. Pages already
+// imported are kept.
+func (h *Handlers) DisconnectNotion(c *gin.Context) {
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	removed, err := h.DB.DeleteNotionIntegration(c.Request.Context(), userID.(string))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to remove Notion integration")
+		return
+	}
+	if !removed {
+		i18n.RespondError(c, http.StatusNotFound, nil, "Notion is not connected")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(c, "Notion workspace disconnected"),
+	})
+}
+
+// SyncNotion handles on-demand Notion syncs
+func (h *Handlers) SyncNotion(c *gin.Context) {
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	integration, err := h.DB.GetNotionIntegration(c.Request.Context(), userID.(string))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch Notion integration")
+		return
+	}
+	if integration == nil {
+		i18n.RespondError(c, http.StatusNotFound, nil, "Notion is not connected")
+		return
+	}
+
+	result, ok, err := h.syncNotionIntegration(c.Request.Context(), integration)
+	if !ok {
+		i18n.RespondError(c, http.StatusConflict, nil, "A Notion sync is already running")
+		return
+	}
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadGateway, err, "Notion sync failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(c, "Notion workspace synced"),
+		"result":  result,
+	})
+}
+
+// RunNotionSync syncs every connected Notion workspace on the given interval until ctx is
+// cancelled. A non-positive interval disables scheduled syncs.
+func (h *Handlers) RunNotionSync(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		integrations, err := h.DB.ListNotionIntegrations(ctx)
+		if err != nil {
+			fmt.Printf("Warning: Failed to list Notion integrations: %v\n", err)
+			continue
+		}
+		for _, integration := range integrations {
+			if ctx.Err() != nil {
+				return
+			}
+			h.syncNotionIntegration(ctx, integration)
+		}
+	}
+}
+
+// syncNotionIntegration runs a sync and records its outcome. It reports false without
+// syncing if a sync for the same user is already running.
+func (h *Handlers) syncNotionIntegration(ctx context.Context, integration *database.NotionIntegration) (*notionSyncResult, bool, error) {
+	if _, running := h.notionSyncs.LoadOrStore(integration.UserID, true); running {
+		return nil, false, nil
+	}
+	defer h.notionSyncs.Delete(integration.UserID)
+
+	ctx = services.WithBillingUser(ctx, integration.UserID)
+	result, pageCount, err := h.syncNotion(ctx, integration)
+
+	lastError := ""
+	switch {
+	case err != nil:
+		lastError = err.Error()
+		pageCount = -1
+	case result.Failed > 0:
+		lastError = fmt.Sprintf("%d page(s) failed to import", result.Failed)
+	}
+	if stateErr := h.DB.UpdateNotionSyncState(ctx, integration.UserID, pageCount, lastError); stateErr != nil {
+		fmt.Printf("Warning: Failed to record Notion sync state for %s: %v\n", integration.UserID, stateErr)
+	}
+	if err != nil {
+		fmt.Printf("Warning: Notion sync failed for %s: %v\n", integration.UserID, err)
+	}
+
+	return result, true, err
+}
+
+// syncNotion imports pages edited since they were last imported and removes imports of
+// pages no longer shared with the integration, returning the number of pages shared. A
+// page is re-imported only when its last-edited time differs from the imported one.
+func (h *Handlers) syncNotion(ctx context.Context, integration *database.NotionIntegration) (*notionSyncResult, int, error) {
+	pages, err := h.Notion.ListPages(ctx, integration.AccessToken)
+	if err != nil {
+		return nil, 0, err
+	}
+	imports, err := h.DB.GetUserDataByType(ctx, integration.UserID, "notion")
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list imported Notion pages: %v", err)
+	}
+
+	imported := make(map[string]*database.UserData, len(imports))
+	for _, item := range imports {
+		if pageID, ok := item.Metadata["notion_page_id"].(string); ok {
+			imported[pageID] = item
+		}
+	}
+
+	result := &notionSyncResult{}
+	shared := 0
+	for _, page := range pages {
+		if page.Archived {
+			continue
+		}
+		shared++
+
+		previous := imported[page.ID]
+		delete(imported, page.ID)
+		if previous != nil && previous.Metadata["notion_last_edited"] == page.LastEditedTime.UTC().Format(time.RFC3339) {
+			result.Unchanged++
+			continue
+		}
+		if err := h.importNotionPage(ctx, integration, page, previous); err != nil {
+			fmt.Printf("Warning: Failed to import Notion page %s: %v\n", page.ID, err)
+			result.Failed++
+			continue
+		}
+		result.Imported++
+	}
+
+	// Whatever is left was archived, deleted or unshared since it was imported
+	for pageID, item := range imported {
+		if err := h.removeNotionPage(ctx, integration.UserID, pageID, item); err != nil {
+			fmt.Printf("Warning: Failed to remove Notion page %s: %v\n", pageID, err)
+			result.Failed++
+			continue
+		}
+		result.Removed++
+	}
+
+	return result, shared, nil
+}
+
+// importNotionPage imports a page's content, replacing its previous import if any
+func (h *Handlers) importNotionPage(ctx context.Context, integration *database.NotionIntegration, page *services.NotionPage, previous *database.UserData) error {
+	text, err := h.Notion.GetPageText(ctx, integration.AccessToken, page.ID)
+	if err != nil {
+		return err
+	}
+
+	doc := buildNotionDocument(integration, page, text)
+	if len(doc.Chunks) > maxNotionChunksPerPage {
+		fmt.Printf("Warning: Notion page %s has %d chunks, keeping the first %d\n", page.ID, len(doc.Chunks), maxNotionChunksPerPage)
+		doc.Chunks = doc.Chunks[:maxNotionChunksPerPage]
+	}
+
+	// Import the new version before removing the old one so the page is never missing
+	record, _, err := h.ingestDocument(ctx, doc)
+	if err != nil {
+		return err
+	}
+
+	if previous != nil {
+		if err := h.deleteItem(ctx, previous); err != nil {
+			fmt.Printf("Warning: Failed to remove previous import of Notion page %s: %v\n", page.ID, err)
+		}
+	}
+
+	h.recordAudit(ctx, &database.AuditEvent{
+		UserID:   integration.UserID,
+		Action:   database.AuditActionSave,
+		ItemID:   record.ID.Hex(),
+		ItemType: "notion",
+		Summary:  doc.Title,
+		Details:  map[string]interface{}{"source": "notion_sync", "notion_page_id": page.ID, "chunk_count": len(doc.Chunks)},
+	})
+
+	return nil
+}
+
+// removeNotionPage deletes the import of a Notion page
+func (h *Handlers) removeNotionPage(ctx context.Context, userID, pageID string, item *database.UserData) error {
+	if err := h.deleteItem(ctx, item); err != nil {
+		return err
+	}
+
+	h.recordAudit(ctx, &database.AuditEvent{
+		UserID:   userID,
+		Action:   database.AuditActionDelete,
+		ItemID:   item.ID.Hex(),
+		ItemType: "notion",
+		Summary:  item.DataValue,
+		Details:  map[string]interface{}{"source": "notion_sync", "notion_page_id": pageID},
+	})
+	return nil
+}
+
+// buildNotionDocument creates the parent document for a page, chunked within the sections
+// of its content. A page with no content is stored as its title.
+func buildNotionDocument(integration *database.NotionIntegration, page *services.NotionPage, text string) *parentDocument {
+	title := page.Title
+	if title == "" {
+		title = "Untitled Notion page"
+	}
+	if text == "" {
+		text = title
+	}
+
+	doc := &parentDocument{
+		UserID:    integration.UserID,
+		Type:      "notion",
+		Title:     title,
+		Timestamp: page.CreatedTime,
+		Plan:      integration.Plan,
+		Metadata: map[string]interface{}{
+			"notion_page_id":     page.ID,
+			"notion_last_edited": page.LastEditedTime.UTC().Format(time.RFC3339),
+			"workspace":          integration.WorkspaceName,
+			"url":                page.URL,
+		},
+		Chunks: markdownChunks("Notion page", title, text, chunking.DefaultOptions),
+	}
+	for i := range doc.Chunks {
+		if doc.Chunks[i].Metadata == nil {
+			doc.Chunks[i].Metadata = map[string]interface{}{}
+		}
+		doc.Chunks[i].Metadata["notion_page_id"] = page.ID
+	}
+	return doc
+}
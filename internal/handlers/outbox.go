@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/langdetect"
+	"github.com/siddhantgupta/forgetai-backend/internal/models"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// outboxLease is how long a claimed outbox entry is reserved for one publisher
+	outboxLease = 2 * time.Minute
+	// maxOutboxAttempts is how many times a vector write is tried before it is dead-lettered
+	maxOutboxAttempts = 8
+	// outboxRetryBase is the delay before the first retry; later retries back off exponentially
+	outboxRetryBase = 10 * time.Second
+	// maxOutboxRetryDelay caps the delay between retries
+	maxOutboxRetryDelay = time.Hour
+)
+
+// enqueueVector creates a pending user data record with an outbox entry for its vector write.
+// The vector is written in the background by the outbox publisher, so MongoDB stays the source
+// of truth even if embedding or Pinecone fails. embedText defaults to the data's text.
+func (h *Handlers) enqueueVector(ctx context.Context, userData *database.UserData, data models.Data, embedText string) (*database.UserData, error) {
+	if embedText == "" {
+		embedText = data.Text
+	}
+	timestamp := data.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	// Visible items carry their visibility with the vector so retrieval can filter on it,
+	// and content in a detected language carries the language
+	metadata := data.Metadata
+	language := langdetect.Detect(embedText)
+	if userData.Visibility != "" || language != "" {
+		metadata = make(map[string]interface{}, len(data.Metadata)+2)
+		for key, value := range data.Metadata {
+			metadata[key] = value
+		}
+		if userData.Visibility != "" {
+			metadata["visibility"] = userData.Visibility
+		}
+		if language != "" {
+			metadata[contentLanguageKey] = language
+		}
+	}
+	if language != "" {
+		if userData.Metadata == nil {
+			userData.Metadata = make(map[string]interface{})
+		}
+		userData.Metadata[contentLanguageKey] = language
+	}
+
+	record, err := h.DB.CreateUserDataWithOutbox(ctx, userData, &database.OutboxEntry{
+		DataType:   data.Selected_type,
+		VectorText: data.Text,
+		EmbedText:  embedText,
+		Metadata:   metadata,
+		Timestamp:  timestamp,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	h.wakeOutbox()
+	return record, nil
+}
+
+// wakeOutbox signals the publisher to check for entries without waiting for its next poll
+func (h *Handlers) wakeOutbox() {
+	select {
+	case h.outboxWake <- struct{}{}:
+	default:
+	}
+}
+
+// RunOutboxPublisher writes pending vectors until ctx is cancelled, checking for new
+// entries whenever one is enqueued and at least every interval
+func (h *Handlers) RunOutboxPublisher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		h.drainOutbox(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-h.outboxWake:
+		}
+	}
+}
+
+// drainOutbox publishes outbox entries until none are due
+func (h *Handlers) drainOutbox(ctx context.Context) {
+	for ctx.Err() == nil {
+		entry, err := h.DB.ClaimOutboxEntry(ctx, outboxLease)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return
+		}
+		if err != nil {
+			fmt.Printf("Warning: Failed to claim outbox entry: %v\n", err)
+			return
+		}
+		h.processOutboxEntry(ctx, entry)
+	}
+}
+
+// processOutboxEntry publishes an entry, scheduling a retry or dead-lettering it on error
+func (h *Handlers) processOutboxEntry(ctx context.Context, entry *database.OutboxEntry) {
+	err := h.publishOutboxEntry(ctx, entry)
+	if err == nil {
+		return
+	}
+
+	attempts := entry.Attempts + 1
+	if attempts >= maxOutboxAttempts {
+		fmt.Printf("Warning: Giving up on vector %s after %d attempts: %v\n", entry.VectorID, attempts, err)
+		if err := h.DB.DeadLetterOutboxEntry(ctx, entry, attempts, err.Error()); err != nil {
+			fmt.Printf("Warning: Failed to dead-letter vector %s: %v\n", entry.VectorID, err)
+		}
+		return
+	}
+
+	delay := outboxRetryBase << (attempts - 1)
+	if delay > maxOutboxRetryDelay {
+		delay = maxOutboxRetryDelay
+	}
+	fmt.Printf("Warning: Failed to write vector %s (attempt %d), retrying in %s: %v\n", entry.VectorID, attempts, delay, err)
+	if err := h.DB.RetryOutboxEntry(ctx, entry.ID, err.Error(), time.Now().Add(delay)); err != nil {
+		fmt.Printf("Warning: Failed to schedule retry for vector %s: %v\n", entry.VectorID, err)
+	}
+}
+
+// publishOutboxEntry embeds an entry's text, upserts its vector and marks its item ready
+func (h *Handlers) publishOutboxEntry(ctx context.Context, entry *database.OutboxEntry) error {
+	ctx = services.WithBillingUser(ctx, entry.UserID)
+	language, _ := entry.Metadata[contentLanguageKey].(string)
+	embedding, err := h.OpenAI.GetDocumentEmbedding(ctx, h.retrievalText(ctx, entry.EmbedText, language))
+	if err != nil {
+		return fmt.Errorf("failed to generate embedding: %w", err)
+	}
+
+	data := models.Data{
+		Selected_type: entry.DataType,
+		Text:          entry.VectorText,
+		UserId:        entry.UserID,
+		Metadata:      vectorMetadata(entry.Metadata),
+		Timestamp:     entry.Timestamp,
+	}
+	if err := h.Pinecone.UpsertVector(ctx, entry.VectorID, embedding, data); err != nil {
+		return fmt.Errorf("failed to store vector in Pinecone: %w", err)
+	}
+
+	exists, err := h.DB.CompleteOutboxEntry(ctx, entry)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		// The item was deleted while its vector was being written
+		if err := h.Pinecone.DeleteVector(ctx, entry.VectorID); err != nil {
+			fmt.Printf("Warning: Failed to delete vector %s of deleted item: %v\n", entry.VectorID, err)
+		}
+	}
+	return nil
+}
+
+// vectorMetadata converts metadata decoded from BSON back to the plain types Pinecone accepts
+func vectorMetadata(metadata map[string]interface{}) map[string]interface{} {
+	converted := make(map[string]interface{}, len(metadata))
+	for key, value := range metadata {
+		converted[key] = plainValue(value)
+	}
+	return converted
+}
+
+// plainValue replaces BSON arrays and documents in a decoded value with slices and maps
+func plainValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case primitive.A:
+		values := make([]interface{}, len(v))
+		for i, item := range v {
+			values[i] = plainValue(item)
+		}
+		return values
+	case primitive.D:
+		m := make(map[string]interface{}, len(v))
+		for _, elem := range v {
+			m[elem.Key] = plainValue(elem.Value)
+		}
+		return m
+	case map[string]interface{}:
+		return vectorMetadata(v)
+	default:
+		return value
+	}
+}
+
+// ListDeadLetters handles admin requests for vector writes that permanently failed,
+// optionally for one user
+func (h *Handlers) ListDeadLetters(c *gin.Context) {
+	if !h.requireAdmin(c) {
+		return
+	}
+
+	limit := defaultActivityLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		n, err := strconv.Atoi(limitStr)
+		if err != nil || n <= 0 {
+			i18n.RespondError(c, http.StatusBadRequest, nil, "limit must be a positive integer")
+			return
+		}
+		if n > maxActivityLimit {
+			n = maxActivityLimit
+		}
+		limit = n
+	}
+
+	deadLetters, err := h.DB.GetDeadLetters(c.Request.Context(), c.Query("userId"), int64(limit))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch dead letters")
+		return
+	}
+	if deadLetters == nil {
+		deadLetters = []*database.DeadLetter{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dead_letters": deadLetters,
+		"count":        len(deadLetters),
+	})
+}
+
+// RetryDeadLetter handles admin requests to retry a dead-lettered vector write
+func (h *Handlers) RetryDeadLetter(c *gin.Context) {
+	if !h.requireAdmin(c) {
+		return
+	}
+
+	entry, err := h.DB.RetryDeadLetter(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		i18n.RespondError(c, http.StatusNotFound, nil, "Dead letter not found")
+		return
+	}
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to retry dead letter")
+		return
+	}
+
+	h.wakeOutbox()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      i18n.T(c, "Vector write queued for retry"),
+		"user_data_id": entry.UserDataID.Hex(),
+		"vector_id":    entry.VectorID,
+	})
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// minOverlapChars is the shortest repeated text trimmed where two chunks overlap
+	minOverlapChars = 20
+	// neighborChunks is how many chunks either side of a retrieved document chunk are added
+	// when neighbor expansion is requested
+	neighborChunks = 1
+)
+
+// neighborChunkTypes are the chunk types cut from one continuous text, whose neighbors
+// continue a retrieved chunk's passage
+var neighborChunkTypes = map[string]bool{
+	"pdf-chunk":  true,
+	"docx-chunk": true,
+	"epub-chunk": true,
+}
+
+// contextChunk is a stored chunk being assembled into context, with the candidate that
+// retrieved it; neighbors fetched for continuity have none
+type contextChunk struct {
+	candidate *retrievalCandidate
+	record    *database.UserData
+}
+
+// assembleContext merges included candidates that are adjacent chunks of the same document
+// into single passages, so the prompt gets contiguous text instead of near-duplicate
+// fragments. Each passage takes the place and best score of its highest ranked chunk.
+// With neighbors > 0, that many chunks either side of each retrieved document chunk are
+// fetched and merged in too, since a lone chunk often lacks the sentence that answers the
+// question.
+// If the chunks can't be looked up, the candidates are returned unchanged.
+func (h *Handlers) assembleContext(ctx context.Context, userID string, included []*retrievalCandidate, neighbors int) []*retrievalCandidate {
+	if len(included) == 0 || (len(included) < 2 && neighbors == 0) {
+		return included
+	}
+
+	vectorIDs := make([]string, 0, len(included))
+	for _, candidate := range included {
+		vectorIDs = append(vectorIDs, candidate.ID)
+	}
+	records, err := h.DB.GetUserDataByVectorIDs(ctx, userID, vectorIDs)
+	if err != nil {
+		fmt.Printf("Warning: Failed to look up context chunks: %v\n", err)
+		return included
+	}
+	byVectorID := make(map[string]*database.UserData, len(records))
+	for _, record := range records {
+		byVectorID[record.VectorID] = record
+	}
+
+	// Group the chunks of each document, keeping their candidates
+	groups := make(map[string][]contextChunk)
+	for _, candidate := range included {
+		if record, ok := byVectorID[candidate.ID]; ok && record.ParentID != nil {
+			key := record.ParentID.Hex()
+			groups[key] = append(groups[key], contextChunk{candidate, record})
+		}
+	}
+	if neighbors > 0 {
+		for key, members := range groups {
+			for _, record := range h.fetchNeighbors(ctx, members[0].record.ParentID, members, neighbors) {
+				groups[key] = append(groups[key], contextChunk{nil, record})
+			}
+		}
+	}
+
+	// Replace each run of consecutive chunks with one passage at its first ranked member
+	replacement := make(map[*retrievalCandidate]*retrievalCandidate)
+	merged := make(map[*retrievalCandidate]bool)
+	for _, members := range groups {
+		if len(members) < 2 {
+			continue
+		}
+		sort.Slice(members, func(i, j int) bool {
+			return members[i].record.ChunkIndex < members[j].record.ChunkIndex
+		})
+		for start := 0; start < len(members); {
+			end := start + 1
+			for end < len(members) && members[end].record.ChunkIndex == members[end-1].record.ChunkIndex+1 {
+				end++
+			}
+			if end-start > 1 {
+				var best *contextChunk
+				var texts []string
+				for i, m := range members[start:end] {
+					texts = append(texts, m.record.DataValue)
+					if m.candidate == nil {
+						continue
+					}
+					merged[m.candidate] = true
+					if best == nil || m.candidate.Score > best.candidate.Score {
+						best = &members[start+i]
+					}
+				}
+				if best != nil {
+					replacement[best.candidate] = mergePassage(best.candidate, passagePrefix(best.candidate, best.record), texts)
+				}
+			}
+			start = end
+		}
+	}
+	if len(replacement) == 0 {
+		return included
+	}
+
+	assembled := make([]*retrievalCandidate, 0, len(included))
+	for _, candidate := range included {
+		if passage, ok := replacement[candidate]; ok {
+			assembled = append(assembled, passage)
+		} else if !merged[candidate] {
+			assembled = append(assembled, candidate)
+		}
+	}
+	return assembled
+}
+
+// fetchNeighbors fetches the chunks within distance of the retrieved chunks of one
+// document that weren't retrieved themselves
+func (h *Handlers) fetchNeighbors(ctx context.Context, parentID *primitive.ObjectID, members []contextChunk, distance int) []*database.UserData {
+	retrieved := make(map[int]bool, len(members))
+	for _, m := range members {
+		if !neighborChunkTypes[m.record.DataType] {
+			return nil
+		}
+		retrieved[m.record.ChunkIndex] = true
+	}
+
+	var indexes []int
+	wanted := make(map[int]bool)
+	for index := range retrieved {
+		for i := index - distance; i <= index+distance; i++ {
+			if i >= 0 && !retrieved[i] && !wanted[i] {
+				wanted[i] = true
+				indexes = append(indexes, i)
+			}
+		}
+	}
+	if len(indexes) == 0 {
+		return nil
+	}
+
+	records, err := h.DB.GetChunksByIndex(ctx, *parentID, indexes)
+	if err != nil {
+		fmt.Printf("Warning: Failed to fetch neighboring chunks of %s: %v\n", parentID.Hex(), err)
+		return nil
+	}
+	return records
+}
+
+// passagePrefix returns the label a candidate's text carries before its chunk, such as
+// the document name of PDF chunks, so merged passages keep it
+func passagePrefix(candidate *retrievalCandidate, record *database.UserData) string {
+	if record.DataValue == "" || !strings.HasSuffix(candidate.Text, record.DataValue) {
+		return ""
+	}
+	return strings.TrimSuffix(candidate.Text, record.DataValue)
+}
+
+// mergePassage returns a copy of the best ranked chunk's candidate holding the raw text
+// of a run of chunks after prefix, trimming any text the chunks repeat where they overlap
+func mergePassage(best *retrievalCandidate, prefix string, texts []string) *retrievalCandidate {
+	passage := *best
+	passage.Text = prefix + texts[0]
+	for _, text := range texts[1:] {
+		if text = trimOverlap(passage.Text, text); text != "" {
+			passage.Text += "\n" + text
+		}
+	}
+	return &passage
+}
+
+// trimOverlap removes the longest prefix of next that repeats the end of previous
+func trimOverlap(previous, next string) string {
+	longest := len(next)
+	if len(previous) < longest {
+		longest = len(previous)
+	}
+	for size := longest; size >= minOverlapChars; size-- {
+		if strings.HasSuffix(previous, next[:size]) {
+			return strings.TrimSpace(next[size:])
+		}
+	}
+	return next
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+)
+
+const (
+	// peopleExtractionTimeout bounds entity extraction when saving an item
+	peopleExtractionTimeout = 10 * time.Second
+	// maxKnownPeople is the number of a user's most mentioned people matched against queries
+	maxKnownPeople = 500
+	// minFirstNameLength is the shortest first name matched on its own in a query
+	minFirstNameLength = 3
+)
+
+// ListPeople handles requests for the people mentioned in the user's items
+func (h *Handlers) ListPeople(c *gin.Context) {
+	userId, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusInternalServerError, nil, "User ID not found in request context")
+		return
+	}
+
+	people, err := h.DB.ListPeople(c.Request.Context(), userId.(string), maxKnownPeople)
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to list people")
+		return
+	}
+	if people == nil {
+		people = []*database.PersonSummary{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"people": people,
+		"count":  len(people),
+	})
+}
+
+// GetPersonItems handles requests for the items that mention a person
+func (h *Handlers) GetPersonItems(c *gin.Context) {
+	userId, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusInternalServerError, nil, "User ID not found in request context")
+		return
+	}
+
+	key := services.PersonKey(c.Param("name"))
+	if key == "" {
+		i18n.RespondError(c, http.StatusBadRequest, nil, "Missing required parameter: name")
+		return
+	}
+
+	items, err := h.DB.GetUserDataByPerson(c.Request.Context(), userId.(string), key)
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to retrieve items")
+		return
+	}
+	if items == nil {
+		items = []*database.UserData{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"person": key,
+		"items":  items,
+		"count":  len(items),
+	})
+}
+
+// extractPeople finds the people mentioned in text, merged with any already known (such as
+// meeting speakers or authors). Extraction is best-effort: on failure only the known people are returned.
+func (h *Handlers) extractPeople(ctx context.Context, text string, known []string) []string {
+	ctx, cancel := context.WithTimeout(ctx, peopleExtractionTimeout)
+	defer cancel()
+
+	extracted, err := h.People.ExtractPeople(ctx, text)
+	if err != nil {
+		fmt.Printf("Warning: Failed to extract people: %v\n", err)
+	}
+	return services.CleanPeople(append(known, extracted...))
+}
+
+// setPeopleMetadata records people on an item's metadata, with the normalized keys used for lookups
+func setPeopleMetadata(metadata map[string]interface{}, people []string) {
+	if len(people) == 0 {
+		return
+	}
+	metadata["people"] = people
+	metadata["people_keys"] = personKeys(people)
+}
+
+// personKeys returns the lookup keys for people as a list that can be stored with a vector
+func personKeys(people []string) []interface{} {
+	keys := make([]interface{}, 0, len(people))
+	for _, person := range people {
+		keys = append(keys, services.PersonKey(person))
+	}
+	return keys
+}
+
+// tagPeople extracts the people mentioned in a document and records them on the document
+// and each of its chunks
+func (h *Handlers) tagPeople(ctx context.Context, doc *parentDocument, known []string) []string {
+	var sb strings.Builder
+	for _, chunk := range doc.Chunks {
+		sb.WriteString(chunk.Text)
+		sb.WriteString("\n\n")
+	}
+
+	people := h.extractPeople(ctx, sb.String(), known)
+	if len(people) == 0 {
+		return nil
+	}
+
+	if doc.Metadata == nil {
+		doc.Metadata = make(map[string]interface{})
+	}
+	setPeopleMetadata(doc.Metadata, people)
+	keys := personKeys(people)
+	for i := range doc.Chunks {
+		if doc.Chunks[i].Metadata == nil {
+			doc.Chunks[i].Metadata = make(map[string]interface{})
+		}
+		doc.Chunks[i].Metadata["people_keys"] = keys
+	}
+	return people
+}
+
+// mentionedPeople returns the keys of the user's known people mentioned in a query. A person
+// matches on their full name, or on a first name no other known person shares.
+func (h *Handlers) mentionedPeople(ctx context.Context, userID, query string) []string {
+	people, err := h.DB.ListPeople(ctx, userID, maxKnownPeople)
+	if err != nil {
+		fmt.Printf("Warning: Failed to list people for query: %v\n", err)
+		return nil
+	}
+
+	// Pad with spaces so names only match as whole words
+	text := " " + strings.Join(strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	}), " ") + " "
+
+	firstNames := make(map[string][]string)
+	for _, person := range people {
+		if first, _, found := strings.Cut(person.Key, " "); found && len([]rune(first)) >= minFirstNameLength {
+			firstNames[first] = append(firstNames[first], person.Key)
+		}
+	}
+
+	var mentioned []string
+	matched := make(map[string]bool)
+	for _, person := range people {
+		if strings.Contains(text, " "+person.Key+" ") {
+			mentioned = append(mentioned, person.Key)
+			matched[person.Key] = true
+		}
+	}
+	for first, keys := range firstNames {
+		if len(keys) == 1 && !matched[keys[0]] && strings.Contains(text, " "+first+" ") {
+			mentioned = append(mentioned, keys[0])
+		}
+	}
+	return mentioned
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// maxPinnedItems is the most items a session can have pinned
+	maxPinnedItems = 10
+	// maxPinnedItemChars is how much of each pinned item's text goes into the prompt
+	maxPinnedItemChars = 8000
+)
+
+// PinSessionItems handles replacing the items pinned to a session. Pinned documents and
+// notes are included in every prompt of the session, whether or not retrieval finds them;
+// an empty list unpins everything.
+func (h *Handlers) PinSessionItems(c *gin.Context) {
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req struct {
+		IDs []string `json:"ids"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+		return
+	}
+
+	sessionId := c.Param("sessionId")
+	if !strings.HasPrefix(sessionId, userID.(string)+"-") {
+		i18n.RespondError(c, http.StatusForbidden, nil, "Not authorized to access this session")
+		return
+	}
+	if _, exists := h.Session.GetSession(sessionId); !exists {
+		i18n.RespondError(c, http.StatusNotFound, nil, "Session not found")
+		return
+	}
+
+	ids := make([]string, 0, len(req.IDs))
+	seen := make(map[string]bool)
+	for _, id := range req.IDs {
+		if id = strings.TrimSpace(id); id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > maxPinnedItems {
+		i18n.RespondError(c, http.StatusBadRequest, fmt.Errorf("at most %d items can be pinned", maxPinnedItems), "Too many pinned items")
+		return
+	}
+
+	// Only whole items can be pinned; chunks come with their document
+	ctx := c.Request.Context()
+	for _, id := range ids {
+		userData, err := h.DB.GetUserDataByID(ctx, id)
+		if err != nil {
+			if err == mongo.ErrNoDocuments || !primitive.IsValidObjectID(id) {
+				i18n.RespondError(c, http.StatusNotFound, nil, "Item not found")
+			} else {
+				i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch item")
+			}
+			return
+		}
+		if userData.UserID != userID.(string) || userData.ParentID != nil {
+			i18n.RespondError(c, http.StatusNotFound, nil, "Item not found")
+			return
+		}
+	}
+
+	h.Session.SetPinnedItems(sessionId, ids)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   i18n.T(c, "Pinned items updated"),
+		"sessionId": sessionId,
+		"pinnedIds": ids,
+	})
+}
+
+// buildPinnedContext formats a session's pinned items for the system prompt. Items deleted
+// since they were pinned are skipped.
+func (h *Handlers) buildPinnedContext(ctx context.Context, userID string, ids []string) string {
+	var sb strings.Builder
+	pinned := 0
+	for _, id := range ids {
+		userData, err := h.DB.GetUserDataByID(ctx, id)
+		if err != nil || userData.UserID != userID {
+			if err != nil && err != mongo.ErrNoDocuments {
+				fmt.Printf("Warning: Failed to load pinned item %s: %v\n", id, err)
+			}
+			continue
+		}
+
+		texts, err := h.documentTexts(ctx, userData)
+		if err != nil {
+			fmt.Printf("Warning: Failed to load text of pinned item %s: %v\n", id, err)
+			continue
+		}
+		text := utils.Truncate(strings.Join(texts, "\n\n"), maxPinnedItemChars)
+		pinned++
+		sb.WriteString(fmt.Sprintf("Pinned %d: %s%s\n\n", pinned, contentTypeLabel(userData.DataType), text))
+	}
+	return sb.String()
+}
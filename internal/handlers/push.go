@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/models"
+)
+
+// GetVAPIDPublicKey returns the VAPID public key the frontend needs to subscribe to Web Push
+func (h *Handlers) GetVAPIDPublicKey(c *gin.Context) {
+	if h.WebPush == nil {
+		i18n.RespondError(c, http.StatusServiceUnavailable, nil, "Web Push is not configured")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"public_key": h.WebPush.PublicKey()})
+}
+
+// SubscribePush handles registering a Web Push subscription for the user
+func (h *Handlers) SubscribePush(c *gin.Context) {
+	if h.WebPush == nil {
+		i18n.RespondError(c, http.StatusServiceUnavailable, nil, "Web Push is not configured")
+		return
+	}
+
+	var req models.PushSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+		return
+	}
+
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	err := h.DB.UpsertPushSubscription(c.Request.Context(), &database.PushSubscription{
+		UserID:    userID.(string),
+		Endpoint:  req.Endpoint,
+		P256dh:    req.Keys.P256dh,
+		Auth:      req.Keys.Auth,
+		UserAgent: c.Request.UserAgent(),
+	})
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to save push subscription")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  i18n.T(c, "Push subscription registered"),
+		"endpoint": req.Endpoint,
+	})
+}
+
+// UnsubscribePush handles removing one of the user's Web Push subscriptions
+func (h *Handlers) UnsubscribePush(c *gin.Context) {
+	var req struct {
+		Endpoint string `json:"endpoint" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+		return
+	}
+
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	removed, err := h.DB.DeletePushSubscription(c.Request.Context(), userID.(string), req.Endpoint)
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to remove push subscription")
+		return
+	}
+	if !removed {
+		i18n.RespondError(c, http.StatusNotFound, nil, "Push subscription not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  i18n.T(c, "Push subscription removed"),
+		"endpoint": req.Endpoint,
+	})
+}
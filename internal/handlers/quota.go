@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+)
+
+// requestPlan returns the plan from the user's token, or "" for the default plan
+func requestPlan(c *gin.Context) string {
+	plan, _ := c.Get("plan")
+	name, _ := plan.(string)
+	return name
+}
+
+// checkQuota reports a *services.QuotaExceededError if saving the given texts as one item
+// would exceed the user's plan limits
+func (h *Handlers) checkQuota(ctx context.Context, userID, plan string, texts ...string) error {
+	return h.Quota.Check(ctx, userID, plan, itemUsage(texts...))
+}
+
+// itemUsage is the storage used by one item with a vector for each of the given texts
+func itemUsage(texts ...string) database.StorageUsage {
+	usage := database.StorageUsage{Items: 1, Vectors: int64(len(texts))}
+	for _, text := range texts {
+		usage.Characters += int64(utf8.RuneCountInString(text))
+	}
+	return usage
+}
+
+// documentTexts returns the stored text of each of a document's chunks
+func documentTexts(doc *parentDocument) []string {
+	texts := make([]string, 0, len(doc.Chunks))
+	for _, chunk := range doc.Chunks {
+		texts = append(texts, chunk.Text)
+	}
+	return texts
+}
+
+// quotaState returns the user's resolved plan with its storage limits and current usage
+func (h *Handlers) quotaState(ctx context.Context, userID, plan string) (gin.H, error) {
+	plan, limits, used, err := h.Quota.Usage(ctx, userID, plan)
+	if err != nil {
+		return nil, err
+	}
+	return gin.H{
+		"plan":   plan,
+		"limits": limits,
+		"used":   used,
+	}, nil
+}
+
+// respondSaveError responds with 403 and the exceeded limit when err is a quota error,
+// with 413 and a plan allowing the document when err is a document size limit, and
+// otherwise with 500 and the given message
+func respondSaveError(c *gin.Context, err error, msg string, args ...interface{}) {
+	var quotaErr *services.QuotaExceededError
+	if !errors.As(err, &quotaErr) {
+		i18n.RespondError(c, http.StatusInternalServerError, err, msg, args...)
+		return
+	}
+
+	switch {
+	case quotaErr.Limit == services.QuotaPages && quotaErr.UpgradePlan != "":
+		i18n.RespondError(c, http.StatusRequestEntityTooLarge, nil, "Documents are limited to %d pages on the %s plan; the %s plan allows more", quotaErr.Max, quotaErr.Plan, quotaErr.UpgradePlan)
+		return
+	case quotaErr.Limit == services.QuotaPages:
+		i18n.RespondError(c, http.StatusRequestEntityTooLarge, nil, "Documents are limited to %d pages on the %s plan", quotaErr.Max, quotaErr.Plan)
+		return
+	case quotaErr.Limit == services.QuotaChunks && quotaErr.UpgradePlan != "":
+		i18n.RespondError(c, http.StatusRequestEntityTooLarge, nil, "Documents are limited to %d chunks on the %s plan; the %s plan allows more", quotaErr.Max, quotaErr.Plan, quotaErr.UpgradePlan)
+		return
+	case quotaErr.Limit == services.QuotaChunks:
+		i18n.RespondError(c, http.StatusRequestEntityTooLarge, nil, "Documents are limited to %d chunks on the %s plan", quotaErr.Max, quotaErr.Plan)
+		return
+	}
+
+	switch quotaErr.Limit {
+	case services.QuotaItems:
+		i18n.RespondError(c, http.StatusForbidden, nil, "Item limit of %d reached for the %s plan", quotaErr.Max, quotaErr.Plan)
+	case services.QuotaCharacters:
+		i18n.RespondError(c, http.StatusForbidden, nil, "Character limit of %d reached for the %s plan", quotaErr.Max, quotaErr.Plan)
+	default:
+		i18n.RespondError(c, http.StatusForbidden, nil, "Vector limit of %d reached for the %s plan", quotaErr.Max, quotaErr.Plan)
+	}
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+	"github.com/siddhantgupta/forgetai-backend/internal/textnorm"
+)
+
+// maxRedditComments is the maximum number of top comments saved with a post
+const maxRedditComments = 10
+
+// SaveReddit handles Reddit post saving requests. The post's title, text and top
+// comments are stored as one parent item with subreddit and author metadata.
+func (h *Handlers) SaveReddit(c *gin.Context) {
+	var req struct {
+		PostURL string `json:"postUrl" binding:"required"`
+		chunkingFields
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+		return
+	}
+	options, err := req.options(h.Chunking)
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid chunking parameters")
+		return
+	}
+	options = sourceChunking(options)
+
+	userId, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusInternalServerError, nil, "User ID not found in request context")
+		return
+	}
+
+	postID, err := services.ParseRedditURL(req.PostURL)
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid Reddit URL")
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	post, err := h.Reddit.GetPost(ctx, postID, maxRedditComments)
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadGateway, err, "Failed to fetch Reddit post")
+		return
+	}
+
+	doc := &parentDocument{
+		UserID: userId.(string),
+		Plan:   requestPlan(c),
+		Type:   "reddit",
+		Title:  post.Title,
+		Metadata: map[string]interface{}{
+			"url":       post.Permalink,
+			"link":      textnorm.StripTracking(post.LinkURL),
+			"subreddit": post.Subreddit,
+			"author":    post.Author,
+			"score":     post.Score,
+		},
+	}
+	doc.recordChunking(options)
+	// Chunks are stored as written but embedded without URLs; emoji-only chunks are dropped
+	addChunks := func(section, author, text string) {
+		splitProse(text, options, func(chunk, withContext string) {
+			if textnorm.EmbeddingText(chunk) == "" {
+				return
+			}
+			doc.Chunks = append(doc.Chunks, documentChunk{
+				Text:       chunk,
+				VectorText: fmt.Sprintf("Reddit post in r/%s: %s (%s by %s): %s", post.Subreddit, post.Title, section, author, chunk),
+				EmbedText:  fmt.Sprintf("Reddit post in r/%s: %s (%s by %s): %s", post.Subreddit, post.Title, section, author, textnorm.EmbeddingText(withContext)),
+				Metadata: map[string]interface{}{
+					"section":   section,
+					"subreddit": post.Subreddit,
+					"author":    author,
+					"url":       post.Permalink,
+				},
+			})
+		})
+	}
+
+	body := post.Title
+	if post.Selftext != "" {
+		body += "\n\n" + post.Selftext
+	}
+	addChunks("post", post.Author, body)
+	for _, comment := range post.Comments {
+		addChunks("comment", comment.Author, comment.Body)
+	}
+
+	record, vectorIds, err := h.ingestDocument(ctx, doc)
+	if err != nil {
+		respondSaveError(c, err, "Failed to save Reddit post")
+		return
+	}
+
+	h.recordAudit(ctx, &database.AuditEvent{
+		UserID:   userId.(string),
+		Action:   database.AuditActionSave,
+		ItemID:   record.ID.Hex(),
+		ItemType: "reddit",
+		Summary:  post.Title,
+		Details:  map[string]interface{}{"post_url": post.Permalink, "subreddit": post.Subreddit, "comment_count": len(post.Comments)},
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       i18n.T(c, "Reddit post saved successfully"),
+		"user_id":       userId.(string),
+		"type":          "reddit",
+		"title":         post.Title,
+		"subreddit":     post.Subreddit,
+		"author":        post.Author,
+		"url":           post.Permalink,
+		"comment_count": len(post.Comments),
+		"chunk_count":   len(doc.Chunks),
+		"vector_ids":    vectorIds,
+		"timestamp":     time.Now().Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,485 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pinecone-io/go-pinecone/v3/pinecone"
+	"github.com/siddhantgupta/forgetai-backend/internal/models"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+	"github.com/siddhantgupta/forgetai-backend/internal/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// maxContextMatches is the maximum number of matches included in the prompt context
+	maxContextMatches = 10
+	// personBoost is added to the score of matches about a person the query mentions
+	personBoost = 0.1
+)
+
+// Exclusion reasons reported for retrieval candidates that don't make it into the context
+const (
+	excludeDuplicate  = "duplicate"
+	excludeLowScore   = "below_score_threshold"
+	excludeRankCutoff = "rank_cutoff"
+	excludeNoRecord   = "missing_from_database"
+	excludeMalformed  = "malformed_metadata"
+)
+
+// retrievalOptions controls how raw matches are turned into context
+type retrievalOptions struct {
+	MinScore    float32
+	MaxMatches  int
+	BoostPeople []string // person keys whose matches are boosted
+}
+
+// retrievalCandidate is a single scored match and the outcome of the selection steps
+type retrievalCandidate struct {
+	ID              string
+	Score           float32
+	Text            string
+	Type            string
+	Language        string
+	Section         string // part of the item the match is from, e.g. a markdown heading path
+	Boosted         bool   // score includes personBoost
+	ExclusionReason string
+}
+
+// retrievalResult holds all candidates in score order and the filters used to fetch them
+type retrievalResult struct {
+	Filters    map[string]interface{}
+	Candidates []*retrievalCandidate
+}
+
+// Included returns the candidates that passed every selection step
+func (r *retrievalResult) Included() []*retrievalCandidate {
+	var included []*retrievalCandidate
+	for _, candidate := range r.Candidates {
+		if candidate.ExclusionReason == "" {
+			included = append(included, candidate)
+		}
+	}
+	return included
+}
+
+// defaultRetrievalOptions returns the retrieval options used by QueryData
+func (h *Handlers) defaultRetrievalOptions() retrievalOptions {
+	return retrievalOptions{
+		MinScore:   h.MinScore,
+		MaxMatches: maxContextMatches,
+	}
+}
+
+// validateMinScore checks an optional per-request minimum score override
+func validateMinScore(minScore *float32) error {
+	if minScore != nil && (*minScore < 0 || *minScore > 1) {
+		return fmt.Errorf("min_score must be between 0 and 1")
+	}
+	return nil
+}
+
+// retrieve queries Pinecone for the user's vectors and selects the matches to use as context
+func (h *Handlers) retrieve(ctx context.Context, filter services.QueryFilter, embedding []float32, opts retrievalOptions) (*retrievalResult, error) {
+	res, err := h.Pinecone.QueryVectors(ctx, filter, embedding)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := decodeMatches(res.Matches)
+	malformed := 0
+	for _, match := range matches {
+		if match.Err != nil {
+			fmt.Printf("Warning: Skipping match %s with malformed metadata: %v\n", match.ID, match.Err)
+			malformed++
+		}
+	}
+	if malformed > 0 && h.Metrics != nil {
+		h.Metrics.RecordEvent(services.EventMalformedVector, int64(malformed))
+	}
+
+	result := &retrievalResult{
+		Filters: filter.AsMap(),
+	}
+	result.Candidates = selectCandidates(matches, opts, h.matchTexts(ctx, filter.UserID, matches))
+	return result, nil
+}
+
+// matchMetadata is the metadata stored with a vector, as used for retrieval
+type matchMetadata struct {
+	Text        string
+	Type        string
+	Language    string
+	Section     string
+	PeopleKeys  []string
+	TitleVector bool
+}
+
+// decodedMatch is a query match with its metadata decoded, or the reason it couldn't be
+type decodedMatch struct {
+	ID       string
+	Score    float32
+	Metadata *matchMetadata
+	Err      error
+}
+
+// decodeMatches decodes the metadata of each match. Vectors written by older versions or
+// by hand may lack fields or hold the wrong types, so each match is decoded on its own
+// and one that can't be is kept with its error rather than failing the query.
+func decodeMatches(matches []*pinecone.ScoredVector) []*decodedMatch {
+	decoded := make([]*decodedMatch, 0, len(matches))
+	for _, match := range matches {
+		if match == nil || match.Vector == nil {
+			continue
+		}
+		metadata, err := decodeMatchMetadata(match.Vector.Metadata)
+		decoded = append(decoded, &decodedMatch{
+			ID:       match.Vector.Id,
+			Score:    match.Score,
+			Metadata: metadata,
+			Err:      err,
+		})
+	}
+	return decoded
+}
+
+// decodeMatchMetadata checks the types of a vector's metadata fields. Text is required;
+// the other fields are optional.
+func decodeMatchMetadata(raw *pinecone.Metadata) (*matchMetadata, error) {
+	if raw == nil {
+		return nil, fmt.Errorf("no metadata")
+	}
+	fields := raw.AsMap()
+
+	stringField := func(name string, required bool) (string, error) {
+		value, ok := fields[name]
+		if !ok || value == nil {
+			if required {
+				return "", fmt.Errorf("missing %q", name)
+			}
+			return "", nil
+		}
+		str, ok := value.(string)
+		if !ok {
+			return "", fmt.Errorf("%q is %T, not a string", name, value)
+		}
+		return str, nil
+	}
+
+	metadata := &matchMetadata{}
+	var err error
+	if metadata.Text, err = stringField("text", true); err != nil {
+		return nil, err
+	}
+	if metadata.Type, err = stringField("type", false); err != nil {
+		return nil, err
+	}
+	if metadata.Language, err = stringField("language", false); err != nil {
+		return nil, err
+	}
+	if metadata.Section, err = stringField("section", false); err != nil {
+		return nil, err
+	}
+
+	if value, ok := fields["people_keys"]; ok && value != nil {
+		people, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%q is %T, not a list", "people_keys", value)
+		}
+		for _, person := range people {
+			key, ok := person.(string)
+			if !ok {
+				return nil, fmt.Errorf("%q holds %T, not a string", "people_keys", person)
+			}
+			metadata.PeopleKeys = append(metadata.PeopleKeys, key)
+		}
+	}
+
+	if value, ok := fields["title_vector"]; ok && value != nil {
+		if metadata.TitleVector, ok = value.(bool); !ok {
+			return nil, fmt.Errorf("%q is %T, not a bool", "title_vector", value)
+		}
+	}
+
+	return metadata, nil
+}
+
+// matchTexts looks up the text of each match in MongoDB, which holds the authoritative
+// copy, keyed by vector ID. Vector metadata may only hold a preview, or be stale after an
+// edit. Chunks of hot documents, retrieved often, are read from their Redis cache first,
+// and the documents of chunks read from MongoDB are counted towards becoming hot. Title
+// vectors keep their own text, derived from the document, once the document is found.
+// Matches with no record are left out of the result; a nil result means the lookup failed
+// and the vectors' own text should be used.
+func (h *Handlers) matchTexts(ctx context.Context, userID string, matches []*decodedMatch) map[string]string {
+	texts := make(map[string]string, len(matches))
+	previews := make(map[string]string, len(matches))
+	titleVectors := make(map[primitive.ObjectID][]string)
+	var vectorIDs []string
+	var parentIDs []primitive.ObjectID
+	for _, match := range matches {
+		if match.Err != nil {
+			continue
+		}
+		previews[match.ID] = match.Metadata.Text
+
+		if match.Metadata.TitleVector {
+			if parentID, ok := titleVectorParent(match.ID); ok {
+				if _, seen := titleVectors[parentID]; !seen {
+					parentIDs = append(parentIDs, parentID)
+				}
+				titleVectors[parentID] = append(titleVectors[parentID], match.ID)
+				continue
+			}
+		}
+		vectorIDs = append(vectorIDs, match.ID)
+	}
+
+	cached := h.Redis.GetHotChunkTexts(ctx, userID, vectorIDs)
+	if len(cached) > 0 {
+		var misses []string
+		for _, vectorID := range vectorIDs {
+			if text, ok := cached[vectorID]; ok {
+				texts[vectorID] = restoreVectorText(previews[vectorID], text)
+			} else {
+				misses = append(misses, vectorID)
+			}
+		}
+		vectorIDs = misses
+		if h.Metrics != nil {
+			h.Metrics.RecordEvent(services.EventHotChunkHit, int64(len(cached)))
+		}
+	}
+
+	if len(vectorIDs) > 0 {
+		records, err := h.DB.GetUserDataByVectorIDs(ctx, userID, vectorIDs)
+		if err != nil {
+			fmt.Printf("Warning: Failed to look up the text of %d match(es): %v\n", len(vectorIDs), err)
+			return nil
+		}
+		seen := make(map[primitive.ObjectID]bool)
+		var retrieved []primitive.ObjectID
+		for _, record := range records {
+			texts[record.VectorID] = restoreVectorText(previews[record.VectorID], record.DataValue)
+			if record.ParentID != nil && !seen[*record.ParentID] {
+				seen[*record.ParentID] = true
+				retrieved = append(retrieved, *record.ParentID)
+			}
+		}
+		if len(retrieved) > 0 {
+			go h.countDocumentRetrievals(userID, retrieved)
+		}
+	}
+
+	if len(parentIDs) > 0 {
+		parents, err := h.DB.GetUserDataByIDs(ctx, userID, parentIDs)
+		if err != nil {
+			fmt.Printf("Warning: Failed to look up the documents of %d title match(es): %v\n", len(parentIDs), err)
+			return nil
+		}
+		for _, parent := range parents {
+			for _, vectorID := range titleVectors[parent.ID] {
+				texts[vectorID] = previews[vectorID]
+			}
+		}
+	}
+
+	if missing := len(previews) - len(texts); missing > 0 {
+		fmt.Printf("Warning: %d of %d match(es) for user %s have no MongoDB record\n", missing, len(previews), userID)
+	}
+	return texts
+}
+
+// restoreVectorText rebuilds a vector's full text from its preview and the stored text.
+// Vector text is often the stored text behind a label, such as a document's title, so
+// the label is kept from the preview when the stored text can be found in it.
+func restoreVectorText(preview, stored string) string {
+	probe := stored
+	if len(probe) > 64 {
+		probe = probe[:64]
+	}
+	if i := strings.Index(preview, probe); i >= 0 {
+		return preview[:i] + stored
+	}
+	return stored
+}
+
+// selectCandidates boosts matches about mentioned people, sorts matches by score and marks
+// malformed matches, duplicates, weak matches and overflow as excluded. Unless texts is nil,
+// each match's text is taken from texts, and matches missing from it are excluded as having
+// no record.
+func selectCandidates(matches []*decodedMatch, opts retrievalOptions, texts map[string]string) []*retrievalCandidate {
+	boostPeople := make(map[string]bool, len(opts.BoostPeople))
+	for _, key := range opts.BoostPeople {
+		boostPeople[key] = true
+	}
+
+	candidates := make([]*retrievalCandidate, 0, len(matches))
+	for _, match := range matches {
+		candidate := &retrievalCandidate{
+			ID:    match.ID,
+			Score: match.Score,
+		}
+		if match.Err != nil {
+			candidate.ExclusionReason = excludeMalformed
+			candidates = append(candidates, candidate)
+			continue
+		}
+		candidate.Text = match.Metadata.Text
+		candidate.Type = match.Metadata.Type
+		candidate.Language = match.Metadata.Language
+		candidate.Section = match.Metadata.Section
+		for _, key := range match.Metadata.PeopleKeys {
+			if boostPeople[key] {
+				candidate.Score += personBoost
+				candidate.Boosted = true
+				break
+			}
+		}
+		if texts != nil {
+			if text, ok := texts[candidate.ID]; ok {
+				candidate.Text = text
+			} else {
+				candidate.ExclusionReason = excludeNoRecord
+			}
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	// Sort candidates by score in descending order
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+
+	seen := make(map[string]bool)
+	included := 0
+
+	for _, candidate := range candidates {
+		if candidate.ExclusionReason != "" {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(candidate.Text))
+		switch {
+		case seen[key]:
+			candidate.ExclusionReason = excludeDuplicate
+		case candidate.Score < opts.MinScore:
+			candidate.ExclusionReason = excludeLowScore
+		case included >= opts.MaxMatches:
+			candidate.ExclusionReason = excludeRankCutoff
+		default:
+			included++
+		}
+		seen[key] = true
+	}
+
+	return candidates
+}
+
+// contentTypeLabel returns the prompt label for a stored data type
+func contentTypeLabel(dataType string) string {
+	switch dataType {
+	case "tweet":
+		return "[Tweet] "
+	case "pdf", "pdf-chunk":
+		return "[PDF Content] "
+	case "docx", "docx-chunk":
+		return "[Word Document] "
+	case "epub", "epub-chunk":
+		return "[EPUB Book] "
+	case "markdown", "markdown-chunk":
+		return "[Markdown] "
+	case "code", "code-chunk":
+		return "[Code] "
+	case "github", "github-chunk":
+		return "[GitHub Repository] "
+	case "githubstar", "githubstar-chunk":
+		return "[GitHub Star] "
+	case "gist", "gist-chunk":
+		return "[Gist] "
+	case "hackernews", "hackernews-chunk":
+		return "[Hacker News] "
+	case "reddit", "reddit-chunk":
+		return "[Reddit] "
+	case "zotero", "zotero-chunk":
+		return "[Zotero] "
+	case "notion", "notion-chunk":
+		return "[Notion] "
+	case "bookmark", "bookmark-chunk":
+		return "[Bookmark] "
+	case "meeting", "meeting-chunk":
+		return "[Meeting] "
+	case "youtube", "youtube-chunk":
+		return "[YouTube] "
+	case "slack", "slack-chunk":
+		return "[Slack] "
+	case "image":
+		return "[Image] "
+	default:
+		return "[Note] "
+	}
+}
+
+// buildContextText formats the included candidates for the system prompt
+func buildContextText(candidates []*retrievalCandidate) string {
+	var sb strings.Builder
+	for i, candidate := range candidates {
+		text := candidate.Text
+		if candidate.Language != "" {
+			// Fence code so the model sees (and reproduces) its formatting
+			text = fmt.Sprintf("\n```%s\n%s\n```\n", candidate.Language, strings.TrimRight(text, "\n"))
+		}
+		sb.WriteString(fmt.Sprintf("Result %d: %s%s (Relevance: %.2f)\n\n",
+			i+1, contentTypeLabel(candidate.Type), text, candidate.Score))
+	}
+	return sb.String()
+}
+
+// toQueryMatches converts candidates to the raw match format returned to clients, with the
+// spans of each snippet that match the query highlighted
+func toQueryMatches(candidates []*retrievalCandidate, query string) []models.QueryMatch {
+	terms := queryTerms(query)
+	matches := make([]models.QueryMatch, 0, len(candidates))
+	for _, candidate := range candidates {
+		snippet := utils.Truncate(candidate.Text, matchSnippetLength)
+		matches = append(matches, models.QueryMatch{
+			ID:         candidate.ID,
+			Score:      candidate.Score,
+			Type:       candidate.Type,
+			Section:    candidate.Section,
+			Snippet:    snippet,
+			Highlights: highlightSpans(snippet, terms),
+		})
+	}
+	return matches
+}
+
+// maxSourceTypes is the maximum number of source types accepted in one filter
+const maxSourceTypes = 10
+
+// normalizeSourceTypes trims and lowercases requested source types, rejecting empty or excessive lists
+func normalizeSourceTypes(types []string) ([]string, error) {
+	if len(types) > maxSourceTypes {
+		return nil, fmt.Errorf("at most %d source types may be given", maxSourceTypes)
+	}
+
+	normalized := make([]string, 0, len(types))
+	for _, t := range types {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" {
+			return nil, fmt.Errorf("source types must not be empty")
+		}
+		normalized = append(normalized, t)
+	}
+	return normalized, nil
+}
+
+// validateDateRange checks that an optional after/before range is not inverted
+func validateDateRange(after, before *time.Time) error {
+	if after != nil && before != nil && after.After(*before) {
+		return fmt.Errorf("after must not be later than before")
+	}
+	return nil
+}
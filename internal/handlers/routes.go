@@ -12,6 +12,8 @@ func SetupRoutes(
 	r *gin.Engine,
 	handlers *Handlers,
 	clerkAuth *auth.ClerkAuth,
+	impersonator *auth.Impersonator,
+	internalAuth *auth.InternalAuth,
 	redisService *services.RedisService,
 ) {
 
@@ -22,29 +24,161 @@ func SetupRoutes(
 	// Public endpoints
 	r.GET("/health", handlers.HealthCheck)
 
+	// Telegram bot updates, verified by the webhook's secret token
+	r.POST("/webhooks/telegram", handlers.TelegramWebhook)
+
+	// Slack app installs, events and interactions, verified by the app's signing secret
+	r.GET("/slack/oauth/callback", handlers.SlackOAuthCallback)
+	r.POST("/webhooks/slack/events", handlers.SlackEvents)
+	r.POST("/webhooks/slack/interactions", handlers.SlackInteractions)
+
 	// Protected API group - all endpoints require authentication
 	api := r.Group("/api")
-	api.Use(auth.AuthMiddleware(clerkAuth))
+	api.Use(auth.AuthMiddleware(clerkAuth, impersonator))
+	api.Use(handlers.AuditImpersonation)
+	api.Use(handlers.RecordClientAddress)
 
 	// Non-rate-limited endpoints (data retrieval and session management)
 	api.GET("/data", handlers.GetUserData)              // MongoDB data retrieval
 	api.DELETE("/data/:id", handlers.DeleteData)        // MongoDB data deletion
 	api.GET("/session/:sessionId", handlers.GetSession) // Get session
 	api.GET("/usage", handlers.GetUsage)                // Usage statistics
+	api.GET("/activity", handlers.GetActivity)          // Activity feed from the audit log
+	api.GET("/me", handlers.GetMe)                      // Token introspection for debugging auth
+
+	// Items always included in a session's prompts, regardless of retrieval
+	api.POST("/session/:sessionId/pin", handlers.PinSessionItems)
+
+	// Who can see an item: private, shared or public
+	api.PUT("/data/:id/visibility", handlers.SetItemVisibility)
+
+	// Original files items were saved from, when file storage is configured
+	api.GET("/data/:id/file", handlers.DownloadFile)
+
+	// Removing single chunks of a document from retrieval
+	api.DELETE("/data/:id/chunks/:chunkIndex", handlers.DeleteChunk)
+
+	// Search analytics built from the audit log
+	api.GET("/analytics/queries", handlers.GetQueryAnalytics)
+	api.GET("/reports/gaps", handlers.GetGapReport)
+
+	// People mentioned in saved items
+	api.GET("/people", handlers.ListPeople)
+	api.GET("/people/:name/items", handlers.GetPersonItems)
+
+	// Public profile settings
+	api.GET("/profile/public", handlers.GetPublicProfileSettings)
+	api.PUT("/profile/public", handlers.UpdatePublicProfile)
+	api.DELETE("/profile/public", handlers.DeletePublicProfile)
+
+	// Contradictions between saved items
+	api.GET("/contradictions", handlers.ListContradictions)
+	api.POST("/contradictions/:id/dismiss", handlers.DismissContradiction)
+
+	// Notification preferences
+	api.GET("/notifications/preferences", handlers.GetNotificationPreferences)
+	api.PUT("/notifications/preferences", handlers.UpdateNotificationPreferences)
+
+	// Scheduled digests of recent saves
+	api.GET("/digest/schedule", handlers.GetDigestSchedule)
+	api.PUT("/digest/schedule", handlers.UpdateDigestSchedule)
+
+	// Web Push subscriptions
+	api.GET("/push/vapid-public-key", handlers.GetVAPIDPublicKey)
+	api.POST("/push/subscriptions", handlers.SubscribePush)
+	api.DELETE("/push/subscriptions", handlers.UnsubscribePush)
+
+	// Zotero library integration
+	api.GET("/integrations/zotero", handlers.GetZoteroStatus)
+	api.PUT("/integrations/zotero", handlers.ConnectZotero)
+	api.DELETE("/integrations/zotero", handlers.DisconnectZotero)
+
+	// Notion workspace integration
+	api.GET("/integrations/notion", handlers.GetNotionStatus)
+	api.PUT("/integrations/notion", handlers.ConnectNotion)
+	api.DELETE("/integrations/notion", handlers.DisconnectNotion)
+
+	// GitHub account integration for starred repositories
+	api.GET("/integrations/github", handlers.GetGitHubStatus)
+	api.PUT("/integrations/github", handlers.ConnectGitHub)
+	api.DELETE("/integrations/github", handlers.DisconnectGitHub)
+
+	// Telegram bot linking; messages sent to the bot are saved for the linked user
+	api.GET("/integrations/telegram", handlers.GetTelegramStatus)
+	api.POST("/integrations/telegram/link", handlers.CreateTelegramLinkCode)
+	api.DELETE("/integrations/telegram", handlers.UnlinkTelegram)
+
+	// Slack app install; messages saved from Slack arrive through the Slack webhooks
+	api.GET("/integrations/slack", handlers.GetSlackStatus)
+	api.GET("/integrations/slack/install", handlers.GetSlackInstallURL)
+	api.DELETE("/integrations/slack", handlers.DisconnectSlack)
+
+	// Background jobs, such as bookmark imports
+	api.GET("/jobs", handlers.ListJobs)
+	api.GET("/jobs/:id", handlers.GetJob)
 
 	// Rate-limited endpoints (resource-intensive operations)
 	rateLimited := api.Group("/")
 	rateLimited.Use(auth.RateLimitMiddleware(redisService))
+	rateLimited.Use(handlers.EnforceAbuseThrottle)
+	rateLimited.Use(handlers.EnforceSpendingCap)
 
 	// Data creation routes (rate-limited)
 	rateLimited.POST("/save", handlers.SaveData)
 	rateLimited.POST("/query", handlers.QueryData)
+	rateLimited.POST("/query/explain", handlers.ExplainQuery)
+	rateLimited.POST("/query/voice", handlers.VoiceQuery)
 	rateLimited.POST("/reset-session", handlers.ResetSession)
 	rateLimited.POST("/save-tweet", handlers.SaveTweet)
-	rateLimited.POST("/save-pdf", handlers.SavePDF)
+	rateLimited.POST("/save-pdf", handlers.SaveDocument) // kept for clients uploading a "pdf" field
+	rateLimited.POST("/save-document", handlers.SaveDocument)
+	rateLimited.POST("/save-code", handlers.SaveCode)
+	rateLimited.POST("/save-github", handlers.SaveGitHub)
+	rateLimited.POST("/save-gist", handlers.SaveGist)
+	rateLimited.POST("/save-hackernews", handlers.SaveHackerNews)
+	rateLimited.POST("/save-reddit", handlers.SaveReddit)
+	rateLimited.POST("/save-meeting", handlers.SaveMeeting)
+	rateLimited.POST("/save-youtube", handlers.SaveYouTube)
+	rateLimited.POST("/save-image", handlers.SaveImage)
+	rateLimited.POST("/save-bookmarks", handlers.SaveBookmarks)
+	rateLimited.POST("/import", handlers.ImportItems)
+	rateLimited.GET("/export", handlers.ExportData)
+	rateLimited.GET("/digest", handlers.GetDigest)
+	rateLimited.POST("/data/delete-by-query", handlers.DeleteByQuery)
+	rateLimited.POST("/data/:id/summarize", handlers.SummarizeData)
+	rateLimited.POST("/data/:id/rechunk", handlers.RechunkData)
+	rateLimited.POST("/contradictions/scan", handlers.ScanContradictions)
+	rateLimited.POST("/integrations/zotero/sync", handlers.SyncZotero)
+	rateLimited.POST("/integrations/notion/sync", handlers.SyncNotion)
+	rateLimited.POST("/integrations/github/sync", handlers.SyncGitHub)
 
 	// Admin routes
 	r.POST("/admin/clear-cache", handlers.ClearCache)
+	r.GET("/admin/analytics", handlers.GetAdminAnalytics)
+	r.GET("/admin/dead-letters", handlers.ListDeadLetters)
+	r.POST("/admin/dead-letters/:id/retry", handlers.RetryDeadLetter)
+	r.GET("/admin/users", handlers.ListUsers)
+	r.GET("/admin/users/:id", handlers.GetUser)
+	r.POST("/admin/users/:id/impersonate", handlers.ImpersonateUser)
+	r.GET("/admin/backups", handlers.ListBackups)
+	r.POST("/admin/backups", handlers.StartBackup)
+	r.POST("/admin/backups/restore", handlers.RestoreBackup)
+	r.GET("/admin/abuse-flags", handlers.ListAbuseFlags)
+	r.POST("/admin/abuse-flags/:id/review", handlers.ReviewAbuseFlag)
+
+	// Public profiles, queried without authentication and rate limited by client address
+	public := r.Group("/public")
+	public.Use(auth.PublicRateLimitMiddleware(redisService))
+	public.GET("/:username", handlers.GetPublicProfile)
+	public.POST("/:username/query", handlers.QueryPublicProfile)
+
+	// Worker endpoints for background infrastructure, authenticated with internal tokens
+	internal := r.Group("/internal")
+	internal.Use(auth.InternalAuthMiddleware(internalAuth))
+	internal.POST("/outbox/drain", handlers.DrainOutbox)
+	internal.POST("/zotero/sync", handlers.SyncZoteroForUser)
+	internal.POST("/notion/sync", handlers.SyncNotionForUser)
+	internal.POST("/github/sync", handlers.SyncGitHubForUser)
 }
 
 // SetupCORS configures CORS for the application
@@ -52,7 +186,7 @@ func SetupCORS() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*") // In production, set specific origin
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, X-Admin-API-Key, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Accept-Language, Content-Length, Accept-Encoding, X-CSRF-Token, X-Admin-API-Key, X-Impersonation-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
@@ -0,0 +1,525 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+	"github.com/siddhantgupta/forgetai-backend/internal/textnorm"
+	"github.com/siddhantgupta/forgetai-backend/internal/utils"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// slackSaveCallbackID is the callback ID of the app's "Save to ForgetAI" message action
+	slackSaveCallbackID = "save_to_forgetai"
+	// maxSlackRequestSize bounds the body read from Slack requests
+	maxSlackRequestSize = 1 << 20
+	// slackSaveTimeout bounds saving one message or thread
+	slackSaveTimeout = 5 * time.Minute
+	// slackTitleLength is how much of a thread's first message its title keeps
+	slackTitleLength = 80
+)
+
+// slackMarkup matches Slack's inline markup: user and channel mentions, special mentions
+// and links, with an optional label after "|"
+var slackMarkup = regexp.MustCompile(`<([@#!]?)([^<>|]+)(?:\|([^<>]*))?>`)
+
+// GetSlackInstallURL handles creating the link that installs the Slack app for the user
+func (h *Handlers) GetSlackInstallURL(c *gin.Context) {
+	if h.Slack == nil {
+		i18n.RespondError(c, http.StatusServiceUnavailable, nil, "Slack is not configured")
+		return
+	}
+
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	installURL, err := h.Slack.InstallURL(userID.(string), requestPlan(c))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to create Slack install link")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"url": installURL})
+}
+
+// GetSlackStatus handles retrieving the user's Slack integration
+func (h *Handlers) GetSlackStatus(c *gin.Context) {
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	integration, err := h.DB.GetSlackIntegration(c.Request.Context(), userID.(string))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch Slack integration")
+		return
+	}
+
+	saveEmoji := ""
+	if h.Slack != nil {
+		saveEmoji = h.Slack.SaveReaction()
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"configured":  h.Slack != nil,
+		"connected":   integration != nil,
+		"integration": integration,
+		"save_emoji":  saveEmoji,
+	})
+}
+
+// DisconnectSlack handles removing the user's Slack integration. Messages already saved are kept.
+func (h *Handlers) DisconnectSlack(c *gin.Context) {
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	removed, err := h.DB.DeleteSlackIntegration(c.Request.Context(), userID.(string))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to remove Slack integration")
+		return
+	}
+	if !removed {
+		i18n.RespondError(c, http.StatusNotFound, nil, "Slack is not connected")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(c, "Slack disconnected"),
+	})
+}
+
+// SlackOAuthCallback handles the browser returning from Slack after the user authorized an
+// install. The signed state names the user who started it.
+func (h *Handlers) SlackOAuthCallback(c *gin.Context) {
+	if h.Slack == nil {
+		i18n.RespondError(c, http.StatusNotFound, nil, "Slack is not configured")
+		return
+	}
+
+	if denied := c.Query("error"); denied != "" {
+		h.finishSlackInstall(c, http.StatusBadRequest, fmt.Errorf("%s", denied), "Slack install was cancelled")
+		return
+	}
+
+	userID, plan, err := h.Slack.ParseInstallState(c.Query("state"))
+	if err != nil {
+		h.finishSlackInstall(c, http.StatusBadRequest, err, "Invalid or expired Slack install link")
+		return
+	}
+
+	ctx := c.Request.Context()
+	installation, err := h.Slack.ExchangeCode(ctx, c.Query("code"))
+	if err != nil {
+		h.finishSlackInstall(c, http.StatusBadGateway, err, "Failed to install Slack app")
+		return
+	}
+
+	existing, err := h.DB.GetSlackIntegration(ctx, userID)
+	if err != nil {
+		h.finishSlackInstall(c, http.StatusInternalServerError, err, "Failed to save Slack integration")
+		return
+	}
+	integration := &database.SlackIntegration{
+		UserID:      userID,
+		TeamID:      installation.TeamID,
+		TeamName:    installation.TeamName,
+		SlackUserID: installation.SlackUserID,
+		BotToken:    installation.BotToken,
+		UserToken:   installation.UserToken,
+		Plan:        plan,
+	}
+	if existing != nil && existing.TeamID == installation.TeamID {
+		integration.SavedCount = existing.SavedCount
+		integration.LastSavedAt = existing.LastSavedAt
+		integration.CreatedAt = existing.CreatedAt
+	}
+	if err := h.DB.UpsertSlackIntegration(ctx, integration); err != nil {
+		h.finishSlackInstall(c, http.StatusInternalServerError, err, "Failed to save Slack integration")
+		return
+	}
+
+	h.finishSlackInstall(c, http.StatusOK, nil, "Slack workspace connected")
+}
+
+// finishSlackInstall ends an install by sending the browser back to the app when a
+// return URL is configured, or by responding with the outcome
+func (h *Handlers) finishSlackInstall(c *gin.Context, status int, err error, msg string) {
+	if err != nil {
+		fmt.Printf("Warning: Slack install failed: %v\n", err)
+	}
+
+	if h.SlackReturn != "" {
+		target, parseErr := url.Parse(h.SlackReturn)
+		if parseErr == nil {
+			query := target.Query()
+			if status == http.StatusOK {
+				query.Set("slack", "connected")
+			} else {
+				query.Set("slack", "error")
+				query.Set("message", msg)
+			}
+			target.RawQuery = query.Encode()
+			c.Redirect(http.StatusFound, target.String())
+			return
+		}
+	}
+
+	if status != http.StatusOK {
+		i18n.RespondError(c, status, err, msg)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c, msg)})
+}
+
+// slackEventEnvelope is a request of the Events API
+type slackEventEnvelope struct {
+	Type      string `json:"type"` // "url_verification" or "event_callback"
+	Challenge string `json:"challenge"`
+	TeamID    string `json:"team_id"`
+	Event     struct {
+		Type     string `json:"type"`
+		User     string `json:"user"`
+		Reaction string `json:"reaction"`
+		Item     struct {
+			Type    string `json:"type"`
+			Channel string `json:"channel"`
+			TS      string `json:"ts"`
+		} `json:"item"`
+	} `json:"event"`
+}
+
+// SlackEvents handles the Events API. Adding the save reaction to a message saves it, with
+// its thread, for the user who reacted. Slack retries events that aren't acknowledged
+// within 3 seconds, so messages are saved in the background and retries are ignored.
+func (h *Handlers) SlackEvents(c *gin.Context) {
+	body, ok := h.readSlackRequest(c)
+	if !ok {
+		return
+	}
+
+	var envelope slackEventEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+		return
+	}
+
+	if envelope.Type == "url_verification" {
+		c.JSON(http.StatusOK, gin.H{"challenge": envelope.Challenge})
+		return
+	}
+	if envelope.Type != "event_callback" || c.GetHeader("X-Slack-Retry-Num") != "" {
+		c.Status(http.StatusOK)
+		return
+	}
+
+	event := envelope.Event
+	switch {
+	case event.Type == "reaction_added" && event.Reaction == h.Slack.SaveReaction() && event.Item.Type == "message":
+		go h.handleSlackSave(envelope.TeamID, event.User, event.Item.Channel, event.Item.TS, "")
+	case event.Type == "app_uninstalled":
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			if _, err := h.DB.DeleteSlackTeamIntegrations(ctx, envelope.TeamID); err != nil {
+				fmt.Printf("Warning: Failed to remove Slack integrations of team %s: %v\n", envelope.TeamID, err)
+			}
+		}()
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// slackInteraction is the payload of an interaction, such as a message action
+type slackInteraction struct {
+	Type        string `json:"type"`
+	CallbackID  string `json:"callback_id"`
+	ResponseURL string `json:"response_url"`
+	Team        struct {
+		ID string `json:"id"`
+	} `json:"team"`
+	User struct {
+		ID string `json:"id"`
+	} `json:"user"`
+	Channel struct {
+		ID string `json:"id"`
+	} `json:"channel"`
+	Message struct {
+		TS string `json:"ts"`
+	} `json:"message"`
+}
+
+// SlackInteractions handles interactions with the app. The "Save to ForgetAI" message
+// action saves the message, with its thread, for the user who chose it.
+func (h *Handlers) SlackInteractions(c *gin.Context) {
+	body, ok := h.readSlackRequest(c)
+	if !ok {
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+		return
+	}
+	var interaction slackInteraction
+	if err := json.Unmarshal([]byte(form.Get("payload")), &interaction); err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+		return
+	}
+
+	if interaction.Type == "message_action" && interaction.CallbackID == slackSaveCallbackID {
+		go h.handleSlackSave(interaction.Team.ID, interaction.User.ID, interaction.Channel.ID, interaction.Message.TS, interaction.ResponseURL)
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// readSlackRequest reads a request's body and verifies Slack's signature of it,
+// responding with an error if it can't be read or isn't signed
+func (h *Handlers) readSlackRequest(c *gin.Context) ([]byte, bool) {
+	if h.Slack == nil {
+		i18n.RespondError(c, http.StatusNotFound, nil, "Slack is not configured")
+		return nil, false
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxSlackRequestSize))
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+		return nil, false
+	}
+	if !h.Slack.VerifyRequest(c.GetHeader("X-Slack-Request-Timestamp"), c.GetHeader("X-Slack-Signature"), body) {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "Unauthorized")
+		return nil, false
+	}
+	return body, true
+}
+
+// handleSlackSave saves a message with its thread for the user connected to the Slack
+// user, then tells the Slack user the outcome through the interaction's response URL or,
+// for reactions, an ephemeral message
+func (h *Handlers) handleSlackSave(teamID, slackUserID, channel, ts, responseURL string) {
+	ctx, cancel := context.WithTimeout(context.Background(), slackSaveTimeout)
+	defer cancel()
+
+	integration, err := h.DB.GetSlackIntegrationBySlackUser(ctx, teamID, slackUserID)
+	if err != nil {
+		fmt.Printf("Warning: Failed to look up Slack user %s of team %s: %v\n", slackUserID, teamID, err)
+		return
+	}
+	if integration == nil {
+		// Reactions from people who haven't connected ForgetAI are everyday emoji use
+		if responseURL != "" {
+			h.replySlack(ctx, nil, channel, slackUserID, responseURL, "Connect your Slack account in the ForgetAI app to save messages.")
+		}
+		return
+	}
+
+	if err := h.checkSpendingCap(ctx, integration.UserID); err != nil {
+		h.replySlack(ctx, integration, channel, slackUserID, responseURL, fmt.Sprintf("Not saved to ForgetAI: %v.", err))
+		return
+	}
+
+	summary, err := h.saveSlackThread(services.WithBillingUser(ctx, integration.UserID), integration, channel, ts)
+	var quotaErr *services.QuotaExceededError
+	switch {
+	case errors.As(err, &quotaErr):
+		h.replySlack(ctx, integration, channel, slackUserID, responseURL, fmt.Sprintf("Not saved to ForgetAI: %v.", quotaErr))
+	case err != nil:
+		fmt.Printf("Warning: Failed to save Slack message %s/%s for %s: %v\n", channel, ts, integration.UserID, err)
+		h.replySlack(ctx, integration, channel, slackUserID, responseURL, "Sorry, ForgetAI couldn't save that message.")
+	default:
+		h.replySlack(ctx, integration, channel, slackUserID, responseURL, "Saved to ForgetAI: "+summary)
+	}
+}
+
+// replySlack shows a message only the Slack user can see
+func (h *Handlers) replySlack(ctx context.Context, integration *database.SlackIntegration, channel, slackUserID, responseURL, text string) {
+	var err error
+	switch {
+	case responseURL != "":
+		err = h.Slack.Respond(ctx, responseURL, text)
+	case integration != nil:
+		err = h.Slack.PostEphemeral(ctx, integration.BotToken, channel, slackUserID, text)
+	default:
+		return
+	}
+	if err != nil {
+		fmt.Printf("Warning: Failed to reply to Slack user %s: %v\n", slackUserID, err)
+	}
+}
+
+// saveSlackThread saves a Slack message with the rest of its thread as one item, replacing
+// an earlier save of the thread so replies added since are kept. It returns the item's title.
+func (h *Handlers) saveSlackThread(ctx context.Context, integration *database.SlackIntegration, channel, ts string) (string, error) {
+	messages, err := h.Slack.GetThread(ctx, integration.UserToken, channel, ts)
+	if err != nil {
+		return "", err
+	}
+	root := messages[0]
+
+	// Mentions and authors are shown by name
+	names := make(map[string]string)
+	lookup := func(userID string) string {
+		if name, ok := names[userID]; ok {
+			return name
+		}
+		name, err := h.Slack.GetUserName(ctx, integration.UserToken, userID)
+		if err != nil || name == "" {
+			fmt.Printf("Warning: Failed to look up Slack user %s: %v\n", userID, err)
+			name = userID
+		}
+		names[userID] = name
+		return name
+	}
+
+	var lines, authors []string
+	seenAuthors := make(map[string]bool)
+	for i := range messages {
+		message := &messages[i]
+		author := message.Username
+		if message.User != "" {
+			author = lookup(message.User)
+		}
+		if author == "" {
+			author = "unknown"
+		}
+		if !seenAuthors[author] {
+			seenAuthors[author] = true
+			authors = append(authors, author)
+		}
+
+		text := slackText(message.Text, lookup)
+		for _, file := range message.Files {
+			title := file.Title
+			if title == "" {
+				title = file.Name
+			}
+			text = strings.TrimSpace(text + "\n[File: " + title + "]")
+		}
+		if text == "" {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s (%s): %s", author, message.SentAt().UTC().Format("2006-01-02 15:04"), text))
+	}
+	if len(lines) == 0 {
+		return "", fmt.Errorf("message has no text")
+	}
+
+	permalink, err := h.Slack.GetPermalink(ctx, integration.UserToken, channel, root.TS)
+	if err != nil {
+		fmt.Printf("Warning: Failed to get permalink of Slack message %s/%s: %v\n", channel, root.TS, err)
+	}
+
+	title := utils.Truncate(strings.Join(strings.Fields(lines[0]), " "), slackTitleLength)
+	threadKey := fmt.Sprintf("%s:%s:%s", integration.TeamID, channel, root.TS)
+	doc := &parentDocument{
+		UserID:    integration.UserID,
+		Plan:      integration.Plan,
+		Type:      "slack",
+		Title:     title,
+		Timestamp: root.SentAt(),
+		People:    authors,
+		Metadata: map[string]interface{}{
+			"url":              permalink,
+			"slack_team_id":    integration.TeamID,
+			"slack_channel_id": channel,
+			"slack_thread_key": threadKey,
+			"message_count":    len(lines),
+		},
+	}
+	// Chunks are stored as written but embedded without URLs
+	options := sourceChunking(h.Chunking)
+	doc.recordChunking(options)
+	splitProse(textnorm.Normalize(strings.Join(lines, "\n\n")), options, func(chunk, withContext string) {
+		if textnorm.EmbeddingText(chunk) == "" {
+			return
+		}
+		doc.Chunks = append(doc.Chunks, documentChunk{
+			Text:       chunk,
+			VectorText: fmt.Sprintf("Slack thread (%s): %s", title, chunk),
+			EmbedText:  fmt.Sprintf("Slack thread (%s): %s", title, textnorm.EmbeddingText(withContext)),
+			Metadata:   map[string]interface{}{"url": permalink},
+		})
+	})
+
+	previous, err := h.DB.GetUserDataByMetadata(ctx, integration.UserID, "slack", "slack_thread_key", threadKey)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return "", err
+	}
+
+	// Save the new version before removing the old one so the thread is never missing
+	record, _, err := h.ingestDocument(ctx, doc)
+	if err != nil {
+		return "", err
+	}
+	if previous != nil {
+		if err := h.deleteItem(ctx, previous); err != nil {
+			fmt.Printf("Warning: Failed to remove previous save of Slack thread %s: %v\n", threadKey, err)
+		}
+	}
+
+	h.recordAudit(ctx, &database.AuditEvent{
+		UserID:   integration.UserID,
+		Action:   database.AuditActionSave,
+		ItemID:   record.ID.Hex(),
+		ItemType: "slack",
+		Summary:  title,
+		Details:  map[string]interface{}{"source": "slack", "slack_thread_key": threadKey, "message_count": len(lines), "chunk_count": len(doc.Chunks)},
+	})
+	if err := h.DB.RecordSlackSave(ctx, integration.UserID); err != nil {
+		fmt.Printf("Warning: Failed to record Slack save of %s: %v\n", integration.UserID, err)
+	}
+
+	return title, nil
+}
+
+// slackText converts a message's Slack markup to plain text: mentions become names, links
+// keep their label and URL, and escaped characters are restored
+func slackText(text string, userName func(string) string) string {
+	text = slackMarkup.ReplaceAllStringFunc(text, func(markup string) string {
+		parts := slackMarkup.FindStringSubmatch(markup)
+		kind, target, label := parts[1], parts[2], parts[3]
+		switch kind {
+		case "@":
+			if label != "" {
+				return "@" + label
+			}
+			return "@" + userName(target)
+		case "#":
+			if label != "" {
+				return "#" + label
+			}
+			return "#" + target
+		case "!":
+			if label != "" {
+				return label
+			}
+			return "@" + strings.SplitN(target, "^", 2)[0] // e.g. <!here>, <!subteam^ID>
+		}
+		if label != "" && label != target {
+			return fmt.Sprintf("%s (%s)", label, target)
+		}
+		return strings.TrimPrefix(target, "mailto:")
+	})
+	return strings.TrimSpace(html.UnescapeString(text))
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+)
+
+// EnforceSpendingCap rejects requests of users whose estimated OpenAI spend today has
+// reached the daily spending cap, so abuse within the request limits can't run up costs.
+// Exports are let through, as they use no tokens, as are requests whose spend can't be read.
+func (h *Handlers) EnforceSpendingCap(c *gin.Context) {
+	if h.Spending == nil || c.FullPath() == "/api/export" {
+		c.Next()
+		return
+	}
+
+	userID := c.GetString("userId")
+	err := h.Spending.Check(c.Request.Context(), userID)
+	var capErr *services.SpendingCapExceededError
+	if errors.As(err, &capErr) {
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error":       i18n.T(c, "Daily spending limit of $%.2f reached", capErr.CapUSD),
+			"code":        i18n.ErrorCode(http.StatusTooManyRequests),
+			"cap_usd":     capErr.CapUSD,
+			"spent_usd":   roundUSD(capErr.SpentUSD),
+			"resets_at":   capErr.ResetsAt.Format(time.RFC3339),
+			"retry_after": i18n.T(c, "Try again tomorrow"),
+		})
+		c.Abort()
+		return
+	}
+	if err != nil {
+		fmt.Printf("Warning: Failed to check spending of user %s: %v\n", userID, err)
+	}
+	c.Next()
+}
+
+// checkSpendingCap returns a *services.SpendingCapExceededError once the user's estimated
+// spend today has reached the daily spending cap, for work outside the rate-limited routes
+// that's billed to the user. As in EnforceSpendingCap, spend that can't be read doesn't block.
+func (h *Handlers) checkSpendingCap(ctx context.Context, userID string) error {
+	if h.Spending == nil {
+		return nil
+	}
+	err := h.Spending.Check(ctx, userID)
+	var capErr *services.SpendingCapExceededError
+	if errors.As(err, &capErr) {
+		return capErr
+	}
+	if err != nil {
+		fmt.Printf("Warning: Failed to check spending of user %s: %v\n", userID, err)
+	}
+	return nil
+}
+
+// spendingState returns the user's estimated spend today and the daily cap, or nil if
+// spend isn't tracked
+func (h *Handlers) spendingState(c *gin.Context, userID string) gin.H {
+	if h.Spending == nil {
+		return nil
+	}
+	spent, err := h.Spending.Spent(c.Request.Context(), userID)
+	if err != nil {
+		fmt.Printf("Warning: Failed to get spending of user %s: %v\n", userID, err)
+		return nil
+	}
+	return gin.H{
+		"spent_usd": roundUSD(spent),
+		"cap_usd":   h.Spending.CapUSD(),
+	}
+}
+
+// roundUSD rounds an amount to a hundredth of a cent
+func roundUSD(amount float64) float64 {
+	return math.Round(amount*1e4) / 1e4
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/chunking"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// SummarizeData handles document summarization requests. Long documents are
+// summarized hierarchically so any length fits within model context limits.
+func (h *Handlers) SummarizeData(c *gin.Context) {
+	var req struct {
+		Instructions string `json:"instructions"`
+	}
+	// The body is optional
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+			return
+		}
+	}
+
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	idStr := c.Param("id")
+	userData, err := h.DB.GetUserDataByID(c.Request.Context(), idStr)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			i18n.RespondError(c, http.StatusNotFound, nil, "Item not found")
+		} else {
+			i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch item")
+		}
+		return
+	}
+
+	if userData.UserID != userID.(string) {
+		i18n.RespondError(c, http.StatusForbidden, nil, "Not authorized to access this item")
+		return
+	}
+
+	texts, err := h.documentTexts(c.Request.Context(), userData)
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to load document text")
+		return
+	}
+
+	summary, err := h.Summarizer.Summarize(c.Request.Context(), userData.DataValue, texts, req.Instructions)
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to summarize document")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":          idStr,
+		"type":        userData.DataType,
+		"summary":     summary,
+		"chunk_count": len(texts),
+		"timestamp":   time.Now(),
+	})
+}
+
+// documentTexts returns the ordered text of an item: its stored full text or chunks for
+// documents, or its own value
+func (h *Handlers) documentTexts(ctx context.Context, userData *database.UserData) ([]string, error) {
+	if !isParentType(userData.DataType) {
+		return []string{userData.DataValue}, nil
+	}
+	text, stored, err := h.storedFullText(ctx, userData)
+	if err != nil {
+		return nil, err
+	}
+	if stored {
+		return chunking.ChunkText(text, chunking.DefaultTextChunkSize), nil
+	}
+
+	chunks, err := h.DB.GetChunks(ctx, userData.ID.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	texts := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		texts = append(texts, chunk.DataValue)
+	}
+	return texts, nil
+}
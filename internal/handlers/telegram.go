@@ -0,0 +1,432 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/models"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+	"github.com/siddhantgupta/forgetai-backend/internal/textnorm"
+	"github.com/siddhantgupta/forgetai-backend/internal/utils"
+)
+
+const (
+	// telegramLinkCodeTTL is how long a link code can be sent to the bot
+	telegramLinkCodeTTL = 15 * time.Minute
+	// telegramLinkCodeLength is the number of characters in a link code
+	telegramLinkCodeLength = 8
+	// telegramLinkCodeAlphabet leaves out characters that are easily confused when typed
+	telegramLinkCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	// telegramMessageTimeout bounds saving one message, including downloading its file
+	telegramMessageTimeout = 10 * time.Minute
+)
+
+// telegramHelp is the bot's reply to /help and to messages from chats that aren't linked
+const telegramHelp = "Send me any text, link, document or photo and I'll save it to your ForgetAI memory.\n\n" +
+	"To link this chat, create a link code in the ForgetAI app and send /link followed by the code."
+
+// CreateTelegramLinkCode handles creating a one-time code that links the Telegram chat it
+// is sent from to the user. The code is sent to the bot as "/link <code>", or through the
+// returned link, which opens the bot with the code filled in.
+func (h *Handlers) CreateTelegramLinkCode(c *gin.Context) {
+	if h.Telegram == nil {
+		i18n.RespondError(c, http.StatusServiceUnavailable, nil, "Telegram is not configured")
+		return
+	}
+
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	code, err := newTelegramLinkCode()
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to create Telegram link code")
+		return
+	}
+	expiresAt := time.Now().Add(telegramLinkCodeTTL)
+	if err := h.DB.SetTelegramLinkCode(c.Request.Context(), userID.(string), requestPlan(c), code, expiresAt); err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to create Telegram link code")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    i18n.T(c, "Send the code to the bot to link your Telegram chat"),
+		"code":       code,
+		"command":    "/link " + code,
+		"link":       h.Telegram.StartLink(code),
+		"expires_at": expiresAt.Format(time.RFC3339),
+	})
+}
+
+// GetTelegramStatus handles retrieving whether the user has linked a Telegram chat
+func (h *Handlers) GetTelegramStatus(c *gin.Context) {
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	link, err := h.DB.GetTelegramLink(c.Request.Context(), userID.(string))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch Telegram link")
+		return
+	}
+	if link != nil && link.ChatID == 0 {
+		link = nil // only a link code was created
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"configured": h.Telegram != nil,
+		"linked":     link != nil,
+		"link":       link,
+	})
+}
+
+// UnlinkTelegram handles unlinking the user's Telegram chat. Messages already saved are kept.
+func (h *Handlers) UnlinkTelegram(c *gin.Context) {
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	removed, err := h.DB.DeleteTelegramLink(c.Request.Context(), userID.(string))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to remove Telegram link")
+		return
+	}
+	if !removed {
+		i18n.RespondError(c, http.StatusNotFound, nil, "Telegram is not linked")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(c, "Telegram chat unlinked"),
+	})
+}
+
+// TelegramWebhook handles updates Telegram delivers for the bot. Updates are verified by
+// the secret token registered with setWebhook. Telegram redelivers updates that aren't
+// acknowledged quickly, so messages are saved in the background and the sender is
+// replied to once saving finishes.
+func (h *Handlers) TelegramWebhook(c *gin.Context) {
+	if h.Telegram == nil {
+		i18n.RespondError(c, http.StatusNotFound, nil, "Telegram is not configured")
+		return
+	}
+
+	if !h.Telegram.VerifyWebhook(c.GetHeader("X-Telegram-Bot-Api-Secret-Token")) {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "Unauthorized")
+		return
+	}
+
+	var update services.TelegramUpdate
+	if err := json.NewDecoder(c.Request.Body).Decode(&update); err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+		return
+	}
+
+	// Only direct messages are saved, so nothing from a group is saved for one member
+	if message := update.Message; message != nil && message.Chat.Type == "private" {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), telegramMessageTimeout)
+			defer cancel()
+			h.handleTelegramMessage(ctx, message)
+		}()
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// handleTelegramMessage links the chat for /link and /start commands, and otherwise saves
+// the message for the chat's user, replying with the outcome
+func (h *Handlers) handleTelegramMessage(ctx context.Context, message *services.TelegramMessage) {
+	reply := func(text string) {
+		if err := h.Telegram.SendMessage(ctx, message.Chat.ID, text); err != nil {
+			fmt.Printf("Warning: Failed to reply to Telegram chat %d: %v\n", message.Chat.ID, err)
+		}
+	}
+
+	if command, arg, ok := telegramCommand(message.Text); ok {
+		switch command {
+		case "/start", "/link":
+			if arg == "" {
+				reply(telegramHelp)
+				return
+			}
+			username := ""
+			if message.From != nil {
+				username = message.From.Username
+			}
+			link, err := h.DB.LinkTelegramChat(ctx, strings.ToUpper(arg), message.Chat.ID, username)
+			switch {
+			case err != nil:
+				fmt.Printf("Warning: Failed to link Telegram chat %d: %v\n", message.Chat.ID, err)
+				reply("Sorry, linking failed. Please try again.")
+			case link == nil:
+				reply("That link code is invalid or has expired. Create a new one in the ForgetAI app.")
+			default:
+				reply("This chat is now linked to your ForgetAI account. Anything you send me will be saved.")
+			}
+			return
+		case "/help":
+			reply(telegramHelp)
+			return
+		}
+	}
+
+	link, err := h.DB.GetTelegramLinkByChat(ctx, message.Chat.ID)
+	if err != nil {
+		fmt.Printf("Warning: Failed to look up Telegram chat %d: %v\n", message.Chat.ID, err)
+		reply("Sorry, I couldn't save that. Please try again.")
+		return
+	}
+	if link == nil {
+		reply(telegramHelp)
+		return
+	}
+	if err := h.DB.TouchTelegramLink(ctx, link.UserID); err != nil {
+		fmt.Printf("Warning: Failed to update Telegram link of %s: %v\n", link.UserID, err)
+	}
+
+	ctx = services.WithBillingUser(ctx, link.UserID)
+	if err := h.checkSpendingCap(ctx, link.UserID); err != nil {
+		reply(fmt.Sprintf("Not saved: %v.", err))
+		return
+	}
+	summary, err := h.saveTelegramMessage(ctx, link, message)
+	var quotaErr *services.QuotaExceededError
+	switch {
+	case errors.As(err, &quotaErr):
+		reply(fmt.Sprintf("Not saved: %v.", quotaErr))
+	case err != nil:
+		fmt.Printf("Warning: Failed to save Telegram message from %s: %v\n", link.UserID, err)
+		reply("Sorry, I couldn't save that: " + err.Error())
+	case summary == "":
+		reply("Nothing to save in that message.")
+	default:
+		reply("Saved: " + summary)
+	}
+}
+
+// saveTelegramMessage saves a message's document, photo, link or text, returning a short
+// description of what was saved, or "" if the message held nothing to save
+func (h *Handlers) saveTelegramMessage(ctx context.Context, link *database.TelegramLink, message *services.TelegramMessage) (string, error) {
+	details := map[string]interface{}{
+		"source":              "telegram",
+		"telegram_chat_id":    message.Chat.ID,
+		"telegram_message_id": message.MessageID,
+	}
+	caption := textnorm.Normalize(message.Caption)
+
+	switch {
+	case message.Document != nil:
+		if message.Document.FileSize > services.TelegramMaxFileSize {
+			return "", fmt.Errorf("files over %d MB can't be downloaded from Telegram", services.TelegramMaxFileSize>>20)
+		}
+		file, err := h.Telegram.DownloadFile(ctx, message.Document.FileID)
+		if err != nil {
+			return "", err
+		}
+		filename := message.Document.FileName
+		if filename == "" {
+			filename = "Telegram file"
+		}
+		if mimeType := http.DetectContentType(file); imageTypes[mimeType] {
+			return h.saveTelegramImage(ctx, link, filename, mimeType, file, caption, details)
+		}
+		return h.saveTelegramDocument(ctx, link, message, filename, file, details)
+
+	case len(message.Photo) > 0:
+		// Sizes are listed smallest first; the largest is read best by the vision model
+		photo := message.Photo[len(message.Photo)-1]
+		file, err := h.Telegram.DownloadFile(ctx, photo.FileID)
+		if err != nil {
+			return "", err
+		}
+		mimeType := http.DetectContentType(file)
+		if !imageTypes[mimeType] {
+			return "", fmt.Errorf("unsupported image type %s", mimeType)
+		}
+		return h.saveTelegramImage(ctx, link, fmt.Sprintf("Telegram photo %d", message.MessageID), mimeType, file, caption, details)
+	}
+
+	text := strings.TrimSpace(message.Text)
+	if text == "" {
+		return "", nil
+	}
+	if u, err := url.Parse(text); err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && !strings.ContainsAny(text, " \n") {
+		delete(details, "source")
+		record, err := h.importBookmark(ctx, link.UserID, link.Plan, h.Chunking, bookmarkItem{URL: textnorm.StripTracking(text)}, "telegram", details)
+		if err != nil {
+			return "", err
+		}
+		if record == nil {
+			return "", fmt.Errorf("this link is already saved")
+		}
+		return record.DataValue, nil
+	}
+	return h.saveTelegramText(ctx, link, message, textnorm.Normalize(text), details)
+}
+
+// saveTelegramText saves a text message as a note
+func (h *Handlers) saveTelegramText(ctx context.Context, link *database.TelegramLink, message *services.TelegramMessage, text string, details map[string]interface{}) (string, error) {
+	if err := h.checkQuota(ctx, link.UserID, link.Plan, text); err != nil {
+		return "", err
+	}
+
+	metadata := make(map[string]interface{}, len(details)+2)
+	for key, value := range details {
+		metadata[key] = value
+	}
+	setPeopleMetadata(metadata, h.extractPeople(ctx, text, nil))
+
+	data := models.Data{
+		Selected_type: "note",
+		Text:          text,
+		UserId:        link.UserID,
+		Timestamp:     message.SentAt(),
+	}
+	if keys, ok := metadata["people_keys"]; ok {
+		data.Metadata = map[string]interface{}{"people_keys": keys}
+	}
+
+	userData := &database.UserData{
+		UserID:     link.UserID,
+		VectorID:   fmt.Sprintf("%s-%d", link.UserID, time.Now().UnixNano()),
+		DataType:   "note",
+		DataValue:  text,
+		ChunkIndex: 0,
+		Metadata:   metadata,
+		CreatedAt:  time.Now(),
+	}
+
+	// The vector is written in the background
+	if _, err := h.enqueueVector(ctx, userData, data, ""); err != nil {
+		return "", err
+	}
+
+	summary := utils.Truncate(text, auditSummaryLength)
+	h.recordAudit(ctx, &database.AuditEvent{
+		UserID:   link.UserID,
+		Action:   database.AuditActionSave,
+		ItemID:   userData.ID.Hex(),
+		ItemType: "note",
+		Summary:  summary,
+		Details:  details,
+	})
+	return summary, nil
+}
+
+// saveTelegramImage saves a photo or image file read by the vision model
+func (h *Handlers) saveTelegramImage(ctx context.Context, link *database.TelegramLink, filename, mimeType string, image []byte, caption string, details map[string]interface{}) (string, error) {
+	content, err := h.Images.Read(ctx, mimeType, image)
+	if err != nil {
+		return "", fmt.Errorf("failed to read image: %v", err)
+	}
+	content.Text = textnorm.Normalize(content.Text)
+
+	if _, err := h.storeImage(ctx, link.UserID, link.Plan, filename, mimeType, image, content, caption, details); err != nil {
+		return "", err
+	}
+	return utils.Truncate(content.Description, auditSummaryLength), nil
+}
+
+// saveTelegramDocument saves a PDF, DOCX, EPUB or markdown file as a document
+func (h *Handlers) saveTelegramDocument(ctx context.Context, link *database.TelegramLink, message *services.TelegramMessage, filename string, file []byte, details map[string]interface{}) (string, error) {
+	r, size := bytes.NewReader(file), int64(len(file))
+	dataType, err := detectDocumentType(r, size, filename)
+	if err != nil {
+		return "", fmt.Errorf("only PDF, DOCX, EPUB, Markdown and image files can be saved")
+	}
+
+	pages := 0
+	if dataType == "pdf" {
+		if pages, err = pdfPageCount(r, size); err != nil {
+			return "", err
+		}
+		if err := h.Quota.CheckDocument(link.Plan, pages, 0); err != nil {
+			return "", err
+		}
+	}
+
+	text, err := documentFormats[dataType].extract(r, size)
+	if err != nil {
+		return "", err
+	}
+	if text == "" {
+		return "", fmt.Errorf("no readable text found in the document")
+	}
+
+	metadata := chunkingMetadata(dataType, h.Chunking)
+	for key, value := range details {
+		metadata[key] = value
+	}
+	doc := &parentDocument{
+		UserID:    link.UserID,
+		Type:      dataType,
+		Title:     filename,
+		Metadata:  metadata,
+		Timestamp: message.SentAt(),
+		Plan:      link.Plan,
+		Pages:     pages,
+		FullText:  text,
+		File:      &originalFile{Name: filename, ContentType: documentFormats[dataType].mime, Size: size, Body: r},
+		Chunks:    documentChunks(dataType, filename, text, h.Chunking),
+	}
+
+	record, _, err := h.ingestDocument(ctx, doc)
+	if err != nil {
+		return "", err
+	}
+
+	h.recordAudit(ctx, &database.AuditEvent{
+		UserID:   link.UserID,
+		Action:   database.AuditActionSave,
+		ItemID:   record.ID.Hex(),
+		ItemType: dataType,
+		Summary:  filename,
+		Details:  map[string]interface{}{"source": "telegram", "telegram_chat_id": message.Chat.ID, "chunk_count": len(doc.Chunks)},
+	})
+	return fmt.Sprintf("%s (%d chunks)", filename, len(doc.Chunks)), nil
+}
+
+// telegramCommand splits a bot command such as "/link ABCD2345" into the command and its
+// argument. Commands may be addressed to the bot, as in "/link@ForgetAIBot".
+func telegramCommand(text string) (string, string, bool) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "/") {
+		return "", "", false
+	}
+	command, arg, _ := strings.Cut(text, " ")
+	if at := strings.Index(command, "@"); at >= 0 {
+		command = command[:at]
+	}
+	return strings.ToLower(command), strings.TrimSpace(arg), true
+}
+
+// newTelegramLinkCode returns a random link code
+func newTelegramLinkCode() (string, error) {
+	random := make([]byte, telegramLinkCodeLength)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	code := make([]byte, telegramLinkCodeLength)
+	for i, b := range random {
+		code[i] = telegramLinkCodeAlphabet[int(b)%len(telegramLinkCodeAlphabet)]
+	}
+	return string(code), nil
+}
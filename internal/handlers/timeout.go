@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+)
+
+// Request deadlines by kind of route
+const (
+	// listTimeout bounds reads such as listings and lookups
+	listTimeout = 10 * time.Second
+	// defaultTimeout bounds other routes, such as single-item saves and updates
+	defaultTimeout = 30 * time.Second
+	// queryTimeout bounds retrieval and answer generation
+	queryTimeout = 2 * time.Minute
+	// processingTimeout bounds uploads and imports that are parsed, chunked and embedded
+	processingTimeout = 5 * time.Minute
+	// recordingTimeout bounds meeting recording uploads, which can be up to a gigabyte
+	recordingTimeout = 30 * time.Minute
+	// exportTimeout bounds full data exports, which stream every item a user has saved
+	exportTimeout = 30 * time.Minute
+)
+
+// routeTimeouts are the deadlines of routes that don't use the default for their method,
+// keyed by method and route pattern
+var routeTimeouts = map[string]time.Duration{
+	"POST /api/query":                    queryTimeout,
+	"POST /api/query/explain":            queryTimeout,
+	"POST /api/data/delete-by-query":     queryTimeout,
+	"POST /public/:username/query":       queryTimeout,
+	"GET /api/analytics/queries":         defaultTimeout,
+	"GET /api/reports/gaps":              defaultTimeout,
+	"GET /admin/analytics":               defaultTimeout,
+	"GET /admin/users":                   defaultTimeout,
+	"POST /api/query/voice":              processingTimeout,
+	"POST /api/save-pdf":                 processingTimeout,
+	"POST /api/save-document":            processingTimeout,
+	"POST /api/save-code":                processingTimeout,
+	"POST /api/save-github":              processingTimeout,
+	"POST /api/save-gist":                processingTimeout,
+	"POST /api/save-hackernews":          processingTimeout,
+	"POST /api/save-reddit":              processingTimeout,
+	"POST /api/save-youtube":             processingTimeout,
+	"POST /api/save-image":               processingTimeout,
+	"POST /api/data/:id/summarize":       processingTimeout,
+	"POST /api/data/:id/rechunk":         processingTimeout,
+	"POST /api/contradictions/scan":      processingTimeout,
+	"GET /api/digest":                    processingTimeout,
+	"POST /api/integrations/zotero/sync": processingTimeout,
+	"POST /api/integrations/notion/sync": processingTimeout,
+	"POST /api/integrations/github/sync": processingTimeout,
+	"POST /internal/outbox/drain":        processingTimeout,
+	"POST /internal/zotero/sync":         processingTimeout,
+	"POST /internal/notion/sync":         processingTimeout,
+	"POST /internal/github/sync":         processingTimeout,
+	"POST /api/save-meeting":             recordingTimeout,
+	"GET /api/export":                    exportTimeout,
+}
+
+// routeTimeout returns the deadline for requests to a route: its own if it has one, and
+// otherwise the listing deadline for GETs and the default for other methods
+func routeTimeout(method, route string) time.Duration {
+	if timeout, ok := routeTimeouts[method+" "+route]; ok {
+		return timeout
+	}
+	if method == http.MethodGet {
+		return listTimeout
+	}
+	return defaultTimeout
+}
+
+// RouteTimeoutMiddleware bounds each request's context by its route's deadline, so the
+// MongoDB, Pinecone and OpenAI calls made with it are cancelled when the deadline passes.
+// Handlers that return without responding after the deadline get a 504.
+func RouteTimeoutMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Unmatched paths are answered by the router without reaching a handler
+		if c.FullPath() == "" {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), routeTimeout(c.Request.Method, c.FullPath()))
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if ctx.Err() == context.DeadlineExceeded && !c.Writer.Written() {
+			i18n.RespondError(c, http.StatusGatewayTimeout, ctx.Err(), "Request timed out")
+		}
+	}
+}
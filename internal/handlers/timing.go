@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/timing"
+	"github.com/siddhantgupta/forgetai-backend/internal/utils"
+)
+
+// requestLogger writes one structured line per request for log-based analysis
+var requestLogger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+// RequestTimingMiddleware times each request's OpenAI, Pinecone, MongoDB and Redis calls
+// and logs them with the request. Requests carrying the admin API key also get the
+// timings in a "debug" section of JSON responses.
+func RequestTimingMiddleware(adminKey string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		ctx := timing.With(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+
+		debug := adminKey != "" && c.GetHeader("X-Admin-API-Key") == adminKey
+		var buffer *utils.ResponseBuffer
+		if debug {
+			buffer = utils.NewResponseBuffer(c.Writer)
+			c.Writer = buffer
+		}
+
+		c.Next()
+
+		elapsed := time.Since(start)
+		timings := timing.Summary(ctx)
+		if buffer != nil {
+			c.Writer = buffer.ResponseWriter
+			buffer.Commit(utils.AddJSONField(buffer.Body(), "debug", gin.H{
+				"duration_ms":  float64(elapsed.Microseconds()) / 1000,
+				"dependencies": timings,
+			}))
+		}
+
+		// Unmatched paths are not logged so scanners can't flood the logs
+		if c.FullPath() == "" {
+			return
+		}
+		userID, _ := c.Get("userId")
+		requestLogger.Info("request",
+			"method", c.Request.Method,
+			"route", c.FullPath(),
+			"status", c.Writer.Status(),
+			"duration_ms", float64(elapsed.Microseconds())/1000,
+			"user_id", userID,
+			"dependencies", timings,
+		)
+	}
+}
@@ -0,0 +1,429 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/models"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+	"github.com/siddhantgupta/forgetai-backend/internal/textnorm"
+	"github.com/siddhantgupta/forgetai-backend/internal/utils"
+)
+
+// xTweetFields are the X API query parameters requesting a tweet's author, creation time,
+// quoted tweet and image alt text alongside its text
+var xTweetFields = url.Values{
+	"tweet.fields": {"created_at,author_id,referenced_tweets,attachments"},
+	"expansions":   {"author_id,referenced_tweets.id,referenced_tweets.id.author_id,attachments.media_keys"},
+	"user.fields":  {"username,name"},
+	"media.fields": {"type,alt_text"},
+}
+
+// xTweet is a tweet object of the X API
+type xTweet struct {
+	ID               string             `json:"id"`
+	Text             string             `json:"text"`
+	AuthorID         string             `json:"author_id"`
+	CreatedAt        time.Time          `json:"created_at"`
+	ReferencedTweets []xReferencedTweet `json:"referenced_tweets"`
+	Attachments      struct {
+		MediaKeys []string `json:"media_keys"`
+	} `json:"attachments"`
+}
+
+// xReferencedTweet is a tweet a tweet quotes, replies to or retweets
+type xReferencedTweet struct {
+	Type string `json:"type"` // "quoted", "replied_to" or "retweeted"
+	ID   string `json:"id"`
+}
+
+// xUser is a user object of the X API
+type xUser struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Username string `json:"username"`
+}
+
+// xMedia is a media object of the X API
+type xMedia struct {
+	MediaKey string `json:"media_key"`
+	Type     string `json:"type"` // "photo", "video" or "animated_gif"
+	AltText  string `json:"alt_text"`
+}
+
+// xTweetResponse is the X API's response for a tweet lookup with expansions
+type xTweetResponse struct {
+	Data     xTweet `json:"data"`
+	Includes struct {
+		Users  []xUser  `json:"users"`
+		Tweets []xTweet `json:"tweets"`
+		Media  []xMedia `json:"media"`
+	} `json:"includes"`
+}
+
+// tweetContent is a tweet's text with the details that give it context
+type tweetContent struct {
+	Text           string
+	AuthorUsername string
+	AuthorName     string
+	CreatedAt      time.Time
+	QuotedText     string
+	QuotedUsername string
+	ImageAltTexts  []string
+}
+
+// content resolves the tweet's expansions
+func (r *xTweetResponse) content() *tweetContent {
+	usernames := make(map[string]string, len(r.Includes.Users))
+	names := make(map[string]string, len(r.Includes.Users))
+	for _, user := range r.Includes.Users {
+		usernames[user.ID] = user.Username
+		names[user.ID] = user.Name
+	}
+
+	tweet := &tweetContent{
+		Text:           textnorm.Normalize(r.Data.Text),
+		AuthorUsername: usernames[r.Data.AuthorID],
+		AuthorName:     names[r.Data.AuthorID],
+		CreatedAt:      r.Data.CreatedAt,
+	}
+
+	for _, ref := range r.Data.ReferencedTweets {
+		if ref.Type != "quoted" {
+			continue
+		}
+		for _, included := range r.Includes.Tweets {
+			if included.ID == ref.ID {
+				tweet.QuotedText = textnorm.Normalize(included.Text)
+				tweet.QuotedUsername = usernames[included.AuthorID]
+			}
+		}
+	}
+
+	mediaKeys := make(map[string]bool, len(r.Data.Attachments.MediaKeys))
+	for _, key := range r.Data.Attachments.MediaKeys {
+		mediaKeys[key] = true
+	}
+	for _, media := range r.Includes.Media {
+		if mediaKeys[media.MediaKey] && strings.TrimSpace(media.AltText) != "" {
+			tweet.ImageAltTexts = append(tweet.ImageAltTexts, textnorm.Normalize(media.AltText))
+		}
+	}
+
+	return tweet
+}
+
+// header is the label the tweet's text is indexed behind, naming its author and date
+func (t *tweetContent) header() string {
+	header := "Tweet from X (Twitter)"
+	switch {
+	case t.AuthorUsername != "" && t.AuthorName != "":
+		header += fmt.Sprintf(" by @%s (%s)", t.AuthorUsername, t.AuthorName)
+	case t.AuthorUsername != "":
+		header += " by @" + t.AuthorUsername
+	}
+	if !t.CreatedAt.IsZero() {
+		header += " on " + t.CreatedAt.UTC().Format("2006-01-02")
+	}
+	return header
+}
+
+// indexedText is the tweet as stored and retrieved: its text behind the header, followed
+// by the quoted tweet and image descriptions. clean is applied to each piece of tweet
+// text, e.g. to strip links before embedding.
+func (t *tweetContent) indexedText(clean func(string) string) string {
+	var b strings.Builder
+	b.WriteString(t.header() + ": " + clean(t.Text))
+	if quoted := clean(t.QuotedText); quoted != "" {
+		if t.QuotedUsername != "" {
+			fmt.Fprintf(&b, "\n\nQuoting @%s: %s", t.QuotedUsername, quoted)
+		} else {
+			b.WriteString("\n\nQuoting: " + quoted)
+		}
+	}
+	for _, alt := range t.ImageAltTexts {
+		b.WriteString("\n\nImage: " + alt)
+	}
+	return b.String()
+}
+
+// metadata is the tweet's details kept with the stored tweet
+func (t *tweetContent) metadata() map[string]interface{} {
+	metadata := make(map[string]interface{})
+	if t.AuthorUsername != "" {
+		metadata["author_username"] = t.AuthorUsername
+	}
+	if t.AuthorName != "" {
+		metadata["author_name"] = t.AuthorName
+	}
+	if !t.CreatedAt.IsZero() {
+		metadata["tweet_created_at"] = t.CreatedAt.UTC().Format(time.RFC3339)
+	}
+	if t.QuotedText != "" {
+		metadata["quoted_text"] = t.QuotedText
+		if t.QuotedUsername != "" {
+			metadata["quoted_username"] = t.QuotedUsername
+		}
+	}
+	if len(t.ImageAltTexts) > 0 {
+		metadata["image_alt_texts"] = t.ImageAltTexts
+	}
+	return metadata
+}
+
+const (
+	// tweetCacheTTL is how long X API lookups are cached, so saving a tweet again doesn't
+	// use up the X API's rate limit
+	tweetCacheTTL = 24 * time.Hour
+	// maxTweetResponseSize is the most of an X API response read
+	maxTweetResponseSize = 1 << 20
+	// defaultXAPIBackoff is how long the X API is left alone after rejecting a request
+	// without saying when its rate limit resets
+	defaultXAPIBackoff = time.Minute
+	// xRetryJitter spreads out the retries of queued saves when the rate limit resets
+	xRetryJitter = 30 * time.Second
+	// maxTweetSaveDelay is the longest a save is queued for while the X API is rate
+	// limited; saves that would wait longer are turned away
+	maxTweetSaveDelay = 20 * time.Minute
+)
+
+// xRateLimitError reports that the X API is rejecting requests until its rate limit resets
+type xRateLimitError struct {
+	RetryAt time.Time
+}
+
+func (e *xRateLimitError) Error() string {
+	return fmt.Sprintf("X API rate limit reached until %s", e.RetryAt.Format(time.RFC3339))
+}
+
+// xAPIStatusError reports an unexpected status of an X API response
+type xAPIStatusError struct {
+	Status int
+}
+
+func (e *xAPIStatusError) Error() string {
+	return fmt.Sprintf("X API returned status: %d", e.Status)
+}
+
+// fetchTweet looks up a tweet, from the cache of recent lookups or the X API, or the public
+// embed endpoints if no X API bearer token is configured. While the X API is rate limiting
+// requests it isn't called, and an *xRateLimitError is returned.
+func (h *Handlers) fetchTweet(ctx context.Context, tweetID string) (*tweetContent, error) {
+	body, err := h.Redis.GetCachedTweet(ctx, tweetID)
+	if err != nil {
+		if h.XAPIToken == "" {
+			body, err = h.requestPublicTweet(ctx, tweetID)
+		} else if retryAt := h.Redis.XAPIBackoff(ctx); time.Now().Before(retryAt) {
+			return nil, &xRateLimitError{RetryAt: retryAt}
+		} else {
+			body, err = h.requestTweet(ctx, tweetID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		h.Redis.CacheTweet(ctx, tweetID, body, tweetCacheTTL)
+	}
+
+	var tweetData xTweetResponse
+	if err := json.Unmarshal(body, &tweetData); err != nil {
+		return nil, fmt.Errorf("failed to parse tweet data: %v", err)
+	}
+	return tweetData.content(), nil
+}
+
+// requestTweet looks up a tweet in the X API, returning the response body. A rate-limited
+// request backs every instance off until the limit resets.
+func (h *Handlers) requestTweet(ctx context.Context, tweetID string) ([]byte, error) {
+	apiReq, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("https://api.x.com/2/tweets/%s?%s", url.PathEscape(tweetID), xTweetFields.Encode()), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	apiReq.Header.Set("Authorization", "Bearer "+h.XAPIToken)
+
+	resp, err := http.DefaultClient.Do(apiReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusTooManyRequests:
+		retryAt := xRetryAt(resp.Header, time.Now())
+		h.Redis.SetXAPIBackoff(ctx, retryAt)
+		return nil, &xRateLimitError{RetryAt: retryAt}
+	default:
+		return nil, &xAPIStatusError{Status: resp.StatusCode}
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxTweetResponseSize))
+}
+
+// xRetryAt returns when a rate-limited X API request may be retried: after the
+// Retry-After delay, or at the x-rate-limit-reset time the X API usually sends instead
+func xRetryAt(header http.Header, now time.Time) time.Time {
+	if retryAfter := header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+			return now.Add(time.Duration(seconds) * time.Second)
+		}
+		if date, err := http.ParseTime(retryAfter); err == nil {
+			return date
+		}
+	}
+	if reset, err := strconv.ParseInt(header.Get("x-rate-limit-reset"), 10, 64); err == nil {
+		return time.Unix(reset, 0)
+	}
+	return now.Add(defaultXAPIBackoff)
+}
+
+// storeTweet stores a fetched tweet for a user, returning the stored item
+func (h *Handlers) storeTweet(ctx context.Context, userID, plan, tweetURL, tweetID string, tweet *tweetContent) (*database.UserData, error) {
+	tweetText := tweet.indexedText(func(text string) string { return text })
+	if err := h.checkQuota(ctx, userID, plan, tweetText); err != nil {
+		return nil, err
+	}
+
+	metadata := tweet.metadata()
+	metadata["tweet_id"] = tweetID
+	metadata["tweet_url"] = tweetURL
+	setPeopleMetadata(metadata, h.extractPeople(ctx, strings.TrimSpace(tweet.Text+"\n\n"+tweet.QuotedText), nil))
+
+	// Create Data struct for saving, dated when the tweet was posted
+	data := models.Data{
+		Selected_type: "tweet",
+		Text:          tweetText,
+		UserId:        userID,
+		Timestamp:     tweet.CreatedAt,
+	}
+	if keys, ok := metadata["people_keys"]; ok {
+		data.Metadata = map[string]interface{}{"people_keys": keys}
+	}
+
+	userData := &database.UserData{
+		UserID:     userID,
+		VectorID:   fmt.Sprintf("%s-tweet-%d", userID, time.Now().UnixNano()),
+		DataType:   "tweet",
+		DataValue:  tweetText,
+		ChunkIndex: 0,
+		Metadata:   metadata,
+		CreatedAt:  time.Now(),
+	}
+
+	// The tweet is stored as written but embedded without links and retweet prefixes, which
+	// would match unrelated tweets; tweets of only emoji and links are embedded as they are
+	embedText := ""
+	if textnorm.EmbeddingText(tweet.Text) != "" {
+		embedText = tweet.indexedText(textnorm.EmbeddingText)
+	}
+
+	// The vector is written in the background
+	if _, err := h.enqueueVector(ctx, userData, data, embedText); err != nil {
+		return nil, err
+	}
+
+	h.recordAudit(ctx, &database.AuditEvent{
+		UserID:   userID,
+		Action:   database.AuditActionSave,
+		ItemID:   userData.ID.Hex(),
+		ItemType: "tweet",
+		Summary:  utils.Truncate(tweetText, auditSummaryLength),
+		Details:  map[string]interface{}{"tweet_url": tweetURL},
+	})
+	return userData, nil
+}
+
+// respondTweetRateLimited queues a save whose tweet lookup the X API rate limited, to run
+// once the limit resets, and responds with the job tracking it. Saves that would wait
+// longer than maxTweetSaveDelay are turned away with when to try again.
+func (h *Handlers) respondTweetRateLimited(c *gin.Context, userID, tweetURL, tweetID string, retryAt time.Time) {
+	if time.Until(retryAt) > maxTweetSaveDelay {
+		c.Header("Retry-After", strconv.Itoa(int(time.Until(retryAt).Seconds())))
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error":       i18n.T(c, "X API rate limit reached"),
+			"code":        i18n.ErrorCode(http.StatusTooManyRequests),
+			"retry_at":    retryAt.Format(time.RFC3339),
+			"retry_after": i18n.T(c, "Try again later"),
+		})
+		return
+	}
+
+	job := &database.Job{
+		UserID: userID,
+		Kind:   "tweet",
+		Total:  1,
+	}
+	if err := h.DB.CreateJob(c.Request.Context(), job); err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to save tweet")
+		return
+	}
+	go h.runQueuedTweetSave(job, requestPlan(c), tweetURL, tweetID, retryAt)
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":  i18n.T(c, "X API rate limit reached; the tweet will be saved when it resets"),
+		"job_id":   job.ID.Hex(),
+		"retry_at": retryAt.Format(time.RFC3339),
+	})
+}
+
+// runQueuedTweetSave saves a tweet whose lookup was rate limited once the X API's rate
+// limit resets, recording the outcome on the job
+func (h *Handlers) runQueuedTweetSave(job *database.Job, plan, tweetURL, tweetID string, retryAt time.Time) {
+	ctx, cancel := context.WithTimeout(services.WithBillingUser(context.Background(), job.UserID), maxTweetSaveDelay+time.Minute)
+	defer cancel()
+
+	run := h.startJob(ctx, job)
+
+	item := database.JobItem{Key: tweetURL, Status: database.JobItemFailed}
+	tweet, err := h.awaitTweet(ctx, tweetID, retryAt)
+	switch {
+	case err != nil:
+		item.Reason = err.Error()
+	case tweet.Text == "":
+		item.Reason = "no text found in tweet"
+	default:
+		saved, err := h.storeTweet(ctx, job.UserID, plan, tweetURL, tweetID, tweet)
+		if err != nil {
+			item.Reason = err.Error()
+		} else {
+			item.Status, item.ItemID = database.JobItemSucceeded, saved.ID.Hex()
+		}
+	}
+	if item.Status == database.JobItemFailed {
+		fmt.Printf("Warning: Failed to save queued tweet %s: %v\n", tweetURL, item.Reason)
+	}
+
+	run.finish(ctx, "tweet save timed out", item)
+}
+
+// awaitTweet fetches a tweet once the X API's rate limit resets at retryAt, waiting for
+// the next reset whenever it's rate limited again, until ctx is done. Retries are spread
+// out so saves queued during the same limit don't exhaust it again at once.
+func (h *Handlers) awaitTweet(ctx context.Context, tweetID string, retryAt time.Time) (*tweetContent, error) {
+	for {
+		timer := time.NewTimer(time.Until(retryAt) + time.Duration(rand.Int63n(int64(xRetryJitter))))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("X API rate limit did not reset in time")
+		case <-timer.C:
+		}
+
+		tweet, err := h.fetchTweet(ctx, tweetID)
+		var rateErr *xRateLimitError
+		if !errors.As(err, &rateErr) {
+			return tweet, err
+		}
+		retryAt = rateErr.RetryAt
+	}
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// Without an X API bearer token, tweets are looked up through the public endpoints that
+// embedded tweets are rendered from. The syndication endpoint returns the tweet with its
+// quoted tweet and image alt text; the oEmbed endpoint, tried when it fails, only the
+// tweet's text, author and date.
+
+// syndicationTweet is a tweet of the syndication endpoint
+type syndicationTweet struct {
+	Typename  string    `json:"__typename"` // "TweetTombstone" for deleted and withheld tweets
+	IDStr     string    `json:"id_str"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+	User      struct {
+		IDStr      string `json:"id_str"`
+		Name       string `json:"name"`
+		ScreenName string `json:"screen_name"`
+	} `json:"user"`
+	MediaDetails []struct {
+		IDStr      string `json:"id_str"`
+		Type       string `json:"type"`
+		ExtAltText string `json:"ext_alt_text"`
+	} `json:"mediaDetails"`
+	QuotedTweet *syndicationTweet `json:"quoted_tweet"`
+}
+
+// oEmbedResponse is the oEmbed endpoint's response for a tweet
+type oEmbedResponse struct {
+	AuthorName string `json:"author_name"`
+	AuthorURL  string `json:"author_url"`
+	HTML       string `json:"html"`
+}
+
+// requestPublicTweet looks up a tweet through the public embed endpoints, returning it as
+// an X API response body so it's cached and parsed like one
+func (h *Handlers) requestPublicTweet(ctx context.Context, tweetID string) ([]byte, error) {
+	tweet, err := requestSyndicationTweet(ctx, tweetID)
+	if err != nil {
+		fmt.Printf("Warning: Failed to fetch tweet %s from the syndication endpoint, trying oEmbed: %v\n", tweetID, err)
+		if tweet, err = requestOEmbedTweet(ctx, tweetID); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(tweet)
+}
+
+// requestSyndicationTweet looks up a tweet in the syndication endpoint
+func requestSyndicationTweet(ctx context.Context, tweetID string) (*xTweetResponse, error) {
+	query := url.Values{"id": {tweetID}, "token": {syndicationToken(tweetID)}}
+	body, err := getPublicTweetEndpoint(ctx, "https://cdn.syndication.twimg.com/tweet-result?"+query.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	var tweet syndicationTweet
+	if err := json.Unmarshal(body, &tweet); err != nil {
+		return nil, fmt.Errorf("failed to parse tweet data: %v", err)
+	}
+	if tweet.Typename == "TweetTombstone" {
+		return nil, &xAPIStatusError{Status: http.StatusNotFound}
+	}
+	if tweet.Text == "" {
+		return nil, fmt.Errorf("no text in syndication response")
+	}
+
+	var resp xTweetResponse
+	resp.Data = tweet.apiTweet()
+	resp.Includes.Users = append(resp.Includes.Users, tweet.apiUser())
+	for _, media := range tweet.MediaDetails {
+		resp.Data.Attachments.MediaKeys = append(resp.Data.Attachments.MediaKeys, media.IDStr)
+		resp.Includes.Media = append(resp.Includes.Media, xMedia{MediaKey: media.IDStr, Type: media.Type, AltText: media.ExtAltText})
+	}
+	if quoted := tweet.QuotedTweet; quoted != nil && quoted.Text != "" {
+		resp.Data.ReferencedTweets = append(resp.Data.ReferencedTweets, xReferencedTweet{Type: "quoted", ID: quoted.IDStr})
+		resp.Includes.Tweets = append(resp.Includes.Tweets, quoted.apiTweet())
+		resp.Includes.Users = append(resp.Includes.Users, quoted.apiUser())
+	}
+	return &resp, nil
+}
+
+// apiTweet converts the tweet to an X API tweet object
+func (t *syndicationTweet) apiTweet() xTweet {
+	return xTweet{ID: t.IDStr, Text: t.Text, AuthorID: t.User.IDStr, CreatedAt: t.CreatedAt}
+}
+
+// apiUser converts the tweet's author to an X API user object
+func (t *syndicationTweet) apiUser() xUser {
+	return xUser{ID: t.User.IDStr, Name: t.User.Name, Username: t.User.ScreenName}
+}
+
+// syndicationToken computes the token the syndication endpoint requires for a tweet, as
+// embedded tweets do: the base 36 digits of id / 1e15 * π with zeros and the point removed
+func syndicationToken(tweetID string) string {
+	id, err := strconv.ParseFloat(tweetID, 64)
+	if err != nil {
+		return ""
+	}
+	token := strings.NewReplacer("0", "", ".", "").Replace(formatBase36(id / 1e15 * math.Pi))
+	if token == "" {
+		return "0"
+	}
+	return token
+}
+
+// formatBase36 formats a positive number in base 36 with the shortest fraction that
+// identifies it, as JavaScript's Number.prototype.toString(36) does
+func formatBase36(value float64) string {
+	const digits = "0123456789abcdefghijklmnopqrstuvwxyz"
+	integer := math.Floor(value)
+	fraction := value - integer
+
+	// delta is half the distance to the next float, the precision the fraction needs
+	delta := math.Max(0.5*(math.Nextafter(value, math.Inf(1))-value), math.SmallestNonzeroFloat64)
+	var frac []byte
+	for fraction >= delta {
+		fraction *= 36
+		delta *= 36
+		digit := int(fraction)
+		frac = append(frac, digits[digit])
+		fraction -= float64(digit)
+		if (fraction > 0.5 || (fraction == 0.5 && digit&1 == 1)) && fraction+delta > 1 {
+			// Round up, carrying into the integer part if every digit was the last one
+			for {
+				if len(frac) == 0 {
+					integer++
+					break
+				}
+				last := strings.IndexByte(digits, frac[len(frac)-1])
+				frac = frac[:len(frac)-1]
+				if last+1 < 36 {
+					frac = append(frac, digits[last+1])
+					break
+				}
+			}
+			break
+		}
+	}
+
+	s := strconv.FormatInt(int64(integer), 36)
+	if len(frac) > 0 {
+		s += "." + string(frac)
+	}
+	return s
+}
+
+// requestOEmbedTweet looks up a tweet in the oEmbed endpoint, reading its text, author and
+// date from the embed's markup
+func requestOEmbedTweet(ctx context.Context, tweetID string) (*xTweetResponse, error) {
+	query := url.Values{
+		"url":         {"https://twitter.com/i/status/" + tweetID},
+		"omit_script": {"true"},
+		"dnt":         {"true"},
+	}
+	body, err := getPublicTweetEndpoint(ctx, "https://publish.twitter.com/oembed?"+query.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	var embed oEmbedResponse
+	if err := json.Unmarshal(body, &embed); err != nil {
+		return nil, fmt.Errorf("failed to parse tweet data: %v", err)
+	}
+	text, date := parseTweetEmbed(embed.HTML)
+
+	var resp xTweetResponse
+	resp.Data = xTweet{ID: tweetID, Text: text, AuthorID: embed.AuthorURL}
+	if createdAt, err := time.Parse("January 2, 2006", date); err == nil {
+		resp.Data.CreatedAt = createdAt
+	}
+	if authorURL, err := url.Parse(embed.AuthorURL); err == nil {
+		resp.Includes.Users = append(resp.Includes.Users, xUser{
+			ID:       embed.AuthorURL,
+			Name:     embed.AuthorName,
+			Username: strings.Trim(authorURL.Path, "/"),
+		})
+	}
+	return &resp, nil
+}
+
+// parseTweetEmbed returns the text of an embedded tweet's markup, its paragraph, and the
+// date of its permalink, which follows the paragraph
+func parseTweetEmbed(markup string) (string, string) {
+	tokenizer := html.NewTokenizer(strings.NewReader(markup))
+
+	var text, date strings.Builder
+	inText, textDone, inLink := false, false, false
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return strings.TrimSpace(text.String()), strings.TrimSpace(date.String())
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, _ := tokenizer.TagName()
+			switch tag := string(name); {
+			case tag == "p" && !textDone:
+				inText = true
+			case tag == "br" && inText:
+				text.WriteString("\n")
+			case tag == "a" && textDone:
+				inLink = true
+				date.Reset()
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			switch string(name) {
+			case "p":
+				if inText {
+					inText, textDone = false, true
+				}
+			case "a":
+				inLink = false
+			}
+		case html.TextToken:
+			switch {
+			case inText:
+				text.Write(tokenizer.Text())
+			case inLink:
+				date.Write(tokenizer.Text())
+			}
+		}
+	}
+}
+
+// getPublicTweetEndpoint requests a public embed endpoint, returning the response body.
+// Rate-limited requests return an *xRateLimitError, so the save is queued like one the X
+// API rate limited.
+func getPublicTweetEndpoint(ctx context.Context, endpoint string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusTooManyRequests:
+		return nil, &xRateLimitError{RetryAt: xRetryAt(resp.Header, time.Now())}
+	case http.StatusForbidden:
+		// Protected tweets can't be embedded
+		return nil, &xAPIStatusError{Status: http.StatusNotFound}
+	default:
+		return nil, &xAPIStatusError{Status: resp.StatusCode}
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxTweetResponseSize))
+}
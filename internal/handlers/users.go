@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+)
+
+// adminUser is a user with saved data and, when Clerk lookups are configured, their profile
+type adminUser struct {
+	*database.UserSummary
+	Profile interface{} `json:"profile,omitempty"`
+}
+
+// ListUsers handles admin requests to page through users with saved data. Pass the last
+// user ID of a page as "after" to get the next page.
+func (h *Handlers) ListUsers(c *gin.Context) {
+	if !h.requireAdmin(c) {
+		return
+	}
+
+	limit := defaultActivityLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		n, err := strconv.Atoi(limitStr)
+		if err != nil || n <= 0 {
+			i18n.RespondError(c, http.StatusBadRequest, nil, "limit must be a positive integer")
+			return
+		}
+		if n > maxActivityLimit {
+			n = maxActivityLimit
+		}
+		limit = n
+	}
+
+	ctx := c.Request.Context()
+	summaries, err := h.DB.ListUserSummaries(ctx, c.Query("after"), int64(limit))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to list users")
+		return
+	}
+
+	users := make([]adminUser, 0, len(summaries))
+	userIDs := make([]string, 0, len(summaries))
+	for _, summary := range summaries {
+		users = append(users, adminUser{UserSummary: summary})
+		userIDs = append(userIDs, summary.UserID)
+	}
+
+	// Profiles are best effort; the data summary is still useful without them
+	if h.ClerkUsers != nil {
+		profiles, err := h.ClerkUsers.GetUsers(ctx, userIDs)
+		if err != nil {
+			i18n.RespondError(c, http.StatusBadGateway, err, "Failed to look up users in Clerk")
+			return
+		}
+		for i := range users {
+			if profile, ok := profiles[users[i].UserID]; ok {
+				users[i].Profile = profile
+			}
+		}
+	}
+
+	response := gin.H{
+		"users": users,
+		"count": len(users),
+	}
+	if len(users) == limit {
+		response["next_after"] = users[len(users)-1].UserID
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetUser handles admin requests for a user's profile, plan, quota state and item counts
+func (h *Handlers) GetUser(c *gin.Context) {
+	if !h.requireAdmin(c) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID := c.Param("id")
+
+	summary, itemCounts, err := h.DB.GetUserSummary(ctx, userID)
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to retrieve user")
+		return
+	}
+
+	// The plan normally comes from the user's token; here it is read from their Clerk profile
+	plan := ""
+	response := gin.H{
+		"user_id":  userID,
+		"has_data": summary != nil,
+	}
+	if h.ClerkUsers != nil {
+		profile, err := h.ClerkUsers.GetUser(ctx, userID)
+		if err != nil {
+			i18n.RespondError(c, http.StatusBadGateway, err, "Failed to look up users in Clerk")
+			return
+		}
+		if profile == nil && summary == nil {
+			i18n.RespondError(c, http.StatusNotFound, nil, "User not found")
+			return
+		}
+		if profile != nil {
+			plan = profile.Plan
+			response["profile"] = profile
+		}
+	} else if summary == nil {
+		i18n.RespondError(c, http.StatusNotFound, nil, "User not found")
+		return
+	}
+
+	quota, err := h.quotaState(ctx, userID, plan)
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to get storage usage")
+		return
+	}
+	response["plan"] = quota["plan"]
+	response["quota"] = quota
+
+	if summary != nil {
+		response["item_count"] = summary.ItemCount
+		response["item_counts"] = itemCounts
+		response["first_saved_at"] = summary.FirstSavedAt
+		response["last_saved_at"] = summary.LastSavedAt
+	}
+
+	c.JSON(http.StatusOK, response)
+}
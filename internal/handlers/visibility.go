@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// SetItemVisibility handles changing who can see an item. Documents change together with
+// all their chunks, and the new visibility is applied to their vectors, including the
+// title vector, so retrieval for other people (such as public profile queries) filters
+// on it.
+func (h *Handlers) SetItemVisibility(c *gin.Context) {
+	var req struct {
+		Visibility string `json:"visibility" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+		return
+	}
+	if !database.IsValidVisibility(req.Visibility) {
+		i18n.RespondError(c, http.StatusBadRequest, nil, "Invalid visibility")
+		return
+	}
+
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	ctx := c.Request.Context()
+	userData, err := h.DB.GetUserDataByID(ctx, c.Param("id"))
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			i18n.RespondError(c, http.StatusNotFound, nil, "Item not found")
+		} else {
+			i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch item")
+		}
+		return
+	}
+	if userData.UserID != userID.(string) {
+		i18n.RespondError(c, http.StatusForbidden, nil, "Not authorized to access this item")
+		return
+	}
+	// Chunks share their document's visibility
+	if userData.ParentID != nil {
+		if userData, err = h.DB.GetUserDataByID(ctx, userData.ParentID.Hex()); err != nil {
+			i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch item")
+			return
+		}
+	}
+
+	vectorIDs := []string{userData.VectorID}
+	if isParentType(userData.DataType) {
+		chunks, err := h.DB.GetChunks(ctx, userData.ID.Hex())
+		if err != nil {
+			i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch item")
+			return
+		}
+		vectorIDs = vectorIDs[:0]
+		for _, chunk := range chunks {
+			vectorIDs = append(vectorIDs, chunk.VectorID)
+		}
+		// The title vector carries the document's opening text, so it must not stay
+		// visible either
+		if len(chunks) >= minTitleVectorChunks {
+			vectorIDs = append(vectorIDs, titleVectorID(userData))
+		}
+	}
+
+	// MongoDB is updated first so a failed vector update can simply be retried
+	if err := h.DB.SetVisibility(ctx, userData.ID, userData.UserID, req.Visibility); err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to update visibility")
+		return
+	}
+	for _, vectorID := range vectorIDs {
+		// Vectors still waiting in the outbox are written with the new visibility
+		if err := h.Pinecone.UpdateVectorMetadata(ctx, vectorID, map[string]interface{}{"visibility": req.Visibility}); err != nil {
+			i18n.RespondError(c, http.StatusBadGateway, err, "Failed to update visibility")
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    i18n.T(c, "Visibility updated"),
+		"id":         userData.ID.Hex(),
+		"visibility": req.Visibility,
+	})
+}
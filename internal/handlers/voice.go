@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/models"
+	"github.com/siddhantgupta/forgetai-backend/internal/utils"
+)
+
+// maxVoiceQuerySize is the largest audio clip accepted, matching the transcription API limit
+const maxVoiceQuerySize = 25 << 20
+
+// ttsMaxChars is the longest text synthesized to speech, the speech API's limit
+const ttsMaxChars = 4096
+
+// speechVoices lists the voices accepted for spoken answers
+var speechVoices = map[string]bool{
+	"alloy": true, "echo": true, "fable": true, "onyx": true, "nova": true, "shimmer": true,
+}
+
+// VoiceQuery handles voice query requests. It transcribes the uploaded audio clip,
+// runs the normal query pipeline on the transcript and, when tts=true, returns a
+// spoken answer alongside the text.
+func (h *Handlers) VoiceQuery(c *gin.Context) {
+	// Get authenticated user ID from context
+	userId, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusInternalServerError, nil, "User ID not found in request context")
+		return
+	}
+
+	file, err := c.FormFile("audio")
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Failed to retrieve audio file")
+		return
+	}
+	if file.Size > maxVoiceQuerySize {
+		i18n.RespondError(c, http.StatusRequestEntityTooLarge, nil, "Audio file exceeds the %d MB limit", maxVoiceQuerySize>>20)
+		return
+	}
+
+	wantSpeech := c.PostForm("tts") == "true"
+	voice := strings.ToLower(c.PostForm("voice"))
+	if voice != "" && !speechVoices[voice] {
+		i18n.RespondError(c, http.StatusBadRequest, nil, "Unknown voice: %s", voice)
+		return
+	}
+
+	mode := c.PostForm("mode")
+	if !isValidMode(mode) {
+		i18n.RespondError(c, http.StatusBadRequest, nil, "mode must be \"strict\", \"augmented\" or \"agent\"")
+		return
+	}
+
+	var sourceTypes []string
+	if raw := c.PostForm("source_types"); raw != "" {
+		types, err := normalizeSourceTypes(strings.Split(raw, ","))
+		if err != nil {
+			i18n.RespondError(c, http.StatusBadRequest, err, "Invalid source_types")
+			return
+		}
+		sourceTypes = types
+	}
+
+	after, err := parseOptionalTime(c.PostForm("after"))
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid date range")
+		return
+	}
+	before, err := parseOptionalTime(c.PostForm("before"))
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid date range")
+		return
+	}
+	if err := validateDateRange(after, before); err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid date range")
+		return
+	}
+
+	var minScore *float32
+	if raw := c.PostForm("min_score"); raw != "" {
+		score, err := strconv.ParseFloat(raw, 32)
+		if err != nil {
+			i18n.RespondError(c, http.StatusBadRequest, err, "Invalid min_score")
+			return
+		}
+		value := float32(score)
+		minScore = &value
+		if err := validateMinScore(minScore); err != nil {
+			i18n.RespondError(c, http.StatusBadRequest, err, "Invalid min_score")
+			return
+		}
+	}
+
+	audio, err := file.Open()
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to open audio file")
+		return
+	}
+	defer audio.Close()
+
+	transcript, err := h.OpenAI.Transcribe(c.Request.Context(), file.Filename, audio)
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to transcribe audio")
+		return
+	}
+	transcript = strings.TrimSpace(transcript)
+	if transcript == "" {
+		i18n.RespondError(c, http.StatusBadRequest, nil, "No speech detected in audio")
+		return
+	}
+
+	response, qerr := h.runQuery(c, userId.(string), models.QueryRequest{
+		Text:           transcript,
+		UserId:         userId.(string),
+		SessionId:      c.PostForm("sessionId"),
+		IncludeMatches: c.PostForm("include_matches") == "true",
+		Mode:           mode,
+		SourceTypes:    sourceTypes,
+		After:          after,
+		Before:         before,
+		MinScore:       minScore,
+		ExpandChunks:   c.PostForm("expand_chunks") == "true",
+		Incognito:      c.PostForm("incognito") == "true",
+	})
+	if qerr != nil {
+		qerr.respond(c)
+		return
+	}
+
+	result := models.VoiceQueryResponse{
+		QueryResponse: *response,
+		Transcript:    transcript,
+	}
+
+	if wantSpeech {
+		speech, err := h.OpenAI.TextToSpeech(c.Request.Context(), utils.Truncate(response.Answer, ttsMaxChars-len("...")), voice)
+		if err != nil {
+			i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to synthesize speech")
+			return
+		}
+		result.Audio = base64.StdEncoding.EncodeToString(speech)
+		result.AudioFormat = "mp3"
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// parseOptionalTime parses an RFC3339 form value, returning nil when it is empty
+func parseOptionalTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
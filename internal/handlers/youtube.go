@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+	"github.com/siddhantgupta/forgetai-backend/internal/textnorm"
+)
+
+// SaveYouTube handles YouTube video saving requests. The video's transcript is stored as
+// chunks of consecutive captions, each linking to the moment of the video it starts at,
+// under a parent item holding the video's title and URL.
+func (h *Handlers) SaveYouTube(c *gin.Context) {
+	var req struct {
+		VideoURL string `json:"videoUrl" binding:"required"`
+		chunkingFields
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+		return
+	}
+	options, err := req.options(h.Chunking)
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid chunking parameters")
+		return
+	}
+	options = sourceChunking(options)
+
+	userId, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusInternalServerError, nil, "User ID not found in request context")
+		return
+	}
+
+	videoID, err := services.ParseYouTubeURL(req.VideoURL)
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid YouTube URL")
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	video, err := h.YouTube.GetVideo(ctx, videoID)
+	if err != nil {
+		if errors.Is(err, services.ErrNoYouTubeTranscript) {
+			i18n.RespondError(c, http.StatusUnprocessableEntity, err, "Video has no transcript")
+		} else {
+			i18n.RespondError(c, http.StatusBadGateway, err, "Failed to fetch YouTube video")
+		}
+		return
+	}
+
+	doc := &parentDocument{
+		UserID: userId.(string),
+		Plan:   requestPlan(c),
+		Type:   "youtube",
+		Title:  video.Title,
+		Metadata: map[string]interface{}{
+			"url":                 video.URL,
+			"video_id":            video.ID,
+			"channel":             video.Channel,
+			"duration_seconds":    int(video.Duration.Seconds()),
+			"transcript_language": video.Language,
+			"generated_captions":  video.Generated,
+		},
+		People: []string{video.Channel},
+	}
+	doc.recordChunking(options)
+	previous := ""
+	for _, chunk := range transcriptChunks(video.Transcript, options.MaxChars()) {
+		link := fmt.Sprintf("%s&t=%ds", video.URL, int(chunk.start.Seconds()))
+		prefix := fmt.Sprintf("YouTube video %q by %s (%s at %s): ", video.Title, video.Channel, link, videoTimestamp(chunk.start))
+		doc.Chunks = append(doc.Chunks, documentChunk{
+			Text:       chunk.text,
+			VectorText: prefix + chunk.text,
+			EmbedText:  prefix + withOverlap(previous, chunk.text, options),
+			Metadata: map[string]interface{}{
+				"channel":       video.Channel,
+				"url":           link,
+				"start_seconds": int(chunk.start.Seconds()),
+			},
+		})
+		previous = chunk.text
+	}
+
+	record, vectorIds, err := h.ingestDocument(ctx, doc)
+	if err != nil {
+		respondSaveError(c, err, "Failed to save YouTube video")
+		return
+	}
+
+	h.recordAudit(ctx, &database.AuditEvent{
+		UserID:   userId.(string),
+		Action:   database.AuditActionSave,
+		ItemID:   record.ID.Hex(),
+		ItemType: "youtube",
+		Summary:  video.Title,
+		Details:  map[string]interface{}{"video_url": video.URL, "channel": video.Channel},
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     i18n.T(c, "YouTube video saved successfully"),
+		"user_id":     userId.(string),
+		"type":        "youtube",
+		"title":       video.Title,
+		"channel":     video.Channel,
+		"url":         video.URL,
+		"language":    video.Language,
+		"chunk_count": len(doc.Chunks),
+		"vector_ids":  vectorIds,
+		"timestamp":   time.Now().Format(time.RFC3339),
+	})
+}
+
+// transcriptChunk is a run of consecutive captions with the time the first one starts
+type transcriptChunk struct {
+	start time.Duration
+	text  string
+}
+
+// transcriptChunks joins captions into chunks of at most maxChars, only breaking between
+// captions so each chunk starts at a caption's timestamp
+func transcriptChunks(captions []services.YouTubeCaption, maxChars int) []transcriptChunk {
+	var chunks []transcriptChunk
+	var current []string
+	var start time.Duration
+	size := 0
+	flush := func() {
+		if len(current) > 0 {
+			chunks = append(chunks, transcriptChunk{start, textnorm.Normalize(strings.Join(current, " "))})
+		}
+		current, size = nil, 0
+	}
+
+	for _, caption := range captions {
+		if size > 0 && size+1+len(caption.Text) > maxChars {
+			flush()
+		}
+		if len(current) == 0 {
+			start = caption.Start
+		}
+		current = append(current, caption.Text)
+		size += len(caption.Text) + 1
+	}
+	flush()
+	return chunks
+}
+
+// videoTimestamp formats a position in a video as m:ss, or h:mm:ss for long videos
+func videoTimestamp(d time.Duration) string {
+	seconds := int(d.Seconds())
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
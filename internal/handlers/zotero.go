@@ -0,0 +1,477 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/siddhantgupta/forgetai-backend/internal/chunking"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// maxZoteroChunksPerItem caps the chunks stored for one Zotero item and its attachments
+const maxZoteroChunksPerItem = 200
+
+// zoteroSyncResult counts the changes applied by a Zotero sync
+type zoteroSyncResult struct {
+	Imported int `json:"imported"`
+	Removed  int `json:"removed"`
+	Failed   int `json:"failed"`
+}
+
+// ConnectZotero handles connecting a Zotero library with a user's API key and starts an initial sync
+func (h *Handlers) ConnectZotero(c *gin.Context) {
+	var req struct {
+		APIKey string `json:"apiKey" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid request")
+		return
+	}
+
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	key, err := h.Zotero.GetKey(c.Request.Context(), strings.TrimSpace(req.APIKey))
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadRequest, err, "Invalid Zotero API key")
+		return
+	}
+
+	existing, err := h.DB.GetZoteroIntegration(c.Request.Context(), userID.(string))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to save Zotero integration")
+		return
+	}
+
+	integration := &database.ZoteroIntegration{
+		UserID:         userID.(string),
+		ZoteroUserID:   key.UserID,
+		ZoteroUsername: key.Username,
+		APIKey:         strings.TrimSpace(req.APIKey),
+		Plan:           requestPlan(c),
+	}
+	// Keep the sync position when the same library is reconnected with a new key
+	if existing != nil && existing.ZoteroUserID == key.UserID {
+		integration.LibraryVersion = existing.LibraryVersion
+		integration.CreatedAt = existing.CreatedAt
+	}
+
+	if err := h.DB.UpsertZoteroIntegration(c.Request.Context(), integration); err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to save Zotero integration")
+		return
+	}
+
+	go h.syncZoteroIntegration(context.Background(), integration)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     i18n.T(c, "Zotero library connected, syncing in the background"),
+		"integration": integration,
+	})
+}
+
+// GetZoteroStatus handles retrieving the user's Zotero integration and sync state
+func (h *Handlers) GetZoteroStatus(c *gin.Context) {
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	integration, err := h.DB.GetZoteroIntegration(c.Request.Context(), userID.(string))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch Zotero integration")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"connected":   integration != nil,
+		"integration": integration,
+	})
+}
+
+// DisconnectZotero handles removing the user's Zotero integration. Items already
+// imported are kept.
+func (h *Handlers) DisconnectZotero(c *gin.Context) {
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	removed, err := h.DB.DeleteZoteroIntegration(c.Request.Context(), userID.(string))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to remove Zotero integration")
+		return
+	}
+	if !removed {
+		i18n.RespondError(c, http.StatusNotFound, nil, "Zotero is not connected")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(c, "Zotero library disconnected"),
+	})
+}
+
+// SyncZotero handles on-demand Zotero syncs
+func (h *Handlers) SyncZotero(c *gin.Context) {
+	userID, exists := c.Get("userId")
+	if !exists {
+		i18n.RespondError(c, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	integration, err := h.DB.GetZoteroIntegration(c.Request.Context(), userID.(string))
+	if err != nil {
+		i18n.RespondError(c, http.StatusInternalServerError, err, "Failed to fetch Zotero integration")
+		return
+	}
+	if integration == nil {
+		i18n.RespondError(c, http.StatusNotFound, nil, "Zotero is not connected")
+		return
+	}
+
+	result, ok, err := h.syncZoteroIntegration(c.Request.Context(), integration)
+	if !ok {
+		i18n.RespondError(c, http.StatusConflict, nil, "A Zotero sync is already running")
+		return
+	}
+	if err != nil {
+		i18n.RespondError(c, http.StatusBadGateway, err, "Zotero sync failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(c, "Zotero library synced"),
+		"result":  result,
+	})
+}
+
+// RunZoteroSync syncs every connected Zotero library on the given interval until ctx is
+// cancelled. A non-positive interval disables scheduled syncs.
+func (h *Handlers) RunZoteroSync(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		integrations, err := h.DB.ListZoteroIntegrations(ctx)
+		if err != nil {
+			fmt.Printf("Warning: Failed to list Zotero integrations: %v\n", err)
+			continue
+		}
+		for _, integration := range integrations {
+			if ctx.Err() != nil {
+				return
+			}
+			h.syncZoteroIntegration(ctx, integration)
+		}
+	}
+}
+
+// syncZoteroIntegration runs a sync and records its outcome. It reports false without
+// syncing if a sync for the same user is already running.
+func (h *Handlers) syncZoteroIntegration(ctx context.Context, integration *database.ZoteroIntegration) (*zoteroSyncResult, bool, error) {
+	if _, running := h.zoteroSyncs.LoadOrStore(integration.UserID, true); running {
+		return nil, false, nil
+	}
+	defer h.zoteroSyncs.Delete(integration.UserID)
+
+	ctx = services.WithBillingUser(ctx, integration.UserID)
+	result, version, err := h.syncZotero(ctx, integration)
+
+	lastError := ""
+	switch {
+	case err != nil:
+		lastError = err.Error()
+		version = integration.LibraryVersion
+	case result.Failed > 0:
+		lastError = fmt.Sprintf("%d item(s) failed to import", result.Failed)
+	}
+	if stateErr := h.DB.UpdateZoteroSyncState(ctx, integration.UserID, version, lastError); stateErr != nil {
+		fmt.Printf("Warning: Failed to record Zotero sync state for %s: %v\n", integration.UserID, stateErr)
+	}
+	if err != nil {
+		fmt.Printf("Warning: Zotero sync failed for %s: %v\n", integration.UserID, err)
+	}
+
+	return result, true, err
+}
+
+// syncZotero imports items changed since the last synced library version and removes
+// deleted ones, returning the library version synced up to
+func (h *Handlers) syncZotero(ctx context.Context, integration *database.ZoteroIntegration) (*zoteroSyncResult, int64, error) {
+	apiKey, zoteroUserID, since := integration.APIKey, integration.ZoteroUserID, integration.LibraryVersion
+
+	collections, err := h.Zotero.GetCollectionNames(ctx, apiKey, zoteroUserID)
+	if err != nil {
+		return nil, 0, err
+	}
+	changed, version, err := h.Zotero.GetChangedItems(ctx, apiKey, zoteroUserID, since)
+	if err != nil {
+		return nil, 0, err
+	}
+	var deleted []string
+	if since > 0 {
+		if deleted, err = h.Zotero.GetDeletedItemKeys(ctx, apiKey, zoteroUserID, since); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	// Work out which top-level items to re-import and which to remove. A changed or
+	// deleted note/attachment re-imports the item it belongs to.
+	var affected []string
+	seen := make(map[string]bool)
+	remove := make(map[string]bool)
+	addAffected := func(key string) {
+		if !seen[key] {
+			seen[key] = true
+			affected = append(affected, key)
+		}
+	}
+	for _, item := range changed {
+		switch {
+		case item.IsTopLevel() && item.Data.Deleted:
+			remove[item.Key] = true
+		case item.IsTopLevel():
+			addAffected(item.Key)
+		case item.Data.ParentItem != "":
+			addAffected(item.Data.ParentItem)
+		}
+	}
+	for _, key := range deleted {
+		if parent, err := h.DB.GetUserDataByMetadata(ctx, integration.UserID, "zotero", "zotero_children", key); err == nil {
+			if parentKey, ok := parent.Metadata["zotero_key"].(string); ok {
+				addAffected(parentKey)
+				continue
+			}
+		}
+		remove[key] = true
+	}
+
+	result := &zoteroSyncResult{}
+	for key := range remove {
+		removed, err := h.removeZoteroItem(ctx, integration.UserID, key)
+		if err != nil {
+			fmt.Printf("Warning: Failed to remove Zotero item %s: %v\n", key, err)
+			result.Failed++
+		} else if removed {
+			result.Removed++
+		}
+	}
+	for _, key := range affected {
+		if remove[key] {
+			continue
+		}
+		if err := h.importZoteroItem(ctx, integration, key, collections); err != nil {
+			fmt.Printf("Warning: Failed to import Zotero item %s: %v\n", key, err)
+			result.Failed++
+			continue
+		}
+		result.Imported++
+	}
+
+	return result, version, nil
+}
+
+// importZoteroItem imports a top-level item with its notes and PDF attachments, replacing
+// any earlier import of it
+func (h *Handlers) importZoteroItem(ctx context.Context, integration *database.ZoteroIntegration, key string, collections map[string]string) error {
+	apiKey, zoteroUserID := integration.APIKey, integration.ZoteroUserID
+
+	item, err := h.Zotero.GetItem(ctx, apiKey, zoteroUserID, key)
+	if err != nil {
+		return err
+	}
+	children, err := h.Zotero.GetChildren(ctx, apiKey, zoteroUserID, key)
+	if err != nil {
+		return err
+	}
+
+	options := sourceChunking(h.Chunking)
+	doc := buildZoteroDocument(integration.UserID, item, collections, options)
+	doc.Plan = integration.Plan
+	childKeys := make([]string, 0, len(children))
+	for _, child := range children {
+		childKeys = append(childKeys, child.Key)
+		if child.Data.Deleted {
+			continue
+		}
+		switch {
+		case child.Data.ItemType == "note":
+			addZoteroChunks(doc, item, "note", services.HTMLToText(child.Data.Note), options)
+		case child.IsStoredPDF():
+			file, err := h.Zotero.DownloadFile(ctx, apiKey, zoteroUserID, child.Key)
+			if err != nil {
+				fmt.Printf("Warning: Failed to download Zotero attachment %s: %v\n", child.Key, err)
+				continue
+			}
+			text, err := extractPDFText(bytes.NewReader(file), int64(len(file)))
+			if err != nil {
+				fmt.Printf("Warning: Failed to read Zotero attachment %s: %v\n", child.Key, err)
+				continue
+			}
+			if pages, err := pdfPageCount(bytes.NewReader(file), int64(len(file))); err == nil {
+				doc.Pages += pages
+			}
+			addZoteroChunks(doc, item, "pdf", text, options)
+		}
+	}
+	doc.Metadata["zotero_children"] = childKeys
+	if len(doc.Chunks) > maxZoteroChunksPerItem {
+		fmt.Printf("Warning: Zotero item %s has %d chunks, keeping the first %d\n", key, len(doc.Chunks), maxZoteroChunksPerItem)
+		doc.Chunks = doc.Chunks[:maxZoteroChunksPerItem]
+	}
+
+	// Import the new version before removing the old one so the item is never missing
+	previous, err := h.DB.GetUserDataByMetadata(ctx, integration.UserID, "zotero", "zotero_key", key)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return err
+	}
+
+	record, _, err := h.ingestDocument(ctx, doc)
+	if err != nil {
+		return err
+	}
+
+	if previous != nil {
+		if err := h.deleteItem(ctx, previous); err != nil {
+			fmt.Printf("Warning: Failed to remove previous import of Zotero item %s: %v\n", key, err)
+		}
+	}
+
+	h.recordAudit(ctx, &database.AuditEvent{
+		UserID:   integration.UserID,
+		Action:   database.AuditActionSave,
+		ItemID:   record.ID.Hex(),
+		ItemType: "zotero",
+		Summary:  doc.Title,
+		Details:  map[string]interface{}{"source": "zotero_sync", "zotero_key": key, "chunk_count": len(doc.Chunks)},
+	})
+
+	return nil
+}
+
+// removeZoteroItem deletes the import of a Zotero item, reporting whether there was one
+func (h *Handlers) removeZoteroItem(ctx context.Context, userID, key string) (bool, error) {
+	existing, err := h.DB.GetUserDataByMetadata(ctx, userID, "zotero", "zotero_key", key)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := h.deleteItem(ctx, existing); err != nil {
+		return false, err
+	}
+
+	h.recordAudit(ctx, &database.AuditEvent{
+		UserID:   userID,
+		Action:   database.AuditActionDelete,
+		ItemID:   existing.ID.Hex(),
+		ItemType: "zotero",
+		Summary:  existing.DataValue,
+		Details:  map[string]interface{}{"source": "zotero_sync", "zotero_key": key},
+	})
+	return true, nil
+}
+
+// buildZoteroDocument creates the parent document for an item with its bibliographic chunk.
+// Zotero collections are recorded by name in the item's metadata.
+func buildZoteroDocument(userID string, item *services.ZoteroItem, collections map[string]string, options chunking.Options) *parentDocument {
+	title := strings.TrimSpace(item.Data.Title)
+	if title == "" {
+		title = "Untitled Zotero item"
+	}
+
+	var authors []string
+	for _, creator := range item.Data.Creators {
+		name := strings.TrimSpace(creator.FirstName + " " + creator.LastName)
+		if name == "" {
+			name = creator.Name
+		}
+		if name != "" {
+			authors = append(authors, name)
+		}
+	}
+	var collectionNames []string
+	for _, collectionKey := range item.Data.Collections {
+		if name, ok := collections[collectionKey]; ok {
+			collectionNames = append(collectionNames, name)
+		}
+	}
+
+	doc := &parentDocument{
+		UserID: userID,
+		Type:   "zotero",
+		Title:  title,
+		People: authors,
+		Metadata: map[string]interface{}{
+			"zotero_key":     item.Key,
+			"zotero_version": item.Version,
+			"item_type":      item.Data.ItemType,
+			"authors":        authors,
+			"collections":    collectionNames,
+			"url":            item.Data.URL,
+			"doi":            item.Data.DOI,
+		},
+	}
+	doc.recordChunking(options)
+
+	var sb strings.Builder
+	sb.WriteString(title)
+	if len(authors) > 0 {
+		sb.WriteString("\nAuthors: " + strings.Join(authors, ", "))
+	}
+	if item.Data.Date != "" {
+		sb.WriteString("\nDate: " + item.Data.Date)
+	}
+	if len(collectionNames) > 0 {
+		sb.WriteString("\nCollections: " + strings.Join(collectionNames, ", "))
+	}
+	if item.Data.DOI != "" {
+		sb.WriteString("\nDOI: " + item.Data.DOI)
+	}
+	if item.Data.AbstractNote != "" {
+		sb.WriteString("\n\n" + item.Data.AbstractNote)
+	}
+	addZoteroChunks(doc, item, "metadata", sb.String(), options)
+
+	return doc
+}
+
+// addZoteroChunks chunks one section of a Zotero item into the document
+func addZoteroChunks(doc *parentDocument, item *services.ZoteroItem, section, text string, options chunking.Options) {
+	splitProse(text, options, func(chunk, withContext string) {
+		doc.Chunks = append(doc.Chunks, documentChunk{
+			Text:       chunk,
+			VectorText: fmt.Sprintf("Zotero item %s (%s): %s", doc.Title, section, chunk),
+			EmbedText:  fmt.Sprintf("Zotero item %s (%s): %s", doc.Title, section, withContext),
+			Metadata: map[string]interface{}{
+				"section":    section,
+				"zotero_key": item.Key,
+			},
+		})
+	})
+}
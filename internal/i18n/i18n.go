@@ -0,0 +1,144 @@
+package i18n
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultLanguage is used when the client doesn't ask for a supported language
+const DefaultLanguage = "en"
+
+// contextKey is the gin context key holding the negotiated language
+const contextKey = "lang"
+
+// SupportedLanguages lists the languages with message catalogs
+var SupportedLanguages = []string{"en", "hi", "es"}
+
+// Middleware negotiates the response language from the Accept-Language header
+// and stores it in the request context for handlers
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lang := ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+		c.Set(contextKey, lang)
+		c.Header("Content-Language", lang)
+		c.Next()
+	}
+}
+
+// Language returns the negotiated language for the request
+func Language(c *gin.Context) string {
+	if lang, ok := c.Get(contextKey); ok {
+		return lang.(string)
+	}
+	return ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+}
+
+// ParseAcceptLanguage picks the highest-weighted supported language from an Accept-Language header
+func ParseAcceptLanguage(header string) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		q := 1.0
+		tag := part
+		if idx := strings.Index(part, ";"); idx >= 0 {
+			tag = strings.TrimSpace(part[:idx])
+			param := strings.TrimSpace(part[idx+1:])
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+
+		// Only the primary subtag matters (es-MX -> es)
+		base := strings.ToLower(strings.SplitN(tag, "-", 2)[0])
+		candidates = append(candidates, candidate{lang: base, q: q})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+
+	for _, cand := range candidates {
+		if cand.q <= 0 {
+			continue
+		}
+		if _, ok := catalogs[cand.lang]; ok || cand.lang == DefaultLanguage {
+			return cand.lang
+		}
+	}
+	return DefaultLanguage
+}
+
+// Translate returns the message in the given language, falling back to the English
+// message itself. The message may contain fmt verbs filled in from args.
+func Translate(lang, msg string, args ...interface{}) string {
+	if catalog, ok := catalogs[lang]; ok {
+		if translated, ok := catalog[msg]; ok {
+			msg = translated
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// T translates a message into the request's negotiated language
+func T(c *gin.Context, msg string, args ...interface{}) string {
+	return Translate(Language(c), msg, args...)
+}
+
+// ErrorCode returns the stable machine-readable code for an HTTP error status
+func ErrorCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "bad_request"
+	case http.StatusUnauthorized:
+		return "unauthorized"
+	case http.StatusForbidden:
+		return "forbidden"
+	case http.StatusNotFound:
+		return "not_found"
+	case http.StatusConflict:
+		return "conflict"
+	case http.StatusRequestEntityTooLarge:
+		return "payload_too_large"
+	case http.StatusTooManyRequests:
+		return "rate_limited"
+	case http.StatusServiceUnavailable:
+		return "unavailable"
+	default:
+		if status >= 500 {
+			return "internal_error"
+		}
+		return strings.ToLower(strings.ReplaceAll(http.StatusText(status), " ", "_"))
+	}
+}
+
+// RespondError writes the standard error envelope {"error": message, "code": code}.
+// The message is localized for the request; err, when set, is appended as detail.
+func RespondError(c *gin.Context, status int, err error, msg string, args ...interface{}) {
+	message := T(c, msg, args...)
+	if err != nil {
+		message += ": " + err.Error()
+	}
+	c.JSON(status, gin.H{
+		"error": message,
+		"code":  ErrorCode(status),
+	})
+}
@@ -0,0 +1,588 @@
+package i18n
+
+// catalogs maps a language to translations keyed by the English message.
+// English needs no catalog: untranslated messages are returned as-is.
+var catalogs = map[string]map[string]string{
+	"hi": hindi,
+	"es": spanish,
+}
+
+var hindi = map[string]string{
+	// Authentication
+	"Authorization header is required":          "Authorization हेडर आवश्यक है",
+	"Authorization header must be Bearer token": "Authorization हेडर Bearer टोकन होना चाहिए",
+	"Invalid token":                                        "अमान्य टोकन",
+	"User ID not found in token":                           "टोकन में यूज़र ID नहीं मिली",
+	"User ID not found in request context":                 "अनुरोध में यूज़र ID नहीं मिली",
+	"User not authenticated":                               "यूज़र प्रमाणित नहीं है",
+	"User ID in request does not match authenticated user": "अनुरोध की यूज़र ID प्रमाणित यूज़र से मेल नहीं खाती",
+	"Unauthorized":                                         "अनधिकृत",
+	"Rate limit exceeded. Maximum %d requests per API endpoint per day.": "दर सीमा पार हो गई। प्रति API एंडपॉइंट प्रति दिन अधिकतम %d अनुरोध।",
+	"Try again tomorrow": "कल फिर से प्रयास करें",
+	"Try again later":    "बाद में फिर से प्रयास करें",
+	"Rate limit exceeded. Maximum %d requests per hour.":        "दर सीमा पार हो गई। प्रति घंटे अधिकतम %d अनुरोध।",
+	"This profile has answered its maximum questions for today": "इस प्रोफ़ाइल ने आज के अधिकतम प्रश्नों के उत्तर दे दिए हैं",
+	"Daily spending limit of $%.2f reached":                     "दैनिक खर्च सीमा $%.2f पूरी हो गई",
+
+	// Validation
+	"Invalid request":                     "अमान्य अनुरोध",
+	"Invalid status":                      "अमान्य status",
+	"Missing required parameter: text":    "आवश्यक पैरामीटर नहीं है: text",
+	"Missing required parameter: query":   "आवश्यक पैरामीटर नहीं है: query",
+	"Missing required parameter: name":    "आवश्यक पैरामीटर नहीं है: name",
+	"Invalid tweet URL format":            "ट्वीट URL का प्रारूप अमान्य है",
+	"X API rate limit reached":            "X API की दर सीमा पूरी हो गई",
+	"Tweet not found":                     "ट्वीट नहीं मिला",
+	"User ID required":                    "यूज़र ID आवश्यक है",
+	"limit must be a positive integer":    "limit एक धनात्मक पूर्णांक होना चाहिए",
+	"admin and reason are required":       "admin और reason आवश्यक हैं",
+	"ttl_minutes must be 1 to %d":         "ttl_minutes 1 और %d के बीच होना चाहिए",
+	"bookmarks must have 1 to %d items":   "bookmarks में 1 से %d आइटम होने चाहिए",
+	"imports must have 1 to %d items":     "imports में 1 से %d आइटम होने चाहिए",
+	"days must be a positive integer":     "days एक धनात्मक पूर्णांक होना चाहिए",
+	"object is not a backup":              "object एक बैकअप नहीं है",
+	"before must be an RFC3339 timestamp": "before एक RFC3339 टाइमस्टैम्प होना चाहिए",
+	"Unknown notification channel: %s":    "अज्ञात सूचना चैनल: %s",
+	"Unknown notification type: %s":       "अज्ञात सूचना प्रकार: %s",
+	"Unknown abuse flag status: %s":       "अज्ञात दुरुपयोग फ़्लैग स्थिति: %s",
+	"Unknown digest frequency: %s":        "अज्ञात डाइजेस्ट आवृत्ति: %s",
+	"No readable text found in document":  "दस्तावेज़ में पढ़ने योग्य टेक्स्ट नहीं मिला",
+	"No text found in tweet":              "ट्वीट में कोई टेक्स्ट नहीं मिला",
+
+	// Not found / forbidden
+	"Item not found":                                      "आइटम नहीं मिला",
+	"User not found":                                      "यूज़र नहीं मिला",
+	"Impersonated requests are read-only":                 "इम्परसोनेट किए गए अनुरोध केवल पढ़ने के लिए हैं",
+	"Session not found":                                   "सत्र नहीं मिला",
+	"Too many pinned items":                               "बहुत अधिक पिन किए गए आइटम",
+	"Chunk not found":                                     "खंड नहीं मिला",
+	"Public profile not found":                            "सार्वजनिक प्रोफ़ाइल नहीं मिली",
+	"Username is already taken":                           "यह यूज़रनेम पहले से लिया जा चुका है",
+	"Dead letter not found":                               "विफल कार्य नहीं मिला",
+	"Abuse flag not found":                                "दुरुपयोग फ़्लैग नहीं मिला",
+	"Contradiction not found":                             "विरोधाभास नहीं मिला",
+	"Push subscription not found":                         "पुश सदस्यता नहीं मिली",
+	"No original file is stored for this item":            "इस आइटम के लिए कोई मूल फ़ाइल संग्रहीत नहीं है",
+	"Telegram is not linked":                              "टेलीग्राम लिंक नहीं है",
+	"Job not found":                                       "जॉब नहीं मिला",
+	"status must be succeeded, failed or skipped":         "status succeeded, failed या skipped होना चाहिए",
+	"Preview token not found or expired":                  "प्रीव्यू टोकन नहीं मिला या समाप्त हो गया",
+	"Not authorized to access this session":               "इस सत्र तक पहुँचने की अनुमति नहीं है",
+	"Not authorized to delete this item":                  "इस आइटम को हटाने की अनुमति नहीं है",
+	"Cannot delete the only chunk of a document":          "दस्तावेज़ का एकमात्र खंड हटाया नहीं जा सकता",
+	"Preview token does not belong to authenticated user": "प्रीव्यू टोकन प्रमाणित यूज़र का नहीं है",
+	"Item limit of %d reached for the %s plan":            "%[2]s प्लान की %[1]d आइटम की सीमा पूरी हो गई",
+	"Character limit of %d reached for the %s plan":       "%[2]s प्लान की %[1]d अक्षरों की सीमा पूरी हो गई",
+	"Vector limit of %d reached for the %s plan":          "%[2]s प्लान की %[1]d वेक्टर की सीमा पूरी हो गई",
+
+	// Document size limits
+	"Documents are limited to %d pages on the %s plan":                           "%[2]s प्लान पर दस्तावेज़ अधिकतम %[1]d पृष्ठों के हो सकते हैं",
+	"Documents are limited to %d chunks on the %s plan":                          "%[2]s प्लान पर दस्तावेज़ अधिकतम %[1]d खंडों के हो सकते हैं",
+	"Documents are limited to %d pages on the %s plan; the %s plan allows more":  "%[2]s प्लान पर दस्तावेज़ अधिकतम %[1]d पृष्ठों के हो सकते हैं; %[3]s प्लान में इससे अधिक की अनुमति है",
+	"Documents are limited to %d chunks on the %s plan; the %s plan allows more": "%[2]s प्लान पर दस्तावेज़ अधिकतम %[1]d खंडों के हो सकते हैं; %[3]s प्लान में इससे अधिक की अनुमति है",
+
+	// Configuration
+	"Web Push is not configured":                "वेब पुश कॉन्फ़िगर नहीं है",
+	"Telegram is not configured":                "टेलीग्राम कॉन्फ़िगर नहीं है",
+	"Slack is not configured":                   "Slack कॉन्फ़िगर नहीं है",
+	"File storage is not configured":            "फ़ाइल स्टोरेज कॉन्फ़िगर नहीं है",
+	"Internal authentication is not configured": "आंतरिक प्रमाणीकरण कॉन्फ़िगर नहीं है",
+	"Impersonation is not configured":           "इम्परसोनेशन कॉन्फ़िगर नहीं है",
+	"Backups are not configured":                "बैकअप कॉन्फ़िगर नहीं हैं",
+
+	// Server errors
+	"Failed to get embedding":                      "एम्बेडिंग प्राप्त करने में विफल",
+	"Failed to query database":                     "डेटाबेस से पूछताछ विफल",
+	"Failed to save data":                          "डेटा सहेजने में विफल",
+	"Failed to save tweet":                         "ट्वीट सहेजने में विफल",
+	"Failed to save chunk %d":                      "खंड %d सहेजने में विफल",
+	"Failed to get AI response":                    "AI उत्तर प्राप्त करने में विफल",
+	"Request timed out":                            "अनुरोध का समय समाप्त हो गया",
+	"Failed to fetch user data":                    "यूज़र डेटा प्राप्त करने में विफल",
+	"Failed to fetch item":                         "आइटम प्राप्त करने में विफल",
+	"Failed to fetch matching items":               "मेल खाने वाले आइटम प्राप्त करने में विफल",
+	"Failed to fetch activity":                     "गतिविधि प्राप्त करने में विफल",
+	"Failed to fetch query analytics":              "खोज विश्लेषण प्राप्त करने में विफल",
+	"Failed to build gap report":                   "ज्ञान अंतराल रिपोर्ट बनाने में विफल",
+	"Failed to fetch analytics":                    "विश्लेषण प्राप्त करने में विफल",
+	"Failed to fetch dead letters":                 "विफल कार्य प्राप्त करने में विफल",
+	"Failed to retry dead letter":                  "विफल कार्य दोबारा चलाने में विफल",
+	"Failed to fetch abuse flags":                  "दुरुपयोग फ़्लैग प्राप्त करने में विफल",
+	"Failed to review abuse flag":                  "दुरुपयोग फ़्लैग की समीक्षा करने में विफल",
+	"Failed to delete item":                        "आइटम हटाने में विफल",
+	"Failed to clear cache":                        "कैश साफ़ करने में विफल",
+	"Failed to create request":                     "अनुरोध बनाने में विफल",
+	"Failed to fetch tweet":                        "ट्वीट प्राप्त करने में विफल",
+	"Failed to parse tweet data":                   "ट्वीट डेटा पढ़ने में विफल",
+	"X API returned status: %d":                    "X API ने स्थिति लौटाई: %d",
+	"Failed to retrieve document file":             "दस्तावेज़ फ़ाइल प्राप्त करने में विफल",
+	"Failed to open document file":                 "दस्तावेज़ फ़ाइल खोलने में विफल",
+	"Failed to read document":                      "दस्तावेज़ पढ़ने में विफल",
+	"Document must be PDF, DOCX, EPUB or Markdown": "दस्तावेज़ PDF, DOCX, EPUB या Markdown फ़ाइल होना चाहिए",
+	"Failed to save document metadata":             "दस्तावेज़ मेटाडेटा सहेजने में विफल",
+	"Failed to save code":                          "कोड सहेजने में विफल",
+	"Invalid GitHub repository URL":                "अमान्य GitHub रिपॉजिटरी URL",
+	"Failed to fetch GitHub repository":            "GitHub रिपॉजिटरी प्राप्त करने में विफल",
+	"No README or docs found in repository":        "रिपॉजिटरी में कोई README या दस्तावेज़ नहीं मिला",
+	"Failed to save GitHub repository":             "GitHub रिपॉजिटरी सहेजने में विफल",
+	"Invalid gist URL":                             "अमान्य Gist URL",
+	"Failed to fetch gist":                         "Gist प्राप्त करने में विफल",
+	"Gist has no content":                          "Gist में कोई सामग्री नहीं है",
+	"Failed to save gist":                          "Gist सहेजने में विफल",
+	"Invalid Hacker News URL":                      "अमान्य Hacker News URL",
+	"Failed to fetch Hacker News item":             "Hacker News आइटम प्राप्त करने में विफल",
+	"Hacker News item not found":                   "Hacker News आइटम नहीं मिला",
+	"Failed to save Hacker News item":              "Hacker News आइटम सहेजने में विफल",
+	"Invalid Reddit URL":                           "अमान्य Reddit URL",
+	"Failed to fetch Reddit post":                  "Reddit पोस्ट प्राप्त करने में विफल",
+	"Failed to save Reddit post":                   "Reddit पोस्ट सहेजने में विफल",
+	"Invalid YouTube URL":                          "अमान्य YouTube URL",
+	"Failed to fetch YouTube video":                "YouTube वीडियो प्राप्त करने में विफल",
+	"Video has no transcript":                      "वीडियो का कोई ट्रांसक्रिप्ट नहीं है",
+	"Failed to save YouTube video":                 "YouTube वीडियो सहेजने में विफल",
+	"Invalid Zotero API key":                       "अमान्य Zotero API कुंजी",
+	"Failed to save Zotero integration":            "Zotero एकीकरण सहेजने में विफल",
+	"Failed to fetch Zotero integration":           "Zotero एकीकरण प्राप्त करने में विफल",
+	"Failed to remove Zotero integration":          "Zotero एकीकरण हटाने में विफल",
+	"Zotero is not connected":                      "Zotero जुड़ा नहीं है",
+	"A Zotero sync is already running":             "Zotero सिंक पहले से चल रहा है",
+	"A backup or restore is already running":       "बैकअप या रीस्टोर पहले से चल रहा है",
+	"Zotero sync failed":                           "Zotero सिंक विफल रहा",
+	"Invalid Notion access token":                  "अमान्य Notion एक्सेस टोकन",
+	"Failed to save Notion integration":            "Notion एकीकरण सहेजने में विफल",
+	"Failed to fetch Notion integration":           "Notion एकीकरण प्राप्त करने में विफल",
+	"Failed to remove Notion integration":          "Notion एकीकरण हटाने में विफल",
+	"Notion is not connected":                      "Notion जुड़ा नहीं है",
+	"A Notion sync is already running":             "Notion सिंक पहले से चल रहा है",
+	"Notion sync failed":                           "Notion सिंक विफल रहा",
+	"Failed to create Telegram link code":          "टेलीग्राम लिंक कोड बनाने में विफल",
+	"Failed to fetch Telegram link":                "टेलीग्राम लिंक प्राप्त करने में विफल",
+	"Failed to remove Telegram link":               "टेलीग्राम लिंक हटाने में विफल",
+	"Failed to create Slack install link":          "Slack इंस्टॉल लिंक बनाने में विफल",
+	"Failed to fetch Slack integration":            "Slack एकीकरण प्राप्त करने में विफल",
+	"Failed to save Slack integration":             "Slack एकीकरण सहेजने में विफल",
+	"Failed to remove Slack integration":           "Slack एकीकरण हटाने में विफल",
+	"Failed to install Slack app":                  "Slack ऐप इंस्टॉल करने में विफल",
+	"Invalid or expired Slack install link":        "Slack इंस्टॉल लिंक अमान्य है या समाप्त हो गया",
+	"Slack install was cancelled":                  "Slack इंस्टॉल रद्द कर दिया गया",
+	"Slack is not connected":                       "Slack जुड़ा नहीं है",
+	"Failed to download file":                      "फ़ाइल डाउनलोड करने में विफल",
+	"Invalid GitHub token":                         "अमान्य GitHub टोकन",
+	"Failed to save GitHub integration":            "GitHub एकीकरण सहेजने में विफल",
+	"Failed to fetch GitHub integration":           "GitHub एकीकरण प्राप्त करने में विफल",
+	"Failed to remove GitHub integration":          "GitHub एकीकरण हटाने में विफल",
+	"GitHub is not connected":                      "GitHub जुड़ा नहीं है",
+	"A GitHub sync is already running":             "GitHub सिंक पहले से चल रहा है",
+	"GitHub sync failed":                           "GitHub सिंक विफल रहा",
+	"Failed to start bookmark import":              "बुकमार्क आयात शुरू करने में विफल",
+	"Failed to start import":                       "आयात शुरू करने में विफल",
+	"Failed to start document processing":          "दस्तावेज़ प्रोसेसिंग शुरू करने में विफल",
+	"Failed to fetch job":                          "जॉब प्राप्त करने में विफल",
+	"Failed to fetch jobs":                         "जॉब्स प्राप्त करने में विफल",
+	"Meeting transcription is not configured":      "मीटिंग ट्रांसक्रिप्शन कॉन्फ़िगर नहीं है",
+	"Failed to retrieve recording file":            "रिकॉर्डिंग फ़ाइल प्राप्त करने में विफल",
+	"Recording must be at most %d MB":              "रिकॉर्डिंग अधिकतम %d MB की होनी चाहिए",
+	"Invalid speakers":                             "अमान्य वक्ता",
+	"Invalid visibility":                           "अमान्य दृश्यता",
+	"Invalid chunk index":                          "अमान्य खंड क्रमांक",
+	"Item has no chunks":                           "इस आइटम में कोई खंड नहीं है",
+	"Item type cannot be re-chunked":               "इस प्रकार के आइटम को फिर से खंडित नहीं किया जा सकता",
+	"Invalid chunking parameters":                  "अमान्य चंकिंग पैरामीटर",
+	"Invalid username":                             "अमान्य यूज़रनेम",
+	"Invalid collections":                          "अमान्य संग्रह",
+	"Question must be at most %d characters":       "प्रश्न अधिकतम %d अक्षरों का होना चाहिए",
+	"Invalid speakers_expected":                    "अमान्य speakers_expected",
+	"Invalid meeting_date, expected RFC3339":       "अमान्य meeting_date, RFC3339 अपेक्षित",
+	"Failed to open recording file":                "रिकॉर्डिंग फ़ाइल खोलने में विफल",
+	"Failed to retrieve image file":                "छवि फ़ाइल प्राप्त करने में विफल",
+	"Image must be at most %d MB":                  "छवि अधिकतम %d MB की होनी चाहिए",
+	"Failed to open image file":                    "छवि फ़ाइल खोलने में विफल",
+	"Image must be PNG, JPEG, GIF or WebP":         "छवि PNG, JPEG, GIF या WebP होनी चाहिए",
+	"Failed to read image":                         "छवि पढ़ने में विफल",
+	"Failed to save image":                         "छवि सहेजने में विफल",
+	"Failed to upload recording for transcription": "ट्रांसक्रिप्शन के लिए रिकॉर्डिंग अपलोड करने में विफल",
+	"Failed to save meeting metadata":              "मीटिंग मेटाडेटा सहेजने में विफल",
+	"Failed to create preview":                     "प्रीव्यू बनाने में विफल",
+	"Failed to store preview":                      "प्रीव्यू सहेजने में विफल",
+	"Failed to load preview":                       "प्रीव्यू लोड करने में विफल",
+	"Failed to parse preview":                      "प्रीव्यू पढ़ने में विफल",
+	"Failed to fetch notification preferences":     "सूचना प्राथमिकताएँ प्राप्त करने में विफल",
+	"Failed to save notification preferences":      "सूचना प्राथमिकताएँ सहेजने में विफल",
+	"Failed to build digest":                       "डाइजेस्ट बनाने में विफल",
+	"Failed to fetch digest schedule":              "डाइजेस्ट शेड्यूल प्राप्त करने में विफल",
+	"Failed to save digest schedule":               "डाइजेस्ट शेड्यूल सहेजने में विफल",
+	"Failed to update visibility":                  "दृश्यता अपडेट करने में विफल",
+	"Failed to delete chunk":                       "खंड हटाने में विफल",
+	"Failed to re-chunk item":                      "आइटम को फिर से खंडित करने में विफल",
+	"Failed to fetch public profile":               "सार्वजनिक प्रोफ़ाइल प्राप्त करने में विफल",
+	"Failed to save public profile":                "सार्वजनिक प्रोफ़ाइल सहेजने में विफल",
+	"Failed to delete public profile":              "सार्वजनिक प्रोफ़ाइल हटाने में विफल",
+	"Failed to save push subscription":             "पुश सदस्यता सहेजने में विफल",
+	"Failed to remove push subscription":           "पुश सदस्यता हटाने में विफल",
+	"Failed to list people":                        "लोगों की सूची प्राप्त करने में विफल",
+	"Failed to retrieve items":                     "आइटम प्राप्त करने में विफल",
+	"Failed to get storage usage":                  "स्टोरेज उपयोग प्राप्त करने में विफल",
+	"Failed to get usage history":                  "उपयोग इतिहास प्राप्त करने में विफल",
+	"Failed to list users":                         "यूज़र की सूची प्राप्त करने में विफल",
+	"Failed to list backups":                       "बैकअप की सूची प्राप्त करने में विफल",
+	"Failed to list contradictions":                "विरोधाभासों की सूची प्राप्त करने में विफल",
+	"Failed to scan for contradictions":            "विरोधाभास जाँचने में विफल",
+	"Failed to dismiss contradiction":              "विरोधाभास खारिज करने में विफल",
+	"Failed to retrieve user":                      "यूज़र प्राप्त करने में विफल",
+	"Failed to look up users in Clerk":             "Clerk में यूज़र खोजने में विफल",
+	"Failed to create impersonation token":         "इम्परसोनेशन टोकन बनाने में विफल",
+
+	// Warnings
+	"%d %s request(s) left today":             "आज %[2]s के %[1]d अनुरोध शेष हैं",
+	"%d of %d items used on the %s plan":      "%[3]s प्लान पर %[2]d में से %[1]d आइटम उपयोग हुए",
+	"%d of %d characters used on the %s plan": "%[3]s प्लान पर %[2]d में से %[1]d अक्षर उपयोग हुए",
+	"%d of %d vectors used on the %s plan":    "%[3]s प्लान पर %[2]d में से %[1]d वेक्टर उपयोग हुए",
+
+	// Status messages
+	"Data saved successfully":                                                  "डेटा सफलतापूर्वक सहेजा गया",
+	"Query successful":                                                         "पूछताछ सफल",
+	"Session reset successfully":                                               "सत्र सफलतापूर्वक रीसेट किया गया",
+	"Tweet saved successfully":                                                 "ट्वीट सफलतापूर्वक सहेजा गया",
+	"PDF processed and stored successfully":                                    "PDF संसाधित और सफलतापूर्वक सहेजी गई",
+	"Code saved successfully":                                                  "कोड सफलतापूर्वक सहेजा गया",
+	"GitHub repository saved successfully":                                     "GitHub रिपॉजिटरी सफलतापूर्वक सहेजी गई",
+	"Gist saved successfully":                                                  "Gist सफलतापूर्वक सहेजा गया",
+	"Hacker News item saved successfully":                                      "Hacker News आइटम सफलतापूर्वक सहेजा गया",
+	"Reddit post saved successfully":                                           "Reddit पोस्ट सफलतापूर्वक सहेजी गई",
+	"Zotero library connected, syncing in the background":                      "Zotero लाइब्रेरी जुड़ गई, पृष्ठभूमि में सिंक हो रही है",
+	"Zotero library disconnected":                                              "Zotero लाइब्रेरी डिस्कनेक्ट की गई",
+	"Zotero library synced":                                                    "Zotero लाइब्रेरी सिंक की गई",
+	"Dry run: the document was not stored":                                     "ड्राई रन: दस्तावेज़ सहेजा नहीं गया",
+	"Send the code to the bot to link your Telegram chat":                      "अपनी टेलीग्राम चैट लिंक करने के लिए बॉट को कोड भेजें",
+	"Telegram chat unlinked":                                                   "टेलीग्राम चैट अनलिंक की गई",
+	"Slack workspace connected":                                                "Slack वर्कस्पेस जुड़ गया",
+	"Slack disconnected":                                                       "Slack डिस्कनेक्ट किया गया",
+	"GitHub connected, syncing starred repositories in the background":         "GitHub जुड़ गया, पृष्ठभूमि में स्टार किए गए रिपॉज़िटरी सिंक हो रहे हैं",
+	"GitHub starred repositories synced":                                       "GitHub के स्टार किए गए रिपॉज़िटरी सिंक किए गए",
+	"GitHub account disconnected":                                              "GitHub खाता डिस्कनेक्ट किया गया",
+	"Notion workspace connected, syncing in the background":                    "Notion वर्कस्पेस जुड़ गया, पृष्ठभूमि में सिंक हो रहा है",
+	"Notion workspace disconnected":                                            "Notion वर्कस्पेस डिस्कनेक्ट किया गया",
+	"Notion workspace synced":                                                  "Notion वर्कस्पेस सिंक किया गया",
+	"Bookmark import started":                                                  "बुकमार्क आयात शुरू हुआ",
+	"Import started":                                                           "आयात शुरू हुआ",
+	"Document processing started":                                              "दस्तावेज़ प्रोसेसिंग शुरू हुई",
+	"Meeting recording uploaded, transcription in progress":                    "मीटिंग रिकॉर्डिंग अपलोड हुई, ट्रांसक्रिप्शन जारी है",
+	"Item deleted successfully":                                                "आइटम सफलतापूर्वक हटाया गया",
+	"Notification preferences updated":                                         "सूचना प्राथमिकताएँ अपडेट की गईं",
+	"Digest schedule updated":                                                  "डाइजेस्ट शेड्यूल अपडेट किया गया",
+	"Push subscription registered":                                             "पुश सदस्यता पंजीकृत की गई",
+	"Push subscription removed":                                                "पुश सदस्यता हटाई गई",
+	"Vector write queued for retry":                                            "वेक्टर लेखन दोबारा प्रयास के लिए कतार में है",
+	"X API rate limit reached; the tweet will be saved when it resets":         "X API की दर सीमा पूरी हो गई; सीमा रीसेट होने पर ट्वीट सहेजा जाएगा",
+	"Abuse flag reviewed":                                                      "दुरुपयोग फ़्लैग की समीक्षा की गई",
+	"No items match the description":                                           "विवरण से कोई आइटम मेल नहीं खाता",
+	"Deleted %d item(s)":                                                       "%d आइटम हटाए गए",
+	"Review the items below and confirm with the preview token to delete them": "नीचे दिए आइटम देखें और उन्हें हटाने के लिए प्रीव्यू टोकन से पुष्टि करें",
+	"Outbox drain requested":                                                   "आउटबॉक्स खाली करने का अनुरोध किया गया",
+	"Backup started":                                                           "बैकअप शुरू हुआ",
+	"Restore started":                                                          "रीस्टोर शुरू हुआ",
+	"Public profile updated":                                                   "सार्वजनिक प्रोफ़ाइल अपडेट की गई",
+	"Public profile deleted":                                                   "सार्वजनिक प्रोफ़ाइल हटाई गई",
+	"Visibility updated":                                                       "दृश्यता अपडेट की गई",
+	"Chunk deleted successfully":                                               "खंड सफलतापूर्वक हटाया गया",
+	"Item re-chunked successfully":                                             "आइटम सफलतापूर्वक फिर से खंडित किया गया",
+	"Contradiction scan complete":                                              "विरोधाभास जाँच पूरी हुई",
+	"Contradiction dismissed":                                                  "विरोधाभास खारिज किया गया",
+	"Pinned items updated":                                                     "पिन किए गए आइटम अपडेट किए गए",
+	"YouTube video saved successfully":                                         "YouTube वीडियो सफलतापूर्वक सहेजा गया",
+	"The answer took too long to generate, and no saved items matched the question.":   "उत्तर तैयार करने में बहुत समय लगा, और कोई सहेजा गया आइटम प्रश्न से मेल नहीं खाया।",
+	"The answer took too long to generate. These saved items best match the question:": "उत्तर तैयार करने में बहुत समय लगा। ये सहेजे गए आइटम प्रश्न से सबसे अधिक मेल खाते हैं:",
+	"Requests are throttled after unusual activity. Maximum %d requests per hour.":     "असामान्य गतिविधि के बाद अनुरोध सीमित किए गए हैं। प्रति घंटे अधिकतम %d अनुरोध।",
+	"Image saved successfully":                   "छवि सफलतापूर्वक सहेजी गई",
+	"Document processed and stored successfully": "दस्तावेज़ संसाधित और सफलतापूर्वक सहेजा गया",
+}
+
+var spanish = map[string]string{
+	// Authentication
+	"Authorization header is required":          "Se requiere el encabezado Authorization",
+	"Authorization header must be Bearer token": "El encabezado Authorization debe ser un token Bearer",
+	"Invalid token":                                        "Token no válido",
+	"User ID not found in token":                           "No se encontró el ID de usuario en el token",
+	"User ID not found in request context":                 "No se encontró el ID de usuario en la solicitud",
+	"User not authenticated":                               "Usuario no autenticado",
+	"User ID in request does not match authenticated user": "El ID de usuario de la solicitud no coincide con el usuario autenticado",
+	"Unauthorized":                                         "No autorizado",
+	"Rate limit exceeded. Maximum %d requests per API endpoint per day.": "Límite de solicitudes superado. Máximo %d solicitudes por endpoint de la API por día.",
+	"Try again tomorrow": "Inténtalo de nuevo mañana",
+	"Try again later":    "Inténtalo de nuevo más tarde",
+	"Rate limit exceeded. Maximum %d requests per hour.":        "Límite de solicitudes superado. Máximo %d solicitudes por hora.",
+	"This profile has answered its maximum questions for today": "Este perfil ya respondió el máximo de preguntas de hoy",
+	"Daily spending limit of $%.2f reached":                     "Se alcanzó el límite de gasto diario de $%.2f",
+
+	// Validation
+	"Invalid request":                     "Solicitud no válida",
+	"Invalid status":                      "status no válido",
+	"Missing required parameter: text":    "Falta el parámetro obligatorio: text",
+	"Missing required parameter: query":   "Falta el parámetro obligatorio: query",
+	"Missing required parameter: name":    "Falta el parámetro obligatorio: name",
+	"Invalid tweet URL format":            "Formato de URL de tweet no válido",
+	"X API rate limit reached":            "Se alcanzó el límite de solicitudes de la API de X",
+	"Tweet not found":                     "Tweet no encontrado",
+	"User ID required":                    "Se requiere el ID de usuario",
+	"limit must be a positive integer":    "limit debe ser un entero positivo",
+	"admin and reason are required":       "Se requieren admin y reason",
+	"ttl_minutes must be 1 to %d":         "ttl_minutes debe estar entre 1 y %d",
+	"bookmarks must have 1 to %d items":   "bookmarks debe tener entre 1 y %d elementos",
+	"imports must have 1 to %d items":     "imports debe tener entre 1 y %d elementos",
+	"days must be a positive integer":     "days debe ser un entero positivo",
+	"object is not a backup":              "object no es una copia de seguridad",
+	"before must be an RFC3339 timestamp": "before debe ser una marca de tiempo RFC3339",
+	"Unknown notification channel: %s":    "Canal de notificación desconocido: %s",
+	"Unknown notification type: %s":       "Tipo de notificación desconocido: %s",
+	"Unknown abuse flag status: %s":       "Estado de alerta de abuso desconocido: %s",
+	"Unknown digest frequency: %s":        "Frecuencia de resumen desconocida: %s",
+	"No readable text found in document":  "No se encontró texto legible en el documento",
+	"No text found in tweet":              "No se encontró texto en el tweet",
+
+	// Not found / forbidden
+	"Item not found":                                      "Elemento no encontrado",
+	"User not found":                                      "Usuario no encontrado",
+	"Impersonated requests are read-only":                 "Las solicitudes suplantadas son de solo lectura",
+	"Session not found":                                   "Sesión no encontrada",
+	"Too many pinned items":                               "Demasiados elementos fijados",
+	"Chunk not found":                                     "Fragmento no encontrado",
+	"Public profile not found":                            "Perfil público no encontrado",
+	"Username is already taken":                           "El nombre de usuario ya está en uso",
+	"Dead letter not found":                               "Trabajo fallido no encontrado",
+	"Abuse flag not found":                                "Alerta de abuso no encontrada",
+	"Contradiction not found":                             "Contradicción no encontrada",
+	"Push subscription not found":                         "Suscripción push no encontrada",
+	"No original file is stored for this item":            "No hay ningún archivo original guardado para este elemento",
+	"Telegram is not linked":                              "Telegram no está vinculado",
+	"Job not found":                                       "Trabajo no encontrado",
+	"status must be succeeded, failed or skipped":         "status debe ser succeeded, failed o skipped",
+	"Preview token not found or expired":                  "Token de vista previa no encontrado o caducado",
+	"Not authorized to access this session":               "No tienes permiso para acceder a esta sesión",
+	"Not authorized to delete this item":                  "No tienes permiso para eliminar este elemento",
+	"Cannot delete the only chunk of a document":          "No se puede eliminar el único fragmento de un documento",
+	"Preview token does not belong to authenticated user": "El token de vista previa no pertenece al usuario autenticado",
+	"Item limit of %d reached for the %s plan":            "Se alcanzó el límite de %d elementos del plan %s",
+	"Character limit of %d reached for the %s plan":       "Se alcanzó el límite de %d caracteres del plan %s",
+	"Vector limit of %d reached for the %s plan":          "Se alcanzó el límite de %d vectores del plan %s",
+
+	// Document size limits
+	"Documents are limited to %d pages on the %s plan":                           "Los documentos están limitados a %d páginas en el plan %s",
+	"Documents are limited to %d chunks on the %s plan":                          "Los documentos están limitados a %d fragmentos en el plan %s",
+	"Documents are limited to %d pages on the %s plan; the %s plan allows more":  "Los documentos están limitados a %d páginas en el plan %s; el plan %s permite más",
+	"Documents are limited to %d chunks on the %s plan; the %s plan allows more": "Los documentos están limitados a %d fragmentos en el plan %s; el plan %s permite más",
+
+	// Configuration
+	"Web Push is not configured":                "Web Push no está configurado",
+	"Telegram is not configured":                "Telegram no está configurado",
+	"Slack is not configured":                   "Slack no está configurado",
+	"File storage is not configured":            "El almacenamiento de archivos no está configurado",
+	"Internal authentication is not configured": "La autenticación interna no está configurada",
+	"Impersonation is not configured":           "La suplantación no está configurada",
+	"Backups are not configured":                "Las copias de seguridad no están configuradas",
+
+	// Server errors
+	"Failed to get embedding":                      "No se pudo obtener el embedding",
+	"Failed to query database":                     "No se pudo consultar la base de datos",
+	"Failed to save data":                          "No se pudieron guardar los datos",
+	"Failed to save tweet":                         "No se pudo guardar el tweet",
+	"Failed to save chunk %d":                      "No se pudo guardar el fragmento %d",
+	"Failed to get AI response":                    "No se pudo obtener la respuesta de la IA",
+	"Request timed out":                            "La solicitud superó el tiempo de espera",
+	"Failed to fetch user data":                    "No se pudieron obtener los datos del usuario",
+	"Failed to fetch item":                         "No se pudo obtener el elemento",
+	"Failed to fetch matching items":               "No se pudieron obtener los elementos coincidentes",
+	"Failed to fetch activity":                     "No se pudo obtener la actividad",
+	"Failed to fetch query analytics":              "No se pudieron obtener las estadísticas de búsqueda",
+	"Failed to build gap report":                   "No se pudo generar el informe de lagunas",
+	"Failed to fetch analytics":                    "No se pudieron obtener las estadísticas",
+	"Failed to fetch dead letters":                 "No se pudieron obtener los trabajos fallidos",
+	"Failed to retry dead letter":                  "No se pudo reintentar el trabajo fallido",
+	"Failed to fetch abuse flags":                  "No se pudieron obtener las alertas de abuso",
+	"Failed to review abuse flag":                  "No se pudo revisar la alerta de abuso",
+	"Failed to delete item":                        "No se pudo eliminar el elemento",
+	"Failed to clear cache":                        "No se pudo limpiar la caché",
+	"Failed to create request":                     "No se pudo crear la solicitud",
+	"Failed to fetch tweet":                        "No se pudo obtener el tweet",
+	"Failed to parse tweet data":                   "No se pudieron leer los datos del tweet",
+	"X API returned status: %d":                    "La API de X devolvió el estado: %d",
+	"Failed to retrieve document file":             "No se pudo recibir el archivo del documento",
+	"Failed to open document file":                 "No se pudo abrir el archivo del documento",
+	"Failed to read document":                      "No se pudo leer el documento",
+	"Document must be PDF, DOCX, EPUB or Markdown": "El documento debe ser un archivo PDF, DOCX, EPUB o Markdown",
+	"Failed to save document metadata":             "No se pudieron guardar los metadatos del documento",
+	"Failed to save code":                          "No se pudo guardar el código",
+	"Invalid GitHub repository URL":                "URL de repositorio de GitHub no válida",
+	"Failed to fetch GitHub repository":            "No se pudo obtener el repositorio de GitHub",
+	"No README or docs found in repository":        "No se encontró README ni documentación en el repositorio",
+	"Failed to save GitHub repository":             "No se pudo guardar el repositorio de GitHub",
+	"Invalid gist URL":                             "URL de gist no válida",
+	"Failed to fetch gist":                         "No se pudo obtener el gist",
+	"Gist has no content":                          "El gist no tiene contenido",
+	"Failed to save gist":                          "No se pudo guardar el gist",
+	"Invalid Hacker News URL":                      "URL de Hacker News no válida",
+	"Failed to fetch Hacker News item":             "No se pudo obtener el elemento de Hacker News",
+	"Hacker News item not found":                   "No se encontró el elemento de Hacker News",
+	"Failed to save Hacker News item":              "No se pudo guardar el elemento de Hacker News",
+	"Invalid Reddit URL":                           "URL de Reddit no válida",
+	"Failed to fetch Reddit post":                  "No se pudo obtener la publicación de Reddit",
+	"Failed to save Reddit post":                   "No se pudo guardar la publicación de Reddit",
+	"Invalid YouTube URL":                          "URL de YouTube no válida",
+	"Failed to fetch YouTube video":                "No se pudo obtener el video de YouTube",
+	"Video has no transcript":                      "El video no tiene transcripción",
+	"Failed to save YouTube video":                 "No se pudo guardar el video de YouTube",
+	"Invalid Zotero API key":                       "Clave de API de Zotero no válida",
+	"Failed to save Zotero integration":            "No se pudo guardar la integración con Zotero",
+	"Failed to fetch Zotero integration":           "No se pudo obtener la integración con Zotero",
+	"Failed to remove Zotero integration":          "No se pudo eliminar la integración con Zotero",
+	"Zotero is not connected":                      "Zotero no está conectado",
+	"A Zotero sync is already running":             "Ya hay una sincronización de Zotero en curso",
+	"A backup or restore is already running":       "Ya hay una copia de seguridad o restauración en curso",
+	"Zotero sync failed":                           "La sincronización de Zotero falló",
+	"Invalid Notion access token":                  "Token de acceso de Notion no válido",
+	"Failed to save Notion integration":            "No se pudo guardar la integración con Notion",
+	"Failed to fetch Notion integration":           "No se pudo obtener la integración con Notion",
+	"Failed to remove Notion integration":          "No se pudo eliminar la integración con Notion",
+	"Notion is not connected":                      "Notion no está conectado",
+	"A Notion sync is already running":             "Ya hay una sincronización de Notion en curso",
+	"Notion sync failed":                           "La sincronización de Notion falló",
+	"Failed to create Telegram link code":          "No se pudo crear el código de vinculación de Telegram",
+	"Failed to fetch Telegram link":                "No se pudo obtener la vinculación de Telegram",
+	"Failed to remove Telegram link":               "No se pudo eliminar la vinculación de Telegram",
+	"Failed to create Slack install link":          "No se pudo crear el enlace de instalación de Slack",
+	"Failed to fetch Slack integration":            "No se pudo obtener la integración con Slack",
+	"Failed to save Slack integration":             "No se pudo guardar la integración con Slack",
+	"Failed to remove Slack integration":           "No se pudo eliminar la integración con Slack",
+	"Failed to install Slack app":                  "No se pudo instalar la app de Slack",
+	"Invalid or expired Slack install link":        "El enlace de instalación de Slack no es válido o ha caducado",
+	"Slack install was cancelled":                  "Se canceló la instalación de Slack",
+	"Slack is not connected":                       "Slack no está conectado",
+	"Failed to download file":                      "No se pudo descargar el archivo",
+	"Invalid GitHub token":                         "Token de GitHub no válido",
+	"Failed to save GitHub integration":            "No se pudo guardar la integración con GitHub",
+	"Failed to fetch GitHub integration":           "No se pudo obtener la integración con GitHub",
+	"Failed to remove GitHub integration":          "No se pudo eliminar la integración con GitHub",
+	"GitHub is not connected":                      "GitHub no está conectado",
+	"A GitHub sync is already running":             "Ya hay una sincronización de GitHub en curso",
+	"GitHub sync failed":                           "La sincronización de GitHub falló",
+	"Failed to start bookmark import":              "No se pudo iniciar la importación de marcadores",
+	"Failed to start import":                       "No se pudo iniciar la importación",
+	"Failed to start document processing":          "No se pudo iniciar el procesamiento del documento",
+	"Failed to fetch job":                          "No se pudo obtener el trabajo",
+	"Failed to fetch jobs":                         "No se pudieron obtener los trabajos",
+	"Meeting transcription is not configured":      "La transcripción de reuniones no está configurada",
+	"Failed to retrieve recording file":            "No se pudo obtener el archivo de grabación",
+	"Recording must be at most %d MB":              "La grabación debe tener como máximo %d MB",
+	"Invalid speakers":                             "Hablantes no válidos",
+	"Invalid visibility":                           "Visibilidad no válida",
+	"Invalid chunk index":                          "Índice de fragmento no válido",
+	"Item has no chunks":                           "El elemento no tiene fragmentos",
+	"Item type cannot be re-chunked":               "Este tipo de elemento no se puede volver a fragmentar",
+	"Invalid chunking parameters":                  "Parámetros de fragmentación no válidos",
+	"Invalid username":                             "Nombre de usuario no válido",
+	"Invalid collections":                          "Colecciones no válidas",
+	"Question must be at most %d characters":       "La pregunta debe tener como máximo %d caracteres",
+	"Invalid speakers_expected":                    "speakers_expected no válido",
+	"Invalid meeting_date, expected RFC3339":       "meeting_date no válida, se esperaba RFC3339",
+	"Failed to open recording file":                "No se pudo abrir el archivo de grabación",
+	"Failed to retrieve image file":                "No se pudo obtener el archivo de imagen",
+	"Image must be at most %d MB":                  "La imagen debe tener como máximo %d MB",
+	"Failed to open image file":                    "No se pudo abrir el archivo de imagen",
+	"Image must be PNG, JPEG, GIF or WebP":         "La imagen debe ser PNG, JPEG, GIF o WebP",
+	"Failed to read image":                         "No se pudo leer la imagen",
+	"Failed to save image":                         "No se pudo guardar la imagen",
+	"Failed to upload recording for transcription": "No se pudo subir la grabación para transcribirla",
+	"Failed to save meeting metadata":              "No se pudieron guardar los metadatos de la reunión",
+	"Failed to create preview":                     "No se pudo crear la vista previa",
+	"Failed to store preview":                      "No se pudo guardar la vista previa",
+	"Failed to load preview":                       "No se pudo cargar la vista previa",
+	"Failed to parse preview":                      "No se pudo leer la vista previa",
+	"Failed to fetch notification preferences":     "No se pudieron obtener las preferencias de notificación",
+	"Failed to save notification preferences":      "No se pudieron guardar las preferencias de notificación",
+	"Failed to build digest":                       "No se pudo generar el resumen",
+	"Failed to fetch digest schedule":              "No se pudo obtener la programación del resumen",
+	"Failed to save digest schedule":               "No se pudo guardar la programación del resumen",
+	"Failed to update visibility":                  "No se pudo actualizar la visibilidad",
+	"Failed to delete chunk":                       "No se pudo eliminar el fragmento",
+	"Failed to re-chunk item":                      "No se pudo volver a fragmentar el elemento",
+	"Failed to fetch public profile":               "No se pudo obtener el perfil público",
+	"Failed to save public profile":                "No se pudo guardar el perfil público",
+	"Failed to delete public profile":              "No se pudo eliminar el perfil público",
+	"Failed to save push subscription":             "No se pudo guardar la suscripción push",
+	"Failed to remove push subscription":           "No se pudo eliminar la suscripción push",
+	"Failed to list people":                        "No se pudo obtener la lista de personas",
+	"Failed to retrieve items":                     "No se pudieron obtener los elementos",
+	"Failed to get storage usage":                  "No se pudo obtener el uso de almacenamiento",
+	"Failed to get usage history":                  "No se pudo obtener el historial de uso",
+	"Failed to list users":                         "No se pudo obtener la lista de usuarios",
+	"Failed to list backups":                       "No se pudo obtener la lista de copias de seguridad",
+	"Failed to list contradictions":                "No se pudo obtener la lista de contradicciones",
+	"Failed to scan for contradictions":            "No se pudieron buscar contradicciones",
+	"Failed to dismiss contradiction":              "No se pudo descartar la contradicción",
+	"Failed to retrieve user":                      "No se pudo obtener el usuario",
+	"Failed to look up users in Clerk":             "No se pudieron buscar los usuarios en Clerk",
+	"Failed to create impersonation token":         "No se pudo crear el token de suplantación",
+
+	// Warnings
+	"%d %s request(s) left today":             "Quedan %d solicitud(es) de %s hoy",
+	"%d of %d items used on the %s plan":      "%d de %d elementos usados en el plan %s",
+	"%d of %d characters used on the %s plan": "%d de %d caracteres usados en el plan %s",
+	"%d of %d vectors used on the %s plan":    "%d de %d vectores usados en el plan %s",
+
+	// Status messages
+	"Data saved successfully":                                                  "Datos guardados correctamente",
+	"Query successful":                                                         "Consulta realizada correctamente",
+	"Session reset successfully":                                               "Sesión reiniciada correctamente",
+	"Tweet saved successfully":                                                 "Tweet guardado correctamente",
+	"PDF processed and stored successfully":                                    "PDF procesado y guardado correctamente",
+	"Code saved successfully":                                                  "Código guardado correctamente",
+	"GitHub repository saved successfully":                                     "Repositorio de GitHub guardado correctamente",
+	"Gist saved successfully":                                                  "Gist guardado correctamente",
+	"Hacker News item saved successfully":                                      "Elemento de Hacker News guardado correctamente",
+	"Reddit post saved successfully":                                           "Publicación de Reddit guardada correctamente",
+	"Zotero library connected, syncing in the background":                      "Biblioteca de Zotero conectada, sincronizando en segundo plano",
+	"Zotero library disconnected":                                              "Biblioteca de Zotero desconectada",
+	"Zotero library synced":                                                    "Biblioteca de Zotero sincronizada",
+	"Dry run: the document was not stored":                                     "Simulación: el documento no se guardó",
+	"Send the code to the bot to link your Telegram chat":                      "Envía el código al bot para vincular tu chat de Telegram",
+	"Telegram chat unlinked":                                                   "Chat de Telegram desvinculado",
+	"Slack workspace connected":                                                "Espacio de trabajo de Slack conectado",
+	"Slack disconnected":                                                       "Slack desconectado",
+	"GitHub connected, syncing starred repositories in the background":         "GitHub conectado, sincronizando los repositorios destacados en segundo plano",
+	"GitHub starred repositories synced":                                       "Repositorios destacados de GitHub sincronizados",
+	"GitHub account disconnected":                                              "Cuenta de GitHub desconectada",
+	"Notion workspace connected, syncing in the background":                    "Espacio de trabajo de Notion conectado, sincronizando en segundo plano",
+	"Notion workspace disconnected":                                            "Espacio de trabajo de Notion desconectado",
+	"Notion workspace synced":                                                  "Espacio de trabajo de Notion sincronizado",
+	"Bookmark import started":                                                  "Importación de marcadores iniciada",
+	"Import started":                                                           "Importación iniciada",
+	"Document processing started":                                              "Procesamiento del documento iniciado",
+	"Meeting recording uploaded, transcription in progress":                    "Grabación de la reunión subida, transcripción en curso",
+	"Item deleted successfully":                                                "Elemento eliminado correctamente",
+	"Notification preferences updated":                                         "Preferencias de notificación actualizadas",
+	"Digest schedule updated":                                                  "Programación del resumen actualizada",
+	"Push subscription registered":                                             "Suscripción push registrada",
+	"Push subscription removed":                                                "Suscripción push eliminada",
+	"Vector write queued for retry":                                            "Escritura del vector en cola para reintento",
+	"X API rate limit reached; the tweet will be saved when it resets":         "Se alcanzó el límite de solicitudes de la API de X; el tweet se guardará cuando se restablezca",
+	"Abuse flag reviewed":                                                      "Alerta de abuso revisada",
+	"No items match the description":                                           "Ningún elemento coincide con la descripción",
+	"Deleted %d item(s)":                                                       "Se eliminaron %d elemento(s)",
+	"Review the items below and confirm with the preview token to delete them": "Revisa los elementos y confirma con el token de vista previa para eliminarlos",
+	"Outbox drain requested":                                                   "Vaciado de la cola de salida solicitado",
+	"Backup started":                                                           "Copia de seguridad iniciada",
+	"Restore started":                                                          "Restauración iniciada",
+	"Public profile updated":                                                   "Perfil público actualizado",
+	"Public profile deleted":                                                   "Perfil público eliminado",
+	"Visibility updated":                                                       "Visibilidad actualizada",
+	"Chunk deleted successfully":                                               "Fragmento eliminado correctamente",
+	"Item re-chunked successfully":                                             "Elemento fragmentado de nuevo correctamente",
+	"Contradiction scan complete":                                              "Búsqueda de contradicciones completada",
+	"Contradiction dismissed":                                                  "Contradicción descartada",
+	"Pinned items updated":                                                     "Elementos fijados actualizados",
+	"YouTube video saved successfully":                                         "Video de YouTube guardado correctamente",
+	"The answer took too long to generate, and no saved items matched the question.":   "La respuesta tardó demasiado en generarse y ningún elemento guardado coincidió con la pregunta.",
+	"The answer took too long to generate. These saved items best match the question:": "La respuesta tardó demasiado en generarse. Estos elementos guardados son los que mejor coinciden con la pregunta:",
+	"Requests are throttled after unusual activity. Maximum %d requests per hour.":     "Las solicitudes están limitadas tras actividad inusual. Máximo %d solicitudes por hora.",
+	"Image saved successfully":                   "Imagen guardada correctamente",
+	"Document processed and stored successfully": "Documento procesado y guardado correctamente",
+}
@@ -0,0 +1,139 @@
+package langdetect
+
+import (
+	"strings"
+	"unicode"
+)
+
+// English is the code of the language retrieval translates into
+const English = "en"
+
+// sampleChars bounds how much of a text is examined
+const sampleChars = 4000
+
+// scripts maps writing systems used by a single common language to its ISO 639-1 code
+var scripts = []struct {
+	table    *unicode.RangeTable
+	language string
+}{
+	{unicode.Devanagari, "hi"},
+	{unicode.Bengali, "bn"},
+	{unicode.Tamil, "ta"},
+	{unicode.Telugu, "te"},
+	{unicode.Gujarati, "gu"},
+	{unicode.Gurmukhi, "pa"},
+	{unicode.Kannada, "kn"},
+	{unicode.Malayalam, "ml"},
+	{unicode.Thai, "th"},
+	{unicode.Hangul, "ko"},
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Han, "zh"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Greek, "el"},
+	{unicode.Cyrillic, "ru"},
+}
+
+// stopwords are frequent function words of languages written in the Latin script
+var stopwords = map[string][]string{
+	"en": {"the", "and", "of", "to", "is", "in", "that", "it", "for", "with", "was", "this", "are", "on", "you"},
+	"es": {"el", "la", "de", "que", "y", "en", "los", "las", "por", "con", "una", "es", "para", "del", "se"},
+	"fr": {"le", "la", "les", "de", "et", "des", "est", "un", "une", "que", "pour", "dans", "pas", "du", "sur"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "ein", "eine", "zu", "den", "mit", "auf", "von", "sich", "ich"},
+	"pt": {"o", "a", "os", "de", "que", "e", "do", "da", "em", "um", "uma", "para", "com", "não", "se"},
+	"it": {"il", "la", "di", "che", "e", "un", "una", "per", "non", "sono", "del", "della", "con", "gli", "le"},
+	"nl": {"de", "het", "een", "en", "van", "is", "dat", "niet", "op", "te", "met", "voor", "zijn", "ik", "ook"},
+}
+
+// stopwordLanguages maps each stopword to the languages it belongs to
+var stopwordLanguages = func() map[string][]string {
+	index := make(map[string][]string)
+	for language, words := range stopwords {
+		for _, word := range words {
+			index[word] = append(index[word], language)
+		}
+	}
+	return index
+}()
+
+// Detect returns the ISO 639-1 code of the text's language, or "" when the text is too
+// short or too mixed to tell. Non-Latin scripts are identified by their writing system
+// and Latin-script languages by their most frequent words.
+func Detect(text string) string {
+	if runes := []rune(text); len(runes) > sampleChars {
+		text = string(runes[:sampleChars])
+	}
+
+	letters, latin := 0, 0
+	scriptCounts := make(map[string]int)
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		if unicode.Is(unicode.Latin, r) {
+			latin++
+			continue
+		}
+		for _, script := range scripts {
+			if unicode.Is(script.table, r) {
+				scriptCounts[script.language]++
+				break
+			}
+		}
+	}
+	if letters == 0 {
+		return ""
+	}
+
+	// Japanese text mixes kana with Han characters
+	if scriptCounts["ja"] > 0 {
+		scriptCounts["ja"] += scriptCounts["zh"]
+	}
+	best, bestCount := "", 0
+	for language, count := range scriptCounts {
+		if count > bestCount || (count == bestCount && language < best) {
+			best, bestCount = language, count
+		}
+	}
+	if bestCount > latin {
+		if bestCount*2 < letters {
+			return ""
+		}
+		return best
+	}
+	if latin*2 < letters {
+		return ""
+	}
+	return detectLatin(text)
+}
+
+// detectLatin guesses a Latin-script language from stopword frequencies
+func detectLatin(text string) string {
+	scores := make(map[string]int)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	for _, word := range words {
+		for _, language := range stopwordLanguages[word] {
+			scores[language]++
+		}
+	}
+
+	best, bestScore, runnerUp := "", 0, 0
+	for language, score := range scores {
+		switch {
+		case score > bestScore || (score == bestScore && language < best):
+			runnerUp = bestScore
+			best, bestScore = language, score
+		case score > runnerUp:
+			runnerUp = score
+		}
+	}
+	// Require a clear winner so mixed or jargon-heavy text stays undetected
+	if bestScore < 2 || bestScore == runnerUp {
+		return ""
+	}
+	return best
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/sashabaranov/go-openai"
@@ -8,16 +9,146 @@ import (
 
 // Data represents user content to be stored
 type Data struct {
-	Selected_type string `json:"selected_type"`
-	Text          string `json:"text"`
-	UserId        string `json:"user_id"`
+	Selected_type string                 `json:"selected_type"`
+	Text          string                 `json:"text"`
+	UserId        string                 `json:"user_id"`
+	Visibility    string                 `json:"visibility"`
+	Metadata      map[string]interface{} `json:"-"` // extra vector metadata set by typed save handlers
+	Timestamp     time.Time              `json:"-"` // when the content was created; defaults to now
 }
 
 // QueryRequest represents a query request from the client
 type QueryRequest struct {
-	Text      string `json:"text" binding:"required"`
-	UserId    string `json:"userId" binding:"required"`
-	SessionId string `json:"sessionId"`
+	Text           string          `json:"text" binding:"required"`
+	UserId         string          `json:"userId" binding:"required"`
+	SessionId      string          `json:"sessionId"`
+	IncludeMatches bool            `json:"include_matches"`
+	Mode           string          `json:"mode"` // "strict" (default), "augmented" or "agent"
+	SourceTypes    []string        `json:"source_types"`
+	After          *time.Time      `json:"after"`           // RFC3339, inclusive
+	Before         *time.Time      `json:"before"`          // RFC3339, inclusive
+	Format         string          `json:"format"`          // "plain", "markdown", "bullets" or "json"; unconstrained by default
+	ResponseSchema json.RawMessage `json:"response_schema"` // JSON schema the answer must follow, instead of prose
+	MinScore       *float32        `json:"min_score"`       // overrides the configured minimum match score
+	ExpandChunks   bool            `json:"expand_chunks"`   // adds the chunks around retrieved document chunks to the context
+	Incognito      bool            `json:"incognito"`       // runs without a session or query history
+}
+
+// QueryMatch represents a raw retrieval match returned alongside an answer
+type QueryMatch struct {
+	ID         string      `json:"id,omitempty"`
+	Score      float32     `json:"score"`
+	Type       string      `json:"type"`
+	Section    string      `json:"section,omitempty"` // part of the item matched, e.g. "Project > Decisions"
+	Snippet    string      `json:"snippet"`
+	Highlights []Highlight `json:"highlights,omitempty"` // spans of the snippet matching the query
+}
+
+// Highlight is a span of a snippet relevant to the query, as Unicode code point offsets
+// from the start of the snippet; End is exclusive
+type Highlight struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// ExplainRequest represents a retrieval explanation request
+type ExplainRequest struct {
+	Text        string     `json:"text" binding:"required"`
+	SourceTypes []string   `json:"source_types"`
+	After       *time.Time `json:"after"`
+	Before      *time.Time `json:"before"`
+	MinScore    *float32   `json:"min_score"`
+}
+
+// RetrievalCandidate represents a scored match and whether it was used as context
+type RetrievalCandidate struct {
+	ID              string      `json:"id"`
+	Rank            int         `json:"rank"`
+	Score           float32     `json:"score"`
+	Type            string      `json:"type"`
+	Snippet         string      `json:"snippet"`
+	Highlights      []Highlight `json:"highlights,omitempty"`
+	Included        bool        `json:"included"`
+	PersonBoosted   bool        `json:"person_boosted,omitempty"`
+	ExclusionReason string      `json:"exclusion_reason,omitempty"`
+}
+
+// ExplainResponse represents the response to a retrieval explanation request
+type ExplainResponse struct {
+	Query          string                 `json:"query"`
+	Filters        map[string]interface{} `json:"filters"`
+	MinScore       float32                `json:"min_score"`
+	MaxMatches     int                    `json:"max_matches"`
+	BoostedPeople  []string               `json:"boosted_people,omitempty"`
+	CandidateCount int                    `json:"candidate_count"`
+	IncludedCount  int                    `json:"included_count"`
+	Candidates     []RetrievalCandidate   `json:"candidates"`
+	Timestamp      time.Time              `json:"timestamp"`
+}
+
+// DeleteByQueryRequest represents a natural-language bulk deletion request.
+// Without a preview token it only previews; with one it confirms the deletion.
+type DeleteByQueryRequest struct {
+	Query        string   `json:"query"`
+	MinScore     *float32 `json:"min_score"`
+	PreviewToken string   `json:"preview_token"`
+}
+
+// DeletionPreviewItem represents an item that would be deleted by a delete-by-query request
+type DeletionPreviewItem struct {
+	ID      string  `json:"id"`
+	Type    string  `json:"type"`
+	Snippet string  `json:"snippet"`
+	Score   float32 `json:"score"`
+}
+
+// NotificationPreferencesRequest represents an update to a user's notification preferences.
+// Omitted fields keep their current values.
+type NotificationPreferencesRequest struct {
+	Email    *string         `json:"email"`
+	Channels map[string]bool `json:"channels"`
+	Types    map[string]bool `json:"types"`
+}
+
+// DigestScheduleRequest represents how often a user wants a digest of their recent saves
+type DigestScheduleRequest struct {
+	Frequency string `json:"frequency" binding:"required"` // "daily", "weekly" or "off"
+}
+
+// AbuseFlagReviewRequest represents an admin's verdict on flagged usage
+type AbuseFlagReviewRequest struct {
+	Status string `json:"status" binding:"required"` // "confirmed" or "dismissed"
+}
+
+// PublicProfileRequest represents the settings of a user's public profile
+type PublicProfileRequest struct {
+	Username    string   `json:"username" binding:"required"`
+	DisplayName string   `json:"display_name"`
+	Bio         string   `json:"bio"`
+	Collections []string `json:"collections" binding:"required"` // content types anyone may query
+}
+
+// PublicQueryRequest represents a visitor's question to a public profile
+type PublicQueryRequest struct {
+	Text string `json:"text" binding:"required"`
+}
+
+// PublicQueryResponse represents the answer to a visitor's question, with the public
+// items it was based on
+type PublicQueryResponse struct {
+	Username  string       `json:"username"`
+	Answer    string       `json:"answer"`
+	Sources   []QueryMatch `json:"sources"`
+	Timestamp time.Time    `json:"timestamp"`
+}
+
+// PushSubscriptionRequest represents a browser PushSubscription as serialized by PushSubscription.toJSON()
+type PushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint" binding:"required,url"`
+	Keys     struct {
+		P256dh string `json:"p256dh" binding:"required"`
+		Auth   string `json:"auth" binding:"required"`
+	} `json:"keys" binding:"required"`
 }
 
 // ChatMessage represents a message in a chat session
@@ -29,18 +160,41 @@ type ChatMessage struct {
 // ChatSession represents a conversation session
 type ChatSession struct {
 	Messages  []ChatMessage `json:"messages"`
+	PinnedIDs []string      `json:"pinned_ids,omitempty"` // items always included in the session's prompts
 	CreatedAt time.Time     `json:"created_at"`
 	UpdatedAt time.Time     `json:"updated_at"`
 }
 
 // QueryResponse represents the response to a query request
 type QueryResponse struct {
-	Message      string    `json:"message"`
-	Answer       string    `json:"answer"`
-	ContextText  string    `json:"context_text"`
-	SessionId    string    `json:"session_id"`
-	SessionCount int       `json:"session_count"`
-	Timestamp    time.Time `json:"timestamp"`
+	Message      string          `json:"message"`
+	Answer       string          `json:"answer"`
+	Mode         string          `json:"mode"`
+	Incognito    bool            `json:"incognito,omitempty"` // the query was kept out of sessions and history
+	TimedOut     bool            `json:"timed_out,omitempty"` // generation missed its deadline; the answer lists matches instead
+	Format       string          `json:"format,omitempty"`
+	Structured   json.RawMessage `json:"structured,omitempty"` // the answer object for the JSON format or response schema
+	ContextText  string          `json:"context_text"`
+	SessionId    string          `json:"session_id"`
+	SessionCount int             `json:"session_count"`
+	Matches      []QueryMatch    `json:"matches,omitempty"`
+	AgentSteps   []AgentStep     `json:"agent_steps,omitempty"` // tool calls made in agent mode
+	Timestamp    time.Time       `json:"timestamp"`
+}
+
+// AgentStep is a tool call made while answering a query in agent mode
+type AgentStep struct {
+	Tool      string          `json:"tool"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// VoiceQueryResponse represents the response to a voice query, optionally with a spoken answer
+type VoiceQueryResponse struct {
+	QueryResponse
+	Transcript  string `json:"transcript"`
+	Audio       string `json:"audio,omitempty"` // base64-encoded
+	AudioFormat string `json:"audio_format,omitempty"`
 }
 
 // UpsertResponse represents the response to an upsert request
@@ -50,6 +204,7 @@ type UpsertResponse struct {
 	UserId    string    `json:"user_id"`
 	Type      string    `json:"type"`
 	VectorId  string    `json:"vector_id"`
+	Status    string    `json:"status,omitempty"` // "pending" until the vector is written
 	Timestamp time.Time `json:"timestamp"`
 }
 
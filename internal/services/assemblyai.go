@@ -0,0 +1,158 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	assemblyAIBaseURL = "https://api.assemblyai.com/v2"
+	// assemblyAIPollInterval is how often a pending transcript's status is checked
+	assemblyAIPollInterval = 5 * time.Second
+)
+
+// AssemblyAIService transcribes long recordings with speaker diarization
+type AssemblyAIService struct {
+	client *http.Client
+	apiKey string
+}
+
+// Utterance is a stretch of speech by a single speaker
+type Utterance struct {
+	Speaker string `json:"speaker"` // diarization label, e.g. "A"
+	Text    string `json:"text"`
+	Start   int64  `json:"start"` // milliseconds from the start of the recording
+	End     int64  `json:"end"`
+}
+
+// DiarizedTranscript is a completed transcript split into speaker utterances
+type DiarizedTranscript struct {
+	ID         string
+	DurationMS int64
+	Utterances []Utterance
+}
+
+// NewAssemblyAIService creates a new AssemblyAI service
+func NewAssemblyAIService(apiKey string) *AssemblyAIService {
+	return &AssemblyAIService{
+		// Uploads of long recordings can take a while; polling requests are short
+		client: &http.Client{Timeout: 30 * time.Minute},
+		apiKey: apiKey,
+	}
+}
+
+// Upload streams a recording to AssemblyAI and returns the URL to transcribe it from
+func (s *AssemblyAIService) Upload(ctx context.Context, recording io.Reader) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, assemblyAIBaseURL+"/upload", recording)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	var uploaded struct {
+		UploadURL string `json:"upload_url"`
+	}
+	if err := s.do(req, &uploaded); err != nil {
+		return "", fmt.Errorf("failed to upload recording: %v", err)
+	}
+	return uploaded.UploadURL, nil
+}
+
+// Transcribe starts a diarized transcription of an uploaded recording and waits for it
+// to finish. speakersExpected is optional and improves diarization when known.
+func (s *AssemblyAIService) Transcribe(ctx context.Context, audioURL string, speakersExpected int) (*DiarizedTranscript, error) {
+	params := map[string]interface{}{
+		"audio_url":      audioURL,
+		"speaker_labels": true,
+	}
+	if speakersExpected > 0 {
+		params["speakers_expected"] = speakersExpected
+	}
+	body, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, assemblyAIBaseURL+"/transcript", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := s.do(req, &created); err != nil {
+		return nil, fmt.Errorf("failed to start transcription: %v", err)
+	}
+
+	ticker := time.NewTicker(assemblyAIPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		transcript, done, err := s.getTranscript(ctx, created.ID)
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			return transcript, nil
+		}
+	}
+}
+
+// getTranscript fetches a transcript, reporting whether it has finished
+func (s *AssemblyAIService) getTranscript(ctx context.Context, id string) (*DiarizedTranscript, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, assemblyAIBaseURL+"/transcript/"+id, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	var transcript struct {
+		Status        string      `json:"status"`
+		Error         string      `json:"error"`
+		AudioDuration float64     `json:"audio_duration"` // seconds
+		Utterances    []Utterance `json:"utterances"`
+	}
+	if err := s.do(req, &transcript); err != nil {
+		return nil, false, fmt.Errorf("failed to fetch transcript: %v", err)
+	}
+
+	switch transcript.Status {
+	case "completed":
+		return &DiarizedTranscript{
+			ID:         id,
+			DurationMS: int64(transcript.AudioDuration * 1000),
+			Utterances: transcript.Utterances,
+		}, true, nil
+	case "error":
+		return nil, false, fmt.Errorf("transcription failed: %s", transcript.Error)
+	default:
+		return nil, false, nil
+	}
+}
+
+// do sends an authenticated request and decodes the JSON response into v
+func (s *AssemblyAIService) do(req *http.Request, v interface{}) error {
+	req.Header.Set("Authorization", s.apiKey)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("AssemblyAI API returned status: %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
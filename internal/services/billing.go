@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Billing metrics emitted as metering events
+const (
+	BillingMetricSaves   = "saves"
+	BillingMetricQueries = "queries"
+	BillingMetricTokens  = "tokens"
+	BillingMetricStorage = "storage" // stored characters; a snapshot rather than an increment
+)
+
+const (
+	// billingBatchSize is the most events sent to the sink at once
+	billingBatchSize = 500
+	// maxPendingBillingEvents bounds the events kept while the sink is failing; the
+	// oldest are dropped beyond it
+	maxPendingBillingEvents = 100000
+)
+
+// BillingEvent is a metering event for usage-based billing
+type BillingEvent struct {
+	ID         string                 `json:"id"` // unique, so sinks can drop retried duplicates
+	UserID     string                 `json:"user_id"`
+	Metric     string                 `json:"metric"`
+	Quantity   int64                  `json:"quantity"`
+	Timestamp  time.Time              `json:"timestamp"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+// BillingSink delivers metering events to a billing system
+type BillingSink interface {
+	// Name returns the sink's name for logs
+	Name() string
+	// Send delivers a batch of events. Events may be sent again after an error.
+	Send(ctx context.Context, events []BillingEvent) error
+}
+
+type billingUserKey struct{}
+
+// WithBillingUser returns a context whose API usage, such as OpenAI tokens, is billed to the user
+func WithBillingUser(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, billingUserKey{}, userID)
+}
+
+// billingUser returns the user a context's usage is billed to, or "" for unbilled work
+func billingUser(ctx context.Context) string {
+	userID, _ := ctx.Value(billingUserKey{}).(string)
+	return userID
+}
+
+// BillingService buffers metering events and sends them to the configured sink in batches
+type BillingService struct {
+	sink BillingSink
+
+	mu      sync.Mutex
+	pending []BillingEvent
+	dropped int // events dropped since the last flush
+}
+
+// NewBillingService creates a new billing service. Without a sink, events are discarded.
+func NewBillingService(sink BillingSink) *BillingService {
+	return &BillingService{sink: sink}
+}
+
+// Emit queues a metering event for the user
+func (s *BillingService) Emit(userID, metric string, quantity int64, properties map[string]interface{}) {
+	if s.sink == nil || userID == "" || quantity < 0 {
+		return
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		fmt.Printf("Warning: Failed to generate billing event ID: %v\n", err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.pending) >= maxPendingBillingEvents {
+		s.pending = s.pending[1:]
+		s.dropped++
+	}
+	s.pending = append(s.pending, BillingEvent{
+		ID:         hex.EncodeToString(id),
+		UserID:     userID,
+		Metric:     metric,
+		Quantity:   quantity,
+		Timestamp:  time.Now().UTC(),
+		Properties: properties,
+	})
+}
+
+// RecordTokens bills API tokens to the context's billing user, if any
+func (s *BillingService) RecordTokens(ctx context.Context, unit string, amount int64) {
+	if amount <= 0 {
+		return
+	}
+	s.Emit(billingUser(ctx), BillingMetricTokens, amount, map[string]interface{}{"unit": unit})
+}
+
+// Flush sends pending events in batches. Events of a failed batch stay pending and are
+// retried on the next flush.
+func (s *BillingService) Flush(ctx context.Context) {
+	if s.sink == nil {
+		return
+	}
+
+	s.mu.Lock()
+	if s.dropped > 0 {
+		fmt.Printf("Warning: Dropped %d billing events while %s was failing\n", s.dropped, s.sink.Name())
+		s.dropped = 0
+	}
+	s.mu.Unlock()
+
+	for {
+		s.mu.Lock()
+		n := len(s.pending)
+		if n > billingBatchSize {
+			n = billingBatchSize
+		}
+		batch := append([]BillingEvent(nil), s.pending[:n]...)
+		s.mu.Unlock()
+		if len(batch) == 0 {
+			return
+		}
+
+		if err := s.sink.Send(ctx, batch); err != nil {
+			fmt.Printf("Warning: Failed to send %d billing events to %s: %v\n", len(batch), s.sink.Name(), err)
+			return
+		}
+
+		// Some sent events may have been dropped from the front while sending
+		sent := make(map[string]bool, len(batch))
+		for _, event := range batch {
+			sent[event.ID] = true
+		}
+		s.mu.Lock()
+		for len(s.pending) > 0 && sent[s.pending[0].ID] {
+			s.pending = s.pending[1:]
+		}
+		s.mu.Unlock()
+	}
+}
+
+// Run flushes events every interval until ctx is cancelled, then flushes once more
+func (s *BillingService) Run(ctx context.Context, interval time.Duration) {
+	if s.sink == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			s.Flush(flushCtx)
+			cancel()
+			return
+		case <-ticker.C:
+			s.Flush(ctx)
+		}
+	}
+}
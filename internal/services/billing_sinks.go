@@ -0,0 +1,236 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	pubSubAPIBaseURL = "https://pubsub.googleapis.com/v1"
+	stripeAPIBaseURL = "https://api.stripe.com/v1"
+	// BillingSignatureHeader carries the HMAC-SHA256 of webhook bodies when a secret is set
+	BillingSignatureHeader = "X-Billing-Signature"
+)
+
+// WebhookBillingSink posts event batches as JSON to an HTTP endpoint
+type WebhookBillingSink struct {
+	client *http.Client
+	url    string
+	secret string
+}
+
+// NewWebhookBillingSink creates a webhook sink. When secret is set, each body is signed
+// so the receiver can verify it came from this server.
+func NewWebhookBillingSink(url, secret string) *WebhookBillingSink {
+	return &WebhookBillingSink{
+		client: &http.Client{Timeout: 30 * time.Second},
+		url:    url,
+		secret: secret,
+	}
+}
+
+// Name returns the sink's name
+func (s *WebhookBillingSink) Name() string {
+	return "webhook"
+}
+
+// Send posts the events as {"events": [...]}
+func (s *WebhookBillingSink) Send(ctx context.Context, events []BillingEvent) error {
+	body, err := json.Marshal(map[string]interface{}{"events": events})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.secret != "" {
+		mac := hmac.New(sha256.New, []byte(s.secret))
+		mac.Write(body)
+		req.Header.Set(BillingSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("billing webhook returned status: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// PubSubBillingSink publishes each event as a Pub/Sub message
+type PubSubBillingSink struct {
+	client *http.Client
+	topic  string // projects/<project>/topics/<topic>
+	tokens *googleTokenSource
+}
+
+// NewPubSubBillingSink creates a Pub/Sub sink for a topic. Requests are authorized like
+// GCSService's.
+func NewPubSubBillingSink(topic, accessToken string) *PubSubBillingSink {
+	return &PubSubBillingSink{
+		client: &http.Client{Timeout: 30 * time.Second},
+		topic:  topic,
+		tokens: newGoogleTokenSource(accessToken),
+	}
+}
+
+// Name returns the sink's name
+func (s *PubSubBillingSink) Name() string {
+	return "pubsub"
+}
+
+// Send publishes the events, with the metric and user as message attributes for filtering
+func (s *PubSubBillingSink) Send(ctx context.Context, events []BillingEvent) error {
+	type message struct {
+		Data       string            `json:"data"`
+		Attributes map[string]string `json:"attributes"`
+	}
+	messages := make([]message, 0, len(events))
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, message{
+			Data: base64.StdEncoding.EncodeToString(data),
+			Attributes: map[string]string{
+				"event_id": event.ID,
+				"metric":   event.Metric,
+				"user_id":  event.UserID,
+			},
+		})
+	}
+	body, err := json.Marshal(map[string]interface{}{"messages": messages})
+	if err != nil {
+		return err
+	}
+
+	token, err := s.tokens.Token(ctx)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, pubSubAPIBaseURL+"/"+s.topic+":publish", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Pub/Sub API returned status: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// StripeCustomerLookup finds the Stripe customers of users
+type StripeCustomerLookup interface {
+	GetUsers(ctx context.Context, userIDs []string) (map[string]*ClerkUser, error)
+}
+
+// StripeBillingSink reports events as Stripe billing meter events. Each metric is a meter
+// whose event name is the prefix followed by the metric, e.g. "forgetai_tokens". Users
+// are matched to customers by the stripe_customer_id in their Clerk public metadata.
+type StripeBillingSink struct {
+	client    *http.Client
+	secretKey string
+	prefix    string
+	customers StripeCustomerLookup
+}
+
+// NewStripeBillingSink creates a Stripe meter event sink
+func NewStripeBillingSink(secretKey, eventPrefix string, customers StripeCustomerLookup) *StripeBillingSink {
+	return &StripeBillingSink{
+		client:    &http.Client{Timeout: 30 * time.Second},
+		secretKey: secretKey,
+		prefix:    eventPrefix,
+		customers: customers,
+	}
+}
+
+// Name returns the sink's name
+func (s *StripeBillingSink) Name() string {
+	return "stripe"
+}
+
+// Send creates a meter event for each event. Events of users without a Stripe customer
+// are skipped. Stripe drops events with an identifier it has already seen, so a batch
+// that failed partway can be sent again.
+func (s *StripeBillingSink) Send(ctx context.Context, events []BillingEvent) error {
+	seen := make(map[string]bool)
+	var userIDs []string
+	for _, event := range events {
+		if !seen[event.UserID] {
+			seen[event.UserID] = true
+			userIDs = append(userIDs, event.UserID)
+		}
+	}
+	users, err := s.customers.GetUsers(ctx, userIDs)
+	if err != nil {
+		return fmt.Errorf("failed to look up Stripe customers: %v", err)
+	}
+
+	skipped := 0
+	for _, event := range events {
+		user, ok := users[event.UserID]
+		if !ok || user.StripeCustomerID == "" {
+			skipped++
+			continue
+		}
+		if err := s.sendMeterEvent(ctx, event, user.StripeCustomerID); err != nil {
+			return err
+		}
+	}
+	if skipped > 0 {
+		fmt.Printf("Warning: Skipped %d billing events of users without a Stripe customer\n", skipped)
+	}
+	return nil
+}
+
+// sendMeterEvent creates one Stripe meter event
+func (s *StripeBillingSink) sendMeterEvent(ctx context.Context, event BillingEvent, customerID string) error {
+	form := url.Values{}
+	form.Set("event_name", s.prefix+event.Metric)
+	form.Set("identifier", event.ID)
+	form.Set("timestamp", strconv.FormatInt(event.Timestamp.Unix(), 10))
+	form.Set("payload[stripe_customer_id]", customerID)
+	form.Set("payload[value]", strconv.FormatInt(event.Quantity, 10))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, stripeAPIBaseURL+"/billing/meter_events", strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer "+s.secretKey)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Stripe API returned status %d for event %s", resp.StatusCode, event.ID)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const clerkAPIBaseURL = "https://api.clerk.com/v1"
+
+// ClerkUserService looks up user profiles with the Clerk Backend API
+type ClerkUserService struct {
+	client    *http.Client
+	secretKey string
+}
+
+// ClerkUser holds the profile details shown to admins
+type ClerkUser struct {
+	ID               string     `json:"id"`
+	Name             string     `json:"name,omitempty"`
+	Username         string     `json:"username,omitempty"`
+	Email            string     `json:"email,omitempty"`
+	Plan             string     `json:"plan,omitempty"`               // from public metadata, as issued in session tokens
+	StripeCustomerID string     `json:"stripe_customer_id,omitempty"` // from public metadata, for metered billing
+	CreatedAt        time.Time  `json:"created_at"`
+	LastSignInAt     *time.Time `json:"last_sign_in_at,omitempty"`
+}
+
+// clerkAPIUser is a user as returned by the Clerk Backend API
+type clerkAPIUser struct {
+	ID                    string                 `json:"id"`
+	FirstName             string                 `json:"first_name"`
+	LastName              string                 `json:"last_name"`
+	Username              string                 `json:"username"`
+	PrimaryEmailAddressID string                 `json:"primary_email_address_id"`
+	PublicMetadata        map[string]interface{} `json:"public_metadata"`
+	CreatedAt             int64                  `json:"created_at"` // milliseconds since the epoch
+	LastSignInAt          *int64                 `json:"last_sign_in_at"`
+	EmailAddresses        []struct {
+		ID           string `json:"id"`
+		EmailAddress string `json:"email_address"`
+	} `json:"email_addresses"`
+}
+
+// NewClerkUserService creates a new Clerk user service
+func NewClerkUserService(secretKey string) *ClerkUserService {
+	return &ClerkUserService{
+		client:    &http.Client{Timeout: 10 * time.Second},
+		secretKey: secretKey,
+	}
+}
+
+// GetUsers fetches the profiles of the given users, keyed by user ID. Users unknown to
+// Clerk are left out.
+func (s *ClerkUserService) GetUsers(ctx context.Context, userIDs []string) (map[string]*ClerkUser, error) {
+	users := make(map[string]*ClerkUser, len(userIDs))
+	if len(userIDs) == 0 {
+		return users, nil
+	}
+
+	query := url.Values{}
+	for _, id := range userIDs {
+		query.Add("user_id", id)
+	}
+	query.Set("limit", fmt.Sprintf("%d", len(userIDs)))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, clerkAPIBaseURL+"/users?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+s.secretKey)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Clerk API returned status: %d", resp.StatusCode)
+	}
+
+	var apiUsers []clerkAPIUser
+	if err := json.NewDecoder(resp.Body).Decode(&apiUsers); err != nil {
+		return nil, fmt.Errorf("failed to decode users: %v", err)
+	}
+	for _, apiUser := range apiUsers {
+		users[apiUser.ID] = apiUser.toClerkUser()
+	}
+	return users, nil
+}
+
+// GetUser fetches a user's profile, returning nil if Clerk doesn't know the user
+func (s *ClerkUserService) GetUser(ctx context.Context, userID string) (*ClerkUser, error) {
+	users, err := s.GetUsers(ctx, []string{userID})
+	if err != nil {
+		return nil, err
+	}
+	return users[userID], nil
+}
+
+// toClerkUser converts an API user to the profile shown to admins
+func (u *clerkAPIUser) toClerkUser() *ClerkUser {
+	user := &ClerkUser{
+		ID:        u.ID,
+		Name:      strings.TrimSpace(u.FirstName + " " + u.LastName),
+		Username:  u.Username,
+		CreatedAt: time.UnixMilli(u.CreatedAt),
+	}
+	for _, email := range u.EmailAddresses {
+		if email.ID == u.PrimaryEmailAddressID {
+			user.Email = email.EmailAddress
+		}
+	}
+	if plan, ok := u.PublicMetadata["plan"].(string); ok {
+		user.Plan = plan
+	}
+	if customerID, ok := u.PublicMetadata["stripe_customer_id"].(string); ok {
+		user.StripeCustomerID = customerID
+	}
+	if u.LastSignInAt != nil {
+		lastSignIn := time.UnixMilli(*u.LastSignInAt)
+		user.LastSignInAt = &lastSignIn
+	}
+	return user
+}
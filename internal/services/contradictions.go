@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// contradictionInputChars is the amount of each item's text sent for comparison
+const contradictionInputChars = 2000
+
+// contradictionSchema is the structured output schema for contradiction checks
+var contradictionSchema = json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"contradictions": {
+			"type": "array",
+			"items": {
+				"type": "object",
+				"properties": {
+					"candidate": {"type": "integer"},
+					"explanation": {"type": "string"}
+				},
+				"required": ["candidate", "explanation"],
+				"additionalProperties": false
+			}
+		}
+	},
+	"required": ["contradictions"],
+	"additionalProperties": false
+}`)
+
+// ContradictionFinding is a candidate found to conflict with the checked statement
+type ContradictionFinding struct {
+	Candidate   int    // index into the candidates checked
+	Explanation string // what the two disagree about
+}
+
+// ContradictionDetector finds saved items that contradict each other
+type ContradictionDetector struct {
+	openAI *OpenAIService
+}
+
+// NewContradictionDetector creates a new contradiction detector
+func NewContradictionDetector(openAI *OpenAIService) *ContradictionDetector {
+	return &ContradictionDetector{openAI: openAI}
+}
+
+// FindContradictions returns the candidates that directly contradict the statement.
+// Candidates are expected to be on the same topic, e.g. its nearest saved neighbors.
+func (d *ContradictionDetector) FindContradictions(ctx context.Context, statement string, candidates []string) ([]ContradictionFinding, error) {
+	if strings.TrimSpace(statement) == "" || len(candidates) == 0 {
+		return nil, nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Statement:\n%s\n\n", truncateRunes(statement, contradictionInputChars))
+	for i, candidate := range candidates {
+		fmt.Fprintf(&sb, "Candidate %d:\n%s\n\n", i+1, truncateRunes(candidate, contradictionInputChars))
+	}
+
+	response, err := d.openAI.GetStructuredCompletionContext(ctx, []openai.ChatCompletionMessage{
+		{
+			Role: openai.ChatMessageRoleSystem,
+			Content: "You check a user's saved notes for inconsistencies. Report each candidate that directly " +
+				"contradicts the statement: both cannot be true at once, e.g. different decisions, dates, numbers or " +
+				"facts about the same thing. Do not report candidates that are merely different, more detailed, " +
+				"about something else, or that read like a later update the user made on purpose. " +
+				"Candidates are numbered from 1. Explain each contradiction in one short sentence.",
+		},
+		{Role: openai.ChatMessageRoleUser, Content: sb.String()},
+	}, "contradictions", contradictionSchema, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Contradictions []struct {
+			Candidate   int    `json:"candidate"`
+			Explanation string `json:"explanation"`
+		} `json:"contradictions"`
+	}
+	if err := json.Unmarshal([]byte(response), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse contradictions: %v", err)
+	}
+
+	var findings []ContradictionFinding
+	seen := make(map[int]bool)
+	for _, c := range result.Contradictions {
+		index := c.Candidate - 1
+		if index < 0 || index >= len(candidates) || seen[index] {
+			continue
+		}
+		seen[index] = true
+		findings = append(findings, ContradictionFinding{Candidate: index, Explanation: strings.TrimSpace(c.Explanation)})
+	}
+	return findings, nil
+}
+
+// truncateRunes shortens text to at most max characters
+func truncateRunes(text string, max int) string {
+	if runes := []rune(text); len(runes) > max {
+		return string(runes[:max])
+	}
+	return text
+}
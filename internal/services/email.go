@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"strings"
+
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+)
+
+// EmailProvider delivers notifications by email over SMTP
+type EmailProvider struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+// NewEmailProvider creates a new SMTP email provider
+func NewEmailProvider(host, port, username, password, from string) (*EmailProvider, error) {
+	if host == "" || from == "" {
+		return nil, fmt.Errorf("SMTP host and from address are required")
+	}
+	if port == "" {
+		port = "587"
+	}
+
+	return &EmailProvider{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+	}, nil
+}
+
+// Channel returns the email channel name
+func (p *EmailProvider) Channel() string {
+	return ChannelEmail
+}
+
+// Send emails the notification to the address in the user's preferences
+func (p *EmailProvider) Send(ctx context.Context, prefs *database.NotificationPreferences, n Notification) error {
+	if prefs.Email == "" {
+		return fmt.Errorf("no email address set for user %s", prefs.UserID)
+	}
+
+	body := n.Body
+	if n.URL != "" {
+		body += "\n\n" + n.URL
+	}
+
+	msg := strings.Join([]string{
+		"From: " + p.from,
+		"To: " + headerValue(prefs.Email),
+		"Subject: " + headerValue(n.Title),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=\"utf-8\"",
+		"",
+		body,
+	}, "\r\n")
+
+	var auth smtp.Auth
+	if p.username != "" {
+		auth = smtp.PlainAuth("", p.username, p.password, p.host)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- smtp.SendMail(p.host+":"+p.port, auth, p.from, []string{prefs.Email}, []byte(msg))
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			return fmt.Errorf("failed to send email: %v", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// headerValue strips line breaks so user-controlled values can't inject extra headers
+func headerValue(v string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(v)
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+const (
+	// entityInputChars is the amount of text sent for entity extraction
+	entityInputChars = 8000
+	// maxPeoplePerItem bounds the people recorded for one item
+	maxPeoplePerItem = 20
+)
+
+// EntityExtractor finds named entities in saved content
+type EntityExtractor struct {
+	openAI *OpenAIService
+}
+
+// NewEntityExtractor creates a new entity extractor
+func NewEntityExtractor(openAI *OpenAIService) *EntityExtractor {
+	return &EntityExtractor{openAI: openAI}
+}
+
+// ExtractPeople returns the names of real people mentioned in the text, deduplicated
+func (e *EntityExtractor) ExtractPeople(ctx context.Context, text string) ([]string, error) {
+	if runes := []rune(text); len(runes) > entityInputChars {
+		text = string(runes[:entityInputChars])
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+
+	response, err := e.openAI.GetChatCompletionContext(ctx, []openai.ChatCompletionMessage{
+		{
+			Role: "system",
+			Content: "You extract the names of people mentioned in text. Include individual humans only, " +
+				"not organizations, products or places. Use the most complete form of each name that appears. " +
+				"Respond with JSON only, in the form {\"people\": [\"Full Name\", ...]}.",
+		},
+		{Role: "user", Content: text},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		People []string `json:"people"`
+	}
+	response = strings.TrimSpace(response)
+	response = strings.TrimPrefix(strings.TrimPrefix(response, "```json"), "```")
+	response = strings.TrimSuffix(response, "```")
+	if err := json.Unmarshal([]byte(response), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse extracted people: %v", err)
+	}
+
+	return CleanPeople(result.People), nil
+}
+
+// CleanPeople tidies person names and drops duplicates and empty names
+func CleanPeople(names []string) []string {
+	var people []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = strings.Join(strings.Fields(strings.TrimFunc(name, func(r rune) bool {
+			return !unicode.IsLetter(r)
+		})), " ")
+		key := PersonKey(name)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		people = append(people, name)
+		if len(people) >= maxPeoplePerItem {
+			break
+		}
+	}
+	return people
+}
+
+// PersonKey returns the normalized form of a person's name used for lookups
+func PersonKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	gcsAPIBaseURL    = "https://storage.googleapis.com/storage/v1"
+	gcsUploadBaseURL = "https://storage.googleapis.com/upload/storage/v1"
+)
+
+// GCSService reads and writes objects in a Google Cloud Storage bucket
+type GCSService struct {
+	client *http.Client
+	bucket string
+	tokens *googleTokenSource
+}
+
+// GCSObject describes a stored object
+type GCSObject struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewGCSService creates a new GCS service for a bucket. Requests are authorized with
+// accessToken when given, e.g. for local development, and otherwise with tokens from
+// the instance metadata server.
+func NewGCSService(bucket, accessToken string) *GCSService {
+	return &GCSService{
+		// Backups stream whole collections, so uploads get no overall timeout
+		client: &http.Client{},
+		bucket: bucket,
+		tokens: newGoogleTokenSource(accessToken),
+	}
+}
+
+// Upload streams an object into the bucket, replacing any object with the same name
+func (s *GCSService) Upload(ctx context.Context, name, contentType string, body io.Reader) error {
+	query := url.Values{}
+	query.Set("uploadType", "media")
+	query.Set("name", name)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		fmt.Sprintf("%s/b/%s/o?%s", gcsUploadBaseURL, url.PathEscape(s.bucket), query.Encode()), body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := s.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload %s: %v", name, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Download opens an object for reading. The caller must close it.
+func (s *GCSService) Download(ctx context.Context, name string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		fmt.Sprintf("%s/b/%s/o/%s?alt=media", gcsAPIBaseURL, url.PathEscape(s.bucket), url.PathEscape(name)), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %v", name, err)
+	}
+	return resp.Body, nil
+}
+
+// Delete removes an object from the bucket
+func (s *GCSService) Delete(ctx context.Context, name string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete,
+		fmt.Sprintf("%s/b/%s/o/%s", gcsAPIBaseURL, url.PathEscape(s.bucket), url.PathEscape(name)), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to delete %s: %v", name, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// List returns the objects whose names start with prefix
+func (s *GCSService) List(ctx context.Context, prefix string) ([]GCSObject, error) {
+	var objects []GCSObject
+	pageToken := ""
+	for {
+		query := url.Values{}
+		query.Set("prefix", prefix)
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+			fmt.Sprintf("%s/b/%s/o?%s", gcsAPIBaseURL, url.PathEscape(s.bucket), query.Encode()), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %v", err)
+		}
+
+		resp, err := s.do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects: %v", err)
+		}
+
+		var page struct {
+			Items []struct {
+				Name        string    `json:"name"`
+				Size        int64     `json:"size,string"`
+				TimeCreated time.Time `json:"timeCreated"`
+			} `json:"items"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode object list: %v", err)
+		}
+
+		for _, item := range page.Items {
+			objects = append(objects, GCSObject{Name: item.Name, Size: item.Size, CreatedAt: item.TimeCreated})
+		}
+		if page.NextPageToken == "" {
+			return objects, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+// do sends an authorized request, returning the response if it succeeded
+func (s *GCSService) do(req *http.Request) (*http.Response, error) {
+	token, err := s.tokens.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		resp.Body.Close()
+		return nil, fmt.Errorf("GCS API returned status: %d", resp.StatusCode)
+	}
+	return resp, nil
+}
@@ -0,0 +1,407 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	githubAPIBaseURL = "https://api.github.com"
+	// githubMaxFileSize is the largest file fetched from a repository in bytes
+	githubMaxFileSize = 512 * 1024
+	// githubPageSize is the most results the API returns per page
+	githubPageSize = 100
+	// githubMaxStarred caps the starred repositories listed for one user
+	githubMaxStarred = 5000
+)
+
+// GitHubService handles interactions with the GitHub REST API
+type GitHubService struct {
+	client *http.Client
+	token  string
+}
+
+// GitHubRepository holds the repository details used for ingestion
+type GitHubRepository struct {
+	FullName      string `json:"full_name"`
+	Description   string `json:"description"`
+	DefaultBranch string `json:"default_branch"`
+	HTMLURL       string `json:"html_url"`
+	Stars         int    `json:"stargazers_count"`
+}
+
+// GitHubStarredRepository is a repository a user has starred
+type GitHubStarredRepository struct {
+	FullName      string    `json:"full_name"`
+	Description   string    `json:"description"`
+	DefaultBranch string    `json:"default_branch"`
+	HTMLURL       string    `json:"html_url"`
+	Homepage      string    `json:"homepage"`
+	Language      string    `json:"language"`
+	Topics        []string  `json:"topics"`
+	Stars         int       `json:"stargazers_count"`
+	Archived      bool      `json:"archived"`
+	PushedAt      time.Time `json:"pushed_at"`
+	StarredAt     time.Time `json:"-"`
+}
+
+// GitHubFile is a file fetched from a repository
+type GitHubFile struct {
+	Path    string
+	Content string
+}
+
+// NewGitHubService creates a new GitHub service. The token is optional and only
+// raises the API rate limit and grants access to private repositories.
+func NewGitHubService(token string) *GitHubService {
+	return &GitHubService{
+		client: &http.Client{Timeout: 15 * time.Second},
+		token:  token,
+	}
+}
+
+// WithToken returns a service making requests with a user's token instead of the
+// service's own, sharing its HTTP client
+func (s *GitHubService) WithToken(token string) *GitHubService {
+	return &GitHubService{client: s.client, token: token}
+}
+
+// GetAuthenticatedUser returns the login of the user the service's token belongs to
+func (s *GitHubService) GetAuthenticatedUser(ctx context.Context) (string, error) {
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := s.getJSON(ctx, "/user", &user); err != nil {
+		return "", err
+	}
+	return user.Login, nil
+}
+
+// ListStarred lists the repositories starred by the user the service's token belongs to,
+// most recently starred first
+func (s *GitHubService) ListStarred(ctx context.Context) ([]*GitHubStarredRepository, error) {
+	var starred []*GitHubStarredRepository
+	for page := 1; len(starred) < githubMaxStarred; page++ {
+		// The star media type adds when each repository was starred
+		resp, err := s.get(ctx, fmt.Sprintf("/user/starred?per_page=%d&page=%d", githubPageSize, page), "application/vnd.github.star+json")
+		if err != nil {
+			return nil, err
+		}
+		var stars []struct {
+			StarredAt time.Time               `json:"starred_at"`
+			Repo      GitHubStarredRepository `json:"repo"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&stars)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode GitHub response: %v", err)
+		}
+
+		for _, star := range stars {
+			repo := star.Repo
+			repo.StarredAt = star.StarredAt
+			starred = append(starred, &repo)
+		}
+		if len(stars) < githubPageSize {
+			break
+		}
+	}
+	if len(starred) > githubMaxStarred {
+		starred = starred[:githubMaxStarred]
+	}
+	return starred, nil
+}
+
+// ParseRepoURL extracts the owner and repository name from a GitHub repository URL
+func ParseRepoURL(repoURL string) (string, string, error) {
+	u, err := url.Parse(strings.TrimSpace(repoURL))
+	if err != nil {
+		return "", "", fmt.Errorf("invalid repository URL: %v", err)
+	}
+	if host := strings.ToLower(u.Host); host != "github.com" && host != "www.github.com" {
+		return "", "", fmt.Errorf("not a GitHub URL: %s", repoURL)
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("repository URL must include owner and name: %s", repoURL)
+	}
+
+	return parts[0], strings.TrimSuffix(parts[1], ".git"), nil
+}
+
+// GetRepository fetches a repository's details
+func (s *GitHubService) GetRepository(ctx context.Context, owner, repo string) (*GitHubRepository, error) {
+	var repository GitHubRepository
+	if err := s.getJSON(ctx, fmt.Sprintf("/repos/%s/%s", owner, repo), &repository); err != nil {
+		return nil, err
+	}
+	return &repository, nil
+}
+
+// GetReadme fetches a repository's README from its default branch. It returns nil
+// when the repository has no README.
+func (s *GitHubService) GetReadme(ctx context.Context, owner, repo string) (*GitHubFile, error) {
+	var content struct {
+		Path     string `json:"path"`
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+	err := s.getJSON(ctx, fmt.Sprintf("/repos/%s/%s/readme", owner, repo), &content)
+	if err == errGitHubNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if content.Encoding != "base64" {
+		return nil, fmt.Errorf("unsupported README encoding: %s", content.Encoding)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(content.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode README: %v", err)
+	}
+
+	return &GitHubFile{Path: content.Path, Content: string(decoded)}, nil
+}
+
+// ListFiles lists the paths of files under dir on the given ref whose extension is one of exts
+func (s *GitHubService) ListFiles(ctx context.Context, owner, repo, ref, dir string, exts []string) ([]string, error) {
+	var tree struct {
+		Tree []struct {
+			Path string `json:"path"`
+			Type string `json:"type"`
+			Size int    `json:"size"`
+		} `json:"tree"`
+		Truncated bool `json:"truncated"`
+	}
+	err := s.getJSON(ctx, fmt.Sprintf("/repos/%s/%s/git/trees/%s?recursive=1", owner, repo, url.PathEscape(ref)), &tree)
+	if err != nil {
+		return nil, err
+	}
+	if tree.Truncated {
+		fmt.Printf("Warning: Git tree for %s/%s is truncated, some files may be skipped\n", owner, repo)
+	}
+
+	prefix := strings.Trim(dir, "/") + "/"
+	var paths []string
+	for _, entry := range tree.Tree {
+		if entry.Type != "blob" || !strings.HasPrefix(entry.Path, prefix) || entry.Size > githubMaxFileSize {
+			continue
+		}
+		ext := strings.ToLower(path.Ext(entry.Path))
+		for _, e := range exts {
+			if ext == e {
+				paths = append(paths, entry.Path)
+				break
+			}
+		}
+	}
+	return paths, nil
+}
+
+// GetFile fetches the raw content of a file on the given ref
+func (s *GitHubService) GetFile(ctx context.Context, owner, repo, ref, filePath string) (*GitHubFile, error) {
+	endpoint := fmt.Sprintf("/repos/%s/%s/contents/%s?ref=%s", owner, repo, escapePath(filePath), url.QueryEscape(ref))
+	resp, err := s.get(ctx, endpoint, "application/vnd.github.raw+json")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, githubMaxFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", filePath, err)
+	}
+	if len(body) > githubMaxFileSize {
+		return nil, fmt.Errorf("%s exceeds %d bytes", filePath, githubMaxFileSize)
+	}
+
+	return &GitHubFile{Path: filePath, Content: string(body)}, nil
+}
+
+// GitHubGist holds a gist's details and files
+type GitHubGist struct {
+	ID          string
+	Description string
+	HTMLURL     string
+	Owner       string
+	Files       []GitHubGistFile
+}
+
+// GitHubGistFile is a single file of a gist
+type GitHubGistFile struct {
+	Filename string
+	Language string // GitHub's detected language, e.g. "Go" or "Markdown"
+	Content  string
+}
+
+// ParseGistURL extracts the gist ID from a gist URL
+// (e.g. https://gist.github.com/user/0123abcd or https://gist.github.com/0123abcd)
+func ParseGistURL(gistURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(gistURL))
+	if err != nil {
+		return "", fmt.Errorf("invalid gist URL: %v", err)
+	}
+	if strings.ToLower(u.Host) != "gist.github.com" {
+		return "", fmt.Errorf("not a gist URL: %s", gistURL)
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	id := parts[len(parts)-1]
+	if id == "" {
+		return "", fmt.Errorf("gist URL must include the gist ID: %s", gistURL)
+	}
+	return id, nil
+}
+
+// GetGist fetches a gist and the content of all of its files
+func (s *GitHubService) GetGist(ctx context.Context, id string) (*GitHubGist, error) {
+	var raw struct {
+		ID          string `json:"id"`
+		Description string `json:"description"`
+		HTMLURL     string `json:"html_url"`
+		Owner       struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+		Files map[string]struct {
+			Filename  string `json:"filename"`
+			Language  string `json:"language"`
+			RawURL    string `json:"raw_url"`
+			Size      int    `json:"size"`
+			Truncated bool   `json:"truncated"`
+			Content   string `json:"content"`
+		} `json:"files"`
+	}
+	if err := s.getJSON(ctx, "/gists/"+url.PathEscape(id), &raw); err != nil {
+		return nil, err
+	}
+
+	gist := &GitHubGist{
+		ID:          raw.ID,
+		Description: raw.Description,
+		HTMLURL:     raw.HTMLURL,
+		Owner:       raw.Owner.Login,
+	}
+	for _, file := range raw.Files {
+		if file.Size > githubMaxFileSize {
+			fmt.Printf("Warning: Skipping gist file %s larger than %d bytes\n", file.Filename, githubMaxFileSize)
+			continue
+		}
+		content := file.Content
+		if file.Truncated {
+			// The API truncates large file contents; fetch the full file from its raw URL
+			full, err := s.getRaw(ctx, file.RawURL)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch gist file %s: %v", file.Filename, err)
+			}
+			content = full
+		}
+		gist.Files = append(gist.Files, GitHubGistFile{
+			Filename: file.Filename,
+			Language: file.Language,
+			Content:  content,
+		})
+	}
+
+	// Map iteration order is random; keep files in a stable order
+	sort.Slice(gist.Files, func(i, j int) bool {
+		return gist.Files[i].Filename < gist.Files[j].Filename
+	})
+
+	return gist, nil
+}
+
+// getRaw fetches a raw file URL served by GitHub
+func (s *GitHubService) getRaw(ctx context.Context, rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || !strings.HasSuffix(u.Host, ".githubusercontent.com") {
+		return "", fmt.Errorf("unexpected raw URL: %s", rawURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %v", err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("GitHub request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GitHub returned status: %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, githubMaxFileSize+1))
+	if err != nil {
+		return "", err
+	}
+	if len(body) > githubMaxFileSize {
+		return "", fmt.Errorf("file exceeds %d bytes", githubMaxFileSize)
+	}
+	return string(body), nil
+}
+
+// errGitHubNotFound is returned when the GitHub API responds with 404
+var errGitHubNotFound = fmt.Errorf("not found on GitHub")
+
+// getJSON performs a GET request against the API and decodes the JSON response into v
+func (s *GitHubService) getJSON(ctx context.Context, endpoint string, v interface{}) error {
+	resp, err := s.get(ctx, endpoint, "application/vnd.github+json")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode GitHub response: %v", err)
+	}
+	return nil
+}
+
+// get performs an authenticated GET request and checks the response status
+func (s *GitHubService) get(ctx context.Context, endpoint, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, githubAPIBaseURL+endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Accept", accept)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub request failed: %v", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		resp.Body.Close()
+		return nil, errGitHubNotFound
+	case resp.StatusCode != http.StatusOK:
+		resp.Body.Close()
+		return nil, fmt.Errorf("GitHub API returned status: %d", resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// escapePath escapes each segment of a repository file path
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// googleMetadataTokenURL issues access tokens for the service account the server runs as,
+// e.g. on Cloud Run
+const googleMetadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+// googleTokenSource provides access tokens for Google Cloud APIs: a static token when one
+// is configured, e.g. for local development, and otherwise tokens from the metadata server
+type googleTokenSource struct {
+	client *http.Client
+
+	mu     sync.Mutex
+	token  string
+	static bool
+	expiry time.Time
+}
+
+// newGoogleTokenSource creates a token source, using staticToken when it is set
+func newGoogleTokenSource(staticToken string) *googleTokenSource {
+	return &googleTokenSource{
+		client: &http.Client{Timeout: 10 * time.Second},
+		token:  staticToken,
+		static: staticToken != "",
+	}
+}
+
+// Token returns the static token or a cached metadata token, fetching a new one shortly
+// before it expires
+func (s *googleTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.static || (s.token != "" && time.Now().Before(s.expiry)) {
+		return s.token, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, googleMetadataTokenURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch access token: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("metadata server returned status: %d", resp.StatusCode)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"` // seconds
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode access token: %v", err)
+	}
+
+	s.token = token.AccessToken
+	s.expiry = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - time.Minute)
+	return s.token, nil
+}
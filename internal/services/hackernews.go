@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const hackerNewsAPIBaseURL = "https://hacker-news.firebaseio.com/v0"
+
+// HackerNewsService handles interactions with the Hacker News Firebase API
+type HackerNewsService struct {
+	client *http.Client
+}
+
+// HackerNewsItem is a story, comment or other item on Hacker News
+type HackerNewsItem struct {
+	ID      int    `json:"id"`
+	Type    string `json:"type"`
+	By      string `json:"by"`
+	Time    int64  `json:"time"`
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Text    string `json:"text"` // HTML
+	Score   int    `json:"score"`
+	Kids    []int  `json:"kids"` // child comment IDs in ranked order
+	Deleted bool   `json:"deleted"`
+	Dead    bool   `json:"dead"`
+}
+
+// NewHackerNewsService creates a new Hacker News service
+func NewHackerNewsService() *HackerNewsService {
+	return &HackerNewsService{
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// ParseHackerNewsURL extracts the item ID from a Hacker News item URL
+// (e.g. https://news.ycombinator.com/item?id=123456)
+func ParseHackerNewsURL(itemURL string) (int, error) {
+	u, err := url.Parse(strings.TrimSpace(itemURL))
+	if err != nil {
+		return 0, fmt.Errorf("invalid Hacker News URL: %v", err)
+	}
+	if strings.ToLower(u.Host) != "news.ycombinator.com" || strings.Trim(u.Path, "/") != "item" {
+		return 0, fmt.Errorf("not a Hacker News item URL: %s", itemURL)
+	}
+
+	id, err := strconv.Atoi(u.Query().Get("id"))
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("Hacker News URL must include a valid item ID: %s", itemURL)
+	}
+	return id, nil
+}
+
+// GetItem fetches a single item
+func (s *HackerNewsService) GetItem(ctx context.Context, id int) (*HackerNewsItem, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/item/%d.json", hackerNewsAPIBaseURL, id), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Hacker News request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Hacker News API returned status: %d", resp.StatusCode)
+	}
+
+	var item *HackerNewsItem
+	if err := json.NewDecoder(resp.Body).Decode(&item); err != nil {
+		return nil, fmt.Errorf("failed to decode Hacker News item: %v", err)
+	}
+	// The API returns null for unknown IDs
+	if item == nil {
+		return nil, fmt.Errorf("Hacker News item %d not found", id)
+	}
+	return item, nil
+}
+
+// GetTopComments fetches up to limit of an item's top-level comments in ranked order,
+// skipping deleted and dead comments
+func (s *HackerNewsService) GetTopComments(ctx context.Context, item *HackerNewsItem, limit int) ([]*HackerNewsItem, error) {
+	var comments []*HackerNewsItem
+	for _, kid := range item.Kids {
+		if len(comments) >= limit {
+			break
+		}
+		comment, err := s.GetItem(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+		if comment.Deleted || comment.Dead || strings.TrimSpace(comment.Text) == "" {
+			continue
+		}
+		comments = append(comments, comment)
+	}
+	return comments, nil
+}
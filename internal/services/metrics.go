@@ -0,0 +1,254 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+)
+
+// Usage providers and units recorded for spend estimates
+const (
+	ProviderOpenAI   = "openai"
+	ProviderPinecone = "pinecone"
+
+	UnitEmbeddingTokens      = "embedding_tokens"
+	UnitLargeEmbeddingTokens = "large_embedding_tokens"
+	UnitHostedEmbedTokens    = "hosted_embedding_tokens" // of models served at EMBEDDING_BASE_URL, unpriced
+	UnitChatPromptTokens     = "chat_prompt_tokens"
+	UnitChatCompletionTokens = "chat_completion_tokens"
+	UnitSpeechCharacters     = "speech_characters"
+	UnitTranscriptions       = "transcriptions"
+	UnitReadUnits            = "read_units"
+	UnitWriteUnits           = "write_units"
+)
+
+// Events counted for operational analytics
+const (
+	EventMalformedVector = "malformed_vector" // a query match whose metadata couldn't be decoded
+	EventHotChunkHit     = "hot_chunk_hit"    // a query match whose text came from the hot document cache
+)
+
+// latencyBucketsMS are the upper bounds of the request latency histogram in milliseconds
+var latencyBucketsMS = []int64{10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000, 60000}
+
+// latencyOverflowBucket counts requests slower than the largest bucket
+const latencyOverflowBucket = "inf"
+
+// UsageRecorder receives the billable usage of external API calls
+type UsageRecorder interface {
+	RecordUsage(provider, unit string, amount int64)
+}
+
+// UserUsageRecorder receives API usage attributed to users, for their usage history
+type UserUsageRecorder interface {
+	RecordUserTokens(userID, unit string, amount int64)
+}
+
+// MetricsStore persists aggregated metrics
+type MetricsStore interface {
+	IncrementRouteMetrics(ctx context.Context, metrics *database.RouteMetrics) error
+	IncrementUsageMetrics(ctx context.Context, day, provider string, units map[string]int64) error
+	IncrementEventMetrics(ctx context.Context, day string, events map[string]int64) error
+	IncrementUserUsage(ctx context.Context, usage *database.UserUsage) error
+}
+
+// MetricsService aggregates request and usage metrics in memory and periodically
+// adds them to the daily totals in the store
+type MetricsService struct {
+	store MetricsStore
+
+	mu     sync.Mutex
+	routes map[string]*database.RouteMetrics // by day and route
+	usage  map[string]*database.UsageMetrics // by day and provider
+	events map[string]map[string]int64       // counts by day and event
+	users  map[string]*database.UserUsage    // by day and user
+}
+
+// NewMetricsService creates a new metrics service
+func NewMetricsService(store MetricsStore) *MetricsService {
+	return &MetricsService{
+		store:  store,
+		routes: make(map[string]*database.RouteMetrics),
+		usage:  make(map[string]*database.UsageMetrics),
+		events: make(map[string]map[string]int64),
+		users:  make(map[string]*database.UserUsage),
+	}
+}
+
+// RecordRequest records a handled request's status and latency for its route
+func (s *MetricsService) RecordRequest(route string, status int, latency time.Duration) {
+	day := time.Now().UTC().Format(database.MetricsDayFormat)
+	latencyMS := latency.Milliseconds()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := day + " " + route
+	metrics, ok := s.routes[key]
+	if !ok {
+		metrics = &database.RouteMetrics{Day: day, Route: route, Buckets: make(map[string]int64)}
+		s.routes[key] = metrics
+	}
+	metrics.Count++
+	metrics.LatencyTotalMS += latencyMS
+	metrics.Buckets[latencyBucket(latencyMS)]++
+	switch {
+	case status >= 500:
+		metrics.ServerErrors++
+	case status >= 400:
+		metrics.ClientErrors++
+	}
+}
+
+// RecordUsage records usage of an external provider
+func (s *MetricsService) RecordUsage(provider, unit string, amount int64) {
+	if amount <= 0 {
+		return
+	}
+	day := time.Now().UTC().Format(database.MetricsDayFormat)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := day + " " + provider
+	metrics, ok := s.usage[key]
+	if !ok {
+		metrics = &database.UsageMetrics{Day: day, Provider: provider, Units: make(map[string]int64)}
+		s.usage[key] = metrics
+	}
+	metrics.Units[unit] += amount
+}
+
+// RecordUserRequest counts a user's call of a rate-limited endpoint
+func (s *MetricsService) RecordUserRequest(userID, endpoint string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.userUsage(userID).Endpoints[endpoint]++
+}
+
+// RecordUserTokens records API tokens used on a user's behalf
+func (s *MetricsService) RecordUserTokens(userID, unit string, amount int64) {
+	if userID == "" || amount <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.userUsage(userID).Tokens[unit] += amount
+}
+
+// userUsage returns the user's usage recorded today since the last flush. s.mu must be held.
+func (s *MetricsService) userUsage(userID string) *database.UserUsage {
+	day := time.Now().UTC().Format(database.MetricsDayFormat)
+	key := day + " " + userID
+	usage, ok := s.users[key]
+	if !ok {
+		usage = &database.UserUsage{Day: day, UserID: userID, Endpoints: make(map[string]int64), Tokens: make(map[string]int64)}
+		s.users[key] = usage
+	}
+	return usage
+}
+
+// RecordEvent counts occurrences of an event
+func (s *MetricsService) RecordEvent(event string, count int64) {
+	if count <= 0 {
+		return
+	}
+	day := time.Now().UTC().Format(database.MetricsDayFormat)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.events[day] == nil {
+		s.events[day] = make(map[string]int64)
+	}
+	s.events[day][event] += count
+}
+
+// Flush adds the metrics recorded since the last flush to the store. Metrics that
+// fail to save are dropped so a store outage cannot grow memory without bound.
+func (s *MetricsService) Flush(ctx context.Context) {
+	s.mu.Lock()
+	routes, usage, events, users := s.routes, s.usage, s.events, s.users
+	s.routes = make(map[string]*database.RouteMetrics)
+	s.usage = make(map[string]*database.UsageMetrics)
+	s.events = make(map[string]map[string]int64)
+	s.users = make(map[string]*database.UserUsage)
+	s.mu.Unlock()
+
+	for _, metrics := range routes {
+		if err := s.store.IncrementRouteMetrics(ctx, metrics); err != nil {
+			fmt.Printf("Warning: Failed to save metrics for %s: %v\n", metrics.Route, err)
+		}
+	}
+	for _, metrics := range usage {
+		if err := s.store.IncrementUsageMetrics(ctx, metrics.Day, metrics.Provider, metrics.Units); err != nil {
+			fmt.Printf("Warning: Failed to save %s usage metrics: %v\n", metrics.Provider, err)
+		}
+	}
+	for day, counts := range events {
+		if err := s.store.IncrementEventMetrics(ctx, day, counts); err != nil {
+			fmt.Printf("Warning: Failed to save event metrics for %s: %v\n", day, err)
+		}
+	}
+	for _, usage := range users {
+		if err := s.store.IncrementUserUsage(ctx, usage); err != nil {
+			fmt.Printf("Warning: Failed to save usage of %s: %v\n", usage.UserID, err)
+		}
+	}
+}
+
+// Run flushes metrics every interval until ctx is cancelled, then flushes once more
+func (s *MetricsService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			s.Flush(flushCtx)
+			cancel()
+			return
+		case <-ticker.C:
+			s.Flush(ctx)
+		}
+	}
+}
+
+// latencyBucket returns the name of the histogram bucket for a latency
+func latencyBucket(latencyMS int64) string {
+	for _, bound := range latencyBucketsMS {
+		if latencyMS <= bound {
+			return strconv.FormatInt(bound, 10)
+		}
+	}
+	return latencyOverflowBucket
+}
+
+// LatencyPercentile estimates a latency percentile (0-100) in milliseconds from histogram
+// bucket counts, reporting the upper bound of the bucket it falls in. Latencies beyond
+// the largest bucket are reported as -1.
+func LatencyPercentile(buckets map[string]int64, percentile float64) int64 {
+	var total int64
+	for _, count := range buckets {
+		total += count
+	}
+	if total == 0 {
+		return 0
+	}
+
+	target := int64(math.Ceil(float64(total) * percentile / 100))
+	var cumulative int64
+	for _, bound := range latencyBucketsMS {
+		cumulative += buckets[strconv.FormatInt(bound, 10)]
+		if cumulative >= target {
+			return bound
+		}
+	}
+	return -1
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+)
+
+// Notification channels
+const (
+	ChannelEmail = "email"
+	ChannelPush  = "push"
+)
+
+// Notification types that users can opt in or out of
+const (
+	NotificationReminder       = "reminder"
+	NotificationDigest         = "digest"
+	NotificationResurfacing    = "resurfacing"
+	NotificationImportComplete = "import_complete"
+	NotificationContradiction  = "contradiction"
+	NotificationSavesDigest    = "saves_digest" // summary of recent saves, sent on the user's digest schedule
+)
+
+// NotificationTypes lists every notification type users can configure
+var NotificationTypes = []string{
+	NotificationReminder,
+	NotificationDigest,
+	NotificationResurfacing,
+	NotificationImportComplete,
+	NotificationContradiction,
+	NotificationSavesDigest,
+}
+
+// Notification is a message delivered to a user through one or more channels
+type Notification struct {
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	URL   string `json:"url,omitempty"`
+}
+
+// NotificationProvider delivers notifications over a single channel
+type NotificationProvider interface {
+	// Channel returns the channel name the provider delivers on
+	Channel() string
+	// Send delivers the notification to the user described by prefs
+	Send(ctx context.Context, prefs *database.NotificationPreferences, n Notification) error
+}
+
+// NotificationPreferencesStore loads per-user notification preferences
+type NotificationPreferencesStore interface {
+	GetNotificationPreferences(ctx context.Context, userID string) (*database.NotificationPreferences, error)
+}
+
+// NotificationService routes notifications to the channels each user has enabled
+type NotificationService struct {
+	providers map[string]NotificationProvider
+	store     NotificationPreferencesStore
+}
+
+// NewNotificationService creates a new notification service with the given providers
+func NewNotificationService(store NotificationPreferencesStore, providers ...NotificationProvider) *NotificationService {
+	s := &NotificationService{
+		providers: make(map[string]NotificationProvider),
+		store:     store,
+	}
+	for _, p := range providers {
+		s.providers[p.Channel()] = p
+	}
+	return s
+}
+
+// AddProvider registers an additional provider, replacing any existing one for the same channel
+func (s *NotificationService) AddProvider(p NotificationProvider) {
+	s.providers[p.Channel()] = p
+}
+
+// Channels returns the names of the configured channels
+func (s *NotificationService) Channels() []string {
+	channels := make([]string, 0, len(s.providers))
+	for channel := range s.providers {
+		channels = append(channels, channel)
+	}
+	return channels
+}
+
+// DefaultNotificationPreferences returns the preferences used for users who haven't saved any
+func DefaultNotificationPreferences(userID string) *database.NotificationPreferences {
+	types := make(map[string]bool, len(NotificationTypes))
+	for _, t := range NotificationTypes {
+		types[t] = true
+	}
+	return &database.NotificationPreferences{
+		UserID:   userID,
+		Channels: map[string]bool{ChannelEmail: true, ChannelPush: true},
+		Types:    types,
+	}
+}
+
+// Notify sends a notification to every channel the user has enabled for its type.
+// It returns nil when the user has opted out, and an error only if every attempted channel failed.
+func (s *NotificationService) Notify(ctx context.Context, userID string, n Notification) error {
+	prefs, err := s.store.GetNotificationPreferences(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to load notification preferences: %v", err)
+	}
+	if prefs == nil {
+		prefs = DefaultNotificationPreferences(userID)
+	}
+
+	if enabled, ok := prefs.Types[n.Type]; ok && !enabled {
+		return nil
+	}
+
+	var errs []error
+	attempted := 0
+	for channel, provider := range s.providers {
+		if !prefs.Channels[channel] {
+			continue
+		}
+		attempted++
+		if err := provider.Send(ctx, prefs, n); err != nil {
+			fmt.Printf("Warning: Failed to send %s notification to user %s via %s: %v\n", n.Type, userID, channel, err)
+			errs = append(errs, fmt.Errorf("%s: %w", channel, err))
+		}
+	}
+
+	if attempted > 0 && len(errs) == attempted {
+		return fmt.Errorf("failed to deliver notification: %w", errors.Join(errs...))
+	}
+	return nil
+}
@@ -0,0 +1,352 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	notionAPIBaseURL = "https://api.notion.com/v1"
+	notionAPIVersion = "2022-06-28"
+	// notionPageSize is the maximum number of results the API returns per request
+	notionPageSize = 100
+	// notionMaxBlocks caps the blocks read from one page, including nested blocks
+	notionMaxBlocks = 2000
+	// notionMaxRetries is how many times a rate-limited request is retried
+	notionMaxRetries = 3
+)
+
+// NotionService handles interactions with the Notion API
+type NotionService struct {
+	client *http.Client
+}
+
+// NotionBot describes the integration an access token belongs to and its workspace
+type NotionBot struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	WorkspaceName string `json:"workspace_name"`
+}
+
+// NotionPage is a page shared with the integration
+type NotionPage struct {
+	ID             string
+	URL            string
+	Title          string
+	CreatedTime    time.Time
+	LastEditedTime time.Time
+	Archived       bool // archived or in the trash
+}
+
+// notionRichText is a run of formatted text; only its plain text is used
+type notionRichText struct {
+	PlainText string `json:"plain_text"`
+}
+
+// notionBlock is a block of page content. Its type-specific fields are read from the
+// object named after its type.
+type notionBlock struct {
+	ID          string
+	Type        string
+	HasChildren bool
+	RichText    []notionRichText
+	Checked     bool   // to_do
+	Language    string // code
+	Expression  string // equation
+	URL         string // bookmark, embed, link_preview
+}
+
+func (b *notionBlock) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		ID          string `json:"id"`
+		Type        string `json:"type"`
+		HasChildren bool   `json:"has_children"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	var content struct {
+		RichText   []notionRichText `json:"rich_text"`
+		Checked    bool             `json:"checked"`
+		Language   string           `json:"language"`
+		Expression string           `json:"expression"`
+		URL        string           `json:"url"`
+	}
+	if body, ok := fields[raw.Type]; ok {
+		// Unknown block types may have content of other shapes, which is ignored
+		_ = json.Unmarshal(body, &content)
+	}
+
+	*b = notionBlock{
+		ID:          raw.ID,
+		Type:        raw.Type,
+		HasChildren: raw.HasChildren,
+		RichText:    content.RichText,
+		Checked:     content.Checked,
+		Language:    content.Language,
+		Expression:  content.Expression,
+		URL:         content.URL,
+	}
+	return nil
+}
+
+// text returns the block's plain text
+func (b *notionBlock) text() string {
+	var sb strings.Builder
+	for _, run := range b.RichText {
+		sb.WriteString(run.PlainText)
+	}
+	return sb.String()
+}
+
+// NewNotionService creates a new Notion service
+func NewNotionService() *NotionService {
+	return &NotionService{
+		client: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// GetBot looks up the integration an access token belongs to, validating the token
+func (s *NotionService) GetBot(ctx context.Context, token string) (*NotionBot, error) {
+	var user struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+		Type string `json:"type"`
+		Bot  struct {
+			WorkspaceName string `json:"workspace_name"`
+		} `json:"bot"`
+	}
+	if err := s.doJSON(ctx, token, http.MethodGet, "/users/me", nil, &user); err != nil {
+		return nil, err
+	}
+	if user.Type != "bot" {
+		return nil, fmt.Errorf("token does not belong to an integration")
+	}
+	return &NotionBot{ID: user.ID, Name: user.Name, WorkspaceName: user.Bot.WorkspaceName}, nil
+}
+
+// ListPages gets every page shared with the integration
+func (s *NotionService) ListPages(ctx context.Context, token string) ([]*NotionPage, error) {
+	var pages []*NotionPage
+	cursor := ""
+	for {
+		body := map[string]interface{}{
+			"filter":    map[string]string{"property": "object", "value": "page"},
+			"page_size": notionPageSize,
+		}
+		if cursor != "" {
+			body["start_cursor"] = cursor
+		}
+
+		var result struct {
+			Results []struct {
+				ID             string                     `json:"id"`
+				URL            string                     `json:"url"`
+				CreatedTime    time.Time                  `json:"created_time"`
+				LastEditedTime time.Time                  `json:"last_edited_time"`
+				Archived       bool                       `json:"archived"`
+				InTrash        bool                       `json:"in_trash"`
+				Properties     map[string]json.RawMessage `json:"properties"`
+			} `json:"results"`
+			HasMore    bool   `json:"has_more"`
+			NextCursor string `json:"next_cursor"`
+		}
+		if err := s.doJSON(ctx, token, http.MethodPost, "/search", body, &result); err != nil {
+			return nil, err
+		}
+
+		for _, page := range result.Results {
+			pages = append(pages, &NotionPage{
+				ID:             page.ID,
+				URL:            page.URL,
+				Title:          notionPageTitle(page.Properties),
+				CreatedTime:    page.CreatedTime,
+				LastEditedTime: page.LastEditedTime,
+				Archived:       page.Archived || page.InTrash,
+			})
+		}
+		if !result.HasMore || result.NextCursor == "" {
+			break
+		}
+		cursor = result.NextCursor
+	}
+	return pages, nil
+}
+
+// GetPageText gets a page's content as markdown, reading nested blocks such as toggles and
+// list items. Child pages and databases are left out, as they're synced as pages of their own.
+func (s *NotionService) GetPageText(ctx context.Context, token, pageID string) (string, error) {
+	var lines []string
+	read := 0
+	if err := s.appendBlocks(ctx, token, pageID, "", &lines, &read); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), nil
+}
+
+// appendBlocks appends the markdown of a block's children to lines, indenting nested
+// blocks, until notionMaxBlocks blocks have been read
+func (s *NotionService) appendBlocks(ctx context.Context, token, blockID, indent string, lines *[]string, read *int) error {
+	cursor := ""
+	for {
+		endpoint := fmt.Sprintf("/blocks/%s/children?page_size=%d", url.PathEscape(blockID), notionPageSize)
+		if cursor != "" {
+			endpoint += "&start_cursor=" + url.QueryEscape(cursor)
+		}
+		var result struct {
+			Results    []notionBlock `json:"results"`
+			HasMore    bool          `json:"has_more"`
+			NextCursor string        `json:"next_cursor"`
+		}
+		if err := s.doJSON(ctx, token, http.MethodGet, endpoint, nil, &result); err != nil {
+			return err
+		}
+
+		for i := range result.Results {
+			block := &result.Results[i]
+			if *read >= notionMaxBlocks {
+				return nil
+			}
+			*read++
+
+			if block.Type == "child_page" || block.Type == "child_database" {
+				continue
+			}
+			if line, ok := notionBlockMarkdown(block, indent); ok {
+				*lines = append(*lines, line)
+			}
+			if block.HasChildren {
+				if err := s.appendBlocks(ctx, token, block.ID, indent+"  ", lines, read); err != nil {
+					return err
+				}
+			}
+		}
+		if !result.HasMore || result.NextCursor == "" {
+			return nil
+		}
+		cursor = result.NextCursor
+	}
+}
+
+// notionBlockMarkdown renders a block as markdown, reporting false for blocks with no text
+func notionBlockMarkdown(block *notionBlock, indent string) (string, bool) {
+	text := block.text()
+	switch block.Type {
+	case "heading_1":
+		return "# " + text, text != ""
+	case "heading_2":
+		return "## " + text, text != ""
+	case "heading_3":
+		return "### " + text, text != ""
+	case "bulleted_list_item", "toggle":
+		return indent + "- " + text, text != ""
+	case "numbered_list_item":
+		return indent + "1. " + text, text != ""
+	case "to_do":
+		if block.Checked {
+			return indent + "- [x] " + text, text != ""
+		}
+		return indent + "- [ ] " + text, text != ""
+	case "quote", "callout":
+		return indent + "> " + text, text != ""
+	case "code":
+		return "```" + block.Language + "\n" + text + "\n```", text != ""
+	case "equation":
+		return indent + block.Expression, block.Expression != ""
+	case "bookmark", "embed", "link_preview":
+		return indent + block.URL, block.URL != ""
+	case "divider":
+		return indent + "---", true
+	default:
+		return indent + text, text != ""
+	}
+}
+
+// notionPageTitle returns the text of a page's title property
+func notionPageTitle(properties map[string]json.RawMessage) string {
+	for _, raw := range properties {
+		var property struct {
+			Type  string           `json:"type"`
+			Title []notionRichText `json:"title"`
+		}
+		if err := json.Unmarshal(raw, &property); err != nil || property.Type != "title" {
+			continue
+		}
+		var sb strings.Builder
+		for _, run := range property.Title {
+			sb.WriteString(run.PlainText)
+		}
+		return strings.TrimSpace(sb.String())
+	}
+	return ""
+}
+
+// doJSON performs an authenticated request with an optional JSON body and decodes the JSON
+// response into v. Rate-limited requests are retried after the wait the API asks for.
+func (s *NotionService) doJSON(ctx context.Context, token, method, endpoint string, body, v interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, notionAPIBaseURL+endpoint, bytes.NewReader(payload))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Notion-Version", notionAPIVersion)
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("Notion request failed: %v", err)
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < notionMaxRetries {
+			resp.Body.Close()
+			wait := time.Second
+			if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+				wait = time.Duration(seconds) * time.Second
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+			continue
+		}
+
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			var apiErr struct {
+				Message string `json:"message"`
+			}
+			if json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&apiErr) == nil && apiErr.Message != "" {
+				return fmt.Errorf("Notion API returned status %d: %s", resp.StatusCode, apiErr.Message)
+			}
+			return fmt.Errorf("Notion API returned status: %d", resp.StatusCode)
+		}
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			return fmt.Errorf("failed to decode Notion response: %v", err)
+		}
+		return nil
+	}
+}
@@ -2,34 +2,238 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"strings"
+	"time"
 
 	"github.com/sashabaranov/go-openai"
+	"github.com/siddhantgupta/forgetai-backend/internal/timing"
+	"golang.org/x/sync/singleflight"
 )
 
+// sharedEmbeddingTimeout bounds an embedding call shared by coalesced callers, which
+// outlives any one caller's cancellation
+const sharedEmbeddingTimeout = 30 * time.Second
+
+// embeddingModelDimensions are the native output dimensions of the supported embedding models
+var embeddingModelDimensions = map[openai.EmbeddingModel]int{
+	openai.SmallEmbedding3: 1536,
+	openai.LargeEmbedding3: 3072,
+}
+
+// embeddingPrefixes are the input prefixes a model was trained with to tell search queries
+// from the passages they should match
+type embeddingPrefixes struct {
+	query    string
+	document string
+}
+
+// knownEmbeddingPrefixes are the recommended prefixes of open embedding model families, by
+// model name prefix. OpenAI models embed queries and documents alike.
+var knownEmbeddingPrefixes = map[string]embeddingPrefixes{
+	"intfloat/e5-":              {query: "query: ", document: "passage: "},
+	"intfloat/multilingual-e5-": {query: "query: ", document: "passage: "},
+	"nomic-embed-text":          {query: "search_query: ", document: "search_document: "},
+	"nomic-ai/nomic-embed-text": {query: "search_query: ", document: "search_document: "},
+	"BAAI/bge-":                 {query: "Represent this sentence for searching relevant passages: "},
+	"mxbai-embed-large":         {query: "Represent this sentence for searching relevant passages: "},
+}
+
+// EmbeddingConfig configures the embedding model. Models other than OpenAI's are served by
+// an OpenAI-compatible embeddings API at BaseURL, such as a self-hosted model server.
+type EmbeddingConfig struct {
+	Model      string
+	Dimensions int    // shortens OpenAI embeddings; required for other models, as their output size
+	BaseURL    string // "" for the OpenAI API
+	APIKey     string // for BaseURL; "" to use the OpenAI API key
+
+	// Prefixes embedded before search queries and saved content. When neither is set, a
+	// known model family's recommended prefixes are used. Changing them requires
+	// re-embedding saved content.
+	QueryPrefix    string
+	DocumentPrefix string
+}
+
 // OpenAIService handles interactions with the OpenAI API
 type OpenAIService struct {
-	client *openai.Client
+	client              *openai.Client
+	usage               UsageRecorder
+	userUsage           UserUsageRecorder
+	billing             *BillingService
+	embeddingModel      openai.EmbeddingModel
+	embeddingDimensions int // 0 uses the model's native dimensions
+
+	embeddingClient  *openai.Client // the OpenAI client, or one for the model server at the configured base URL
+	nativeDimensions int            // output size of the model
+	hostedEmbeddings bool           // embeddings come from a model server other than OpenAI
+	prefixes         embeddingPrefixes
+
+	embeddings singleflight.Group // coalesces concurrent embeddings of identical text
+}
+
+// NewOpenAIService creates a new OpenAI service. The embedding config's dimensions shorten
+// an OpenAI model's embeddings when set, and must not exceed its native dimensions.
+func NewOpenAIService(apiKey string, embedding EmbeddingConfig) (*OpenAIService, error) {
+	s := &OpenAIService{
+		client:           openai.NewClient(apiKey),
+		embeddingModel:   openai.EmbeddingModel(embedding.Model),
+		hostedEmbeddings: embedding.BaseURL != "",
+		prefixes:         embeddingPrefixes{query: embedding.QueryPrefix, document: embedding.DocumentPrefix},
+	}
+	s.embeddingClient = s.client
+
+	if s.hostedEmbeddings {
+		if embedding.Dimensions <= 0 {
+			return nil, fmt.Errorf("embedding dimensions are required for %s", embedding.Model)
+		}
+		key := embedding.APIKey
+		if key == "" {
+			key = apiKey
+		}
+		config := openai.DefaultConfig(key)
+		config.BaseURL = embedding.BaseURL
+		s.embeddingClient = openai.NewClientWithConfig(config)
+		// The model's output size is configured rather than shortened, since model
+		// servers generally don't accept a dimensions parameter
+		s.nativeDimensions = embedding.Dimensions
+	} else {
+		native, ok := embeddingModelDimensions[s.embeddingModel]
+		if !ok {
+			return nil, fmt.Errorf("unsupported embedding model %q", embedding.Model)
+		}
+		if embedding.Dimensions < 0 || embedding.Dimensions > native {
+			return nil, fmt.Errorf("embedding dimensions must be between 1 and %d for %s", native, embedding.Model)
+		}
+		s.nativeDimensions = native
+		if embedding.Dimensions != native {
+			s.embeddingDimensions = embedding.Dimensions
+		}
+	}
+
+	if s.prefixes.query == "" && s.prefixes.document == "" {
+		for family, prefixes := range knownEmbeddingPrefixes {
+			if strings.HasPrefix(embedding.Model, family) {
+				s.prefixes = prefixes
+				break
+			}
+		}
+	}
+	return s, nil
+}
+
+// EmbeddingDimensions returns the length of the embeddings GetEmbedding generates
+func (s *OpenAIService) EmbeddingDimensions() int {
+	if s.embeddingDimensions > 0 {
+		return s.embeddingDimensions
+	}
+	return s.nativeDimensions
+}
+
+// EmbeddingUnit returns the usage unit embedding tokens are recorded in, which depends on
+// the model since models are priced differently
+func (s *OpenAIService) EmbeddingUnit() string {
+	switch {
+	case s.hostedEmbeddings:
+		return UnitHostedEmbedTokens
+	case s.embeddingModel == openai.LargeEmbedding3:
+		return UnitLargeEmbeddingTokens
+	default:
+		return UnitEmbeddingTokens
+	}
+}
+
+// SetUsageRecorder sets where token usage of API calls is reported
+func (s *OpenAIService) SetUsageRecorder(usage UsageRecorder) {
+	s.usage = usage
 }
 
-// NewOpenAIService creates a new OpenAI service
-func NewOpenAIService(apiKey string) *OpenAIService {
-	return &OpenAIService{
-		client: openai.NewClient(apiKey),
+// SetUserUsageRecorder sets where tokens used on behalf of users are reported for their
+// usage history
+func (s *OpenAIService) SetUserUsageRecorder(userUsage UserUsageRecorder) {
+	s.userUsage = userUsage
+}
+
+// SetBillingService sets where tokens used on behalf of billed users are metered
+func (s *OpenAIService) SetBillingService(billing *BillingService) {
+	s.billing = billing
+}
+
+// recordUsage reports usage when a recorder is set
+func (s *OpenAIService) recordUsage(unit string, amount int) {
+	if s.usage != nil {
+		s.usage.RecordUsage(ProviderOpenAI, unit, int64(amount))
+	}
+}
+
+// recordTokens reports token usage, attributing it to the context's user in their usage
+// history and billing it to them when billing is set
+func (s *OpenAIService) recordTokens(ctx context.Context, unit string, amount int) {
+	s.recordUsage(unit, amount)
+	if s.userUsage != nil {
+		s.userUsage.RecordUserTokens(billingUser(ctx), unit, int64(amount))
+	}
+	if s.billing != nil {
+		s.billing.RecordTokens(ctx, unit, int64(amount))
 	}
 }
 
 // GetEmbedding generates an embedding for the given text
 func (s *OpenAIService) GetEmbedding(text string) ([]float32, error) {
+	return s.GetEmbeddingContext(context.Background(), text)
+}
+
+// GetQueryEmbedding embeds a search query, with the model's query prefix if it has one
+func (s *OpenAIService) GetQueryEmbedding(ctx context.Context, query string) ([]float32, error) {
+	return s.GetEmbeddingContext(ctx, s.prefixes.query+query)
+}
+
+// GetDocumentEmbedding embeds saved content to be searched, with the model's document
+// prefix if it has one
+func (s *OpenAIService) GetDocumentEmbedding(ctx context.Context, text string) ([]float32, error) {
+	return s.GetEmbeddingContext(ctx, s.prefixes.document+text)
+}
+
+// GetEmbeddingContext generates an embedding for the given text as is, bounded by ctx.
+// Concurrent calls for identical text share one API call and its result; the tokens
+// are recorded once, against the first caller.
+func (s *OpenAIService) GetEmbeddingContext(ctx context.Context, text string) ([]float32, error) {
+	defer timing.Track(ctx, timing.OpenAI, "embedding")()
+
+	// The shared call keeps the first caller's values but not its cancellation, so one
+	// caller giving up doesn't fail the others
+	shared := context.WithoutCancel(ctx)
+	result := s.embeddings.DoChan(text, func() (interface{}, error) {
+		callCtx, cancel := context.WithTimeout(shared, sharedEmbeddingTimeout)
+		defer cancel()
+		return s.createEmbedding(callCtx, text)
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-result:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.([]float32), nil
+	}
+}
+
+// createEmbedding calls the embeddings API for a single text
+func (s *OpenAIService) createEmbedding(ctx context.Context, text string) ([]float32, error) {
 	fmt.Printf("Generating embedding for text: %s\n", text)
 	req := openai.EmbeddingRequest{
-		Input: []string{text},
-		Model: "text-embedding-3-small",
+		Input:      []string{text},
+		Model:      s.embeddingModel,
+		Dimensions: s.embeddingDimensions,
 	}
-	resp, err := s.client.CreateEmbeddings(context.Background(), req)
+	resp, err := s.embeddingClient.CreateEmbeddings(ctx, req)
 	if err != nil {
 		return nil, err
 	}
+	s.recordTokens(ctx, s.EmbeddingUnit(), resp.Usage.PromptTokens)
 	if len(resp.Data) == 0 {
 		return nil, fmt.Errorf("no embedding data returned")
 	}
@@ -39,15 +243,103 @@ func (s *OpenAIService) GetEmbedding(text string) ([]float32, error) {
 
 // GetChatCompletion generates a chat completion for the given messages
 func (s *OpenAIService) GetChatCompletion(messages []openai.ChatCompletionMessage) (string, error) {
-	resp, err := s.client.CreateChatCompletion(
-		context.Background(),
-		openai.ChatCompletionRequest{
-			Model:    "gpt-4o-mini",
-			Messages: messages,
+	return s.GetChatCompletionContext(context.Background(), messages)
+}
+
+// GetChatCompletionContext generates a chat completion for the given messages, bounded by ctx
+func (s *OpenAIService) GetChatCompletionContext(ctx context.Context, messages []openai.ChatCompletionMessage) (string, error) {
+	message, err := s.createChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model:    "gpt-4o-mini",
+		Messages: messages,
+	})
+	return message.Content, err
+}
+
+// GetToolCompletionContext generates a chat completion that may call the given tools,
+// returning the model's message with any tool calls it made. With allowCalls false the
+// tools stay defined, so earlier calls in messages remain valid, but the model must answer.
+func (s *OpenAIService) GetToolCompletionContext(ctx context.Context, messages []openai.ChatCompletionMessage, tools []openai.Tool, allowCalls bool) (openai.ChatCompletionMessage, error) {
+	req := openai.ChatCompletionRequest{
+		Model:    "gpt-4o-mini",
+		Messages: messages,
+		Tools:    tools,
+	}
+	if !allowCalls {
+		req.ToolChoice = "none"
+	}
+	return s.createChatCompletion(ctx, req)
+}
+
+// GetStructuredCompletionContext generates a chat completion that follows a JSON schema,
+// returning the response's JSON text. In strict mode the response is guaranteed to match,
+// but the schema must meet the API's structured output rules.
+func (s *OpenAIService) GetStructuredCompletionContext(ctx context.Context, messages []openai.ChatCompletionMessage, name string, schema json.RawMessage, strict bool) (string, error) {
+	message, err := s.createChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model:    "gpt-4o-mini",
+		Messages: messages,
+		ResponseFormat: &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+			JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+				Name:   name,
+				Schema: schema,
+				Strict: strict,
+			},
 		},
-	)
+	})
+	return message.Content, err
+}
+
+// createChatCompletion sends a chat completion request and returns the first choice's message
+func (s *OpenAIService) createChatCompletion(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionMessage, error) {
+	defer timing.Track(ctx, timing.OpenAI, "chat_completion")()
+
+	resp, err := s.client.CreateChatCompletion(ctx, req)
+	if err != nil {
+		return openai.ChatCompletionMessage{}, err
+	}
+	s.recordTokens(ctx, UnitChatPromptTokens, resp.Usage.PromptTokens)
+	s.recordTokens(ctx, UnitChatCompletionTokens, resp.Usage.CompletionTokens)
+	if len(resp.Choices) == 0 {
+		return openai.ChatCompletionMessage{}, fmt.Errorf("no completion choices returned")
+	}
+	return resp.Choices[0].Message, nil
+}
+
+// Transcribe converts speech audio to text. The filename's extension tells the API the audio format.
+func (s *OpenAIService) Transcribe(ctx context.Context, filename string, audio io.Reader) (string, error) {
+	defer timing.Track(ctx, timing.OpenAI, "transcription")()
+
+	resp, err := s.client.CreateTranscription(ctx, openai.AudioRequest{
+		Model:    openai.Whisper1,
+		FilePath: filename,
+		Reader:   audio,
+	})
 	if err != nil {
 		return "", err
 	}
-	return resp.Choices[0].Message.Content, nil
+	s.recordUsage(UnitTranscriptions, 1)
+	return resp.Text, nil
+}
+
+// TextToSpeech synthesizes spoken mp3 audio for the given text
+func (s *OpenAIService) TextToSpeech(ctx context.Context, text, voice string) ([]byte, error) {
+	defer timing.Track(ctx, timing.OpenAI, "speech")()
+
+	if voice == "" {
+		voice = string(openai.VoiceAlloy)
+	}
+
+	resp, err := s.client.CreateSpeech(ctx, openai.CreateSpeechRequest{
+		Model:          openai.TTSModel1,
+		Input:          text,
+		Voice:          openai.SpeechVoice(voice),
+		ResponseFormat: openai.SpeechResponseFormatMp3,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Close()
+	s.recordUsage(UnitSpeechCharacters, len([]rune(text)))
+
+	return io.ReadAll(resp)
 }
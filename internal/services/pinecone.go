@@ -2,22 +2,54 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/pinecone-io/go-pinecone/v3/pinecone"
 	"google.golang.org/protobuf/types/known/structpb"
 
 	"github.com/siddhantgupta/forgetai-backend/internal/models"
+	"github.com/siddhantgupta/forgetai-backend/internal/timing"
+)
+
+const (
+	// indexReadyPollInterval is how often a newly created index is checked for readiness
+	indexReadyPollInterval = 5 * time.Second
+	// maxMetadataTextBytes caps the text stored with a vector. Longer text is stored as a
+	// preview marked text_truncated; the full text is kept in MongoDB.
+	maxMetadataTextBytes = 4 * 1024
+	// maxMetadataBytes is Pinecone's limit on the metadata of one vector
+	maxMetadataBytes = 40 * 1024
 )
 
 // PineconeService handles interactions with the Pinecone API
 type PineconeService struct {
 	client    *pinecone.Client
+	hostMu    sync.RWMutex // guards indexHost, which EnsureIndex may set while serving
 	indexHost string
+	usage     UsageRecorder
+}
+
+// host returns the index host requests are sent to
+func (s *PineconeService) host() string {
+	s.hostMu.RLock()
+	defer s.hostMu.RUnlock()
+	return s.indexHost
+}
+
+// IndexSpec describes the serverless index EnsureIndex provisions
+type IndexSpec struct {
+	Name      string
+	Dimension int
+	Cloud     string // e.g. "aws"
+	Region    string // e.g. "us-east-1"
 }
 
-// NewPineconeService creates a new Pinecone service
+// NewPineconeService creates a new Pinecone service. indexHost may be empty when the
+// index is resolved by name with EnsureIndex.
 func NewPineconeService(apiKey, indexHost string) (*PineconeService, error) {
 	pc, err := pinecone.NewClient(pinecone.NewClientParams{
 		ApiKey: apiKey,
@@ -32,20 +64,192 @@ func NewPineconeService(apiKey, indexHost string) (*PineconeService, error) {
 	}, nil
 }
 
+// SetUsageRecorder sets where read and write units of index operations are reported
+func (s *PineconeService) SetUsageRecorder(usage UsageRecorder) {
+	s.usage = usage
+}
+
+// recordUsage reports usage when a recorder is set
+func (s *PineconeService) recordUsage(unit string, amount int64) {
+	if s.usage != nil {
+		s.usage.RecordUsage(ProviderPinecone, unit, amount)
+	}
+}
+
+// EnsureIndex looks up the named index through the control-plane API, creating it with
+// the spec's dimension and the cosine metric when it doesn't exist, and points the service
+// at its host once it is ready. It reports whether the index was created.
+func (s *PineconeService) EnsureIndex(ctx context.Context, spec IndexSpec) (bool, error) {
+	indexes, err := s.client.ListIndexes(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to list indexes: %v", err)
+	}
+
+	var index *pinecone.Index
+	for _, idx := range indexes {
+		if idx.Name == spec.Name {
+			index = idx
+			break
+		}
+	}
+
+	created := false
+	if index == nil {
+		dimension := int32(spec.Dimension)
+		metric := pinecone.Cosine
+		index, err = s.client.CreateServerlessIndex(ctx, &pinecone.CreateServerlessIndexRequest{
+			Name:      spec.Name,
+			Cloud:     pinecone.Cloud(spec.Cloud),
+			Region:    spec.Region,
+			Metric:    &metric,
+			Dimension: &dimension,
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to create index %s: %v", spec.Name, err)
+		}
+		created = true
+	} else {
+		if index.Dimension == nil || int(*index.Dimension) != spec.Dimension {
+			return false, fmt.Errorf("index %s exists with a different dimension than %d", spec.Name, spec.Dimension)
+		}
+		if index.Metric != pinecone.Cosine {
+			return false, fmt.Errorf("index %s uses the %s metric, expected cosine", spec.Name, index.Metric)
+		}
+	}
+
+	for index.Status == nil || !index.Status.Ready {
+		select {
+		case <-ctx.Done():
+			return created, fmt.Errorf("index %s did not become ready: %v", spec.Name, ctx.Err())
+		case <-time.After(indexReadyPollInterval):
+		}
+		if index, err = s.client.DescribeIndex(ctx, spec.Name); err != nil {
+			return created, fmt.Errorf("failed to describe index %s: %v", spec.Name, err)
+		}
+	}
+
+	s.hostMu.Lock()
+	s.indexHost = index.Host
+	s.hostMu.Unlock()
+	return created, nil
+}
+
+// CheckReadWrite upserts, fetches and deletes a throwaway vector in the given namespace
+// to verify the index accepts reads and writes. User data lives in the default namespace
+// and is never touched.
+func (s *PineconeService) CheckReadWrite(ctx context.Context, namespace string, embedding []float32) error {
+	if namespace == "" {
+		return fmt.Errorf("a namespace other than the default is required")
+	}
+	idxConnection, err := s.client.Index(pinecone.NewIndexConnParams{
+		Host:      s.host(),
+		Namespace: namespace,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to connect to index: %v", err)
+	}
+
+	id := fmt.Sprintf("selftest-%d", time.Now().UnixNano())
+	if _, err := idxConnection.UpsertVectors(ctx, []*pinecone.Vector{{Id: id, Values: &embedding}}); err != nil {
+		return fmt.Errorf("upsert failed: %v", err)
+	}
+	defer func() {
+		// The check's own context may have expired, which mustn't leave the probe behind
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := idxConnection.DeleteVectorsById(cleanupCtx, []string{id}); err != nil {
+			fmt.Printf("Warning: Failed to delete self-test vector %s: %v\n", id, err)
+		}
+	}()
+
+	// Writes are eventually consistent, so give the vector a few seconds to appear
+	for attempt := 0; ; attempt++ {
+		res, err := idxConnection.FetchVectors(ctx, []string{id})
+		if err != nil {
+			return fmt.Errorf("fetch failed: %v", err)
+		}
+		if _, ok := res.Vectors[id]; ok {
+			break
+		}
+		if attempt == 10 {
+			return fmt.Errorf("upserted vector never became readable")
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+
+	res, err := idxConnection.QueryByVectorValues(ctx, &pinecone.QueryByVectorValuesRequest{
+		Vector: embedding,
+		TopK:   1,
+	})
+	if err != nil {
+		return fmt.Errorf("query failed: %v", err)
+	}
+	if len(res.Matches) == 0 {
+		return fmt.Errorf("query returned no matches")
+	}
+	return nil
+}
+
+// IndexDimension returns the vector dimension of the configured index
+func (s *PineconeService) IndexDimension(ctx context.Context) (int, error) {
+	idxConnection, err := s.client.Index(pinecone.NewIndexConnParams{
+		Host: s.host(),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to index: %v", err)
+	}
+
+	stats, err := idxConnection.DescribeIndexStats(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to describe index: %v", err)
+	}
+	if stats.Dimension == nil {
+		return 0, fmt.Errorf("index did not report its dimension")
+	}
+
+	return int(*stats.Dimension), nil
+}
+
 // UpsertVector inserts or updates a vector in Pinecone
 func (s *PineconeService) UpsertVector(ctx context.Context, id string, embedding []float32, data models.Data) error {
+	defer timing.Track(ctx, timing.Pinecone, "upsert")()
+
 	idxConnection, err := s.client.Index(pinecone.NewIndexConnParams{
-		Host: s.indexHost,
+		Host: s.host(),
 	})
 	if err != nil {
 		return fmt.Errorf("failed to connect to index: %v", err)
 	}
 
+	timestamp := time.Now()
+	if !data.Timestamp.IsZero() {
+		timestamp = data.Timestamp
+	}
+	text, truncated := textPreview(data.Text, maxMetadataTextBytes)
 	metadataMap := map[string]interface{}{
-		"text":      data.Text,
-		"user_id":   data.UserId,
-		"type":      data.Selected_type,
-		"timestamp": time.Now().Format(time.RFC3339),
+		"text":           text,
+		"user_id":        data.UserId,
+		"type":           data.Selected_type,
+		"timestamp":      timestamp.Format(time.RFC3339),
+		"timestamp_unix": timestamp.Unix(),
+	}
+	if truncated {
+		metadataMap["text_truncated"] = true
+	}
+	// Extra metadata never overrides the fields above
+	for key, value := range data.Metadata {
+		if _, exists := metadataMap[key]; !exists {
+			metadataMap[key] = value
+		}
+	}
+
+	// Pinecone rejects oversized metadata, so fail with the size rather than its error
+	if encoded, err := json.Marshal(metadataMap); err == nil && len(encoded) > maxMetadataBytes {
+		return fmt.Errorf("vector metadata is %d bytes, over Pinecone's limit of %d", len(encoded), maxMetadataBytes)
 	}
 
 	metadata, err := structpb.NewStruct(metadataMap)
@@ -63,23 +267,86 @@ func (s *PineconeService) UpsertVector(ctx context.Context, id string, embedding
 	if err != nil {
 		return fmt.Errorf("failed to upsert vector: %v", err)
 	}
+	// The API doesn't report write units; each small upsert is about one
+	s.recordUsage(UnitWriteUnits, int64(count))
 
 	fmt.Printf("Successfully upserted %d vector(s)!\n", count)
 	return nil
 }
 
+// textPreview cuts text to at most maxBytes on a character boundary, reporting whether
+// it was cut
+func textPreview(text string, maxBytes int) (string, bool) {
+	if len(text) <= maxBytes {
+		return text, false
+	}
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut], true
+}
+
+// QueryFilter restricts a vector query to a user's vectors and optional metadata constraints
+// Vectors written before timestamp_unix was stored never match a date constraint.
+type QueryFilter struct {
+	UserID string
+	Types  []string
+	After  *time.Time
+	Before *time.Time
+	// Visibility restricts matches to items with one of the given visibility levels.
+	// Vectors written without a visibility are private.
+	Visibility []string
+}
+
+// AsMap returns the filter as a Pinecone metadata filter expression
+func (f QueryFilter) AsMap() map[string]interface{} {
+	filter := map[string]interface{}{
+		"user_id": f.UserID,
+	}
+
+	if len(f.Types) > 0 {
+		types := make([]interface{}, len(f.Types))
+		for i, t := range f.Types {
+			types[i] = t
+		}
+		filter["type"] = map[string]interface{}{"$in": types}
+	}
+
+	if len(f.Visibility) > 0 {
+		levels := make([]interface{}, len(f.Visibility))
+		for i, level := range f.Visibility {
+			levels[i] = level
+		}
+		filter["visibility"] = map[string]interface{}{"$in": levels}
+	}
+
+	if f.After != nil || f.Before != nil {
+		dateRange := map[string]interface{}{}
+		if f.After != nil {
+			dateRange["$gte"] = f.After.Unix()
+		}
+		if f.Before != nil {
+			dateRange["$lte"] = f.Before.Unix()
+		}
+		filter["timestamp_unix"] = dateRange
+	}
+
+	return filter
+}
+
 // QueryVectors queries vectors in Pinecone
-func (s *PineconeService) QueryVectors(ctx context.Context, userId string, embedding []float32) (*pinecone.QueryVectorsResponse, error) {
+func (s *PineconeService) QueryVectors(ctx context.Context, queryFilter QueryFilter, embedding []float32) (*pinecone.QueryVectorsResponse, error) {
+	defer timing.Track(ctx, timing.Pinecone, "query")()
+
 	idxConnection, err := s.client.Index(pinecone.NewIndexConnParams{
-		Host: s.indexHost,
+		Host: s.host(),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to index: %v", err)
 	}
 
-	filter, err := structpb.NewStruct(map[string]interface{}{
-		"user_id": userId,
-	})
+	filter, err := structpb.NewStruct(queryFilter.AsMap())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create filter: %v", err)
 	}
@@ -94,14 +361,46 @@ func (s *PineconeService) QueryVectors(ctx context.Context, userId string, embed
 	if err != nil {
 		return nil, fmt.Errorf("failed to query vectors: %v", err)
 	}
+	if res.Usage != nil {
+		s.recordUsage(UnitReadUnits, int64(res.Usage.ReadUnits))
+	}
 
 	return res, nil
 }
 
+// UpdateVectorMetadata sets metadata fields on an existing vector, keeping its other fields
+func (s *PineconeService) UpdateVectorMetadata(ctx context.Context, vectorId string, fields map[string]interface{}) error {
+	defer timing.Track(ctx, timing.Pinecone, "update")()
+
+	idxConnection, err := s.client.Index(pinecone.NewIndexConnParams{
+		Host: s.host(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to connect to index: %v", err)
+	}
+
+	metadata, err := structpb.NewStruct(fields)
+	if err != nil {
+		return fmt.Errorf("failed to create metadata struct: %v", err)
+	}
+
+	if err := idxConnection.UpdateVector(ctx, &pinecone.UpdateVectorRequest{
+		Id:       vectorId,
+		Metadata: metadata,
+	}); err != nil {
+		return fmt.Errorf("failed to update vector: %v", err)
+	}
+	s.recordUsage(UnitWriteUnits, 1)
+
+	return nil
+}
+
 // DeleteVector deletes a vector from Pinecone
 func (s *PineconeService) DeleteVector(ctx context.Context, vectorId string) error {
+	defer timing.Track(ctx, timing.Pinecone, "delete")()
+
 	idxConnection, err := s.client.Index(pinecone.NewIndexConnParams{
-		Host: s.indexHost,
+		Host: s.host(),
 	})
 	if err != nil {
 		return fmt.Errorf("failed to connect to index: %v", err)
@@ -111,6 +410,7 @@ func (s *PineconeService) DeleteVector(ctx context.Context, vectorId string) err
 	if err != nil {
 		return fmt.Errorf("failed to delete vector: %v", err)
 	}
+	s.recordUsage(UnitWriteUnits, 1)
 
 	return nil
 }
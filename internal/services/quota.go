@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+)
+
+// Quota limits that can be exceeded
+const (
+	QuotaItems      = "items"
+	QuotaCharacters = "characters"
+	QuotaVectors    = "vectors"
+	QuotaPages      = "pages"  // per document
+	QuotaChunks     = "chunks" // per document
+)
+
+// PlanQuota holds a plan's storage limits and the size limits of a single document.
+// Zero means unlimited.
+type PlanQuota struct {
+	MaxItems          int64 `json:"max_items"`
+	MaxCharacters     int64 `json:"max_characters"`
+	MaxVectors        int64 `json:"max_vectors"`
+	MaxDocumentPages  int64 `json:"max_document_pages"`
+	MaxDocumentChunks int64 `json:"max_document_chunks"`
+}
+
+// QuotaExceededError reports which limit a save would exceed
+type QuotaExceededError struct {
+	Plan        string
+	Limit       string // QuotaItems, QuotaCharacters, QuotaVectors, QuotaPages or QuotaChunks
+	Max         int64
+	UpgradePlan string // a plan whose limit would allow the save, if any
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("%s quota of %d exceeded on the %s plan", e.Limit, e.Max, e.Plan)
+}
+
+// StorageUsageStore measures how much a user has stored
+type StorageUsageStore interface {
+	GetStorageUsage(ctx context.Context, userID string) (*database.StorageUsage, error)
+}
+
+// QuotaService enforces per-plan storage quotas
+type QuotaService struct {
+	plans       map[string]PlanQuota
+	defaultPlan string
+	store       StorageUsageStore
+}
+
+// NewQuotaService creates a new quota service. Users without a known plan get defaultPlan.
+func NewQuotaService(store StorageUsageStore, plans map[string]PlanQuota, defaultPlan string) *QuotaService {
+	return &QuotaService{
+		plans:       plans,
+		defaultPlan: defaultPlan,
+		store:       store,
+	}
+}
+
+// Plan resolves a user's plan name to a configured plan
+func (s *QuotaService) Plan(plan string) (string, PlanQuota) {
+	if quota, ok := s.plans[plan]; ok {
+		return plan, quota
+	}
+	return s.defaultPlan, s.plans[s.defaultPlan]
+}
+
+// Usage returns a user's resolved plan, its limits and their current storage usage
+func (s *QuotaService) Usage(ctx context.Context, userID, plan string) (string, PlanQuota, *database.StorageUsage, error) {
+	plan, quota := s.Plan(plan)
+	usage, err := s.store.GetStorageUsage(ctx, userID)
+	if err != nil {
+		return plan, quota, nil, err
+	}
+	return plan, quota, usage, nil
+}
+
+// Check reports a *QuotaExceededError if storing the given additions would take the user
+// over their plan's limits. Limits the additions bring close to full are added as warnings.
+func (s *QuotaService) Check(ctx context.Context, userID, plan string, add database.StorageUsage) error {
+	plan, quota, usage, err := s.Usage(ctx, userID, plan)
+	if err != nil {
+		return fmt.Errorf("failed to measure storage usage: %v", err)
+	}
+
+	switch {
+	case quota.MaxItems > 0 && usage.Items+add.Items > quota.MaxItems:
+		return &QuotaExceededError{Plan: plan, Limit: QuotaItems, Max: quota.MaxItems}
+	case quota.MaxCharacters > 0 && usage.Characters+add.Characters > quota.MaxCharacters:
+		return &QuotaExceededError{Plan: plan, Limit: QuotaCharacters, Max: quota.MaxCharacters}
+	case quota.MaxVectors > 0 && usage.Vectors+add.Vectors > quota.MaxVectors:
+		return &QuotaExceededError{Plan: plan, Limit: QuotaVectors, Max: quota.MaxVectors}
+	}
+
+	if nearLimit(usage.Items+add.Items, quota.MaxItems) {
+		AddWarning(ctx, "%d of %d items used on the %s plan", usage.Items+add.Items, quota.MaxItems, plan)
+	}
+	if nearLimit(usage.Characters+add.Characters, quota.MaxCharacters) {
+		AddWarning(ctx, "%d of %d characters used on the %s plan", usage.Characters+add.Characters, quota.MaxCharacters, plan)
+	}
+	if nearLimit(usage.Vectors+add.Vectors, quota.MaxVectors) {
+		AddWarning(ctx, "%d of %d vectors used on the %s plan", usage.Vectors+add.Vectors, quota.MaxVectors, plan)
+	}
+	return nil
+}
+
+// CheckDocument reports a *QuotaExceededError if a single document's page or chunk count
+// exceeds the plan's document limits. pages is 0 for documents without pages. The error
+// names a plan with a high enough limit, if there is one, so clients can suggest it.
+func (s *QuotaService) CheckDocument(plan string, pages, chunks int) error {
+	plan, quota := s.Plan(plan)
+
+	limit, max, count := "", int64(0), int64(0)
+	switch {
+	case quota.MaxDocumentPages > 0 && int64(pages) > quota.MaxDocumentPages:
+		limit, max, count = QuotaPages, quota.MaxDocumentPages, int64(pages)
+	case quota.MaxDocumentChunks > 0 && int64(chunks) > quota.MaxDocumentChunks:
+		limit, max, count = QuotaChunks, quota.MaxDocumentChunks, int64(chunks)
+	default:
+		return nil
+	}
+
+	return &QuotaExceededError{Plan: plan, Limit: limit, Max: max, UpgradePlan: s.upgradePlan(limit, count)}
+}
+
+// upgradePlan returns the plan with the lowest document limit that allows count, or ""
+func (s *QuotaService) upgradePlan(limit string, count int64) string {
+	best, bestMax := "", int64(0)
+	names := make([]string, 0, len(s.plans))
+	for name := range s.plans {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		max := s.plans[name].MaxDocumentPages
+		if limit == QuotaChunks {
+			max = s.plans[name].MaxDocumentChunks
+		}
+		if max > 0 && max < count {
+			continue
+		}
+		// An unlimited plan is only suggested when no limited plan is enough
+		if best == "" || (max > 0 && (bestMax == 0 || max < bestMax)) {
+			best, bestMax = name, max
+		}
+	}
+	return best
+}
+
+// nearLimit reports whether used has crossed WarningThreshold of a non-zero limit
+func nearLimit(used, limit int64) bool {
+	return limit > 0 && float64(used) >= WarningThreshold*float64(limit)
+}
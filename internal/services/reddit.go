@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// RedditService fetches posts through Reddit's public JSON API
+type RedditService struct {
+	client *http.Client
+}
+
+// RedditPost is a post with its top-level comments
+type RedditPost struct {
+	ID        string
+	Title     string
+	Selftext  string
+	Subreddit string
+	Author    string
+	Permalink string // absolute URL of the post
+	LinkURL   string // the linked URL for link posts
+	Score     int
+	Comments  []RedditComment
+}
+
+// RedditComment is a top-level comment on a post
+type RedditComment struct {
+	Author string
+	Body   string
+	Score  int
+}
+
+// redditPostID matches the post ID in /comments/<id> and redd.it/<id> URLs
+var redditPostID = regexp.MustCompile(`^/(?:r/[^/]+/)?comments/([a-z0-9]+)`)
+
+// NewRedditService creates a new Reddit service
+func NewRedditService() *RedditService {
+	return &RedditService{
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// ParseRedditURL extracts the post ID from a Reddit post URL
+func ParseRedditURL(postURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(postURL))
+	if err != nil {
+		return "", fmt.Errorf("invalid Reddit URL: %v", err)
+	}
+
+	host := strings.ToLower(u.Host)
+	switch {
+	case host == "redd.it":
+		if id := strings.Trim(u.Path, "/"); id != "" && !strings.Contains(id, "/") {
+			return strings.ToLower(id), nil
+		}
+	case host == "reddit.com" || strings.HasSuffix(host, ".reddit.com"):
+		if match := redditPostID.FindStringSubmatch(strings.ToLower(u.Path)); match != nil {
+			return match[1], nil
+		}
+	default:
+		return "", fmt.Errorf("not a Reddit URL: %s", postURL)
+	}
+	return "", fmt.Errorf("Reddit URL must point to a post: %s", postURL)
+}
+
+// GetPost fetches a post and up to commentLimit of its top comments
+func (s *RedditService) GetPost(ctx context.Context, id string, commentLimit int) (*RedditPost, error) {
+	endpoint := fmt.Sprintf("https://www.reddit.com/comments/%s.json?sort=top&depth=1&raw_json=1&limit=%d", url.PathEscape(id), commentLimit)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	// Reddit throttles requests without a descriptive user agent
+	req.Header.Set("User-Agent", "web:forgetai:v1.0 (by /u/forgetai)")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Reddit request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Reddit API returned status: %d", resp.StatusCode)
+	}
+
+	// The response is two listings: the post, then its comments
+	var listings []struct {
+		Data struct {
+			Children []struct {
+				Kind string `json:"kind"`
+				Data struct {
+					ID        string `json:"id"`
+					Title     string `json:"title"`
+					Selftext  string `json:"selftext"`
+					Subreddit string `json:"subreddit"`
+					Author    string `json:"author"`
+					Permalink string `json:"permalink"`
+					URL       string `json:"url"`
+					IsSelf    bool   `json:"is_self"`
+					Score     int    `json:"score"`
+					Body      string `json:"body"`
+				} `json:"data"`
+			} `json:"children"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&listings); err != nil {
+		return nil, fmt.Errorf("failed to decode Reddit response: %v", err)
+	}
+	if len(listings) == 0 || len(listings[0].Data.Children) == 0 {
+		return nil, fmt.Errorf("Reddit post %s not found", id)
+	}
+
+	data := listings[0].Data.Children[0].Data
+	post := &RedditPost{
+		ID:        data.ID,
+		Title:     data.Title,
+		Selftext:  data.Selftext,
+		Subreddit: data.Subreddit,
+		Author:    data.Author,
+		Permalink: "https://www.reddit.com" + data.Permalink,
+		Score:     data.Score,
+	}
+	if !data.IsSelf {
+		post.LinkURL = data.URL
+	}
+
+	if len(listings) > 1 {
+		for _, child := range listings[1].Data.Children {
+			// "more" placeholders and removed comments carry no text
+			if child.Kind != "t1" || child.Data.Body == "" || child.Data.Body == "[deleted]" || child.Data.Body == "[removed]" {
+				continue
+			}
+			post.Comments = append(post.Comments, RedditComment{
+				Author: child.Data.Author,
+				Body:   child.Data.Body,
+				Score:  child.Data.Score,
+			})
+			if len(post.Comments) >= commentLimit {
+				break
+			}
+		}
+	}
+
+	return post, nil
+}
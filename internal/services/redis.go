@@ -3,17 +3,49 @@ package services
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/siddhantgupta/forgetai-backend/internal/timing"
 )
 
-// RedisService handles Redis connections and operations
+// DailyRateLimit is how many calls a user may make to each rate-limited endpoint per day
+const DailyRateLimit = 30
+
+// Limits on unauthenticated queries of public profiles
+const (
+	// PublicClientHourlyLimit is how many public queries one client address may make per hour
+	PublicClientHourlyLimit = 20
+	// PublicProfileDailyLimit is how many public queries one profile answers per day
+	PublicProfileDailyLimit = 500
+)
+
+// RedisService handles Redis connections and operations. While Redis is unavailable,
+// rate limits and deletion previews are kept in memory on this instance instead, and
+// cache reads and writes are skipped.
 type RedisService struct {
-	client *redis.Client
+	client redisClient
+	state  redisState
+	memory *memoryStore
 }
 
-// NewRedisService creates a new Redis service
+// redisClient runs the Redis commands RedisService uses, over the Redis protocol or
+// Upstash's REST API. Get returns redis.Nil for a missing key.
+type redisClient interface {
+	Incr(ctx context.Context, key string) (int64, error)
+	IncrBy(ctx context.Context, key string, amount int64) (int64, error)
+	Expire(ctx context.Context, key string, ttl time.Duration) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	MGet(ctx context.Context, keys ...string) ([][]byte, error) // nil for each missing key
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Keys(ctx context.Context, pattern string) ([]string, error)
+	Del(ctx context.Context, keys ...string) (int64, error)
+	Ping(ctx context.Context) (string, error)
+}
+
+// NewRedisService creates a new Redis service connecting over the Redis protocol
 func NewRedisService(redisURL string) (*RedisService, error) {
 	opt, err := redis.ParseURL(redisURL)
 	if err != nil {
@@ -21,85 +53,340 @@ func NewRedisService(redisURL string) (*RedisService, error) {
 	}
 
 	client := redis.NewClient(opt)
+	client.AddHook(timingHook{})
 
-	// Test connection
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	return newRedisService(protocolClient{client: client}), nil
+}
 
-	_, err = client.Ping(ctx).Result()
+// newRedisService creates a Redis service using the given client. No command is sent
+// until the service is used; Ping checks the connection.
+func newRedisService(client redisClient) *RedisService {
+	return &RedisService{
+		client: client,
+		memory: newMemoryStore(),
+	}
+}
+
+// protocolClient is a redisClient speaking the Redis protocol over TCP
+type protocolClient struct {
+	client *redis.Client
+}
+
+func (c protocolClient) Incr(ctx context.Context, key string) (int64, error) {
+	return c.client.Incr(ctx, key).Result()
+}
+
+func (c protocolClient) IncrBy(ctx context.Context, key string, amount int64) (int64, error) {
+	return c.client.IncrBy(ctx, key, amount).Result()
+}
+
+func (c protocolClient) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	return c.client.Expire(ctx, key, ttl).Err()
+}
+
+func (c protocolClient) Get(ctx context.Context, key string) ([]byte, error) {
+	return c.client.Get(ctx, key).Bytes()
+}
+
+func (c protocolClient) MGet(ctx context.Context, keys ...string) ([][]byte, error) {
+	results, err := c.client.MGet(ctx, keys...).Result()
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to Redis: %v", err)
+		return nil, err
+	}
+	values := make([][]byte, len(results))
+	for i, result := range results {
+		if value, ok := result.(string); ok {
+			values[i] = []byte(value)
+		}
 	}
+	return values, nil
+}
 
-	return &RedisService{
-		client: client,
-	}, nil
+func (c protocolClient) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (c protocolClient) Keys(ctx context.Context, pattern string) ([]string, error) {
+	return c.client.Keys(ctx, pattern).Result()
+}
+
+func (c protocolClient) Del(ctx context.Context, keys ...string) (int64, error) {
+	return c.client.Del(ctx, keys...).Result()
+}
+
+func (c protocolClient) Ping(ctx context.Context) (string, error) {
+	return c.client.Ping(ctx).Result()
+}
+
+// timingHook records each command's duration on the request that issued it
+type timingHook struct{}
+
+type commandStartKey struct{}
+
+func (timingHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return context.WithValue(ctx, commandStartKey{}, time.Now()), nil
 }
 
-// CheckRateLimit checks if a user has exceeded their API call limit
-// Returns true if rate limit is exceeded, false otherwise
-func (s *RedisService) CheckRateLimit(ctx context.Context, userId, endpoint string) (bool, error) {
+func (timingHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	if start, ok := ctx.Value(commandStartKey{}).(time.Time); ok {
+		timing.Record(ctx, timing.Redis, cmd.Name(), time.Since(start))
+	}
+	return nil
+}
+
+func (timingHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return context.WithValue(ctx, commandStartKey{}, time.Now()), nil
+}
+
+func (timingHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	if start, ok := ctx.Value(commandStartKey{}).(time.Time); ok {
+		timing.Record(ctx, timing.Redis, "pipeline", time.Since(start))
+	}
+	return nil
+}
+
+// CheckRateLimit counts a call and checks if a user has exceeded their API call limit
+// Returns the day's call count and true if rate limit is exceeded, false otherwise.
+// Calls are counted in memory while Redis is unavailable.
+func (s *RedisService) CheckRateLimit(ctx context.Context, userId, endpoint string) (int, bool, error) {
 	key := fmt.Sprintf("rate-limit:%s:%s:%s", userId, endpoint, time.Now().Format("2006-01-02"))
 
-	// Increment the counter
-	count, err := s.client.Incr(ctx, key).Result()
+	// Set expiry if this is a new key (30 minutes instead of 24 hours)
+	count := s.incr(ctx, key, 30*time.Minute)
+
+	// Check if rate limit exceeded (DailyRateLimit calls per user per endpoint per day)
+	return int(count), count > DailyRateLimit, nil
+}
+
+// CheckPublicRateLimit counts a public query against a fixed window and checks whether
+// the limit for the subject (a client address or profile) is exceeded.
+// Returns the window's call count and true if the limit is exceeded.
+func (s *RedisService) CheckPublicRateLimit(ctx context.Context, subject string, limit int, window time.Duration) (int, bool, error) {
+	key := fmt.Sprintf("public-rate-limit:%s:%d", subject, time.Now().Unix()/int64(window.Seconds()))
+
+	count := s.incr(ctx, key, window)
+	return int(count), count > int64(limit), nil
+}
+
+// incr increments a rate limit counter, setting its expiry when it's new. The counter is
+// kept in memory if Redis fails.
+func (s *RedisService) incr(ctx context.Context, key string, ttl time.Duration) int64 {
+	if s.usable() {
+		count, err := s.client.Incr(ctx, key)
+		if err == nil && count == 1 {
+			err = s.client.Expire(ctx, key, ttl)
+		}
+		if s.record(ctx, err) {
+			return count
+		}
+	}
+	return s.memory.incr(key, ttl)
+}
+
+// setUntil stores a time that something lasts until, such as a throttle, expiring at that
+// time. It's kept in memory if Redis fails.
+func (s *RedisService) setUntil(ctx context.Context, key string, until time.Time) {
+	ttl := time.Until(until)
+	if ttl <= 0 {
+		return
+	}
+	value := []byte(until.UTC().Format(time.RFC3339))
+	if s.usable() && s.record(ctx, s.client.Set(ctx, key, value, ttl)) {
+		return
+	}
+	s.memory.set(key, value, ttl)
+}
+
+// getUntil returns a time stored with setUntil, or the zero time if none is stored or it
+// has passed. Times stored during an outage are found in memory.
+func (s *RedisService) getUntil(ctx context.Context, key string) time.Time {
+	value, ok := s.memory.get(key)
+	if s.usable() {
+		data, err := s.client.Get(ctx, key)
+		if s.record(ctx, err) && err == nil {
+			value, ok = data, true
+		}
+	}
+	if !ok {
+		return time.Time{}
+	}
+
+	until, err := time.Parse(time.RFC3339, string(value))
 	if err != nil {
-		return false, fmt.Errorf("failed to check rate limit: %v", err)
+		fmt.Printf("Warning: Invalid time in %s: %v\n", key, err)
+		return time.Time{}
 	}
+	return until
+}
 
-	// Set expiry if this is a new key (30 minutes instead of 24 hours)
-	if count == 1 {
-		err = s.client.Expire(ctx, key, 30*time.Minute).Err()
-		if err != nil {
-			return false, fmt.Errorf("failed to set expiry on rate limit key: %v", err)
+// AddUserSpend adds to a user's estimated API spend today (UTC), in millionths of a
+// dollar, returning the day's total. Spend is added up in memory while Redis is unavailable.
+func (s *RedisService) AddUserSpend(ctx context.Context, userID string, microUSD int64) int64 {
+	key := userSpendKey(userID)
+	if s.usable() {
+		total, err := s.client.IncrBy(ctx, key, microUSD)
+		if err == nil && total == microUSD {
+			err = s.client.Expire(ctx, key, 48*time.Hour)
+		}
+		if s.record(ctx, err) {
+			return total
 		}
 	}
+	return s.memory.incrBy(key, microUSD, 48*time.Hour)
+}
+
+// GetUserSpend returns a user's estimated API spend today (UTC) in millionths of a dollar
+func (s *RedisService) GetUserSpend(ctx context.Context, userID string) (int64, error) {
+	key := userSpendKey(userID)
+	if s.usable() {
+		value, err := s.client.Get(ctx, key)
+		if err == redis.Nil {
+			return 0, nil
+		} else if s.record(ctx, err) {
+			total, err := strconv.ParseInt(string(value), 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("failed to get spend: %v", err)
+			}
+			return total, nil
+		}
+	}
+	return s.memory.count(key), nil
+}
 
-	// Check if rate limit exceeded (10 calls per user per endpoint per day)
-	return count > 30, nil
+// userSpendKey is where a user's spend today is added up
+func userSpendKey(userID string) string {
+	return fmt.Sprintf("spend:%s:%s", userID, time.Now().UTC().Format("2006-01-02"))
 }
 
 // GetRateLimitCount returns the current rate limit count for a user and endpoint
 func (s *RedisService) GetRateLimitCount(ctx context.Context, userId, endpoint string) (int, error) {
 	key := fmt.Sprintf("rate-limit:%s:%s:%s", userId, endpoint, time.Now().Format("2006-01-02"))
 
-	count, err := s.client.Get(ctx, key).Int()
-	if err == redis.Nil {
-		return 0, nil // Key doesn't exist, so count is 0
-	} else if err != nil {
-		return 0, fmt.Errorf("failed to get rate limit count: %v", err)
+	if s.usable() {
+		value, err := s.client.Get(ctx, key)
+		if err == redis.Nil {
+			return 0, nil // Key doesn't exist, so count is 0
+		} else if s.record(ctx, err) {
+			count, err := strconv.Atoi(string(value))
+			if err != nil {
+				return 0, fmt.Errorf("failed to get rate limit count: %v", err)
+			}
+			return count, nil
+		}
 	}
 
-	return count, nil
+	return int(s.memory.count(key)), nil
 }
 
-// StoreJWKs stores JWKS in Redis cache
-func (s *RedisService) StoreJWKs(ctx context.Context, jwksData []byte) error {
-	return s.client.Set(ctx, "clerk-jwks", jwksData, 30*time.Minute).Err()
+// StoreJWKs stores an issuer's JWKS in Redis cache
+func (s *RedisService) StoreJWKs(ctx context.Context, issuer string, jwksData []byte) error {
+	if !s.usable() {
+		return errRedisUnavailable
+	}
+	err := s.client.Set(ctx, "clerk-jwks:"+issuer, jwksData, 30*time.Minute)
 	// return s.client.Set(ctx, "clerk-jwks", jwksData, 24*time.hours).Err()
+	s.record(ctx, err)
+	return err
 }
 
-// GetJWKs retrieves JWKS from Redis cache
-func (s *RedisService) GetJWKs(ctx context.Context) ([]byte, error) {
-	return s.client.Get(ctx, "clerk-jwks").Bytes()
+// GetJWKs retrieves an issuer's JWKS from Redis cache. It fails without trying Redis
+// while Redis is unavailable, so callers fetch the keys from Clerk straight away.
+func (s *RedisService) GetJWKs(ctx context.Context, issuer string) ([]byte, error) {
+	if !s.usable() {
+		return nil, errRedisUnavailable
+	}
+	data, err := s.client.Get(ctx, "clerk-jwks:"+issuer)
+	s.record(ctx, err)
+	return data, err
 }
 
 // ClearRateLimits clears all rate limiting keys for a specific user
 func (s *RedisService) ClearRateLimits(ctx context.Context, userId string) (int64, error) {
 	pattern := fmt.Sprintf("rate-limit:%s:*", userId)
-	keys, err := s.client.Keys(ctx, pattern).Result()
-	if err != nil {
-		return 0, fmt.Errorf("failed to find keys: %v", err)
+	cleared := s.memory.deletePrefix(strings.TrimSuffix(pattern, "*"))
+	if !s.usable() {
+		return cleared, nil
+	}
+
+	keys, err := s.client.Keys(ctx, pattern)
+	if !s.record(ctx, err) {
+		return cleared, fmt.Errorf("failed to find keys: %v", err)
 	}
 
 	if len(keys) == 0 {
-		return 0, nil
+		return cleared, nil
 	}
 
-	return s.client.Del(ctx, keys...).Result()
+	deleted, err := s.client.Del(ctx, keys...)
+	s.record(ctx, err)
+	return cleared + deleted, err
 }
 
-// Ping checks if the Redis connection is alive
+// Ping checks if the Redis connection is alive, even while it's considered unavailable,
+// and records whether Redis is available, ending an outage as soon as it responds
 func (s *RedisService) Ping(ctx context.Context) (string, error) {
-	return s.client.Ping(ctx).Result()
+	result, err := s.client.Ping(ctx)
+	s.record(ctx, err)
+	return result, err
+}
+
+// CheckReadWrite round-trips a short-lived throwaway key to verify reads and writes work
+func (s *RedisService) CheckReadWrite(ctx context.Context) error {
+	key := fmt.Sprintf("selftest:%d", time.Now().UnixNano())
+	if err := s.client.Set(ctx, key, []byte("ok"), time.Minute); err != nil {
+		return fmt.Errorf("set failed: %v", err)
+	}
+	value, err := s.client.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("get failed: %v", err)
+	}
+	if string(value) != "ok" {
+		return fmt.Errorf("get returned %q, expected \"ok\"", value)
+	}
+	if _, err := s.client.Del(ctx, key); err != nil {
+		return fmt.Errorf("delete failed: %v", err)
+	}
+	return nil
+}
+
+// StoreDeletionPreview stores a pending delete-by-query preview under its confirmation
+// token, in memory if Redis is unavailable
+func (s *RedisService) StoreDeletionPreview(ctx context.Context, token string, preview []byte, ttl time.Duration) error {
+	key := "delete-preview:" + token
+	if s.usable() && s.record(ctx, s.client.Set(ctx, key, preview, ttl)) {
+		return nil
+	}
+	s.memory.set(key, preview, ttl)
+	return nil
+}
+
+// GetDeletionPreview retrieves a pending delete-by-query preview by its confirmation
+// token, from Redis or, for previews made during an outage, from memory. It returns
+// redis.Nil if there's no such preview.
+func (s *RedisService) GetDeletionPreview(ctx context.Context, token string) ([]byte, error) {
+	key := "delete-preview:" + token
+	if s.usable() {
+		data, err := s.client.Get(ctx, key)
+		if s.record(ctx, err) && err == nil {
+			return data, nil
+		}
+	}
+
+	if data, ok := s.memory.get(key); ok {
+		return data, nil
+	}
+	return nil, redis.Nil
+}
+
+// DeleteDeletionPreview removes a delete-by-query preview so its token can't be reused
+func (s *RedisService) DeleteDeletionPreview(ctx context.Context, token string) error {
+	key := "delete-preview:" + token
+	s.memory.del(key)
+	if !s.usable() {
+		// Previews in Redis can't be read during the outage, and expire on their own
+		return nil
+	}
+	_, err := s.client.Del(ctx, key)
+	s.record(ctx, err)
+	return err
 }
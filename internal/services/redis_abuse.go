@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// ThrottledHourlyLimit is how many calls a user throttled for anomalous usage may make
+	// to rate-limited endpoints per hour
+	ThrottledHourlyLimit = 10
+	// clientAddressInterval is how often a user's use of one client address is recorded
+	clientAddressInterval = time.Hour
+)
+
+// abuseThrottleKey holds when a throttled user's throttling ends
+func abuseThrottleKey(userID string) string {
+	return "abuse-throttle:" + userID
+}
+
+// ThrottleUser throttles a user until the given time, in memory if Redis is unavailable
+func (s *RedisService) ThrottleUser(ctx context.Context, userID string, until time.Time) {
+	s.setUntil(ctx, abuseThrottleKey(userID), until)
+}
+
+// ThrottledUntil returns when a user's throttling ends, or the zero time if they aren't
+// throttled. Users throttled during an outage are found in memory.
+func (s *RedisService) ThrottledUntil(ctx context.Context, userID string) time.Time {
+	return s.getUntil(ctx, abuseThrottleKey(userID))
+}
+
+// UnthrottleUser ends a user's throttling
+func (s *RedisService) UnthrottleUser(ctx context.Context, userID string) error {
+	key := abuseThrottleKey(userID)
+	s.memory.del(key)
+	if !s.usable() {
+		return errRedisUnavailable
+	}
+	_, err := s.client.Del(ctx, key)
+	s.record(ctx, err)
+	return err
+}
+
+// CheckThrottledRateLimit counts a throttled user's call and checks whether it exceeds
+// ThrottledHourlyLimit. Returns the hour's call count and true if the limit is exceeded.
+func (s *RedisService) CheckThrottledRateLimit(ctx context.Context, userID string) (int, bool) {
+	key := fmt.Sprintf("abuse-throttle-count:%s:%d", userID, time.Now().Unix()/int64(time.Hour.Seconds()))
+	count := s.incr(ctx, key, time.Hour)
+	return int(count), count > ThrottledHourlyLimit
+}
+
+// FirstClientAddressUse reports whether this is a user's first request from a client
+// address within the hour, so the address is recorded once per hour rather than on
+// every request
+func (s *RedisService) FirstClientAddressUse(ctx context.Context, userID, address string) bool {
+	key := fmt.Sprintf("client-address:%s:%s", userID, address)
+	return s.incr(ctx, key, clientAddressInterval) == 1
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Documents retrieved often are "hot": their chunk text is cached in Redis, so queries
+// matching their chunks skip the MongoDB lookup
+const (
+	// HotDocumentRetrievals is how many queries must retrieve a document within
+	// hotDocumentWindow for its chunk text to be cached
+	HotDocumentRetrievals = 3
+	hotDocumentWindow     = time.Hour
+	// hotDocumentTTL is how long a hot document's chunk text stays cached
+	hotDocumentTTL = 6 * time.Hour
+)
+
+// hotChunkKey holds the text of one chunk of a hot document
+func hotChunkKey(userID, vectorID string) string {
+	return fmt.Sprintf("hot-chunk:%s:%s", userID, vectorID)
+}
+
+// hotDocumentKey lists the vector IDs of a hot document's cached chunks
+func hotDocumentKey(userID, parentID string) string {
+	return fmt.Sprintf("hot-doc:%s:%s", userID, parentID)
+}
+
+// hotRetrievalsKey counts recent queries retrieving a document
+func hotRetrievalsKey(userID, parentID string) string {
+	return fmt.Sprintf("hot-doc-retrievals:%s:%s", userID, parentID)
+}
+
+// GetHotChunkTexts returns the cached text of those of the given vectors that are chunks
+// of hot documents, keyed by vector ID. Nothing is returned while Redis is unavailable,
+// so every text is read from MongoDB.
+func (s *RedisService) GetHotChunkTexts(ctx context.Context, userID string, vectorIDs []string) map[string]string {
+	if len(vectorIDs) == 0 || !s.usable() {
+		return nil
+	}
+
+	keys := make([]string, len(vectorIDs))
+	for i, vectorID := range vectorIDs {
+		keys[i] = hotChunkKey(userID, vectorID)
+	}
+	values, err := s.client.MGet(ctx, keys...)
+	if !s.record(ctx, err) {
+		return nil
+	}
+
+	texts := make(map[string]string)
+	for i, value := range values {
+		if value != nil && i < len(vectorIDs) {
+			texts[vectorIDs[i]] = string(value)
+		}
+	}
+	return texts
+}
+
+// CountDocumentRetrieval counts a query retrieving a document, returning how many have
+// within the hot document window, or 0 while Redis is unavailable
+func (s *RedisService) CountDocumentRetrieval(ctx context.Context, userID, parentID string) int64 {
+	if !s.usable() {
+		return 0
+	}
+	key := hotRetrievalsKey(userID, parentID)
+	count, err := s.client.Incr(ctx, key)
+	if err == nil && count == 1 {
+		err = s.client.Expire(ctx, key, hotDocumentWindow)
+	}
+	if !s.record(ctx, err) {
+		return 0
+	}
+	return count
+}
+
+// CacheHotDocument caches the text of a document's chunks, keyed by vector ID. The list of
+// the document's chunks is written first and outlives them, so InvalidateHotDocument finds
+// every chunk even if caching stops part way.
+func (s *RedisService) CacheHotDocument(ctx context.Context, userID, parentID string, texts map[string]string) error {
+	if !s.usable() {
+		return errRedisUnavailable
+	}
+
+	vectorIDs := make([]string, 0, len(texts))
+	for vectorID := range texts {
+		vectorIDs = append(vectorIDs, vectorID)
+	}
+	list, err := json.Marshal(vectorIDs)
+	if err != nil {
+		return err
+	}
+	err = s.client.Set(ctx, hotDocumentKey(userID, parentID), list, hotDocumentTTL+time.Minute)
+	if !s.record(ctx, err) {
+		return err
+	}
+
+	for _, vectorID := range vectorIDs {
+		err := s.client.Set(ctx, hotChunkKey(userID, vectorID), []byte(texts[vectorID]), hotDocumentTTL)
+		if !s.record(ctx, err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// InvalidateHotDocument removes a document's cached chunk text and its retrieval count,
+// after its chunks are edited or deleted. While Redis is unavailable nothing can be
+// removed; cached text then expires on its own.
+func (s *RedisService) InvalidateHotDocument(ctx context.Context, userID, parentID string) error {
+	if !s.usable() {
+		return nil
+	}
+
+	listKey := hotDocumentKey(userID, parentID)
+	keys := []string{listKey, hotRetrievalsKey(userID, parentID)}
+	list, err := s.client.Get(ctx, listKey)
+	if !s.record(ctx, err) {
+		return err
+	}
+	var vectorIDs []string
+	if err != redis.Nil {
+		if err := json.Unmarshal(list, &vectorIDs); err != nil {
+			fmt.Printf("Warning: Invalid hot document list %s: %v\n", listKey, err)
+		}
+	}
+	for _, vectorID := range vectorIDs {
+		keys = append(keys, hotChunkKey(userID, vectorID))
+	}
+
+	_, err = s.client.Del(ctx, keys...)
+	s.record(ctx, err)
+	return err
+}
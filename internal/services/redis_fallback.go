@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	// redisRetryInterval is how long Redis is skipped after a failed command before it's
+	// tried again, so an outage doesn't add a connection timeout to every request
+	redisRetryInterval = 10 * time.Second
+	// memorySweepInterval is how often expired in-memory fallback entries are removed
+	memorySweepInterval = time.Minute
+)
+
+// errRedisUnavailable is returned by cache reads and writes skipped during an outage
+var errRedisUnavailable = errors.New("redis is unavailable")
+
+// RedisFallbacks is what each Redis-backed feature uses while Redis is unavailable
+var RedisFallbacks = map[string]string{
+	"rate_limits":       "in-memory counters on this instance",
+	"jwks":              "fetched directly from Clerk",
+	"deletion_previews": "in-memory on this instance until they expire",
+	"sessions":          "in-memory on this instance",
+	"hot_documents":     "chunk text read from MongoDB",
+	"spending":          "in-memory totals on this instance",
+	"abuse_throttles":   "in-memory on this instance until they expire",
+	"tweets":            "fetched from the X API on every save, backing off in memory on this instance",
+}
+
+// RedisDegradation describes whether Redis is unavailable and the fallbacks in use
+type RedisDegradation struct {
+	Degraded  bool              `json:"degraded"`
+	Since     *time.Time        `json:"since,omitempty"`
+	LastError string            `json:"last_error,omitempty"`
+	Fallbacks map[string]string `json:"fallbacks,omitempty"`
+}
+
+// redisState tracks Redis outages, so features fall back consistently while it's down
+type redisState struct {
+	mu          sync.Mutex
+	downSince   time.Time
+	lastFailure time.Time
+	lastError   string
+}
+
+// usable reports whether Redis should be tried: when it's up, or when it's been down
+// long enough that it's worth checking again
+func (s *RedisService) usable() bool {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+	return s.state.downSince.IsZero() || time.Since(s.state.lastFailure) >= redisRetryInterval
+}
+
+// record notes the outcome of a Redis command and reports whether it succeeded. Missing
+// keys count as success, and failures caused by the caller's own cancelled context don't
+// mark Redis as down.
+func (s *RedisService) record(ctx context.Context, err error) bool {
+	if err != nil && err != redis.Nil {
+		if ctx.Err() != nil {
+			return false
+		}
+
+		s.state.mu.Lock()
+		defer s.state.mu.Unlock()
+		now := time.Now()
+		if s.state.downSince.IsZero() {
+			s.state.downSince = now
+			fmt.Printf("Warning: Redis is unavailable, falling back to in-memory state: %v\n", err)
+		}
+		s.state.lastFailure = now
+		s.state.lastError = err.Error()
+		return false
+	}
+
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+	if !s.state.downSince.IsZero() {
+		fmt.Printf("Redis is available again after %s\n", time.Since(s.state.downSince).Round(time.Second))
+		s.state.downSince = time.Time{}
+		s.state.lastError = ""
+	}
+	return true
+}
+
+// Degradation returns whether Redis is currently unavailable and what replaces it
+func (s *RedisService) Degradation() RedisDegradation {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+	if s.state.downSince.IsZero() {
+		return RedisDegradation{}
+	}
+	since := s.state.downSince
+	return RedisDegradation{
+		Degraded:  true,
+		Since:     &since,
+		LastError: s.state.lastError,
+		Fallbacks: RedisFallbacks,
+	}
+}
+
+// memoryEntry is a counter or value held in memory while Redis is unavailable
+type memoryEntry struct {
+	count   int64
+	value   []byte
+	expires time.Time
+}
+
+// memoryStore keeps expiring counters and values for one instance, standing in for Redis
+// during an outage
+type memoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	lastSweep time.Time
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{entries: make(map[string]memoryEntry)}
+}
+
+// incr increments a counter, starting it with the given expiry if it doesn't exist
+func (m *memoryStore) incr(key string, ttl time.Duration) int64 {
+	return m.incrBy(key, 1, ttl)
+}
+
+// incrBy adds amount to a counter, starting it with the given expiry if it doesn't exist
+func (m *memoryStore) incrBy(key string, amount int64, ttl time.Duration) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.sweep(now)
+	entry, ok := m.live(key, now)
+	if !ok {
+		entry = memoryEntry{expires: now.Add(ttl)}
+	}
+	entry.count += amount
+	m.entries[key] = entry
+	return entry.count
+}
+
+// count returns a counter's value, or 0 if it doesn't exist
+func (m *memoryStore) count(key string) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, _ := m.live(key, time.Now())
+	return entry.count
+}
+
+// set stores a value until it expires
+func (m *memoryStore) set(key string, value []byte, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.sweep(now)
+	m.entries[key] = memoryEntry{value: value, expires: now.Add(ttl)}
+}
+
+// get returns a stored value, if it exists and hasn't expired
+func (m *memoryStore) get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := m.live(key, time.Now())
+	return entry.value, ok
+}
+
+// del removes an entry
+func (m *memoryStore) del(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+}
+
+// deletePrefix removes every entry whose key starts with prefix and returns how many
+// live entries were removed
+func (m *memoryStore) deletePrefix(prefix string) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	var removed int64
+	for key, entry := range m.entries {
+		if strings.HasPrefix(key, prefix) {
+			if now.Before(entry.expires) {
+				removed++
+			}
+			delete(m.entries, key)
+		}
+	}
+	return removed
+}
+
+// live returns an entry if it exists and hasn't expired. The caller holds m.mu.
+func (m *memoryStore) live(key string, now time.Time) (memoryEntry, bool) {
+	entry, ok := m.entries[key]
+	if !ok || !now.Before(entry.expires) {
+		return memoryEntry{}, false
+	}
+	return entry, true
+}
+
+// sweep removes expired entries, at most once per memorySweepInterval. The caller holds m.mu.
+func (m *memoryStore) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < memorySweepInterval {
+		return
+	}
+	m.lastSweep = now
+	for key, entry := range m.entries {
+		if !now.Before(entry.expires) {
+			delete(m.entries, key)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/siddhantgupta/forgetai-backend/internal/timing"
+)
+
+// NewUpstashRedisService creates a Redis service using Upstash's REST API, which runs each
+// command as an HTTPS request authorized with a token, so there's no connection to keep
+// open or wait for at startup
+func NewUpstashRedisService(restURL, token string) (*RedisService, error) {
+	if token == "" {
+		return nil, fmt.Errorf("upstash REST token is not set")
+	}
+	return newRedisService(&upstashClient{
+		client: &http.Client{Timeout: 10 * time.Second},
+		url:    strings.TrimRight(restURL, "/"),
+		token:  token,
+	}), nil
+}
+
+// upstashClient is a redisClient using Upstash's REST API
+type upstashClient struct {
+	client *http.Client
+	url    string
+	token  string
+}
+
+// upstashResponse is the body returned for a command: its result, or an error message
+type upstashResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  string          `json:"error"`
+}
+
+// do runs a command, given as its name and arguments, and decodes its result into result.
+// A null result, such as GET of a missing key, is returned as redis.Nil.
+func (c *upstashClient) do(ctx context.Context, result interface{}, command ...string) error {
+	start := time.Now()
+	defer func() { timing.Record(ctx, timing.Redis, strings.ToLower(command[0]), time.Since(start)) }()
+
+	body, err := json.Marshal(command)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var decoded upstashResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return fmt.Errorf("upstash returned status %d: %v", resp.StatusCode, err)
+	}
+	if decoded.Error != "" {
+		return fmt.Errorf("upstash %s failed: %s", command[0], decoded.Error)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("upstash returned status: %d", resp.StatusCode)
+	}
+	if len(decoded.Result) == 0 || string(decoded.Result) == "null" {
+		return redis.Nil
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(decoded.Result, result)
+}
+
+func (c *upstashClient) Incr(ctx context.Context, key string) (int64, error) {
+	var count int64
+	err := c.do(ctx, &count, "INCR", key)
+	return count, err
+}
+
+func (c *upstashClient) IncrBy(ctx context.Context, key string, amount int64) (int64, error) {
+	var total int64
+	err := c.do(ctx, &total, "INCRBY", key, strconv.FormatInt(amount, 10))
+	return total, err
+}
+
+func (c *upstashClient) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	return c.do(ctx, nil, "PEXPIRE", key, strconv.FormatInt(ttl.Milliseconds(), 10))
+}
+
+func (c *upstashClient) Get(ctx context.Context, key string) ([]byte, error) {
+	var value string
+	if err := c.do(ctx, &value, "GET", key); err != nil {
+		return nil, err
+	}
+	return []byte(value), nil
+}
+
+func (c *upstashClient) MGet(ctx context.Context, keys ...string) ([][]byte, error) {
+	var results []*string
+	if err := c.do(ctx, &results, append([]string{"MGET"}, keys...)...); err != nil {
+		return nil, err
+	}
+	values := make([][]byte, len(results))
+	for i, result := range results {
+		if result != nil {
+			values[i] = []byte(*result)
+		}
+	}
+	return values, nil
+}
+
+func (c *upstashClient) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.do(ctx, nil, "SET", key, string(value), "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+}
+
+func (c *upstashClient) Keys(ctx context.Context, pattern string) ([]string, error) {
+	var keys []string
+	if err := c.do(ctx, &keys, "KEYS", pattern); err != nil && err != redis.Nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (c *upstashClient) Del(ctx context.Context, keys ...string) (int64, error) {
+	var deleted int64
+	err := c.do(ctx, &deleted, append([]string{"DEL"}, keys...)...)
+	return deleted, err
+}
+
+func (c *upstashClient) Ping(ctx context.Context) (string, error) {
+	var result string
+	err := c.do(ctx, &result, "PING")
+	return result, err
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+	"time"
+)
+
+// xAPIBackoffKey holds when the X API's rate limit resets, while it's rejecting requests
+const xAPIBackoffKey = "x-api-backoff"
+
+// tweetCacheKey holds the X API's response for a tweet lookup
+func tweetCacheKey(tweetID string) string {
+	return "tweet:" + tweetID
+}
+
+// GetCachedTweet returns the cached X API response for a tweet, or redis.Nil if it isn't
+// cached. Nothing is cached while Redis is unavailable.
+func (s *RedisService) GetCachedTweet(ctx context.Context, tweetID string) ([]byte, error) {
+	if !s.usable() {
+		return nil, errRedisUnavailable
+	}
+	data, err := s.client.Get(ctx, tweetCacheKey(tweetID))
+	s.record(ctx, err)
+	return data, err
+}
+
+// CacheTweet caches the X API response for a tweet, unless Redis is unavailable
+func (s *RedisService) CacheTweet(ctx context.Context, tweetID string, data []byte, ttl time.Duration) {
+	if s.usable() {
+		s.record(ctx, s.client.Set(ctx, tweetCacheKey(tweetID), data, ttl))
+	}
+}
+
+// SetXAPIBackoff records that the X API rejects requests until the given time, so every
+// instance waits for the rate limit to reset instead of calling it. The backoff is kept in
+// memory if Redis is unavailable.
+func (s *RedisService) SetXAPIBackoff(ctx context.Context, until time.Time) {
+	s.setUntil(ctx, xAPIBackoffKey, until)
+}
+
+// XAPIBackoff returns when the X API's rate limit resets, or the zero time if it isn't
+// rejecting requests
+func (s *RedisService) XAPIBackoff(ctx context.Context) time.Time {
+	return s.getUntil(ctx, xAPIBackoffKey)
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// FileStore stores uploaded files as objects in a bucket, such as GCS or S3
+type FileStore interface {
+	Upload(ctx context.Context, name, contentType string, body io.Reader) error
+	Download(ctx context.Context, name string) (io.ReadCloser, error)
+	Delete(ctx context.Context, name string) error
+}
+
+// S3Service reads and writes objects in an Amazon S3 bucket, or a bucket of an
+// S3-compatible store, with requests signed by AWS Signature Version 4
+type S3Service struct {
+	client          *http.Client
+	bucket          string
+	region          string
+	endpoint        string // e.g. "https://s3.us-east-1.amazonaws.com"
+	accessKeyID     string
+	secretAccessKey string
+}
+
+// NewS3Service creates a new S3 service for a bucket. endpoint is the store's URL, or ""
+// for Amazon S3 in region; objects are addressed by path, as S3-compatible stores expect.
+func NewS3Service(bucket, region, endpoint, accessKeyID, secretAccessKey string) *S3Service {
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+	return &S3Service{
+		// Uploads of long recordings can take a while, so they get no overall timeout
+		client:          &http.Client{},
+		bucket:          bucket,
+		region:          region,
+		endpoint:        strings.TrimSuffix(endpoint, "/"),
+		accessKeyID:     accessKeyID,
+		secretAccessKey: secretAccessKey,
+	}
+}
+
+// Upload stores an object in the bucket, replacing any object with the same name.
+// S3 needs the length up front, so bodies that can't seek are read into memory first.
+func (s *S3Service) Upload(ctx context.Context, name, contentType string, body io.Reader) error {
+	seeker, ok := body.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", name, err)
+		}
+		seeker = bytes.NewReader(data)
+	}
+	size, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("failed to measure %s: %v", name, err)
+	}
+	if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to measure %s: %v", name, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(name), seeker)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := s.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload %s: %v", name, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Download opens an object for reading. The caller must close it.
+func (s *S3Service) Download(ctx context.Context, name string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(name), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %v", name, err)
+	}
+	return resp.Body, nil
+}
+
+// Delete removes an object from the bucket
+func (s *S3Service) Delete(ctx context.Context, name string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(name), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to delete %s: %v", name, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// objectURL is the path-style URL of an object, with each segment of its name escaped
+func (s *S3Service) objectURL(name string) string {
+	segments := strings.Split(name, "/")
+	for i, segment := range segments {
+		segments[i] = s3Escape(segment)
+	}
+	return fmt.Sprintf("%s/%s/%s", s.endpoint, s3Escape(s.bucket), strings.Join(segments, "/"))
+}
+
+// do signs and sends a request, returning the response if it succeeded
+func (s *S3Service) do(req *http.Request) (*http.Response, error) {
+	s.sign(req, time.Now().UTC())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		resp.Body.Close()
+		return nil, fmt.Errorf("S3 API returned status: %d", resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// sign adds an AWS Signature Version 4 Authorization header to a request. The payload
+// isn't hashed, so uploads stream without being read twice; requests go over TLS.
+func (s *S3Service) sign(req *http.Request, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", "UNSIGNED-PAYLOAD")
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := fmt.Sprintf("host:%s\nx-amz-content-sha256:UNSIGNED-PAYLOAD\nx-amz-date:%s\n", req.URL.Host, amzDate)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", day, s.region)
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hex.EncodeToString(requestHash[:])}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretAccessKey), day)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKeyID, scope, signedHeaders, signature))
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data under key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3Escape escapes a path segment the way SigV4 canonical URIs expect: everything but
+// unreserved characters is percent-encoded
+func s3Escape(segment string) string {
+	return strings.ReplaceAll(url.QueryEscape(segment), "+", "%20")
+}
@@ -81,6 +81,23 @@ func (s *SessionService) GetSessionMessages(sessionId string) []openai.ChatCompl
 	return models.ToOpenAIChatMessages(session.Messages)
 }
 
+// SetPinnedItems replaces the items pinned to a session, returning false if the session
+// doesn't exist
+func (s *SessionService) SetPinnedItems(sessionId string, ids []string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, exists := s.sessions[sessionId]
+	if !exists {
+		return false
+	}
+
+	session.PinnedIDs = ids
+	session.UpdatedAt = time.Now()
+	s.sessions[sessionId] = session
+	return true
+}
+
 // GetSession gets a session by ID
 func (s *SessionService) GetSession(sessionId string) (models.ChatSession, bool) {
 	s.mu.RLock()
@@ -0,0 +1,367 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	slackAPIBaseURL   = "https://slack.com/api"
+	slackAuthorizeURL = "https://slack.com/oauth/v2/authorize"
+	// slackBotScopes let the app add its message action and reply to the user who saved
+	slackBotScopes = "commands,chat:write"
+	// slackUserScopes let the app read the messages the installing user can read, see
+	// their reactions and name the authors of saved messages
+	slackUserScopes = "channels:history,groups:history,im:history,mpim:history,reactions:read,users:read"
+	// slackRequestTolerance is how old a signed request can be before it's rejected as a replay
+	slackRequestTolerance = 5 * time.Minute
+	// slackInstallStateTTL is how long an install link stays valid
+	slackInstallStateTTL = 30 * time.Minute
+	// slackMaxThreadMessages caps the messages read from one thread
+	slackMaxThreadMessages = 1000
+)
+
+// SlackService handles the Slack app's OAuth installs, request verification and Web API calls
+type SlackService struct {
+	clientID      string
+	clientSecret  string
+	signingSecret string
+	redirectURL   string
+	saveReaction  string
+	client        *http.Client
+}
+
+// SlackInstallation is the result of a user installing the app
+type SlackInstallation struct {
+	TeamID      string
+	TeamName    string
+	SlackUserID string
+	BotToken    string
+	UserToken   string
+}
+
+// SlackMessage is a message of a Slack conversation
+type SlackMessage struct {
+	User     string `json:"user"`
+	Username string `json:"username"` // name of bot and integration messages
+	Text     string `json:"text"`
+	TS       string `json:"ts"`
+	Files    []struct {
+		Name  string `json:"name"`
+		Title string `json:"title"`
+	} `json:"files"`
+}
+
+// SentAt returns when the message was sent, from its timestamp
+func (m *SlackMessage) SentAt() time.Time {
+	seconds, err := strconv.ParseFloat(m.TS, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(int64(seconds), 0)
+}
+
+// slackInstallState is the signed state carried through an install, naming the user who
+// started it
+type slackInstallState struct {
+	UserID  string `json:"u"`
+	Plan    string `json:"p,omitempty"`
+	Expires int64  `json:"e"`
+}
+
+// NewSlackService creates a new Slack service for the app with the given credentials.
+// The redirect URL is where Slack returns users after they authorize an install, and the
+// save reaction is the emoji that saves the message it's added to.
+func NewSlackService(clientID, clientSecret, signingSecret, redirectURL, saveReaction string) *SlackService {
+	return &SlackService{
+		clientID:      clientID,
+		clientSecret:  clientSecret,
+		signingSecret: signingSecret,
+		redirectURL:   redirectURL,
+		saveReaction:  saveReaction,
+		client:        &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// SaveReaction returns the name of the emoji that saves the message it's added to
+func (s *SlackService) SaveReaction() string {
+	return s.saveReaction
+}
+
+// InstallURL returns the link that installs the app for the user. The link's state is
+// signed, so the OAuth callback can trust the user it names.
+func (s *SlackService) InstallURL(userID, plan string) (string, error) {
+	payload, err := json.Marshal(slackInstallState{
+		UserID:  userID,
+		Plan:    plan,
+		Expires: time.Now().Add(slackInstallStateTTL).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	state := encoded + "." + base64.RawURLEncoding.EncodeToString(s.sign([]byte(encoded)))
+
+	params := url.Values{
+		"client_id":    {s.clientID},
+		"scope":        {slackBotScopes},
+		"user_scope":   {slackUserScopes},
+		"redirect_uri": {s.redirectURL},
+		"state":        {state},
+	}
+	return slackAuthorizeURL + "?" + params.Encode(), nil
+}
+
+// ParseInstallState verifies the state returned to the OAuth callback, returning the user
+// who started the install and their plan
+func (s *SlackService) ParseInstallState(state string) (string, string, error) {
+	encoded, signature, ok := strings.Cut(state, ".")
+	if !ok {
+		return "", "", fmt.Errorf("malformed install state")
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, s.sign([]byte(encoded))) {
+		return "", "", fmt.Errorf("invalid install state signature")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", "", fmt.Errorf("malformed install state")
+	}
+	var parsed slackInstallState
+	if err := json.Unmarshal(payload, &parsed); err != nil {
+		return "", "", fmt.Errorf("malformed install state")
+	}
+	if time.Now().Unix() > parsed.Expires {
+		return "", "", fmt.Errorf("install link has expired")
+	}
+	return parsed.UserID, parsed.Plan, nil
+}
+
+// sign returns the HMAC of data keyed with the app's client secret
+func (s *SlackService) sign(data []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(s.clientSecret))
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// VerifyRequest reports whether a request body was signed by Slack with the app's signing
+// secret, from the request's X-Slack-Request-Timestamp and X-Slack-Signature headers
+func (s *SlackService) VerifyRequest(timestamp, signature string, body []byte) bool {
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := time.Since(time.Unix(sent, 0)); age > slackRequestTolerance || age < -slackRequestTolerance {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(s.signingSecret))
+	fmt.Fprintf(mac, "v0:%s:", timestamp)
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// ExchangeCode completes an install, exchanging the authorization code for the app's tokens
+func (s *SlackService) ExchangeCode(ctx context.Context, code string) (*SlackInstallation, error) {
+	form := url.Values{
+		"client_id":     {s.clientID},
+		"client_secret": {s.clientSecret},
+		"code":          {code},
+		"redirect_uri":  {s.redirectURL},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, slackAPIBaseURL+"/oauth.v2.access", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		Team        struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"team"`
+		AuthedUser struct {
+			ID          string `json:"id"`
+			AccessToken string `json:"access_token"`
+		} `json:"authed_user"`
+	}
+	if err := s.do(req, "oauth.v2.access", &result); err != nil {
+		return nil, err
+	}
+	if result.AuthedUser.AccessToken == "" {
+		return nil, fmt.Errorf("Slack did not grant the user token")
+	}
+
+	return &SlackInstallation{
+		TeamID:      result.Team.ID,
+		TeamName:    result.Team.Name,
+		SlackUserID: result.AuthedUser.ID,
+		BotToken:    result.AccessToken,
+		UserToken:   result.AuthedUser.AccessToken,
+	}, nil
+}
+
+// GetThread gets a message and, if it's part of a thread, the whole thread in order. ts
+// may be the thread's parent or any reply.
+func (s *SlackService) GetThread(ctx context.Context, token, channel, ts string) ([]SlackMessage, error) {
+	var messages []SlackMessage
+	cursor := ""
+	for len(messages) < slackMaxThreadMessages {
+		params := url.Values{"channel": {channel}, "ts": {ts}, "limit": {"200"}}
+		if cursor != "" {
+			params.Set("cursor", cursor)
+		}
+		var page struct {
+			Messages []SlackMessage `json:"messages"`
+			Metadata struct {
+				NextCursor string `json:"next_cursor"`
+			} `json:"response_metadata"`
+		}
+		if err := s.get(ctx, token, "conversations.replies", params, &page); err != nil {
+			return nil, err
+		}
+		messages = append(messages, page.Messages...)
+		if page.Metadata.NextCursor == "" {
+			break
+		}
+		cursor = page.Metadata.NextCursor
+	}
+	if len(messages) > slackMaxThreadMessages {
+		messages = messages[:slackMaxThreadMessages]
+	}
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("message not found")
+	}
+	return messages, nil
+}
+
+// GetUserName returns the display name of a Slack user, falling back to their real name
+func (s *SlackService) GetUserName(ctx context.Context, token, userID string) (string, error) {
+	var result struct {
+		User struct {
+			Name    string `json:"name"`
+			Profile struct {
+				DisplayName string `json:"display_name"`
+				RealName    string `json:"real_name"`
+			} `json:"profile"`
+		} `json:"user"`
+	}
+	if err := s.get(ctx, token, "users.info", url.Values{"user": {userID}}, &result); err != nil {
+		return "", err
+	}
+	switch {
+	case result.User.Profile.DisplayName != "":
+		return result.User.Profile.DisplayName, nil
+	case result.User.Profile.RealName != "":
+		return result.User.Profile.RealName, nil
+	}
+	return result.User.Name, nil
+}
+
+// GetPermalink returns the link to a message
+func (s *SlackService) GetPermalink(ctx context.Context, token, channel, ts string) (string, error) {
+	var result struct {
+		Permalink string `json:"permalink"`
+	}
+	if err := s.get(ctx, token, "chat.getPermalink", url.Values{"channel": {channel}, "message_ts": {ts}}, &result); err != nil {
+		return "", err
+	}
+	return result.Permalink, nil
+}
+
+// PostEphemeral shows a message only the Slack user can see in a channel
+func (s *SlackService) PostEphemeral(ctx context.Context, token, channel, user, text string) error {
+	payload, err := json.Marshal(map[string]string{"channel": channel, "user": user, "text": text})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, slackAPIBaseURL+"/chat.postEphemeral", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+token)
+	return s.do(req, "chat.postEphemeral", nil)
+}
+
+// Respond replies to an interaction through its response URL, visible only to the user
+func (s *SlackService) Respond(ctx context.Context, responseURL, text string) error {
+	payload, err := json.Marshal(map[string]string{"response_type": "ephemeral", "text": text})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, responseURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Slack response failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Slack response returned status: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// get calls a Web API read method with the given token
+func (s *SlackService) get(ctx context.Context, token, method string, params url.Values, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, slackAPIBaseURL+"/"+method+"?"+params.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return s.do(req, method, v)
+}
+
+// do sends a Web API request, decoding the result into v if it's not nil. The Web API
+// reports failures in the body's "error" field rather than the status code.
+func (s *SlackService) do(req *http.Request, method string, v interface{}) error {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Slack API request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read Slack response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Slack API %s returned status: %d", method, resp.StatusCode)
+	}
+
+	var status struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &status); err != nil {
+		return fmt.Errorf("failed to decode Slack response: %v", err)
+	}
+	if !status.OK {
+		return fmt.Errorf("Slack API %s failed: %s", method, status.Error)
+	}
+	if v != nil {
+		if err := json.Unmarshal(body, v); err != nil {
+			return fmt.Errorf("failed to decode Slack %s result: %v", method, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// UsagePricesUSD are approximate list prices in USD per unit used for spend estimates
+var UsagePricesUSD = map[string]map[string]float64{
+	ProviderOpenAI: {
+		UnitEmbeddingTokens:      0.02 / 1e6, // text-embedding-3-small
+		UnitLargeEmbeddingTokens: 0.13 / 1e6, // text-embedding-3-large
+		UnitChatPromptTokens:     0.15 / 1e6, // gpt-4o-mini input
+		UnitChatCompletionTokens: 0.60 / 1e6, // gpt-4o-mini output
+		UnitSpeechCharacters:     15.0 / 1e6, // tts-1
+	},
+	ProviderPinecone: {
+		UnitReadUnits:  16.0 / 1e6,
+		UnitWriteUnits: 4.0 / 1e6,
+	},
+}
+
+// SpendingCapExceededError reports that a user's estimated API spend today reached the
+// daily spending cap
+type SpendingCapExceededError struct {
+	SpentUSD float64
+	CapUSD   float64
+	ResetsAt time.Time // the next UTC midnight
+}
+
+func (e *SpendingCapExceededError) Error() string {
+	return fmt.Sprintf("daily spending cap of $%.2f reached ($%.2f spent)", e.CapUSD, e.SpentUSD)
+}
+
+// SpendingService estimates the cost of OpenAI tokens used on users' behalf, adds it up
+// per user per UTC day in Redis, and enforces a daily cap on it
+type SpendingService struct {
+	redis  *RedisService
+	capUSD float64           // 0 for no cap
+	next   UserUsageRecorder // also receives the usage, e.g. for usage history; may be nil
+}
+
+// NewSpendingService creates a new spending service passing token usage on to next.
+// capUSD is each user's daily spending cap, or 0 to only track spend.
+func NewSpendingService(redis *RedisService, capUSD float64, next UserUsageRecorder) *SpendingService {
+	return &SpendingService{
+		redis:  redis,
+		capUSD: capUSD,
+		next:   next,
+	}
+}
+
+// CapUSD returns the daily spending cap, or 0 if there is none
+func (s *SpendingService) CapUSD() float64 {
+	return s.capUSD
+}
+
+// RecordUserTokens adds the estimated cost of tokens used on a user's behalf to their
+// spend today. It's called after every API call, so the spend is added in the background.
+func (s *SpendingService) RecordUserTokens(userID, unit string, amount int64) {
+	if s.next != nil {
+		s.next.RecordUserTokens(userID, unit, amount)
+	}
+	if userID == "" || amount <= 0 {
+		return
+	}
+
+	microUSD := int64(math.Ceil(float64(amount) * UsagePricesUSD[ProviderOpenAI][unit] * 1e6))
+	if microUSD <= 0 {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		s.redis.AddUserSpend(ctx, userID, microUSD)
+	}()
+}
+
+// Spent returns a user's estimated spend today in USD
+func (s *SpendingService) Spent(ctx context.Context, userID string) (float64, error) {
+	microUSD, err := s.redis.GetUserSpend(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	return float64(microUSD) / 1e6, nil
+}
+
+// Check returns a *SpendingCapExceededError once the user's spend today has reached the cap
+func (s *SpendingService) Check(ctx context.Context, userID string) error {
+	if s.capUSD <= 0 {
+		return nil
+	}
+	spent, err := s.Spent(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if spent < s.capUSD {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	return &SpendingCapExceededError{
+		SpentUSD: spent,
+		CapUSD:   s.capUSD,
+		ResetsAt: time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC),
+	}
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+const (
+	// summaryGroupChars is the amount of source text summarized by a single map call
+	summaryGroupChars = 12000
+	// summaryConcurrency bounds parallel summarization calls per document
+	summaryConcurrency = 4
+	// maxSummaryLevels bounds how many times summaries are re-summarized
+	maxSummaryLevels = 5
+)
+
+// SummarizerService summarizes documents of any length by summarizing groups of
+// chunks (map) and then summarizing the summaries (reduce) until they fit in one call
+type SummarizerService struct {
+	openAI *OpenAIService
+}
+
+// NewSummarizerService creates a new summarizer service
+func NewSummarizerService(openAI *OpenAIService) *SummarizerService {
+	return &SummarizerService{openAI: openAI}
+}
+
+// Summarize produces a single summary of the given ordered chunks. The title and
+// instructions (e.g. "focus on decisions") are passed to every call.
+func (s *SummarizerService) Summarize(ctx context.Context, title string, chunks []string, instructions string) (string, error) {
+	if len(chunks) == 0 {
+		return "", fmt.Errorf("nothing to summarize")
+	}
+
+	parts := chunks
+	for level := 0; ; level++ {
+		groups := groupChunks(parts, summaryGroupChars)
+		if len(groups) == 1 {
+			return s.summarizeText(ctx, title, groups[0], instructions, true)
+		}
+		if level >= maxSummaryLevels {
+			return "", fmt.Errorf("document too long to summarize")
+		}
+
+		summaries, err := s.summarizeGroups(ctx, title, groups, instructions)
+		if err != nil {
+			return "", err
+		}
+		parts = summaries
+	}
+}
+
+// summarizeGroups summarizes each group concurrently, preserving order
+func (s *SummarizerService) summarizeGroups(ctx context.Context, title string, groups []string, instructions string) ([]string, error) {
+	summaries := make([]string, len(groups))
+	errs := make([]error, len(groups))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, summaryConcurrency)
+	for i, group := range groups {
+		wg.Add(1)
+		go func(i int, group string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			summaries[i], errs[i] = s.summarizeText(ctx, title, group, instructions, false)
+		}(i, group)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("failed to summarize section %d: %v", i+1, err)
+		}
+	}
+	return summaries, nil
+}
+
+// summarizeText makes a single summarization call. Intermediate calls keep more
+// detail so the final call has enough to work with.
+func (s *SummarizerService) summarizeText(ctx context.Context, title, text, instructions string, final bool) (string, error) {
+	system := "You summarize documents for a personal knowledge base. Be faithful to the source and do not add information."
+	if final {
+		system += " Write a clear, well-structured summary of the whole document with the key points."
+	} else {
+		system += " This is one section of a longer document; write a detailed summary that preserves names, numbers, decisions and conclusions so it can be combined with other sections."
+	}
+	if instructions != "" {
+		system += "\n\nAdditional instructions: " + instructions
+	}
+
+	user := text
+	if title != "" {
+		user = fmt.Sprintf("Document: %s\n\n%s", title, text)
+	}
+
+	return s.openAI.GetChatCompletionContext(ctx, []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: system},
+		{Role: openai.ChatMessageRoleUser, Content: user},
+	})
+}
+
+// groupChunks concatenates consecutive chunks into groups of at most maxChars
+// characters. A single oversized chunk becomes its own group.
+func groupChunks(chunks []string, maxChars int) []string {
+	var groups []string
+	var current strings.Builder
+
+	for _, chunk := range chunks {
+		if current.Len() > 0 && current.Len()+len(chunk)+2 > maxChars {
+			groups = append(groups, current.String())
+			current.Reset()
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n\n")
+		}
+		current.WriteString(chunk)
+	}
+	if current.Len() > 0 {
+		groups = append(groups, current.String())
+	}
+
+	return groups
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	telegramAPIBaseURL = "https://api.telegram.org"
+	// TelegramMaxFileSize is the largest file the Bot API lets bots download
+	TelegramMaxFileSize = 20 << 20
+)
+
+// TelegramService handles interactions with the Telegram Bot API
+type TelegramService struct {
+	token         string
+	webhookSecret string
+	botUsername   string
+	client        *http.Client
+}
+
+// TelegramUpdate is an incoming update delivered to the bot's webhook. Only new messages
+// are handled.
+type TelegramUpdate struct {
+	UpdateID int64            `json:"update_id"`
+	Message  *TelegramMessage `json:"message"`
+}
+
+// TelegramMessage is a message sent to the bot
+type TelegramMessage struct {
+	MessageID int64 `json:"message_id"`
+	Date      int64 `json:"date"` // Unix time
+	Chat      struct {
+		ID   int64  `json:"id"`
+		Type string `json:"type"` // "private", "group", "supergroup" or "channel"
+	} `json:"chat"`
+	From *struct {
+		ID       int64  `json:"id"`
+		Username string `json:"username"`
+	} `json:"from"`
+	Text     string              `json:"text"`
+	Caption  string              `json:"caption"`
+	Document *TelegramFile       `json:"document"`
+	Photo    []TelegramPhotoSize `json:"photo"` // sizes of one photo, smallest first
+}
+
+// TelegramFile is a file attached to a message
+type TelegramFile struct {
+	FileID   string `json:"file_id"`
+	FileName string `json:"file_name"`
+	MimeType string `json:"mime_type"`
+	FileSize int64  `json:"file_size"`
+}
+
+// TelegramPhotoSize is one size of a photo attached to a message
+type TelegramPhotoSize struct {
+	FileID   string `json:"file_id"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	FileSize int64  `json:"file_size"`
+}
+
+// SentAt returns when the message was sent
+func (m *TelegramMessage) SentAt() time.Time {
+	if m.Date == 0 {
+		return time.Time{}
+	}
+	return time.Unix(m.Date, 0)
+}
+
+// NewTelegramService creates a new Telegram service for the bot with the given token.
+// The webhook secret is the secret token registered with setWebhook, and the bot's
+// username is used for links that open a chat with it.
+func NewTelegramService(token, webhookSecret, botUsername string) *TelegramService {
+	return &TelegramService{
+		token:         token,
+		webhookSecret: webhookSecret,
+		botUsername:   botUsername,
+		client:        &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// VerifyWebhook reports whether the secret token sent with a webhook update is the bot's
+func (s *TelegramService) VerifyWebhook(secret string) bool {
+	return s.webhookSecret != "" && subtle.ConstantTimeCompare([]byte(secret), []byte(s.webhookSecret)) == 1
+}
+
+// StartLink returns a link that opens a chat with the bot and sends it /start with the
+// given payload, or "" if the bot's username isn't configured
+func (s *TelegramService) StartLink(payload string) string {
+	if s.botUsername == "" {
+		return ""
+	}
+	return fmt.Sprintf("https://t.me/%s?start=%s", url.PathEscape(s.botUsername), url.QueryEscape(payload))
+}
+
+// SendMessage sends a plain text message to a chat
+func (s *TelegramService) SendMessage(ctx context.Context, chatID int64, text string) error {
+	return s.call(ctx, "sendMessage", map[string]interface{}{
+		"chat_id":                  chatID,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	}, nil)
+}
+
+// DownloadFile downloads a file sent to the bot. Files over TelegramMaxFileSize can't be
+// downloaded by bots.
+func (s *TelegramService) DownloadFile(ctx context.Context, fileID string) ([]byte, error) {
+	var file struct {
+		FilePath string `json:"file_path"`
+		FileSize int64  `json:"file_size"`
+	}
+	if err := s.call(ctx, "getFile", map[string]interface{}{"file_id": fileID}, &file); err != nil {
+		return nil, err
+	}
+	if file.FileSize > TelegramMaxFileSize {
+		return nil, fmt.Errorf("file is %d bytes, over the %d byte limit", file.FileSize, TelegramMaxFileSize)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/file/bot%s/%s", telegramAPIBaseURL, s.token, file.FilePath), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Telegram file download failed: %v", withoutURL(err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Telegram file download returned status: %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, TelegramMaxFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Telegram file: %v", err)
+	}
+	if len(data) > TelegramMaxFileSize {
+		return nil, fmt.Errorf("file is over the %d byte limit", TelegramMaxFileSize)
+	}
+	return data, nil
+}
+
+// call calls a Bot API method, decoding its result into v if it's not nil
+func (s *TelegramService) call(ctx context.Context, method string, params, v interface{}) error {
+	payload, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/bot%s/%s", telegramAPIBaseURL, s.token, method), bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Telegram request failed: %v", withoutURL(err))
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK          bool            `json:"ok"`
+		Description string          `json:"description"`
+		Result      json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode Telegram response: %v", err)
+	}
+	if !result.OK {
+		return fmt.Errorf("Telegram API %s failed with status %d: %s", method, resp.StatusCode, result.Description)
+	}
+	if v != nil {
+		if err := json.Unmarshal(result.Result, v); err != nil {
+			return fmt.Errorf("failed to decode Telegram %s result: %v", method, err)
+		}
+	}
+	return nil
+}
+
+// withoutURL strips the request URL from an HTTP client error, since Bot API URLs hold
+// the bot's token
+func withoutURL(err error) error {
+	if urlErr, ok := err.(*url.Error); ok {
+		return urlErr.Err
+	}
+	return err
+}
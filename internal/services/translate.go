@@ -0,0 +1,46 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// translationInputChars is the amount of text sent for translation
+const translationInputChars = 8000
+
+// Translator translates saved content and queries into English so that items and
+// queries in different languages match during retrieval
+type Translator struct {
+	openAI *OpenAIService
+}
+
+// NewTranslator creates a new translator
+func NewTranslator(openAI *OpenAIService) *Translator {
+	return &Translator{openAI: openAI}
+}
+
+// ToEnglish returns an English translation of the text. Names, code and URLs are kept as written.
+func (t *Translator) ToEnglish(ctx context.Context, text string) (string, error) {
+	if runes := []rune(text); len(runes) > translationInputChars {
+		text = string(runes[:translationInputChars])
+	}
+	if strings.TrimSpace(text) == "" {
+		return "", nil
+	}
+
+	response, err := t.openAI.GetChatCompletionContext(ctx, []openai.ChatCompletionMessage{
+		{
+			Role: "system",
+			Content: "Translate the user's text into English. Keep names, code, URLs and numbers as written. " +
+				"Respond with the translation only, without notes or quotes.",
+		},
+		{Role: "user", Content: text},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to translate text: %v", err)
+	}
+	return strings.TrimSpace(response), nil
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// imageContentSchema is the structured output returned for an image
+var imageContentSchema = json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"text": {"type": "string"},
+		"description": {"type": "string"}
+	},
+	"required": ["text", "description"],
+	"additionalProperties": false
+}`)
+
+// ImageContent is what an image shows: its visible text and a description of the rest
+type ImageContent struct {
+	Text        string `json:"text"`        // text visible in the image, transcribed as written
+	Description string `json:"description"` // what the image shows, for searching
+}
+
+// ImageReader reads the text in images and describes them with a vision model, so
+// screenshots, whiteboard photos and scanned notes can be searched like text
+type ImageReader struct {
+	openAI *OpenAIService
+}
+
+// NewImageReader creates a new image reader
+func NewImageReader(openAI *OpenAIService) *ImageReader {
+	return &ImageReader{openAI: openAI}
+}
+
+// Read transcribes the text visible in an image and describes what it shows
+func (r *ImageReader) Read(ctx context.Context, mimeType string, image []byte) (*ImageContent, error) {
+	dataURL := "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(image)
+	message, err := r.openAI.createChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model: openai.GPT4o,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role: "system",
+				Content: "You index images for a personal memory app. In text, transcribe all text visible in the image as written, " +
+					"keeping line breaks and the layout of lists and tables; use an empty string if there is none. " +
+					"In description, describe what the image shows in a few sentences, naming the kind of image " +
+					"(screenshot, whiteboard, photo, diagram, ...), its subject and any people, apps or places recognizable from it.",
+			},
+			{
+				Role: "user",
+				MultiContent: []openai.ChatMessagePart{
+					{Type: openai.ChatMessagePartTypeImageURL, ImageURL: &openai.ChatMessageImageURL{URL: dataURL, Detail: openai.ImageURLDetailHigh}},
+				},
+			},
+		},
+		ResponseFormat: &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+			JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+				Name:   "image_content",
+				Schema: imageContentSchema,
+				Strict: true,
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %v", err)
+	}
+
+	var content ImageContent
+	if err := json.Unmarshal([]byte(message.Content), &content); err != nil {
+		return nil, fmt.Errorf("failed to decode image content: %v", err)
+	}
+	content.Text = strings.TrimSpace(content.Text)
+	content.Description = strings.TrimSpace(content.Description)
+	return &content, nil
+}
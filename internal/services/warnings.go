@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"sync"
+)
+
+// WarningThreshold is the fraction of a limit at which users are warned they are close to it
+const WarningThreshold = 0.8
+
+// Warning is a message for the user that does not fail the request. Message is an i18n
+// format string translated when the response is written.
+type Warning struct {
+	Message string
+	Args    []interface{}
+}
+
+type warningsKey struct{}
+
+// warningCollector gathers warnings raised while a request is handled
+type warningCollector struct {
+	mu       sync.Mutex
+	warnings []Warning
+}
+
+// WithWarnings returns a context that collects warnings added with AddWarning
+func WithWarnings(ctx context.Context) context.Context {
+	return context.WithValue(ctx, warningsKey{}, &warningCollector{})
+}
+
+// AddWarning records a warning on the context. It does nothing if the context doesn't
+// collect warnings, e.g. in background jobs.
+func AddWarning(ctx context.Context, message string, args ...interface{}) {
+	collector, ok := ctx.Value(warningsKey{}).(*warningCollector)
+	if !ok {
+		return
+	}
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	collector.warnings = append(collector.warnings, Warning{Message: message, Args: args})
+}
+
+// Warnings returns the warnings collected on the context
+func Warnings(ctx context.Context) []Warning {
+	collector, ok := ctx.Value(warningsKey{}).(*warningCollector)
+	if !ok {
+		return nil
+	}
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	return append([]Warning(nil), collector.warnings...)
+}
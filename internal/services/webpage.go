@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// maxWebPageSize is the largest web page body read in bytes
+const maxWebPageSize = 2 * 1024 * 1024
+
+// WebPageService fetches web pages and extracts their readable text
+type WebPageService struct {
+	client *http.Client
+}
+
+// WebPage is the readable content of a fetched page
+type WebPage struct {
+	URL   string
+	Title string
+	Text  string
+}
+
+// NewWebPageService creates a new web page service. Requests to loopback, private and
+// link-local addresses are refused since page URLs come from users.
+func NewWebPageService() *WebPageService {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+				ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+				return fmt.Errorf("refusing to connect to %s", host)
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.Proxy = nil // a proxy would bypass the address check
+
+	return &WebPageService{
+		client: &http.Client{
+			Timeout:   15 * time.Second,
+			Transport: transport,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= 5 {
+					return fmt.Errorf("too many redirects")
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// Fetch downloads an HTML or plain text page and extracts its title and readable text
+func (s *WebPageService) Fetch(ctx context.Context, pageURL string) (*WebPage, error) {
+	u, err := url.Parse(pageURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid page URL: %s", pageURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("User-Agent", "ForgetAI/1.0 (+https://forgetai.app)")
+	req.Header.Set("Accept", "text/html,text/plain;q=0.9")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch page: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("page returned status: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxWebPageSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read page: %v", err)
+	}
+
+	page := &WebPage{URL: resp.Request.URL.String()}
+	contentType := resp.Header.Get("Content-Type")
+	switch {
+	case strings.HasPrefix(contentType, "text/plain"):
+		page.Text = strings.TrimSpace(string(body))
+	case contentType == "" || strings.Contains(contentType, "html"):
+		page.Title, page.Text = extractHTML(string(body))
+	default:
+		return nil, fmt.Errorf("unsupported content type: %s", contentType)
+	}
+
+	return page, nil
+}
+
+// HTMLToText returns the readable text of an HTML fragment
+func HTMLToText(fragment string) string {
+	_, text := extractHTML(fragment)
+	return text
+}
+
+// skippedElements are HTML elements whose content is never readable text
+var skippedElements = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true, "svg": true,
+	"nav": true, "header": true, "footer": true, "aside": true, "form": true, "iframe": true,
+}
+
+// blockElements are HTML elements that end a paragraph of text
+var blockElements = map[string]bool{
+	"p": true, "div": true, "br": true, "li": true, "tr": true, "pre": true, "blockquote": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"section": true, "article": true, "table": true, "ul": true, "ol": true,
+}
+
+// extractHTML returns the title and readable text of an HTML document
+func extractHTML(document string) (string, string) {
+	tokenizer := html.NewTokenizer(strings.NewReader(document))
+
+	var title string
+	var sb strings.Builder
+	skipDepth := 0
+	inTitle := false
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return strings.TrimSpace(title), collapseBlankLines(sb.String())
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, _ := tokenizer.TagName()
+			tag := string(name)
+			switch {
+			case tag == "title":
+				inTitle = true
+			case skippedElements[tag]:
+				skipDepth++
+			case blockElements[tag]:
+				sb.WriteString("\n\n")
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			tag := string(name)
+			switch {
+			case tag == "title":
+				inTitle = false
+			case skippedElements[tag] && skipDepth > 0:
+				skipDepth--
+			case blockElements[tag]:
+				sb.WriteString("\n\n")
+			}
+		case html.TextToken:
+			text := string(tokenizer.Text())
+			if inTitle {
+				title += text
+				continue
+			}
+			if skipDepth > 0 {
+				continue
+			}
+			if collapsed := strings.Join(strings.Fields(text), " "); collapsed != "" {
+				sb.WriteString(collapsed + " ")
+			}
+		}
+	}
+}
+
+// collapseBlankLines trims each line and keeps at most one blank line between paragraphs
+func collapseBlankLines(text string) string {
+	var lines []string
+	blank := false
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			blank = len(lines) > 0
+			continue
+		}
+		if blank {
+			lines = append(lines, "")
+			blank = false
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
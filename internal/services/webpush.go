@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/SherClockHolmes/webpush-go"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+)
+
+// PushSubscriptionStore loads and prunes users' Web Push subscriptions
+type PushSubscriptionStore interface {
+	GetPushSubscriptions(ctx context.Context, userID string) ([]*database.PushSubscription, error)
+	DeletePushSubscription(ctx context.Context, userID, endpoint string) (bool, error)
+}
+
+// WebPushProvider delivers notifications to browsers via VAPID-signed Web Push
+type WebPushProvider struct {
+	publicKey  string
+	privateKey string
+	subject    string
+	store      PushSubscriptionStore
+}
+
+// NewWebPushProvider creates a new Web Push provider
+func NewWebPushProvider(store PushSubscriptionStore, publicKey, privateKey, subject string) (*WebPushProvider, error) {
+	if publicKey == "" || privateKey == "" {
+		return nil, fmt.Errorf("VAPID public and private keys are required")
+	}
+	if subject == "" {
+		return nil, fmt.Errorf("VAPID subject is required")
+	}
+
+	return &WebPushProvider{
+		publicKey:  publicKey,
+		privateKey: privateKey,
+		subject:    subject,
+		store:      store,
+	}, nil
+}
+
+// Channel returns the push channel name
+func (p *WebPushProvider) Channel() string {
+	return ChannelPush
+}
+
+// PublicKey returns the VAPID public key clients use to subscribe
+func (p *WebPushProvider) PublicKey() string {
+	return p.publicKey
+}
+
+// Send pushes the notification to every subscription the user has registered.
+// Subscriptions the push service reports as gone are removed.
+func (p *WebPushProvider) Send(ctx context.Context, prefs *database.NotificationPreferences, n Notification) error {
+	subs, err := p.store.GetPushSubscriptions(ctx, prefs.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to load push subscriptions: %v", err)
+	}
+	if len(subs) == 0 {
+		return fmt.Errorf("no push subscriptions for user %s", prefs.UserID)
+	}
+
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("failed to encode push payload: %v", err)
+	}
+
+	var errs []error
+	for _, sub := range subs {
+		resp, err := webpush.SendNotificationWithContext(ctx, payload, &webpush.Subscription{
+			Endpoint: sub.Endpoint,
+			Keys: webpush.Keys{
+				P256dh: sub.P256dh,
+				Auth:   sub.Auth,
+			},
+		}, &webpush.Options{
+			Subscriber:      p.subject,
+			VAPIDPublicKey:  p.publicKey,
+			VAPIDPrivateKey: p.privateKey,
+			TTL:             24 * 60 * 60,
+		})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		switch {
+		case resp.StatusCode == http.StatusGone || resp.StatusCode == http.StatusNotFound:
+			// The browser unsubscribed; stop sending to it
+			if _, err := p.store.DeletePushSubscription(ctx, sub.UserID, sub.Endpoint); err != nil {
+				fmt.Printf("Warning: Failed to remove expired push subscription: %v\n", err)
+			}
+			errs = append(errs, fmt.Errorf("subscription expired"))
+		case resp.StatusCode >= 400:
+			errs = append(errs, fmt.Errorf("push service returned status: %d", resp.StatusCode))
+		}
+	}
+
+	if len(errs) == len(subs) {
+		return fmt.Errorf("failed to deliver push notification: %w", errors.Join(errs...))
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// maxYouTubePageSize is the most of a watch page read while looking for its player data
+const maxYouTubePageSize = 8 << 20
+
+// YouTubeService fetches video details and caption transcripts from YouTube watch pages
+type YouTubeService struct {
+	client *http.Client
+}
+
+// YouTubeVideo is a video with its caption transcript
+type YouTubeVideo struct {
+	ID         string
+	Title      string
+	Channel    string
+	URL        string // canonical watch URL
+	Duration   time.Duration
+	Language   string // language code of the transcript
+	Generated  bool   // the transcript was generated by speech recognition
+	Transcript []YouTubeCaption
+}
+
+// YouTubeCaption is one caption line of a transcript
+type YouTubeCaption struct {
+	Start time.Duration
+	Text  string
+}
+
+// ErrNoYouTubeTranscript is returned for videos without captions
+var ErrNoYouTubeTranscript = fmt.Errorf("video has no captions")
+
+// youTubeVideoID matches the 11-character IDs of YouTube videos
+var youTubeVideoID = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+
+// NewYouTubeService creates a new YouTube service
+func NewYouTubeService() *YouTubeService {
+	return &YouTubeService{
+		client: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// ParseYouTubeURL extracts the video ID from a YouTube video URL (watch, youtu.be,
+// shorts, embed and live links)
+func ParseYouTubeURL(videoURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(videoURL))
+	if err != nil {
+		return "", fmt.Errorf("invalid YouTube URL: %v", err)
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	path := strings.Trim(u.Path, "/")
+	var id string
+	switch host {
+	case "youtu.be":
+		id = path
+	case "youtube.com", "m.youtube.com", "music.youtube.com", "youtube-nocookie.com":
+		if path == "watch" {
+			id = u.Query().Get("v")
+		} else if parts := strings.Split(path, "/"); len(parts) == 2 && (parts[0] == "shorts" || parts[0] == "embed" || parts[0] == "live") {
+			id = parts[1]
+		}
+	default:
+		return "", fmt.Errorf("not a YouTube URL: %s", videoURL)
+	}
+
+	if !youTubeVideoID.MatchString(id) {
+		return "", fmt.Errorf("YouTube URL must point to a video: %s", videoURL)
+	}
+	return id, nil
+}
+
+// GetVideo fetches a video's details and transcript. Captions written by the uploader are
+// preferred over generated ones, and English over other languages.
+func (s *YouTubeService) GetVideo(ctx context.Context, id string) (*YouTubeVideo, error) {
+	player, err := s.getPlayerResponse(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if status := player.PlayabilityStatus.Status; status != "OK" {
+		return nil, fmt.Errorf("YouTube video %s is unavailable: %s %s", id, status, player.PlayabilityStatus.Reason)
+	}
+
+	var seconds int
+	fmt.Sscan(player.VideoDetails.LengthSeconds, &seconds)
+	video := &YouTubeVideo{
+		ID:       id,
+		Title:    player.VideoDetails.Title,
+		Channel:  player.VideoDetails.Author,
+		URL:      "https://www.youtube.com/watch?v=" + id,
+		Duration: time.Duration(seconds) * time.Second,
+	}
+
+	tracks := player.Captions.PlayerCaptionsTracklistRenderer.CaptionTracks
+	if len(tracks) == 0 {
+		return nil, ErrNoYouTubeTranscript
+	}
+	best := tracks[0]
+	rank := func(track youTubeCaptionTrack) int {
+		score := 0
+		if track.Kind != "asr" {
+			score += 2
+		}
+		if strings.HasPrefix(track.LanguageCode, "en") {
+			score++
+		}
+		return score
+	}
+	for _, track := range tracks[1:] {
+		if rank(track) > rank(best) {
+			best = track
+		}
+	}
+	video.Language = best.LanguageCode
+	video.Generated = best.Kind == "asr"
+
+	if video.Transcript, err = s.getCaptions(ctx, best.BaseURL); err != nil {
+		return nil, err
+	}
+	if len(video.Transcript) == 0 {
+		return nil, ErrNoYouTubeTranscript
+	}
+	return video, nil
+}
+
+// youTubeCaptionTrack is a caption track listed in a video's player data
+type youTubeCaptionTrack struct {
+	BaseURL      string `json:"baseUrl"`
+	LanguageCode string `json:"languageCode"`
+	Kind         string `json:"kind"` // "asr" for generated captions
+}
+
+// youTubePlayerResponse is the part of a watch page's player data that is used
+type youTubePlayerResponse struct {
+	PlayabilityStatus struct {
+		Status string `json:"status"`
+		Reason string `json:"reason"`
+	} `json:"playabilityStatus"`
+	VideoDetails struct {
+		Title         string `json:"title"`
+		Author        string `json:"author"`
+		LengthSeconds string `json:"lengthSeconds"`
+	} `json:"videoDetails"`
+	Captions struct {
+		PlayerCaptionsTracklistRenderer struct {
+			CaptionTracks []youTubeCaptionTrack `json:"captionTracks"`
+		} `json:"playerCaptionsTracklistRenderer"`
+	} `json:"captions"`
+}
+
+// getPlayerResponse reads the player data embedded in a video's watch page
+func (s *YouTubeService) getPlayerResponse(ctx context.Context, id string) (*youTubePlayerResponse, error) {
+	endpoint := "https://www.youtube.com/watch?hl=en&v=" + url.QueryEscape(id)
+	body, err := s.get(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	const marker = "ytInitialPlayerResponse = "
+	start := strings.Index(body, marker)
+	if start < 0 {
+		return nil, fmt.Errorf("YouTube watch page for %s has no player data", id)
+	}
+
+	// The data is a JSON object followed by more script, so decode just the first value
+	var player youTubePlayerResponse
+	if err := json.NewDecoder(strings.NewReader(body[start+len(marker):])).Decode(&player); err != nil {
+		return nil, fmt.Errorf("failed to decode YouTube player data: %v", err)
+	}
+	return &player, nil
+}
+
+// getCaptions fetches a caption track in YouTube's JSON caption format
+func (s *YouTubeService) getCaptions(ctx context.Context, baseURL string) ([]YouTubeCaption, error) {
+	trackURL, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid caption track URL: %v", err)
+	}
+	query := trackURL.Query()
+	query.Set("fmt", "json3")
+	trackURL.RawQuery = query.Encode()
+
+	body, err := s.get(ctx, trackURL.String())
+	if err != nil {
+		return nil, err
+	}
+
+	var track struct {
+		Events []struct {
+			StartMs int64 `json:"tStartMs"`
+			Segs    []struct {
+				Text string `json:"utf8"`
+			} `json:"segs"`
+		} `json:"events"`
+	}
+	if err := json.Unmarshal([]byte(body), &track); err != nil {
+		return nil, fmt.Errorf("failed to decode YouTube captions: %v", err)
+	}
+
+	var captions []YouTubeCaption
+	for _, event := range track.Events {
+		var sb strings.Builder
+		for _, seg := range event.Segs {
+			sb.WriteString(seg.Text)
+		}
+		if text := strings.Join(strings.Fields(sb.String()), " "); text != "" {
+			captions = append(captions, YouTubeCaption{
+				Start: time.Duration(event.StartMs) * time.Millisecond,
+				Text:  text,
+			})
+		}
+	}
+	return captions, nil
+}
+
+// get fetches a YouTube page or caption track as text
+func (s *YouTubeService) get(ctx context.Context, endpoint string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; ForgetAI/1.0)")
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("YouTube request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("YouTube returned status: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxYouTubePageSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to read YouTube response: %v", err)
+	}
+	return string(body), nil
+}
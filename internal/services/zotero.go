@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	zoteroAPIBaseURL = "https://api.zotero.org"
+	// zoteroPageSize is the maximum number of objects the API returns per request
+	zoteroPageSize = 100
+	// zoteroMaxFileSize is the largest attachment file downloaded in bytes
+	zoteroMaxFileSize = 20 * 1024 * 1024
+)
+
+// ZoteroService handles interactions with the Zotero Web API
+type ZoteroService struct {
+	client *http.Client
+}
+
+// ZoteroKey describes the library an API key belongs to
+type ZoteroKey struct {
+	UserID   int64  `json:"userID"`
+	Username string `json:"username"`
+}
+
+// ZoteroItem is a library item: a top-level item, or a note or attachment of one
+type ZoteroItem struct {
+	Key     string `json:"key"`
+	Version int64  `json:"version"`
+	Data    struct {
+		ItemType     string   `json:"itemType"`
+		Title        string   `json:"title"`
+		AbstractNote string   `json:"abstractNote"`
+		Date         string   `json:"date"`
+		URL          string   `json:"url"`
+		DOI          string   `json:"DOI"`
+		ParentItem   string   `json:"parentItem"`
+		Collections  []string `json:"collections"`
+		Note         string   `json:"note"` // HTML, notes only
+		ContentType  string   `json:"contentType"`
+		LinkMode     string   `json:"linkMode"`
+		Filename     string   `json:"filename"`
+		Deleted      bool     `json:"deleted"` // in the trash
+		Creators     []struct {
+			CreatorType string `json:"creatorType"`
+			FirstName   string `json:"firstName"`
+			LastName    string `json:"lastName"`
+			Name        string `json:"name"`
+		} `json:"creators"`
+	} `json:"data"`
+}
+
+// IsTopLevel reports whether the item is a regular item rather than a child note or attachment
+func (i *ZoteroItem) IsTopLevel() bool {
+	return i.Data.ParentItem == "" && i.Data.ItemType != "note" && i.Data.ItemType != "attachment"
+}
+
+// IsStoredPDF reports whether the item is a PDF attachment whose file is stored in Zotero
+func (i *ZoteroItem) IsStoredPDF() bool {
+	return i.Data.ItemType == "attachment" && i.Data.ContentType == "application/pdf" &&
+		(i.Data.LinkMode == "imported_file" || i.Data.LinkMode == "imported_url")
+}
+
+// NewZoteroService creates a new Zotero service
+func NewZoteroService() *ZoteroService {
+	return &ZoteroService{
+		client: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// GetKey looks up the library an API key belongs to, validating the key
+func (s *ZoteroService) GetKey(ctx context.Context, apiKey string) (*ZoteroKey, error) {
+	var key ZoteroKey
+	if _, err := s.getJSON(ctx, apiKey, "/keys/"+url.PathEscape(apiKey), &key); err != nil {
+		return nil, err
+	}
+	if key.UserID == 0 {
+		return nil, fmt.Errorf("API key has no user library")
+	}
+	return &key, nil
+}
+
+// GetChangedItems gets every item modified since the given library version, and the
+// library's current version
+func (s *ZoteroService) GetChangedItems(ctx context.Context, apiKey string, userID, since int64) ([]*ZoteroItem, int64, error) {
+	var items []*ZoteroItem
+	var version int64
+	for start := 0; ; start += zoteroPageSize {
+		var page []*ZoteroItem
+		endpoint := fmt.Sprintf("/users/%d/items?since=%d&format=json&includeTrashed=1&limit=%d&start=%d", userID, since, zoteroPageSize, start)
+		pageVersion, err := s.getJSON(ctx, apiKey, endpoint, &page)
+		if err != nil {
+			return nil, 0, err
+		}
+		if version == 0 {
+			version = pageVersion
+		}
+		items = append(items, page...)
+		if len(page) < zoteroPageSize {
+			break
+		}
+	}
+	return items, version, nil
+}
+
+// GetDeletedItemKeys gets the keys of items deleted since the given library version
+func (s *ZoteroService) GetDeletedItemKeys(ctx context.Context, apiKey string, userID, since int64) ([]string, error) {
+	var deleted struct {
+		Items []string `json:"items"`
+	}
+	if _, err := s.getJSON(ctx, apiKey, fmt.Sprintf("/users/%d/deleted?since=%d", userID, since), &deleted); err != nil {
+		return nil, err
+	}
+	return deleted.Items, nil
+}
+
+// GetItem gets a single item
+func (s *ZoteroService) GetItem(ctx context.Context, apiKey string, userID int64, key string) (*ZoteroItem, error) {
+	var item ZoteroItem
+	if _, err := s.getJSON(ctx, apiKey, fmt.Sprintf("/users/%d/items/%s", userID, url.PathEscape(key)), &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// GetChildren gets the notes and attachments of an item
+func (s *ZoteroService) GetChildren(ctx context.Context, apiKey string, userID int64, key string) ([]*ZoteroItem, error) {
+	var children []*ZoteroItem
+	endpoint := fmt.Sprintf("/users/%d/items/%s/children?format=json&limit=%d", userID, url.PathEscape(key), zoteroPageSize)
+	if _, err := s.getJSON(ctx, apiKey, endpoint, &children); err != nil {
+		return nil, err
+	}
+	return children, nil
+}
+
+// GetCollectionNames gets the names of the user's collections by collection key
+func (s *ZoteroService) GetCollectionNames(ctx context.Context, apiKey string, userID int64) (map[string]string, error) {
+	names := make(map[string]string)
+	for start := 0; ; start += zoteroPageSize {
+		var page []struct {
+			Key  string `json:"key"`
+			Data struct {
+				Name string `json:"name"`
+			} `json:"data"`
+		}
+		endpoint := fmt.Sprintf("/users/%d/collections?limit=%d&start=%d", userID, zoteroPageSize, start)
+		if _, err := s.getJSON(ctx, apiKey, endpoint, &page); err != nil {
+			return nil, err
+		}
+		for _, collection := range page {
+			names[collection.Key] = collection.Data.Name
+		}
+		if len(page) < zoteroPageSize {
+			break
+		}
+	}
+	return names, nil
+}
+
+// DownloadFile downloads an attachment item's stored file
+func (s *ZoteroService) DownloadFile(ctx context.Context, apiKey string, userID int64, key string) ([]byte, error) {
+	resp, err := s.get(ctx, apiKey, fmt.Sprintf("/users/%d/items/%s/file", userID, url.PathEscape(key)))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, zoteroMaxFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attachment: %v", err)
+	}
+	if len(body) > zoteroMaxFileSize {
+		return nil, fmt.Errorf("attachment exceeds %d bytes", zoteroMaxFileSize)
+	}
+	return body, nil
+}
+
+// getJSON performs a GET request, decodes the JSON response into v and returns the
+// library version reported by the API
+func (s *ZoteroService) getJSON(ctx context.Context, apiKey, endpoint string, v interface{}) (int64, error) {
+	resp, err := s.get(ctx, apiKey, endpoint)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return 0, fmt.Errorf("failed to decode Zotero response: %v", err)
+	}
+
+	version, _ := strconv.ParseInt(resp.Header.Get("Last-Modified-Version"), 10, 64)
+	return version, nil
+}
+
+// get performs an authenticated GET request and checks the response status
+func (s *ZoteroService) get(ctx context.Context, apiKey, endpoint string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, zoteroAPIBaseURL+endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Zotero-API-Version", "3")
+	req.Header.Set("Zotero-API-Key", apiKey)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Zotero request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("Zotero API returned status: %d", resp.StatusCode)
+	}
+	return resp, nil
+}
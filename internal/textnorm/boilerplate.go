@@ -0,0 +1,89 @@
+package textnorm
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	// minBoilerplatePages is the fewest pages a document needs before repeated lines are
+	// treated as headers and footers
+	minBoilerplatePages = 3
+	// maxBoilerplateLine is the longest line considered a header or footer
+	maxBoilerplateLine = 100
+)
+
+// pageNumber matches lines holding only a page number, e.g. "12", "- 12 -", "Page 3 of 10"
+var pageNumber = regexp.MustCompile(`(?i)^[-–—\s]*(page\s+)?\d+(\s*(/|of)\s*\d+)?[-–—\s]*$`)
+
+// digits matches runs of digits, which differ between otherwise identical headers
+var digits = regexp.MustCompile(`\d+`)
+
+// StripPageBoilerplate removes page numbers, and headers and footers repeated on at least
+// half the pages, from the first and last lines of each page of a document
+func StripPageBoilerplate(pages []string) []string {
+	lines := make([][]string, len(pages))
+	for i, page := range pages {
+		lines[i] = strings.Split(strings.ReplaceAll(page, "\r\n", "\n"), "\n")
+	}
+
+	// Count each page's edge lines once, with digits ignored so "Report - 3" matches "Report - 4"
+	repeated := make(map[string]int)
+	if len(pages) >= minBoilerplatePages {
+		for _, pageLines := range lines {
+			seen := make(map[string]bool)
+			for _, line := range edgeLines(pageLines) {
+				key := boilerplateKey(line)
+				if key != "" && !seen[key] {
+					seen[key] = true
+					repeated[key]++
+				}
+			}
+		}
+	}
+	isBoilerplate := func(line string) bool {
+		if pageNumber.MatchString(line) {
+			return true
+		}
+		key := boilerplateKey(line)
+		return key != "" && repeated[key]*2 >= len(pages) && repeated[key] >= minBoilerplatePages
+	}
+
+	stripped := make([]string, len(pages))
+	for i, pageLines := range lines {
+		start, end := 0, len(pageLines)
+		for start < end && (strings.TrimSpace(pageLines[start]) == "" || isBoilerplate(pageLines[start])) {
+			start++
+		}
+		for end > start && (strings.TrimSpace(pageLines[end-1]) == "" || isBoilerplate(pageLines[end-1])) {
+			end--
+		}
+		stripped[i] = strings.Join(pageLines[start:end], "\n")
+	}
+	return stripped
+}
+
+// edgeLines returns the first and last two non-empty lines of a page, where headers and
+// footers appear
+func edgeLines(lines []string) []string {
+	var nonEmpty []string
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			nonEmpty = append(nonEmpty, line)
+		}
+	}
+	if len(nonEmpty) <= 4 {
+		return nonEmpty
+	}
+	return append(nonEmpty[:2:2], nonEmpty[len(nonEmpty)-2:]...)
+}
+
+// boilerplateKey returns the form of a line compared across pages, or "" for lines too
+// long to be a header or footer
+func boilerplateKey(line string) string {
+	line = strings.ToLower(strings.Join(strings.Fields(line), " "))
+	if line == "" || len(line) > maxBoilerplateLine {
+		return ""
+	}
+	return digits.ReplaceAllString(line, "#")
+}
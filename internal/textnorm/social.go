@@ -0,0 +1,50 @@
+package textnorm
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// retweetPrefix matches the "RT @user:" prefix of retweets
+var retweetPrefix = regexp.MustCompile(`(?i)^RT\s+@\w+:?\s*`)
+
+// urlPattern matches http(s) and www URLs in text
+var urlPattern = regexp.MustCompile(`(?i)\b(https?://|www\.)\S+`)
+
+// trackingParams are query parameters that only record where a click came from
+var trackingParams = map[string]bool{
+	"fbclid": true, "gclid": true, "dclid": true, "msclkid": true, "yclid": true, "twclid": true,
+	"igshid": true, "mc_cid": true, "mc_eid": true, "_hsenc": true, "_hsmi": true, "ref_src": true,
+}
+
+// EmbeddingText returns the text of a tweet or web content as it should be embedded: without
+// a retweet prefix or URLs, which match unrelated items sharing a domain or link shortener,
+// and with whitespace tidied. It returns "" when no words remain, e.g. for emoji-only content.
+func EmbeddingText(text string) string {
+	text = retweetPrefix.ReplaceAllString(strings.TrimSpace(text), "")
+	text = Normalize(urlPattern.ReplaceAllString(text, ""))
+	if !strings.ContainsFunc(text, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsNumber(r) }) {
+		return ""
+	}
+	return text
+}
+
+// StripTracking removes utm_* and other click-tracking parameters from a URL. URLs that
+// can't be parsed are returned unchanged.
+func StripTracking(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.RawQuery == "" {
+		return rawURL
+	}
+
+	query := u.Query()
+	for key := range query {
+		if lower := strings.ToLower(key); trackingParams[lower] || strings.HasPrefix(lower, "utm_") {
+			query.Del(key)
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
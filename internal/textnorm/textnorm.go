@@ -0,0 +1,66 @@
+package textnorm
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// punctuation maps typographic characters to their plain equivalents, and invisible
+// characters that only break up words to nothing. Zero-width joiners are kept because
+// emoji and Indic scripts depend on them.
+var punctuation = strings.NewReplacer(
+	"\u2018", "'", "\u2019", "'", "\u201a", "'", "\u201b", "'", "\u2032", "'",
+	"\u201c", "\"", "\u201d", "\"", "\u201e", "\"", "\u201f", "\"", "\u2033", "\"",
+	"\u2026", "...",
+	"\u00a0", " ", "\u2007", " ", "\u202f", " ",
+	"\u200b", "", "\u2060", "", "\ufeff", "", "\u00ad", "",
+)
+
+// blankLines matches runs of more than one empty line
+var blankLines = regexp.MustCompile(`\n{3,}`)
+
+// Normalize puts prose into a canonical form so the same content is stored, chunked and
+// embedded identically however it was formatted: Unicode NFC, plain quotes, Unix line
+// endings, single spaces within lines and at most one blank line between paragraphs.
+// Indentation is kept, since markdown uses it for nesting and code blocks.
+func Normalize(text string) string {
+	text = punctuation.Replace(norm.NFC.String(text))
+	text = strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\r", "\n")
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		content := strings.TrimLeftFunc(line, isHorizontalSpace)
+		indent := line[:len(line)-len(content)]
+		lines[i] = indent + strings.Join(strings.FieldsFunc(content, isHorizontalSpace), " ")
+		if lines[i] == indent {
+			lines[i] = ""
+		}
+	}
+	text = strings.Join(lines, "\n")
+
+	return strings.TrimSpace(blankLines.ReplaceAllString(text, "\n\n"))
+}
+
+// NormalizeCode is Normalize for source code, where quotes and indentation are significant:
+// only the Unicode form, line endings, invisible characters and trailing spaces change
+func NormalizeCode(code string) string {
+	code = norm.NFC.String(code)
+	code = strings.ReplaceAll(strings.ReplaceAll(code, "\r\n", "\n"), "\r", "\n")
+	for _, invisible := range []string{"\u200b", "\u2060", "\ufeff"} {
+		code = strings.ReplaceAll(code, invisible, "")
+	}
+
+	lines := strings.Split(code, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRightFunc(line, unicode.IsSpace)
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// isHorizontalSpace reports whether r is whitespace other than a line break
+func isHorizontalSpace(r rune) bool {
+	return r != '\n' && unicode.IsSpace(r)
+}
@@ -0,0 +1,93 @@
+// Package timing records how long a request spends waiting on external dependencies
+package timing
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Dependencies whose call latencies are recorded
+const (
+	OpenAI   = "openai"
+	Pinecone = "pinecone"
+	MongoDB  = "mongodb"
+	Redis    = "redis"
+)
+
+// Timing summarizes the calls a request made to one dependency operation
+type Timing struct {
+	Dependency string  `json:"dependency"`
+	Operation  string  `json:"operation"`
+	Calls      int     `json:"calls"`
+	TotalMS    float64 `json:"total_ms"`
+	MaxMS      float64 `json:"max_ms"`
+}
+
+type timingsKey struct{}
+
+// recorder gathers the timings of one request's dependency calls
+type recorder struct {
+	mu      sync.Mutex
+	timings map[[2]string]*Timing
+}
+
+// With returns a context that records the dependency calls made with it
+func With(ctx context.Context) context.Context {
+	return context.WithValue(ctx, timingsKey{}, &recorder{timings: make(map[[2]string]*Timing)})
+}
+
+// Record adds a call's duration to the context's timings. It does nothing if the context
+// doesn't record timings, e.g. in background jobs.
+func Record(ctx context.Context, dependency, operation string, d time.Duration) {
+	rec, ok := ctx.Value(timingsKey{}).(*recorder)
+	if !ok {
+		return
+	}
+	ms := float64(d.Microseconds()) / 1000
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	key := [2]string{dependency, operation}
+	timing, ok := rec.timings[key]
+	if !ok {
+		timing = &Timing{Dependency: dependency, Operation: operation}
+		rec.timings[key] = timing
+	}
+	timing.Calls++
+	timing.TotalMS += ms
+	if ms > timing.MaxMS {
+		timing.MaxMS = ms
+	}
+}
+
+// Track starts timing a call and returns a function that records it, for use with defer:
+//
+//	defer timing.Track(ctx, timing.OpenAI, "embedding")()
+func Track(ctx context.Context, dependency, operation string) func() {
+	start := time.Now()
+	return func() {
+		Record(ctx, dependency, operation, time.Since(start))
+	}
+}
+
+// Summary returns the context's timings, slowest in total first
+func Summary(ctx context.Context) []Timing {
+	rec, ok := ctx.Value(timingsKey{}).(*recorder)
+	if !ok {
+		return nil
+	}
+
+	rec.mu.Lock()
+	summary := make([]Timing, 0, len(rec.timings))
+	for _, timing := range rec.timings {
+		summary = append(summary, *timing)
+	}
+	rec.mu.Unlock()
+
+	sort.Slice(summary, func(i, j int) bool {
+		return summary[i].TotalMS > summary[j].TotalMS
+	})
+	return summary
+}
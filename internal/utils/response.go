@@ -0,0 +1,165 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ResponseBuffer holds back a JSON response so middleware can amend its body after the
+// handler has run. Install it as the context's writer and call Commit when done. Other
+// responses, such as file downloads, are passed straight through as they are written, as
+// is everything after a handler flushes, so streaming isn't held up in memory.
+type ResponseBuffer struct {
+	gin.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	passthrough bool // the response is being written straight to the underlying writer
+}
+
+// NewResponseBuffer creates a buffer in front of the given writer
+func NewResponseBuffer(w gin.ResponseWriter) *ResponseBuffer {
+	return &ResponseBuffer{ResponseWriter: w}
+}
+
+func (w *ResponseBuffer) WriteHeader(code int) {
+	if w.passthrough {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.status = code
+}
+
+func (w *ResponseBuffer) WriteHeaderNow() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.passThroughNonJSON() {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *ResponseBuffer) Write(data []byte) (int, error) {
+	w.WriteHeaderNow()
+	if w.passthrough {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.body.Write(data)
+}
+
+func (w *ResponseBuffer) WriteString(s string) (int, error) {
+	w.WriteHeaderNow()
+	if w.passthrough {
+		return w.ResponseWriter.WriteString(s)
+	}
+	return w.body.WriteString(s)
+}
+
+// Flush writes what's buffered so far and passes the rest of the response through
+func (w *ResponseBuffer) Flush() {
+	w.startPassthrough()
+	w.ResponseWriter.Flush()
+}
+
+func (w *ResponseBuffer) Status() int {
+	if w.passthrough {
+		return w.ResponseWriter.Status()
+	}
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *ResponseBuffer) Size() int {
+	if w.passthrough {
+		return w.ResponseWriter.Size()
+	}
+	return w.body.Len()
+}
+
+func (w *ResponseBuffer) Written() bool {
+	if w.passthrough {
+		return w.ResponseWriter.Written()
+	}
+	return w.status != 0
+}
+
+// Body returns the buffered response body, which is empty once the response has been
+// passed through
+func (w *ResponseBuffer) Body() []byte {
+	return w.body.Bytes()
+}
+
+// Commit writes the buffered status and the given body to the underlying writer. Responses
+// that were passed through are already written, so nothing more is written for them.
+func (w *ResponseBuffer) Commit(body []byte) {
+	if w.passthrough {
+		return
+	}
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	if len(body) > 0 {
+		w.ResponseWriter.Write(body)
+	} else if w.status != 0 {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+// passThroughNonJSON starts passing the response through unless it's JSON, once the
+// handler has set its content type and begun writing. It reports whether it passes
+// through.
+func (w *ResponseBuffer) passThroughNonJSON() bool {
+	if !w.passthrough && !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		w.startPassthrough()
+	}
+	return w.passthrough
+}
+
+// startPassthrough writes the buffered status and body to the underlying writer, after
+// which writes go straight to it
+func (w *ResponseBuffer) startPassthrough() {
+	if w.passthrough {
+		return
+	}
+	w.passthrough = true
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	if w.body.Len() > 0 {
+		w.ResponseWriter.Write(w.body.Bytes())
+		w.body.Reset()
+	}
+}
+
+// AddJSONField appends a field to a JSON object body, leaving other bodies unchanged
+func AddJSONField(body []byte, key string, value interface{}) []byte {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) < 2 || trimmed[0] != '{' || trimmed[len(trimmed)-1] != '}' {
+		return body
+	}
+
+	encodedKey, err := json.Marshal(key)
+	if err != nil {
+		return body
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return body
+	}
+
+	var out bytes.Buffer
+	out.Write(trimmed[:len(trimmed)-1])
+	if len(bytes.TrimSpace(trimmed[1:len(trimmed)-1])) > 0 {
+		out.WriteByte(',')
+	}
+	out.Write(encodedKey)
+	out.WriteByte(':')
+	out.Write(encoded)
+	out.WriteByte('}')
+	return out.Bytes()
+}
@@ -17,3 +17,12 @@ func Min(a, b int) int {
 	}
 	return b
 }
+
+// Truncate shortens s to at most maxRunes characters, appending "..." when it was cut
+func Truncate(s string, maxRunes int) string {
+	runes := []rune(s)
+	if len(runes) <= maxRunes {
+		return s
+	}
+	return string(runes[:maxRunes]) + "..."
+}
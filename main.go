@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
@@ -15,15 +16,15 @@ import (
 	"github.com/siddhantgupta/forgetai-backend/internal/config"
 	"github.com/siddhantgupta/forgetai-backend/internal/database"
 	"github.com/siddhantgupta/forgetai-backend/internal/handlers"
+	"github.com/siddhantgupta/forgetai-backend/internal/i18n"
 	"github.com/siddhantgupta/forgetai-backend/internal/services"
 )
 
 func main() {
-
-	if os.Getenv("CLOUD_RUN") == "true" {
-		fmt.Println("Running in Cloud Run, waiting 5 seconds for network initialization...")
-		time.Sleep(5 * time.Second)
-	}
+	selfTest := flag.Bool("selftest", false, "check every dependency, print a report and exit")
+	mintInternalToken := flag.String("internal-token", "", "print an internal token for the named service and exit")
+	internalTokenTTL := flag.Duration("internal-token-ttl", time.Hour, "lifetime of the token printed by -internal-token")
+	flag.Parse()
 
 	// Load environment variables from .env file
 	if err := godotenv.Load(); err != nil {
@@ -37,6 +38,21 @@ func main() {
 		os.Exit(1)
 	}
 
+	internalAuth := auth.NewInternalAuth(cfg.InternalAuthSecret)
+	if *mintInternalToken != "" {
+		if internalAuth == nil {
+			fmt.Println("INTERNAL_AUTH_SECRET is not set")
+			os.Exit(1)
+		}
+		token, err := internalAuth.SignToken(*mintInternalToken, *internalTokenTTL)
+		if err != nil {
+			fmt.Printf("Failed to sign internal token: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(token)
+		return
+	}
+
 	// Check for PORT environment variable (for Cloud Run)
 	port := os.Getenv("PORT")
 	if port != "" {
@@ -48,7 +64,20 @@ func main() {
 	fmt.Printf("Connecting to MongoDB: %s\n", maskPassword(cfg.MongoDBURI))
 
 	// Initialize services
-	openaiService := services.NewOpenAIService(cfg.OpenAIAPIKey)
+	openaiService, err := services.NewOpenAIService(cfg.OpenAIAPIKey, services.EmbeddingConfig{
+		Model:          cfg.EmbeddingModel,
+		Dimensions:     cfg.EmbeddingDimensions,
+		BaseURL:        cfg.EmbeddingBaseURL,
+		APIKey:         cfg.EmbeddingAPIKey,
+		QueryPrefix:    cfg.EmbeddingQueryPrefix,
+		DocumentPrefix: cfg.EmbeddingDocumentPrefix,
+	})
+	if err != nil {
+		fmt.Printf("Failed to initialize OpenAI service: %v\n", err)
+		os.Exit(1)
+	}
+
+	summarizerService := services.NewSummarizerService(openaiService)
 
 	pineconeService, err := services.NewPineconeService(cfg.PineconeAPIKey, cfg.PineconeIndexHost)
 	if err != nil {
@@ -56,39 +85,228 @@ func main() {
 		os.Exit(1)
 	}
 
-	redisService, err := services.NewRedisService(cfg.RedisURL)
+	// Upstash's REST API needs no connection, so it's usable as soon as the network is
+	var redisService *services.RedisService
+	if cfg.RedisRESTURL != "" {
+		redisService, err = services.NewUpstashRedisService(cfg.RedisRESTURL, cfg.RedisRESTToken)
+	} else {
+		redisService, err = services.NewRedisService(cfg.RedisURL)
+	}
 	if err != nil {
 		fmt.Printf("Failed to initialize Redis service: %v\n", err)
 		os.Exit(1)
 	}
 
-	mongodb, err := database.NewMongoDB(cfg.MongoDBURI)
+	mongoOpts := database.Options{
+		MaxPoolSize:            cfg.MongoMaxPoolSize,
+		MinPoolSize:            cfg.MongoMinPoolSize,
+		MaxConnIdleTime:        cfg.MongoMaxConnIdleTime,
+		ConnectTimeout:         cfg.MongoConnectTimeout,
+		ServerSelectionTimeout: cfg.MongoServerSelectionTimeout,
+		ReadPreference:         cfg.MongoReadPreference,
+		RetryWrites:            cfg.MongoRetryWrites,
+	}
+	mongodb, err := database.NewLazyMongoDB(cfg.MongoDBURI, mongoOpts)
 	if err != nil {
 		fmt.Printf("Failed to initialize MongoDB: %v\n", err)
 		os.Exit(1)
 	}
 	defer mongodb.Close(context.Background())
 
-	fmt.Println("Successfully connected to MongoDB!")
-
-	sessionService := services.NewSessionService()
-
-	clerkAuth, err := auth.NewClerkAuth(redisService, cfg.ClerkIssuerURL)
+	clerkAuth, err := auth.NewLazyClerkAuth(redisService, cfg.ClerkIssuerURLs, auth.VerifyOptions{
+		AuthorizedParties: cfg.ClerkAuthorizedParties,
+		ClockSkew:         cfg.JWTClockSkew,
+	})
 	if err != nil {
 		fmt.Printf("Failed to initialize Clerk authentication: %v\n", err)
 		os.Exit(1)
 	}
 
+	// In lazy startup mode, connections are made in the background once the server is
+	// listening. Otherwise every dependency is checked now, and the server exits with the
+	// code of the first failure's class if any check fails.
+	lazy := cfg.LazyStartup && !*selfTest
+	if lazy {
+		fmt.Println("Lazy startup: connecting to dependencies in the background")
+	} else if code := runPreflight(cfg, openaiService, pineconeService, redisService, mongodb, clerkAuth); code != 0 {
+		mongodb.Close(context.Background())
+		os.Exit(code)
+	}
+
+	if *selfTest {
+		if !runSelfTest(openaiService, pineconeService, redisService, mongodb) {
+			mongodb.Close(context.Background())
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Record request and provider usage metrics for operational analytics
+	metricsService := services.NewMetricsService(mongodb)
+	openaiService.SetUsageRecorder(metricsService)
+	// Tokens used on users' behalf go into their usage history and daily spend
+	spendingService := services.NewSpendingService(redisService, cfg.DailySpendCapUSD, metricsService)
+	openaiService.SetUserUsageRecorder(spendingService)
+	pineconeService.SetUsageRecorder(metricsService)
+
+	sessionService := services.NewSessionService()
+
+	notificationService := services.NewNotificationService(mongodb)
+	if cfg.SMTPHost != "" {
+		emailProvider, err := services.NewEmailProvider(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.NotificationFromEmail)
+		if err != nil {
+			fmt.Printf("Failed to initialize email notifications: %v\n", err)
+			os.Exit(1)
+		}
+		notificationService.AddProvider(emailProvider)
+	}
+
+	var webPushProvider *services.WebPushProvider
+	if cfg.VAPIDPublicKey != "" {
+		webPushProvider, err = services.NewWebPushProvider(mongodb, cfg.VAPIDPublicKey, cfg.VAPIDPrivateKey, cfg.VAPIDSubject)
+		if err != nil {
+			fmt.Printf("Failed to initialize Web Push notifications: %v\n", err)
+			os.Exit(1)
+		}
+		notificationService.AddProvider(webPushProvider)
+	}
+
+	githubService := services.NewGitHubService(cfg.GitHubToken)
+
+	var diarizationService *services.AssemblyAIService
+	if cfg.AssemblyAIAPIKey != "" {
+		diarizationService = services.NewAssemblyAIService(cfg.AssemblyAIAPIKey)
+	}
+
+	var clerkUserService *services.ClerkUserService
+	if cfg.ClerkSecretKey != "" {
+		clerkUserService = services.NewClerkUserService(cfg.ClerkSecretKey)
+	}
+
+	impersonator := auth.NewImpersonator(cfg.ImpersonationSecret)
+
+	var backupService *services.GCSService
+	if cfg.BackupBucket != "" {
+		backupService = services.NewGCSService(cfg.BackupBucket, cfg.GoogleAccessToken)
+	}
+
+	// Usage-based billing: saves, queries, tokens and storage are metered to the sink
+	var billingSink services.BillingSink
+	switch cfg.BillingSink {
+	case "webhook":
+		billingSink = services.NewWebhookBillingSink(cfg.BillingWebhookURL, cfg.BillingWebhookSecret)
+	case "pubsub":
+		billingSink = services.NewPubSubBillingSink(cfg.BillingPubSubTopic, cfg.GoogleAccessToken)
+	case "stripe":
+		billingSink = services.NewStripeBillingSink(cfg.StripeSecretKey, cfg.StripeMeterEventPrefix, clerkUserService)
+	}
+	billingService := services.NewBillingService(billingSink)
+	openaiService.SetBillingService(billingService)
+
+	// Storage quotas by plan
+	planQuotas := make(map[string]services.PlanQuota, len(cfg.QuotaPlans))
+	for plan, quota := range cfg.QuotaPlans {
+		planQuotas[plan] = services.PlanQuota{
+			MaxItems:          quota.MaxItems,
+			MaxCharacters:     quota.MaxCharacters,
+			MaxVectors:        quota.MaxVectors,
+			MaxDocumentPages:  quota.MaxDocumentPages,
+			MaxDocumentChunks: quota.MaxDocumentChunks,
+		}
+	}
+	quotaService := services.NewQuotaService(mongodb, planQuotas, cfg.QuotaDefaultPlan)
+
 	// Initialize handlers
 	apiHandlers := handlers.NewHandlers(
 		openaiService,
+		summarizerService,
 		pineconeService,
 		redisService,
 		sessionService,
+		notificationService,
+		webPushProvider,
+		githubService,
+		diarizationService,
+		clerkUserService,
+		impersonator,
+		backupService,
+		quotaService,
+		billingService,
 		mongodb,
 		cfg.AdminAPIKey,
 		cfg.XAPIBearerToken,
 	)
+	apiHandlers.MinScore = float32(cfg.RetrievalMinScore)
+	apiHandlers.GenTimeout = cfg.GenerationTimeout
+	apiHandlers.Chunking = cfg.Chunking
+	apiHandlers.Metrics = metricsService
+	apiHandlers.Spending = spendingService
+	// Original uploads are kept for download when a file bucket is configured
+	switch cfg.FileStorage {
+	case "gcs":
+		apiHandlers.Files = services.NewGCSService(cfg.FileBucket, cfg.GoogleAccessToken)
+	case "s3":
+		apiHandlers.Files = services.NewS3Service(cfg.FileBucket, cfg.S3Region, cfg.S3Endpoint, cfg.S3AccessKeyID, cfg.S3SecretAccessKey)
+	}
+	if cfg.TelegramBotToken != "" {
+		apiHandlers.Telegram = services.NewTelegramService(cfg.TelegramBotToken, cfg.TelegramWebhookSecret, cfg.TelegramBotUsername)
+	}
+	if cfg.SlackClientID != "" {
+		apiHandlers.Slack = services.NewSlackService(cfg.SlackClientID, cfg.SlackClientSecret, cfg.SlackSigningSecret, cfg.SlackRedirectURL, cfg.SlackSaveReaction)
+		apiHandlers.SlackReturn = cfg.SlackInstalledURL
+	}
+	if cfg.TranslateForRetrieval {
+		apiHandlers.Translator = services.NewTranslator(openaiService)
+	}
+
+	// Sync connected Zotero libraries, Notion workspaces and GitHub stars in the background
+	syncCtx, stopSync := context.WithCancel(context.Background())
+	defer stopSync()
+
+	if lazy {
+		warmUp(syncCtx, []warmupStep{
+			{name: "MongoDB", timeout: time.Minute, run: mongodb.Prepare},
+			{name: "Pinecone index", timeout: 5 * time.Minute, run: func(ctx context.Context) error {
+				return preparePinecone(ctx, cfg, pineconeService, openaiService)
+			}},
+			{name: "Redis", timeout: 5 * time.Second, run: func(ctx context.Context) error {
+				_, err := redisService.Ping(ctx)
+				return err
+			}},
+			{name: "Clerk JWKS", timeout: time.Minute, run: func(ctx context.Context) error {
+				return clerkAuth.RefreshAllJWKs()
+			}},
+		})
+	}
+	go apiHandlers.RunZoteroSync(syncCtx, cfg.ZoteroSyncInterval)
+	go apiHandlers.RunNotionSync(syncCtx, cfg.NotionSyncInterval)
+	go apiHandlers.RunGitHubSync(syncCtx, cfg.GitHubSyncInterval)
+	go apiHandlers.RunBackups(syncCtx, cfg.BackupInterval)
+	go apiHandlers.RunContradictionScans(syncCtx, cfg.ContradictionScanInterval)
+	go apiHandlers.RunDigests(syncCtx, cfg.DigestInterval)
+	go apiHandlers.RunSavesDigests(syncCtx, cfg.SavesDigestCheckInterval)
+	go apiHandlers.RunAbuseDetection(syncCtx, cfg.AbuseDetectionInterval)
+	if billingSink != nil {
+		go apiHandlers.RunStorageMetering(syncCtx, cfg.BillingStorageInterval)
+	}
+	// Billing runs until the server has drained, so events of in-flight requests are flushed
+	billingCtx, stopBilling := context.WithCancel(context.Background())
+	defer stopBilling()
+	billingDone := make(chan struct{})
+	go func() {
+		defer close(billingDone)
+		billingService.Run(billingCtx, cfg.BillingFlushInterval)
+	}()
+	go metricsService.Run(syncCtx, time.Minute)
+
+	// Refresh Clerk signing keys in the background, off the request path
+	go clerkAuth.RunJWKSRefresh(syncCtx, cfg.JWKSRefreshInterval)
+
+	// Keep MongoDB and Redis connections alive through idle periods
+	go apiHandlers.RunKeepalive(syncCtx, cfg.KeepaliveInterval)
+
+	// Write pending vectors to Pinecone in the background
+	go apiHandlers.RunOutboxPublisher(syncCtx, 5*time.Second)
 
 	// Setup Gin router
 	gin.SetMode(gin.ReleaseMode) // Use release mode in production
@@ -97,8 +315,20 @@ func main() {
 	// Setup CORS
 	r.Use(handlers.SetupCORS())
 
+	// Record request status and latency by route
+	r.Use(handlers.MetricsMiddleware(metricsService))
+
+	// Log each request's dependency latencies, returned to admins in a debug section
+	r.Use(handlers.RequestTimingMiddleware(cfg.AdminAPIKey))
+
+	// Negotiate the response language for error and status messages
+	r.Use(i18n.Middleware())
+
+	// Bound each request by its route's deadline
+	r.Use(handlers.RouteTimeoutMiddleware())
+
 	// Setup routes
-	handlers.SetupRoutes(r, apiHandlers, clerkAuth, redisService)
+	handlers.SetupRoutes(r, apiHandlers, clerkAuth, impersonator, internalAuth, redisService)
 
 	// Create a server with graceful shutdown
 	srv := &http.Server{
@@ -121,6 +351,7 @@ func main() {
 	<-quit
 
 	fmt.Println("Shutting down server...")
+	stopSync()
 
 	// Allow 10 seconds for graceful shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -129,6 +360,41 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		fmt.Printf("Server forced to shutdown: %v\n", err)
 	}
+
+	// Flush the last metering events before exiting
+	stopBilling()
+	<-billingDone
+}
+
+// preparePinecone creates the Pinecone index if it's provisioned by name and doesn't exist
+// yet, and checks its dimension matches the embedding model's
+func preparePinecone(ctx context.Context, cfg *config.Config, pinecone *services.PineconeService, openai *services.OpenAIService) error {
+	// Create the index if it doesn't exist yet
+	if cfg.PineconeIndexName != "" {
+		created, err := pinecone.EnsureIndex(ctx, services.IndexSpec{
+			Name:      cfg.PineconeIndexName,
+			Dimension: openai.EmbeddingDimensions(),
+			Cloud:     cfg.PineconeCloud,
+			Region:    cfg.PineconeRegion,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to provision index: %v", err)
+		}
+		if created {
+			fmt.Printf("Created Pinecone index %s\n", cfg.PineconeIndexName)
+		}
+	}
+
+	// Embeddings of the wrong length would fail every upsert and query, so refuse to start
+	indexDimension, err := pinecone.IndexDimension(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check index dimension: %v", err)
+	}
+	if indexDimension != openai.EmbeddingDimensions() {
+		return &permanentError{fmt.Errorf("index dimension %d does not match %s embeddings of dimension %d",
+			indexDimension, cfg.EmbeddingModel, openai.EmbeddingDimensions())}
+	}
+	return nil
 }
 
 // maskPassword masks the password in a connection string for logging
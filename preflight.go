@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/siddhantgupta/forgetai-backend/internal/auth"
+	"github.com/siddhantgupta/forgetai-backend/internal/config"
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+)
+
+// Exit codes of a failed preflight, one per class of failure, so a supervisor or deploy
+// script can tell a misconfiguration from an unreachable dependency without parsing logs
+const (
+	exitMongoUnreachable = 10 // MongoDB can't be reached or its indexes can't be created
+	exitMongoPermission  = 11 // the MongoDB user can't write to the database
+	exitPineconeIndex    = 12 // the Pinecone index can't be reached or provisioned
+	exitDimensionMatch   = 13 // the embedding dimension doesn't match the index's
+	exitJWKSUnavailable  = 14 // a Clerk issuer's signing keys can't be loaded
+)
+
+// redisLatencySamples is how many pings the preflight times Redis over
+const redisLatencySamples = 3
+
+// preflightResult is the outcome of one preflight check
+type preflightResult struct {
+	status   string // "PASS", "WARN", "FAIL" or "SKIP"
+	detail   string
+	hint     string // what to change, for warnings and failures
+	exitCode int    // for failures
+}
+
+// preflightCheck verifies one dependency at startup
+type preflightCheck struct {
+	name    string
+	timeout time.Duration
+	run     func(ctx context.Context) preflightResult
+}
+
+// runPreflight checks each dependency the server needs before it starts listening, prints
+// a report and returns the exit code of the first failed check, or 0 if none failed. Redis
+// problems are only warnings, since the server falls back to in-memory state without it.
+func runPreflight(cfg *config.Config, openai *services.OpenAIService, pinecone *services.PineconeService, redis *services.RedisService, mongodb *database.MongoDB, clerk *auth.ClerkAuth) int {
+	mongoConnected := false
+	checks := []preflightCheck{
+		{"MongoDB connection", time.Minute, func(ctx context.Context) preflightResult {
+			if err := mongodb.Prepare(ctx); err != nil {
+				return preflightFailure(err, exitMongoUnreachable, "check MONGODB_URI and that this host is allowed by the cluster's network access list")
+			}
+			mongoConnected = true
+			return preflightResult{status: "PASS"}
+		}},
+		{"MongoDB write permission", 30 * time.Second, func(ctx context.Context) preflightResult {
+			if !mongoConnected {
+				return preflightResult{status: "SKIP", detail: "MongoDB is not connected"}
+			}
+			err := mongodb.CheckReadWrite(ctx)
+			switch {
+			case err == nil:
+				return preflightResult{status: "PASS"}
+			case database.IsUnauthorized(err):
+				return preflightFailure(err, exitMongoPermission, "grant the MongoDB user the readWrite role on the forgetai database")
+			default:
+				return preflightFailure(err, exitMongoUnreachable, "check the MongoDB cluster is writable, e.g. that it isn't paused or read-only")
+			}
+		}},
+		{"Pinecone index dimension", 5 * time.Minute, func(ctx context.Context) preflightResult {
+			err := preparePinecone(ctx, cfg, pinecone, openai)
+			var permanent *permanentError
+			switch {
+			case err == nil:
+				return preflightResult{status: "PASS", detail: fmt.Sprintf("%d (%s)", openai.EmbeddingDimensions(), cfg.EmbeddingModel)}
+			case errors.As(err, &permanent):
+				return preflightFailure(err, exitDimensionMatch, "set OPENAI_EMBEDDING_MODEL and OPENAI_EMBEDDING_DIMENSIONS to match the index, or point PINECONE_INDEX_HOST at an index created for the embedding model")
+			default:
+				return preflightFailure(err, exitPineconeIndex, "check PINECONE_API_KEY and PINECONE_INDEX_HOST (or PINECONE_INDEX_NAME)")
+			}
+		}},
+		{"Clerk JWKS", time.Minute, func(ctx context.Context) preflightResult {
+			if err := clerk.RefreshAllJWKs(); err != nil {
+				return preflightFailure(err, exitJWKSUnavailable, "check CLERK_ISSUER_URL and that this host can reach each issuer's /.well-known/jwks.json")
+			}
+			return preflightResult{status: "PASS", detail: fmt.Sprintf("%d issuer(s)", len(cfg.ClerkIssuerURLs))}
+		}},
+		{"Redis latency", 10 * time.Second, func(ctx context.Context) preflightResult {
+			var samples []time.Duration
+			for i := 0; i < redisLatencySamples; i++ {
+				start := time.Now()
+				if _, err := redis.Ping(ctx); err != nil {
+					return preflightResult{
+						status: "WARN",
+						detail: err.Error(),
+						hint:   "starting with in-memory rate limits and caches on this instance; check UPSTASH_REDIS_URL or UPSTASH_REDIS_REST_URL",
+					}
+				}
+				samples = append(samples, time.Since(start))
+			}
+			sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+			median := samples[len(samples)/2].Round(time.Millisecond)
+			if cfg.RedisMaxLatency > 0 && median > cfg.RedisMaxLatency {
+				return preflightResult{
+					status: "WARN",
+					detail: fmt.Sprintf("median %s over %d pings, above %s", median, redisLatencySamples, cfg.RedisMaxLatency),
+					hint:   "every request checks a rate limit in Redis; use a Redis instance in the same region as the server",
+				}
+			}
+			return preflightResult{status: "PASS", detail: fmt.Sprintf("median %s over %d pings", median, redisLatencySamples)}
+		}},
+	}
+
+	fmt.Println("Preflight report:")
+	exitCode := 0
+	for _, check := range checks {
+		ctx, cancel := context.WithTimeout(context.Background(), check.timeout)
+		start := time.Now()
+		result := check.run(ctx)
+		cancel()
+		elapsed := time.Since(start).Round(time.Millisecond)
+
+		detail := elapsed.String()
+		if result.detail != "" {
+			detail = result.detail + ", " + detail
+		}
+		fmt.Printf("  %s  %-26s %s\n", result.status, check.name, detail)
+		if result.hint != "" {
+			fmt.Printf("        %-26s hint: %s\n", "", result.hint)
+		}
+		if result.status == "FAIL" && exitCode == 0 {
+			exitCode = result.exitCode
+		}
+	}
+
+	if exitCode != 0 {
+		fmt.Printf("Preflight failed, exiting with code %d\n", exitCode)
+	} else {
+		fmt.Println("Preflight passed")
+	}
+	return exitCode
+}
+
+// preflightFailure is a failed check's result for err
+func preflightFailure(err error, exitCode int, hint string) preflightResult {
+	return preflightResult{status: "FAIL", detail: err.Error(), hint: hint, exitCode: exitCode}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/siddhantgupta/forgetai-backend/internal/database"
+	"github.com/siddhantgupta/forgetai-backend/internal/services"
+)
+
+const (
+	// selfTestNamespace is the Pinecone namespace the self-test writes to, away from user data
+	selfTestNamespace = "selftest"
+	// selfTestTimeout bounds each self-test check
+	selfTestTimeout = 30 * time.Second
+)
+
+// runSelfTest exercises each external dependency, prints a pass/fail report and
+// reports whether every check passed
+func runSelfTest(openai *services.OpenAIService, pinecone *services.PineconeService, redis *services.RedisService, mongodb *database.MongoDB) bool {
+	var embedding []float32
+	checks := []struct {
+		name string
+		run  func(ctx context.Context) error
+	}{
+		{"OpenAI embedding", func(ctx context.Context) error {
+			var err error
+			if embedding, err = openai.GetEmbedding("ForgetAI self-test"); err != nil {
+				return err
+			}
+			if len(embedding) != openai.EmbeddingDimensions() {
+				return fmt.Errorf("got %d dimensions, expected %d", len(embedding), openai.EmbeddingDimensions())
+			}
+			return nil
+		}},
+		{"Pinecone upsert/query/delete", func(ctx context.Context) error {
+			if embedding == nil {
+				return fmt.Errorf("skipped: no embedding to upsert")
+			}
+			return pinecone.CheckReadWrite(ctx, selfTestNamespace, embedding)
+		}},
+		{"Redis set/get", redis.CheckReadWrite},
+		{"MongoDB insert/delete", mongodb.CheckReadWrite},
+	}
+
+	fmt.Println("Running self-test...")
+	passed := true
+	for _, check := range checks {
+		ctx, cancel := context.WithTimeout(context.Background(), selfTestTimeout)
+		start := time.Now()
+		err := check.run(ctx)
+		cancel()
+
+		if err != nil {
+			passed = false
+			fmt.Printf("  FAIL  %-30s %v\n", check.name, err)
+			continue
+		}
+		fmt.Printf("  PASS  %-30s %s\n", check.name, time.Since(start).Round(time.Millisecond))
+	}
+
+	if passed {
+		fmt.Println("Self-test passed")
+	} else {
+		fmt.Println("Self-test failed")
+	}
+	return passed
+}
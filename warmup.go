@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	// warmupInitialBackoff is the wait before a failed warmup step is first retried
+	warmupInitialBackoff = time.Second
+	// warmupMaxBackoff caps the wait between retries of a warmup step
+	warmupMaxBackoff = 30 * time.Second
+)
+
+// warmupStep is a connection or startup check made in the background in lazy startup mode
+type warmupStep struct {
+	name    string
+	timeout time.Duration // bounds each attempt
+	run     func(ctx context.Context) error
+}
+
+// permanentError marks a warmup failure that retrying can't fix, such as a misconfiguration
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+// warmUp runs each step concurrently in the background, retrying failures with exponential
+// backoff until the step succeeds or ctx is cancelled. A permanent failure stops the server,
+// as it would have at an eager startup.
+func warmUp(ctx context.Context, steps []warmupStep) {
+	for _, step := range steps {
+		go func(step warmupStep) {
+			start := time.Now()
+			backoff := warmupInitialBackoff
+			for attempt := 1; ; attempt++ {
+				attemptCtx, cancel := context.WithTimeout(ctx, step.timeout)
+				err := step.run(attemptCtx)
+				cancel()
+
+				var permanent *permanentError
+				switch {
+				case err == nil:
+					fmt.Printf("Warmup: %s ready after %s\n", step.name, time.Since(start).Round(time.Millisecond))
+					return
+				case errors.As(err, &permanent):
+					fmt.Printf("Warmup: %s failed: %v\n", step.name, err)
+					os.Exit(1)
+				case ctx.Err() != nil:
+					return
+				}
+
+				fmt.Printf("Warning: Warmup of %s failed (attempt %d), retrying in %s: %v\n", step.name, attempt, backoff, err)
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(backoff):
+				}
+				if backoff *= 2; backoff > warmupMaxBackoff {
+					backoff = warmupMaxBackoff
+				}
+			}
+		}(step)
+	}
+}